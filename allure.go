@@ -0,0 +1,213 @@
+package fynetest
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Allure result statuses.
+const (
+	allurePassed  = "passed"
+	allureFailed  = "failed"
+	allureBroken  = "broken"
+	allureSkipped = "skipped"
+)
+
+// AllureResult is one test in the Allure results format.
+type AllureResult struct {
+	UUID          string             `json:"uuid"`
+	HistoryID     string             `json:"historyId"`
+	TestCaseID    string             `json:"testCaseId"`
+	FullName      string             `json:"fullName"`
+	Name          string             `json:"name"`
+	Description   string             `json:"description,omitempty"`
+	Status        string             `json:"status"`
+	StatusDetails *AllureDetails     `json:"statusDetails,omitempty"`
+	Stage         string             `json:"stage"`
+	Start         int64              `json:"start"`
+	Stop          int64              `json:"stop"`
+	Labels        []AllureLabel      `json:"labels"`
+	Parameters    []AllureLabel      `json:"parameters,omitempty"`
+	Attachments   []AllureAttachment `json:"attachments,omitempty"`
+}
+
+// AllureDetails explains the status of a result, such as a failure.
+type AllureDetails struct {
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+
+	// Known marks a known issue, such as an expected failure
+	Known bool `json:"known,omitempty"`
+}
+
+// AllureLabel is a name/value pair, used for labels and parameters.
+type AllureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// AllureAttachment is a file in the results directory attached to a result.
+type AllureAttachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+}
+
+// ExportAllure writes results to dir in the Allure results format: one
+// "<uuid>-result.json" per test, its screenshot, review images and crash dump
+// copied next to it as attachments, and an environment.properties file.
+// Point "allure generate" or an Allure server at dir. Mismatches against the
+// baseline are reported as failed and other errors as broken.
+func ExportAllure(results []Result, suiteName, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create Allure results directory: %w", err)
+	}
+
+	for _, result := range results {
+		allure, err := newAllureResult(result, suiteName, dir)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(allure, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, allure.UUID+"-result.json"), data, 0644); err != nil {
+			return err
+		}
+	}
+
+	env := fmt.Sprintf("fyne=%s\ngo=%s\nos=%s/%s\nrenderer.gl=%t\n",
+		orUnknown(FyneVersion()), runtime.Version(), runtime.GOOS, runtime.GOARCH, GLAvailable())
+	return os.WriteFile(filepath.Join(dir, "environment.properties"), []byte(env), 0644)
+}
+
+func newAllureResult(result Result, suiteName, dir string) (AllureResult, error) {
+	uuid, err := newUUID()
+	if err != nil {
+		return AllureResult{}, err
+	}
+
+	fullName := suiteName + "." + result.Test.Name
+	if result.Profile != "" {
+		fullName += "[" + result.Profile + "]"
+	}
+	start := result.Timestamp.UnixMilli()
+	allure := AllureResult{
+		UUID:        uuid,
+		HistoryID:   md5Hex(fullName),
+		TestCaseID:  md5Hex(suiteName + "." + result.Test.Name),
+		FullName:    fullName,
+		Name:        result.Test.Name,
+		Description: result.Test.Description,
+		Status:      allurePassed,
+		Stage:       "finished",
+		Start:       start,
+		Stop:        start + result.Duration.Milliseconds(),
+		Labels: []AllureLabel{
+			{Name: "framework", Value: "vfyne"},
+			{Name: "language", Value: "go"},
+			{Name: "suite", Value: suiteName},
+		},
+	}
+	for _, tag := range result.Test.Tags {
+		allure.Labels = append(allure.Labels, AllureLabel{Name: "tag", Value: tag})
+	}
+	if result.Profile != "" {
+		allure.Parameters = append(allure.Parameters, AllureLabel{Name: "profile", Value: result.Profile})
+	}
+	if renderer, ok := result.Metadata[MetadataRenderer].(string); ok {
+		allure.Parameters = append(allure.Parameters, AllureLabel{Name: "renderer", Value: renderer})
+	}
+
+	if result.Skipped {
+		allure.Status = allureSkipped
+		if result.SkipReason != "" {
+			allure.StatusDetails = &AllureDetails{Message: result.SkipReason}
+		}
+	}
+	if result.ExpectedFailure {
+		allure.StatusDetails = &AllureDetails{Known: true, Message: "Expected failure"}
+		if result.Error != nil {
+			allure.StatusDetails.Message += ": " + result.Error.Error()
+		}
+	}
+	if result.UnexpectedPass {
+		allure.StatusDetails = &AllureDetails{Message: "Expected to fail but passed; needs baseline review"}
+	}
+	if !result.Success {
+		allure.Status = allureBroken
+		if result.Diff != nil && !result.Diff.Match {
+			allure.Status = allureFailed
+		}
+		allure.StatusDetails = &AllureDetails{}
+		if result.Error != nil {
+			allure.StatusDetails.Message = result.Error.Error()
+		}
+		for _, change := range result.WidgetChanges {
+			allure.StatusDetails.Trace += change.String() + "\n"
+		}
+	}
+
+	attach := func(name, path, mime string) error {
+		if path == "" {
+			return nil
+		}
+		source := fmt.Sprintf("%s-%s-attachment%s", uuid, strings.ReplaceAll(strings.ToLower(name), " ", "-"), filepath.Ext(path))
+		if err := copyFile(path, filepath.Join(dir, source)); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to attach %s of %s: %w", name, result.Test.Name, err)
+		}
+		allure.Attachments = append(allure.Attachments, AllureAttachment{Name: name, Source: source, Type: mime})
+		return nil
+	}
+	if err := attach("Screenshot", result.ScreenshotPath, "image/png"); err != nil {
+		return allure, err
+	}
+	if assets := result.DiffAssets; assets != nil {
+		for _, asset := range []struct{ name, path string }{
+			{"Diff", assets.Diff}, {"Heatmap", assets.Heatmap}, {"Expected actual diff", assets.Composite},
+		} {
+			if err := attach(asset.name, asset.path, "image/png"); err != nil {
+				return allure, err
+			}
+		}
+	}
+	if err := attach("Crash dump", result.CrashDump, "text/plain"); err != nil {
+		return allure, err
+	}
+	if result.Output != "" {
+		source := uuid + "-output-attachment.txt"
+		if err := os.WriteFile(filepath.Join(dir, source), []byte(result.Output), 0644); err != nil {
+			return allure, err
+		}
+		allure.Attachments = append(allure.Attachments, AllureAttachment{Name: "Setup output", Source: source, Type: "text/plain"})
+	}
+	return allure, nil
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
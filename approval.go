@@ -0,0 +1,218 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrApprovalRequired is returned by ApplyStagedBaselines when staged
+// baselines lack the approvals their policy requires, and when a baseline
+// the policy covers is written without going through staging.
+var ErrApprovalRequired = errors.New("baseline changes need more approvals")
+
+// ApprovalPolicyName is the file in a baseline directory holding its
+// ApprovalPolicy. It is committed with the baselines, so changing the policy
+// goes through code review like the baselines themselves.
+const ApprovalPolicyName = "approval-policy.json"
+
+// Approval records one person signing off a staged baseline.
+type Approval struct {
+	By string    `json:"by"`
+	At time.Time `json:"at"`
+}
+
+// ApprovalPolicy decides how many distinct approvers a staged baseline needs
+// before ApplyStagedBaselines replaces the committed one. Baselines it covers
+// can only be written through staging.
+type ApprovalPolicy struct {
+	// Tags select the baselines the policy applies to; a baseline created by
+	// a test with any of them needs Approvers approvals
+	Tags []string `json:"tags"`
+
+	// Approvers is the number of distinct people who must approve
+	Approvers int `json:"approvers"`
+
+	// Reviewers are the people whose approvals count, by the identity
+	// ApproverIdentity returns. Approvals by anyone else are ignored, so a
+	// policy requiring approvals can't be met without reviewers.
+	Reviewers []string `json:"reviewers,omitempty"`
+}
+
+// DefaultApprovalPolicy requires two distinct approvers for baselines of
+// tests tagged "critical". Other baselines are applied without approvals.
+// It applies to baseline directories without an ApprovalPolicyName file,
+// and lists no reviewers, so critical baselines need a policy file naming
+// them.
+var DefaultApprovalPolicy = ApprovalPolicy{Tags: []string{"critical"}, Approvers: 2}
+
+// LoadApprovalPolicy reads the approval policy of a baseline directory, or
+// returns DefaultApprovalPolicy when it has none.
+func LoadApprovalPolicy(dir string) (ApprovalPolicy, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ApprovalPolicyName))
+	if os.IsNotExist(err) {
+		return DefaultApprovalPolicy, nil
+	}
+	if err != nil {
+		return ApprovalPolicy{}, err
+	}
+	var policy ApprovalPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return ApprovalPolicy{}, fmt.Errorf("invalid approval policy %s: %w", filepath.Join(dir, ApprovalPolicyName), err)
+	}
+	return policy, nil
+}
+
+// Save writes the policy to the baseline directory dir.
+func (p ApprovalPolicy) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ApprovalPolicyName), append(data, '\n'), 0644)
+}
+
+// Required returns the number of approvals the baseline needs.
+func (p ApprovalPolicy) Required(entry ManifestEntry) int {
+	for _, tag := range p.Tags {
+		if contains(entry.Tags, tag) {
+			return p.Approvers
+		}
+	}
+	return 0
+}
+
+// Approvals returns the distinct reviewers of the policy who approved the
+// baseline.
+func (p ApprovalPolicy) Approvals(entry ManifestEntry) []string {
+	approvals := make([]string, 0)
+	for _, approver := range entry.Approvers() {
+		if containsFold(p.Reviewers, approver) {
+			approvals = append(approvals, approver)
+		}
+	}
+	return approvals
+}
+
+// CheckBaselineWrite returns an error wrapping ErrApprovalRequired when the
+// approval policy of the baseline directory dir covers the baseline of a
+// test with tags, which may then only be replaced through staging and
+// ApplyStagedBaselines. Runner and the testing package call it before
+// writing a baseline with -update-snapshots.
+func CheckBaselineWrite(dir, test string, tags []string) error {
+	policy, err := LoadApprovalPolicy(dir)
+	if err != nil {
+		return err
+	}
+	if required := policy.Required(ManifestEntry{Test: test, Tags: tags}); required > 0 {
+		return fmt.Errorf("%w: the baseline of %s needs %d approvals; stage it with \"fynetest rebaseline -preview\"",
+			ErrApprovalRequired, test, required)
+	}
+	return nil
+}
+
+// ApproverIdentity returns who is approving: the email address git is
+// configured with, the identity reviewers are listed under in the policy.
+func ApproverIdentity() (string, error) {
+	out, err := exec.Command("git", "config", "user.email").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read git user.email: %w", err)
+	}
+	identity := strings.TrimSpace(string(out))
+	if identity == "" {
+		return "", fmt.Errorf("git user.email is not set")
+	}
+	return identity, nil
+}
+
+// Approvers returns the distinct people who approved the baseline.
+func (e ManifestEntry) Approvers() []string {
+	seen := make(map[string]bool)
+	approvers := make([]string, 0, len(e.Approvals))
+	for _, approval := range e.Approvals {
+		key := strings.ToLower(approval.By)
+		if !seen[key] {
+			seen[key] = true
+			approvers = append(approvers, approval.By)
+		}
+	}
+	return approvers
+}
+
+// ApproveStagedBaselines records approver's sign-off on the baselines staged
+// in stagingDir for baselineDir, or only on those created by the named
+// tests, and returns the file names approved. The approver must be one of
+// the reviewers of baselineDir's approval policy. Approving twice as the
+// same person doesn't count twice. Restaging discards every approval.
+func ApproveStagedBaselines(stagingDir, baselineDir, approver string, tests ...string) ([]string, error) {
+	approver = strings.TrimSpace(approver)
+	if approver == "" {
+		return nil, fmt.Errorf("approver name is empty")
+	}
+	policy, err := LoadApprovalPolicy(baselineDir)
+	if err != nil {
+		return nil, err
+	}
+	if !containsFold(policy.Reviewers, approver) {
+		return nil, fmt.Errorf("%s is not a reviewer in %s", approver, filepath.Join(baselineDir, ApprovalPolicyName))
+	}
+
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	staged, err := LoadManifest(stagingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	approved := make([]string, 0)
+	for name, entry := range staged.Baselines {
+		if len(tests) > 0 && !contains(tests, entry.Test) {
+			continue
+		}
+		if err := staged.Check(stagingDir, name); err != nil {
+			return nil, err
+		}
+		if !containsFold(entry.Approvers(), approver) {
+			entry.Approvals = append(entry.Approvals, Approval{By: approver, At: time.Now().UTC()})
+			staged.Baselines[name] = entry
+		}
+		approved = append(approved, name)
+	}
+	if len(approved) == 0 {
+		return nil, fmt.Errorf("no matching staged baselines in %s", stagingDir)
+	}
+	sort.Strings(approved)
+	return approved, staged.Save(stagingDir)
+}
+
+// pendingApprovals lists the staged baselines that lack approvals by
+// reviewers under policy, with the approvals they still need.
+func pendingApprovals(staged *Manifest, policy ApprovalPolicy) []string {
+	pending := make([]string, 0)
+	for name, entry := range staged.Baselines {
+		if missing := policy.Required(entry) - len(policy.Approvals(entry)); missing > 0 {
+			pending = append(pending, fmt.Sprintf("%s (%s, %d more)", name, entry.Test, missing))
+		}
+	}
+	sort.Strings(pending)
+	return pending
+}
+
+func containsFold(items []string, item string) bool {
+	for _, i := range items {
+		if strings.EqualFold(i, item) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package fynetest
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestCriticalBaselinesNeedTwoApprovers(t *testing.T) {
+	r := snapshotRunner(t)
+	policy := ApprovalPolicy{Tags: []string{"critical"}, Approvers: 2, Reviewers: []string{"alice@example.com", "bob@example.com"}}
+	if err := policy.Save(r.BaselineDir); err != nil {
+		t.Fatal(err)
+	}
+	text := "Pay now"
+	test := labelTest("Checkout", &text, "critical")
+
+	// Updating it directly bypasses the approvals
+	r.Update = SnapshotUpdate{All: true}
+	if result := r.RunTest(test); !errors.Is(result.Error, ErrApprovalRequired) {
+		t.Fatalf("direct update: error = %v, want ErrApprovalRequired", result.Error)
+	}
+	r.Update = SnapshotUpdate{}
+
+	staging := filepath.Join(t.TempDir(), "staging")
+	if _, _, err := r.StageBaselines([]Test{test}, staging); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyStagedBaselines(staging, r.BaselineDir); !errors.Is(err, ErrApprovalRequired) {
+		t.Fatalf("applying without approvals: error = %v, want ErrApprovalRequired", err)
+	}
+	if _, err := ApproveStagedBaselines(staging, r.BaselineDir, "mallory@example.com"); err == nil {
+		t.Error("someone who isn't a reviewer approved")
+	}
+
+	// Approving twice counts once
+	for _, approver := range []string{"alice@example.com", "Alice@Example.com"} {
+		if _, err := ApproveStagedBaselines(staging, r.BaselineDir, approver); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := ApplyStagedBaselines(staging, r.BaselineDir); !errors.Is(err, ErrApprovalRequired) {
+		t.Fatalf("applying with one approver: error = %v, want ErrApprovalRequired", err)
+	}
+
+	if _, err := ApproveStagedBaselines(staging, r.BaselineDir, "bob@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyStagedBaselines(staging, r.BaselineDir); err != nil {
+		t.Fatalf("applying with two approvers: %v", err)
+	}
+
+	manifest, err := LoadManifest(r.BaselineDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approvers := manifest.Baselines["Checkout.png"].Approvers()
+	if len(approvers) != 2 || approvers[0] != "alice@example.com" || approvers[1] != "bob@example.com" {
+		t.Errorf("approval trail = %v, want alice and bob", approvers)
+	}
+}
+
+func TestUncoveredBaselinesNeedNoApproval(t *testing.T) {
+	dir := t.TempDir()
+	if err := CheckBaselineWrite(dir, "Settings", []string{"forms"}); err != nil {
+		t.Errorf("default policy: CheckBaselineWrite() = %v, want nil for an untagged baseline", err)
+	}
+	if err := CheckBaselineWrite(dir, "Checkout", []string{"critical"}); !errors.Is(err, ErrApprovalRequired) {
+		t.Errorf("default policy: CheckBaselineWrite() = %v, want ErrApprovalRequired for a critical baseline", err)
+	}
+}
@@ -0,0 +1,164 @@
+package fynetest
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ArchiveExt is the extension of compressed run directories.
+const ArchiveExt = ".zip"
+
+// runTimestamp is the layout of the timestamped run directory names.
+const runTimestamp = "20060102-150405"
+
+// ArchiveRun compresses the run directory into runDir+ArchiveExt and removes
+// the directory. The archive is a zip file whose entries are compressed with
+// zstd (zip method 93, which 7-Zip and recent libarchive read), so reports
+// stay addressable file by file. Paths inside the archive are relative to
+// the run, so the report keeps working when served with ArchiveHandler. PNG
+// files are stored as they are, since they are compressed already.
+func ArchiveRun(runDir string) (string, error) {
+	runDir = filepath.Clean(runDir)
+	archivePath := runDir + ArchiveExt
+	tmpPath := archivePath + ".tmp"
+
+	if err := writeArchive(runDir, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to archive %s: %w", runDir, err)
+	}
+	if err := os.Rename(tmpPath, archivePath); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := os.RemoveAll(runDir); err != nil {
+		return archivePath, fmt.Errorf("archived %s but failed to remove it: %w", runDir, err)
+	}
+	return archivePath, nil
+}
+
+func writeArchive(runDir, archivePath string) error {
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	zw.RegisterCompressor(zstd.ZipMethodWinZip, zstd.ZipCompressor())
+	err = filepath.WalkDir(runDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(runDir, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		header.Method = zstd.ZipMethodWinZip
+		if strings.EqualFold(filepath.Ext(path), ".png") {
+			header.Method = zip.Store
+		}
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(w, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// ArchiveRuns archives the timestamped run directories in outputDir that are
+// older than age, judged by the timestamp in their name, and returns the
+// archives written. An age of 0 archives every run.
+func ArchiveRuns(outputDir string, age time.Duration) ([]string, error) {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-age)
+	archives := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		started, err := time.ParseInLocation(runTimestamp, entry.Name(), time.Local)
+		if err != nil || started.After(cutoff) {
+			continue
+		}
+
+		archivePath, err := ArchiveRun(filepath.Join(outputDir, entry.Name()))
+		if err != nil {
+			return archives, err
+		}
+		archives = append(archives, archivePath)
+	}
+	return archives, nil
+}
+
+// ArchiveHandler serves a run archive written by ArchiveRun over HTTP, as
+// AssetHandler does for run directories. Only the review images stored in
+// the archive are available; none are rendered on demand. Archives written
+// with deflate, before runs were compressed with zstd, are read too. Close
+// the returned closer once the handler is no longer used.
+func ArchiveHandler(archivePath string) (http.Handler, io.Closer, error) {
+	archive, err := openArchive(archivePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	return http.FileServer(http.FS(archive)), archive, nil
+}
+
+// openArchive opens a run archive, able to read both its zstd and deflated
+// entries.
+func openArchive(archivePath string) (*zip.ReadCloser, error) {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	archive.RegisterDecompressor(zstd.ZipMethodWinZip, zstd.ZipDecompressor())
+	return archive, nil
+}
+
+// RunHandler serves a run directory, or a run archive when path ends with
+// ArchiveExt.
+func (r *Runner) RunHandler(path string) (http.Handler, io.Closer, error) {
+	if strings.HasSuffix(path, ArchiveExt) {
+		return ArchiveHandler(path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil, err
+	}
+	return r.AssetHandler(path), io.NopCloser(nil), nil
+}
@@ -0,0 +1,163 @@
+package fynetest
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2/theme"
+)
+
+// Review asset kinds, written next to a capture as "<screenshot>_<kind>.png".
+const (
+	// AssetDiff is the baseline with differing pixels highlighted
+	AssetDiff = "diff"
+
+	// AssetHeatmap shows every change colored by severity
+	AssetHeatmap = "heatmap"
+
+	// AssetComposite lays expected, actual and diff out side by side
+	AssetComposite = "composite"
+)
+
+// DiffAssets lists the review images written for a failed baseline
+// comparison. Diff and Heatmap are empty when the sizes differ.
+type DiffAssets struct {
+	Diff      string `json:"diff,omitempty"`
+	Heatmap   string `json:"heatmap,omitempty"`
+	Composite string `json:"composite"`
+}
+
+// AssetPath returns the path of the review asset of the given kind for the
+// capture saved at screenshotPath.
+func AssetPath(screenshotPath, kind string) string {
+	return strings.TrimSuffix(screenshotPath, ".png") + "_" + kind + ".png"
+}
+
+// renderAsset returns the review image of the given kind, or nil when it
+// cannot be produced for images of different sizes.
+func renderAsset(kind string, expected, actual image.Image, tol Tolerance) image.Image {
+	switch kind {
+	case AssetDiff:
+		return DiffImage(expected, actual, tol, theme.ErrorColor())
+	case AssetHeatmap:
+		return HeatmapImage(expected, actual)
+	case AssetComposite:
+		diff := DiffImage(expected, actual, tol, theme.ErrorColor())
+		defer ReleaseImage(diff)
+		return CompositeImage(expected, actual, diff)
+	}
+	return nil
+}
+
+// writeDiffAssets renders the review images of a failed baseline comparison
+// next to the capture. Only failures get them during a run; passing captures
+// are rendered on demand by AssetHandler.
+func (r *Runner) writeDiffAssets(test Test, screenshotPath string, img image.Image, ignored []image.Rectangle) (*DiffAssets, error) {
+	expected, err := decodePNGFile(r.BaselinePath(test))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline: %w", err)
+	}
+	if expected.Bounds().Size() == img.Bounds().Size() && len(ignored) > 0 {
+		expected, img = maskedPair(expected, img, ignored)
+		defer ReleaseImage(expected)
+		defer ReleaseImage(img)
+	}
+
+	assets := &DiffAssets{}
+	for _, kind := range []string{AssetDiff, AssetHeatmap, AssetComposite} {
+		asset := renderAsset(kind, expected, img, r.ToleranceFor(test))
+		if asset == nil {
+			continue
+		}
+
+		path := AssetPath(screenshotPath, kind)
+		err := r.saveImage(asset, path)
+		ReleaseImage(asset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save %s image: %w", kind, err)
+		}
+
+		switch kind {
+		case AssetDiff:
+			assets.Diff = path
+		case AssetHeatmap:
+			assets.Heatmap = path
+		case AssetComposite:
+			assets.Composite = path
+		}
+	}
+	return assets, nil
+}
+
+func decodePNGFile(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return png.Decode(file)
+}
+
+// assetName matches "<test>_<timestamp>_<kind>.png" as produced by AssetPath
+// for the captures saved by the runner.
+var assetName = regexp.MustCompile(`^(.+)_(\d{8}-\d{6})_(diff|heatmap|composite)\.png$`)
+
+// AssetHandler serves a run directory, such as SuiteResult.OutputDir, over
+// HTTP. Review assets that were not written during the run are rendered from
+// the capture and its baseline on first request and cached on disk, so
+// passing runs stay fast while every compared test can still be inspected.
+// Ignore regions are not applied to assets rendered on demand.
+func (r *Runner) AssetHandler(runDir string) http.Handler {
+	files := http.FileServer(http.Dir(runDir))
+	var mu sync.Mutex
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name := path.Clean("/" + req.URL.Path)
+		if m := assetName.FindStringSubmatch(path.Base(name)); m != nil {
+			assetPath := filepath.Join(runDir, filepath.FromSlash(name))
+
+			mu.Lock()
+			err := r.renderAssetFile(assetPath, m[1], m[3])
+			mu.Unlock()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+		}
+		files.ServeHTTP(w, req)
+	})
+}
+
+// renderAssetFile writes the asset of the given kind at assetPath unless it
+// already exists. The capture sits next to it; the baseline is found by the
+// sanitized test name.
+func (r *Runner) renderAssetFile(assetPath, testName, kind string) error {
+	if _, err := os.Stat(assetPath); err == nil {
+		return nil
+	}
+
+	actual, err := decodePNGFile(strings.TrimSuffix(assetPath, "_"+kind+".png") + ".png")
+	if err != nil {
+		return fmt.Errorf("capture not found: %w", err)
+	}
+	expected, err := decodePNGFile(filepath.Join(r.BaselineDir, testName+".png"))
+	if err != nil {
+		return fmt.Errorf("baseline not found: %w", err)
+	}
+
+	asset := renderAsset(kind, expected, actual, r.Tolerance)
+	if asset == nil {
+		return fmt.Errorf("capture and baseline sizes differ")
+	}
+	defer ReleaseImage(asset)
+	return r.saveImage(asset, assetPath)
+}
@@ -0,0 +1,222 @@
+package fynetest
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BaselinePath returns the path of the baseline capture for a test.
+func (r *Runner) BaselinePath(test Test) string {
+	return filepath.Join(r.BaselineDir, r.BaselineName(test)+".png")
+}
+
+// updateBaseline replaces the baseline of test with img, storing the widget
+// tree next to it for structural diffs of later mismatches, records it in
+// the manifest and stores it in BaselineSource when that accepts baselines.
+func (r *Runner) updateBaseline(test Test, img image.Image, tree *WidgetNode) error {
+	if !r.staging {
+		if err := CheckBaselineWrite(r.BaselineDir, test.Name, test.Tags); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(r.BaselineDir, 0755); err != nil {
+		return err
+	}
+	path := r.BaselinePath(test)
+	
+	// Keep the version being replaced, unless the capture is identical
+	if r.HistoryDepth > 0 {
+		if digest, ok := ReadBaselineDigest(path); !ok || digest != ImageDigest(img) {
+			if _, err := ArchiveBaseline(path, r.HistoryDepth); err != nil {
+				return fmt.Errorf("failed to archive previous baseline: %w", err)
+			}
+		}
+	}
+	
+	if err := SaveBaseline(path, img); err != nil {
+		return err
+	}
+	if tree != nil {
+		if err := SaveWidgetTree(path+TreeSuffix, tree); err != nil {
+			return err
+		}
+	}
+	if err := r.recordBaseline(path, test); err != nil {
+		return err
+	}
+	
+	// Publish it where the baselines are fetched from
+	if sink, ok := r.BaselineSource.(BaselineSink); ok {
+		return sink.Store(filepath.Base(path), path)
+	}
+	return nil
+}
+
+// widgetChanges diffs the widget tree stored with the baseline of test
+// against tree. It returns nil when the baseline has no stored tree.
+func (r *Runner) widgetChanges(test Test, tree *WidgetNode) []WidgetChange {
+	expected, err := LoadWidgetTree(r.BaselinePath(test) + TreeSuffix)
+	if err != nil || tree == nil {
+		return nil
+	}
+	return DiffWidgetTrees(expected, tree)
+}
+
+// compareBaseline compares img with the stored baseline of test using the
+// runner's Comparer or the test's tolerance (see ToleranceFor), masking the
+// ignored rectangles in both. It returns a nil DiffResult when no baseline
+// directory is configured or the test has no baseline yet, and an error when
+// the capture does not match or the baseline fails manifest verification.
+func (r *Runner) compareBaseline(test Test, img image.Image, ignored []image.Rectangle, weights []WeightedRect, focus []FocusRect) (*DiffResult, error) {
+	if r.BaselineDir == "" {
+		return nil, nil
+	}
+	
+	path := r.BaselinePath(test)
+	
+	if err := r.fetchBaseline(test); err != nil {
+		return nil, err
+	}
+	
+	// A baseline changed outside vfyne can't be trusted
+	if err := r.baselineProblem(path); err != nil {
+		return nil, err
+	}
+	
+	// A matching digest proves equality without decoding the baseline
+	if digest, ok := ReadBaselineDigest(path); ok && digest == ImageDigest(img) {
+		return &DiffResult{Match: true, TotalPixels: img.Bounds().Dx() * img.Bounds().Dy()}, nil
+	}
+	
+	// Focus regions are compared on their own, with their tolerance
+	rest := ignored
+	if len(focus) > 0 {
+		rest = append([]image.Rectangle(nil), ignored...)
+		for _, f := range focus {
+			rest = append(rest, f.Rect)
+		}
+	}
+	
+	// The baseline is decoded at most once, by whichever comparisons below
+	// need all of it
+	var expected image.Image
+	baseline := func() (image.Image, error) {
+		if expected == nil {
+			decoded, err := decodePNGFile(path)
+			if err != nil {
+				return nil, err
+			}
+			expected = decoded
+		}
+		return expected, nil
+	}
+	
+	// A custom comparer needs the whole baseline, and so do focus regions,
+	// whose counts are added to complete ones rather than a Partial walk
+	var diff DiffResult
+	var err error
+	if r.Comparer != nil || len(focus) > 0 {
+		var comparer ImageComparer = r.ToleranceFor(test)
+		if r.Comparer != nil {
+			comparer = r.Comparer
+		}
+		if _, err = baseline(); err == nil {
+			diff, err = compareImage(expected, img, rest, comparer)
+		}
+	} else {
+		diff, err = CompareFile(path, img, r.ToleranceFor(test), rest)
+	}
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare baseline: %w", err)
+	}
+	
+	var focusErr error
+	if len(focus) > 0 && !diff.SizeMismatch {
+		focusErr = compareFocus(expected, img, ignored, focus, &diff)
+	}
+	
+	// Changes within the budget are kept for review but don't fail the
+	// test, unless they touch a focus region
+	if !diff.Match && !diff.SizeMismatch {
+		if _, err := baseline(); err != nil {
+			return &diff, fmt.Errorf("failed to load baseline: %w", err)
+		}
+		severity := r.severity(test, expected, img, ignored, weights)
+		diff.Severity = severity
+		if budget := r.ChangeBudget(test); budget > 0 && severity <= budget && focusErr == nil {
+			diff.WithinBudget = true
+			return &diff, nil
+		}
+	}
+	if focusErr != nil {
+		return &diff, focusErr
+	}
+	
+	switch {
+	case diff.SizeMismatch:
+		return &diff, fmt.Errorf("capture size %dx%d differs from baseline", img.Bounds().Dx(), img.Bounds().Dy())
+	case diff.Partial:
+		return &diff, fmt.Errorf("capture differs from baseline: over %.2f%% of pixels changed (severity %.2f)", r.ToleranceFor(test).MaxDiffPercent, diff.Severity)
+	case !diff.Match:
+		return &diff, fmt.Errorf("capture differs from baseline: %.2f%% of pixels changed (severity %.2f)", diff.DiffPercent, diff.Severity)
+	}
+	return &diff, nil
+}
+
+// DigestSuffix is appended to a baseline path to name its digest file.
+const DigestSuffix = ".sha256"
+
+// SaveBaseline writes img as a PNG baseline at path together with a digest
+// file holding its ImageDigest and a metadata file recording the fyne
+// version. Comparisons against baselines with a digest file skip decoding
+// and the pixel walk when the capture is identical.
+func SaveBaseline(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	
+	if err := EncodePNG(file, img); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	
+	// The checksum of the PNG ties the digest to the file it was made for
+	checksum, _, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+DigestSuffix, []byte(ImageDigest(img)+" "+checksum+"\n"), 0644); err != nil {
+		return err
+	}
+	return writeBaselineMeta(path)
+}
+
+// ReadBaselineDigest returns the digest stored next to a baseline. It
+// reports false when there is no digest file or it was written for other
+// PNG bytes, e.g. because the baseline was replaced by hand or by a
+// checkout; modification times aren't trusted for that.
+func ReadBaselineDigest(path string) (string, bool) {
+	data, err := os.ReadFile(path + DigestSuffix)
+	if err != nil {
+		return "", false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return "", false
+	}
+	checksum, _, err := fileChecksum(path)
+	if err != nil || checksum != fields[1] {
+		return "", false
+	}
+	return fields[0], true
+}
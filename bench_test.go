@@ -0,0 +1,179 @@
+package fynetest
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// benchImages returns an 800x600 capture and a copy with a small changed
+// area, the common shape of a failing snapshot.
+func benchImages() (*image.RGBA, *image.RGBA) {
+	expected := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	for i := range expected.Pix {
+		expected.Pix[i] = byte(i / 7)
+		if i%4 == 3 {
+			expected.Pix[i] = 0xff
+		}
+	}
+	actual := image.NewRGBA(expected.Rect)
+	copy(actual.Pix, expected.Pix)
+	for y := 280; y < 300; y++ {
+		for x := 380; x < 420; x++ {
+			actual.Pix[actual.PixOffset(x, y)] ^= 0x40
+		}
+	}
+	return expected, actual
+}
+
+func BenchmarkCapture(b *testing.B) {
+	r := NewRunner()
+	r.DefaultWaitDuration = 0
+	defer r.Cleanup()
+
+	test := NewTest("form").
+		WithSize(400, 300).
+		WithSetup(func() fyne.CanvasObject {
+			return container.NewVBox(
+				widget.NewLabel("Name"),
+				widget.NewEntry(),
+				widget.NewCheck("Subscribe", nil),
+				widget.NewButton("Save", nil),
+			)
+		}).
+		MustBuild()
+	app := r.ensureApp()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.capture(app, test); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompareImages(b *testing.B) {
+	expected, actual := benchImages()
+
+	b.Run("identical", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			CompareImages(expected, expected, ExactTolerance)
+		}
+	})
+	b.Run("pixel", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			CompareImages(expected, actual, ExactTolerance)
+		}
+	})
+	b.Run("perceptual", func(b *testing.B) {
+		tol := Tolerance{Mode: ComparePerceptual}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			CompareImages(expected, actual, tol)
+		}
+	})
+	b.Run("antialiasing", func(b *testing.B) {
+		tol := Tolerance{IgnoreAntialiasing: true}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			CompareImages(expected, actual, tol)
+		}
+	})
+	b.Run("structural", func(b *testing.B) {
+		tol := Tolerance{Mode: CompareStructural}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			CompareImages(expected, actual, tol)
+		}
+	})
+}
+
+func BenchmarkCompareFile(b *testing.B) {
+	expected, actual := benchImages()
+	path := filepath.Join(b.TempDir(), "baseline.png")
+	if err := SaveBaseline(path, expected); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("match", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := CompareFile(path, expected, ExactTolerance, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("early-exit", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := CompareFile(path, actual, ExactTolerance, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkDiffImage(b *testing.B) {
+	expected, actual := benchImages()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ReleaseImage(DiffImage(expected, actual, ExactTolerance, image.White))
+	}
+}
+
+func BenchmarkEncodePNG(b *testing.B) {
+	expected, _ := benchImages()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := EncodePNG(io.Discard, expected); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenerateReport(b *testing.B) {
+	dir := b.TempDir()
+	results := make([]Result, 200)
+	for i := range results {
+		results[i] = Result{
+			Test:           Test{Name: fmt.Sprintf("test_%03d", i), Tags: []string{"bench"}},
+			Success:        i%10 != 0,
+			ScreenshotPath: filepath.Join(dir, "shot.png"),
+			ImageSize:      fyne.NewSize(800, 600),
+			Duration:       120 * time.Millisecond,
+			Timestamp:      time.Now(),
+			Metadata:       map[string]interface{}{"theme": "light"},
+		}
+	}
+	generator := NewReportGenerator()
+	generator.Output = io.Discard
+
+	b.Run("html", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := generator.GenerateHTMLReport(results, filepath.Join(dir, "report.html")); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := generator.GenerateJSONReport(results, filepath.Join(dir, "results.json")); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
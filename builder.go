@@ -1,10 +1,15 @@
 package fynetest
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"image"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
 	"time"
@@ -18,6 +23,9 @@ type Suite struct {
 	tests  []Test
 	runner *Runner
 	config SuiteConfig
+	
+	// serveAddr is the -serve address, set when the report is served after the run
+	serveAddr string
 }
 
 // SuiteConfig contains configuration options for a test suite.
@@ -34,6 +42,13 @@ type SuiteConfig struct {
 	// DefaultSize for test windows (can be overridden per test)
 	DefaultSize fyne.Size
 	
+	// Renderer is the render path of tests that don't select one (-renderer)
+	Renderer RenderPath
+	
+	// MaxCaptureSize in pixels; larger windows fail instead of being clamped
+	// (default: DefaultMaxCaptureSize)
+	MaxCaptureSize image.Point
+	
 	// Parallel enables concurrent test execution
 	Parallel bool
 	
@@ -48,6 +63,134 @@ type SuiteConfig struct {
 	
 	// ReportTitle for the HTML report
 	ReportTitle string
+	
+	// MarkdownReport also writes summary.md for pull-request comments (-markdown)
+	MarkdownReport bool
+	
+	// CSVReport also writes summary.csv, one row per test for spreadsheets
+	// (-csv)
+	CSVReport bool
+	
+	// IncrementalReport rewrites the report as tests finish, so the results
+	// so far survive a crash or an interrupted run (-incremental)
+	IncrementalReport bool
+	
+	// EmbedImages inlines the images into index.html, making it a single
+	// self-contained file (-embed-images)
+	EmbedImages bool
+	
+	// ReportGroupBy groups the tests of the HTML report, e.g. GroupByTag (-group-by)
+	ReportGroupBy string
+	
+	// ReportTemplate is html/template text overriding blocks of the HTML
+	// report, or all of it (see ReportGenerator.Template; -report-template)
+	ReportTemplate string
+	
+	// ThumbnailMaxWidth is the width of the thumbnails the HTML report shows
+	// for wider captures (0: DefaultThumbnailMaxWidth; negative: none;
+	// -thumbnail-width)
+	ThumbnailMaxWidth int
+	
+	// ImageBaseURL is where the run directory is published; summary.md links
+	// images under it instead of relative paths (-artifact-url)
+	ImageBaseURL string
+	
+	// AllureDir receives Allure result files after each run (-allure)
+	AllureDir string
+	
+	// SARIFPath receives a SARIF log of the failed tests after each run,
+	// for code scanning (-sarif)
+	SARIFPath string
+	
+	// CompareWith is a published run, such as the latest run of the main
+	// branch, whose captures each run is compared with (-compare-with); see
+	// FetchRun for the accepted locations
+	CompareWith string
+	
+	// BaselineDir holds reference captures to compare against (empty disables comparison)
+	BaselineDir string
+	
+	// BaselineSource fetches baselines kept outside the repository into BaselineDir (optional, -baseline-url)
+	BaselineSource BaselineSource
+	
+	// Storage keeps baselines and uploaded runs in a bucket (optional, -storage).
+	// Baselines are cached in BaselineDir unless BaselineSource is also set.
+	Storage Storage
+	
+	// Upload tunes how runs are uploaded to Storage (-upload-workers, -upload-rate)
+	Upload UploadOptions
+	
+	// Tolerance for baseline comparisons (default: exact match)
+	Tolerance Tolerance
+	
+	// Comparer replaces Tolerance with custom comparison logic (optional)
+	Comparer ImageComparer
+	
+	// Describer writes a description of every captured screen, shown as a
+	// draft caption in the report (-describe)
+	Describer ScreenDescriber
+	
+	// Update selects baselines to rewrite from fresh captures (-update-snapshots, -update-tag)
+	Update SnapshotUpdate
+	
+	// HistoryDepth keeps this many previous versions of updated baselines (-history)
+	HistoryDepth int
+	
+	// VariantBaselines keeps one baseline per theme, size and scale of a test (-variant-baselines)
+	VariantBaselines bool
+	
+	// Output receives all progress and summary output (default: os.Stdout)
+	Output io.Writer
+	
+	// Profiles defines named runs (e.g. "smoke@light") selectable with -profile
+	Profiles []Profile
+	
+	// PerfBudget fails a CLI run when any test's Overhead exceeds it (0 disables)
+	PerfBudget time.Duration
+	
+	// SetupThreshold warns about tests whose Setup takes longer (0 disables)
+	SetupThreshold time.Duration
+	
+	// AIExport writes a <screenshot>.ai.json next to each capture, with the
+	// semantic roles of its widgets and a summary for LLM agents
+	AIExport bool
+	
+	// IsolateApps renders each test in a fresh app, so themes and settings
+	// can't leak from one test into the next (-isolate-apps)
+	IsolateApps bool
+	
+	// DefaultTimeout fails tests without their own Timeout that take longer
+	// to set up, render and capture, and moves on to the next (0 disables).
+	// See Runner.DefaultTimeout for what a timeout can't stop and how the
+	// tests after it are protected.
+	DefaultTimeout time.Duration
+	
+	// Retries runs failed tests again up to this many times; tests that pass
+	// on a retry are reported as flaky (-retries)
+	Retries int
+	
+	// ChangeBudgets maps tags to the severity up to which a capture of their
+	// tests may differ from the baseline and still pass; "" applies to all
+	// tests (-change-budget)
+	ChangeBudgets map[string]float64
+	
+	// Orphans handles baselines no registered test produces (-orphans)
+	Orphans OrphanPolicy
+	
+	// BeforeSuite is called before a run starts; an error aborts the run
+	BeforeSuite func() error
+	
+	// AfterSuite is called with the result of every run, once its reports
+	// are written
+	AfterSuite func(SuiteResult)
+	
+	// BeforeEach and AfterEach are called around every test attempt, as
+	// Runner.BeforeEach and Runner.AfterEach are
+	BeforeEach func(Test) error
+	AfterEach  func(Result)
+	
+	// ArchiveAfter compresses run directories older than this after each CLI run (0 disables)
+	ArchiveAfter time.Duration
 }
 
 // NewSuite creates a new test suite with default configuration.
@@ -81,20 +224,48 @@ func NewSuiteWithConfig(config SuiteConfig) *Suite {
 	suite.runner.OutputDir = config.OutputDir
 	suite.runner.DefaultTheme = config.DefaultTheme
 	suite.runner.DefaultSize = config.DefaultSize
+	suite.runner.MaxCaptureSize = config.MaxCaptureSize
+	suite.runner.Renderer = config.Renderer
 	suite.runner.Verbose = config.Verbose
+	suite.runner.BaselineDir = config.BaselineDir
+	suite.runner.BaselineSource = config.BaselineSource
+	suite.runner.Tolerance = config.Tolerance
+	suite.runner.Comparer = config.Comparer
+	suite.runner.BeforeEach = config.BeforeEach
+	suite.runner.AfterEach = config.AfterEach
+	suite.runner.Describer = config.Describer
+	suite.runner.Update = config.Update
+	suite.runner.HistoryDepth = config.HistoryDepth
+	suite.runner.VariantBaselines = config.VariantBaselines
+	suite.runner.SetupThreshold = config.SetupThreshold
+	suite.runner.DefaultTimeout = config.DefaultTimeout
+	suite.runner.AIExport = config.AIExport
+	suite.runner.IsolateApps = config.IsolateApps
+	suite.runner.Retries = config.Retries
+	suite.runner.ChangeBudgets = config.ChangeBudgets
+	suite.runner.Output = config.Output
 	
 	return suite
 }
 
 // Add adds a single test to the suite.
 func (s *Suite) Add(test Test) *Suite {
+	if test.SourceFile == "" {
+		_, test.SourceFile, test.SourceLine, _ = runtime.Caller(1)
+	}
 	s.tests = append(s.tests, test)
 	return s
 }
 
 // AddTests adds multiple tests to the suite.
 func (s *Suite) AddTests(tests ...Test) *Suite {
-	s.tests = append(s.tests, tests...)
+	_, file, line, _ := runtime.Caller(1)
+	for _, test := range tests {
+		if test.SourceFile == "" {
+			test.SourceFile, test.SourceLine = file, line
+		}
+		s.tests = append(s.tests, test)
+	}
 	return s
 }
 
@@ -115,7 +286,26 @@ func (s *Suite) WithConfig(fn func(*SuiteConfig)) *Suite {
 	s.runner.OutputDir = s.config.OutputDir
 	s.runner.DefaultTheme = s.config.DefaultTheme
 	s.runner.DefaultSize = s.config.DefaultSize
+	s.runner.MaxCaptureSize = s.config.MaxCaptureSize
+	s.runner.Renderer = s.config.Renderer
 	s.runner.Verbose = s.config.Verbose
+	s.runner.BaselineDir = s.config.BaselineDir
+	s.runner.BaselineSource = s.config.BaselineSource
+	s.runner.Tolerance = s.config.Tolerance
+	s.runner.Comparer = s.config.Comparer
+	s.runner.BeforeEach = s.config.BeforeEach
+	s.runner.AfterEach = s.config.AfterEach
+	s.runner.Describer = s.config.Describer
+	s.runner.Update = s.config.Update
+	s.runner.HistoryDepth = s.config.HistoryDepth
+	s.runner.VariantBaselines = s.config.VariantBaselines
+	s.runner.SetupThreshold = s.config.SetupThreshold
+	s.runner.DefaultTimeout = s.config.DefaultTimeout
+	s.runner.AIExport = s.config.AIExport
+	s.runner.IsolateApps = s.config.IsolateApps
+	s.runner.Retries = s.config.Retries
+	s.runner.ChangeBudgets = s.config.ChangeBudgets
+	s.runner.Output = s.config.Output
 	
 	return s
 }
@@ -168,17 +358,20 @@ func (s *Suite) Run() (SuiteResult, error) {
 
 // RunTests executes specific tests and returns the results.
 func (s *Suite) RunTests(tests []Test) (SuiteResult, error) {
+	if err := s.beforeSuite(); err != nil {
+		return SuiteResult{}, err
+	}
 	startTime := time.Now()
+	s.runner.emit(Event{Type: EventSuiteStart, Suite: s.config.Name, Total: len(tests)})
 	
 	// Create timestamped output directory
-	var results []Result
-	var outputDir string
-	
-	if s.config.Parallel && len(tests) > 1 {
-		results, outputDir = s.runner.RunTestsWithTimestamp(tests)
-	} else {
-		results, outputDir = s.runner.RunTestsWithTimestamp(tests)
-	}
+	outputDir := filepath.Join(s.runner.OutputDir, startTime.Format("20060102-150405"))
+	originalOutputDir := s.runner.OutputDir
+	s.runner.OutputDir = outputDir
+	stop := s.startIncrementalReport(outputDir)
+	results := s.runner.RunTests(tests)
+	stop()
+	s.runner.OutputDir = originalOutputDir
 	
 	// Create suite result
 	suiteResult := SuiteResult{
@@ -188,38 +381,191 @@ func (s *Suite) RunTests(tests []Test) (SuiteResult, error) {
 		EndTime:   time.Now(),
 		OutputDir: outputDir,
 	}
+	defer func() { s.afterSuite(suiteResult) }()
+	
+	if s.config.CompareWith != "" {
+		s.compareWithReference(&suiteResult)
+	}
 	
 	// Generate report if enabled
-	if s.config.GenerateReport {
-		reportPath := filepath.Join(outputDir, "index.html")
-		reporter := NewReportGenerator()
-		reporter.Title = s.config.ReportTitle
-		
-		if err := reporter.GenerateHTMLReport(results, reportPath); err != nil {
-			return suiteResult, fmt.Errorf("failed to generate report: %w", err)
-		}
-		
-		suiteResult.ReportPath = reportPath
+	if err := s.generateReport(&suiteResult); err != nil {
+		return suiteResult, err
 	}
 	
+	s.runner.emit(newSuiteFinishEvent(suiteResult))
 	return suiteResult, nil
 }
 
+// beforeSuite calls the BeforeSuite hook, if set.
+func (s *Suite) beforeSuite() error {
+	if s.config.BeforeSuite == nil {
+		return nil
+	}
+	if err := s.config.BeforeSuite(); err != nil {
+		return fmt.Errorf("BeforeSuite: %w", err)
+	}
+	return nil
+}
+
+// afterSuite calls the AfterSuite hook, if set.
+func (s *Suite) afterSuite(result SuiteResult) {
+	if s.config.AfterSuite != nil {
+		s.config.AfterSuite(result)
+	}
+}
+
+// generateReport writes the HTML report for a suite result when enabled.
+func (s *Suite) generateReport(suiteResult *SuiteResult) error {
+	if !s.config.GenerateReport {
+		return nil
+	}
+	
+	reportPath := filepath.Join(suiteResult.OutputDir, "index.html")
+	reporter := s.newReportGenerator()
+	
+	if err := reporter.GenerateHTMLReport(suiteResult.Results, reportPath); err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+	
+	suiteResult.ReportPath = reportPath
+	
+	// Keep the page listing every run current
+	if err := reporter.GenerateRunIndex(s.config.OutputDir); err != nil {
+		fmt.Fprintf(s.runner.output(), "⚠️  Failed to update the run index: %v\n", err)
+	}
+	
+	if s.config.MarkdownReport {
+		reporter.ImageBaseURL = s.config.ImageBaseURL
+		markdownPath := filepath.Join(suiteResult.OutputDir, MarkdownReportName)
+		if err := reporter.GenerateMarkdownReport(suiteResult.Results, markdownPath); err != nil {
+			return err
+		}
+		suiteResult.MarkdownPath = markdownPath
+	}
+	
+	if s.config.CSVReport {
+		csvPath := filepath.Join(suiteResult.OutputDir, CSVReportName)
+		if err := reporter.GenerateCSVReport(suiteResult.Results, csvPath); err != nil {
+			return err
+		}
+		suiteResult.CSVPath = csvPath
+	}
+	return nil
+}
+
+// newReportGenerator returns a report generator set up from the suite
+// configuration.
+func (s *Suite) newReportGenerator() *ReportGenerator {
+	reporter := NewReportGenerator()
+	reporter.Title = s.config.ReportTitle
+	reporter.Output = s.runner.output()
+	reporter.LazyAssets = s.serveAddr != ""
+	reporter.EmbedImages = s.config.EmbedImages
+	reporter.GroupBy = s.config.ReportGroupBy
+	reporter.Template = s.config.ReportTemplate
+	if s.config.ThumbnailMaxWidth != 0 {
+		reporter.ThumbnailMaxWidth = s.config.ThumbnailMaxWidth
+	}
+	reporter.HistoryDir = s.config.OutputDir
+	return reporter
+}
+
+// startIncrementalReport has the report of runDir rewritten as tests
+// finish, when SuiteConfig.IncrementalReport is set, so a crash doesn't
+// lose the results so far. The returned function ends it.
+func (s *Suite) startIncrementalReport(runDir string) func() {
+	if !s.config.GenerateReport || !s.config.IncrementalReport {
+		return func() {}
+	}
+	report := s.newReportGenerator().NewIncrementalReport(filepath.Join(runDir, "index.html"))
+	previous := s.runner.OnResult
+	s.runner.OnResult = func(result Result) {
+		if previous != nil {
+			previous(result)
+		}
+		if err := report.Add(result); err != nil {
+			fmt.Fprintf(s.runner.output(), "⚠️  Failed to update the report: %v\n", err)
+		}
+	}
+	return func() { s.runner.OnResult = previous }
+}
+
 // RunCLI runs the test suite as a CLI application with flag parsing.
-// This is the main entry point for command-line usage.
+// This is the main entry point for command-line usage. Its flags live in a
+// FlagSet of their own, so they don't clash with flags of the same name
+// registered by other packages, such as the vfyne testing package; the
+// application's own flags from the default FlagSet are parsed along.
 func (s *Suite) RunCLI() {
 	// Parse command line flags
-	outputDir := flag.String("output", s.config.OutputDir, "Output directory for screenshots")
-	testName := flag.String("test", "", "Run specific test by name")
-	testPattern := flag.String("pattern", "", "Run tests matching name pattern")
-	listTests := flag.Bool("list", false, "List all available tests")
-	listTags := flag.Bool("tags", false, "List all available tags")
-	tagFilter := flag.String("tag", "", "Run tests with specific tag")
-	verbose := flag.Bool("verbose", false, "Enable verbose output")
-	parallel := flag.Bool("parallel", s.config.Parallel, "Run tests in parallel")
-	reportTitle := flag.String("title", s.config.ReportTitle, "Title for HTML report")
-	noReport := flag.Bool("no-report", false, "Disable HTML report generation")
-	flag.Parse()
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	outputDir := flags.String("output", s.config.OutputDir, "Output directory for screenshots")
+	testName := flags.String("test", "", "Run specific test by name")
+	testPattern := flags.String("pattern", "", "Run tests matching name pattern")
+	listTests := flags.Bool("list", false, "List all available tests")
+	listTags := flags.Bool("tags", false, "List all available tags")
+	replay := flags.String("replay", "", "Replay a scenario recorded by an exploration session (<test>.scenario.json), saving its captures, and exit")
+	lint := flags.Bool("lint", false, "Check the registered tests for smells (missing descriptions, tags, size variants or baselines, hard-coded themes, naming) and exit, failing on errors")
+	lintFormat := flags.String("lint-format", "text", "Format of the -lint report: text or json")
+	lintStrict := flags.Bool("lint-strict", false, "Make -lint fail on warnings too")
+	mcp := flags.Bool("mcp", false, "Serve the suite to AI agents over the Model Context Protocol on stdin and stdout")
+	tagFilter := flags.String("tag", "", "Run tests with specific tag")
+	verbose := flags.Bool("verbose", false, "Enable verbose output")
+	parallel := flags.Bool("parallel", s.config.Parallel, "Run tests in parallel")
+	reportTitle := flags.String("title", s.config.ReportTitle, "Title for HTML report")
+	noReport := flags.Bool("no-report", false, "Disable HTML report generation")
+	markdown := flags.Bool("markdown", s.config.MarkdownReport, "Also write summary.md, a Markdown summary for pull-request comments")
+	csvReport := flags.Bool("csv", s.config.CSVReport, "Also write summary.csv, one row per test for spreadsheets and BI tools")
+	incremental := flags.Bool("incremental", s.config.IncrementalReport, "Rewrite the report as tests finish, so results survive a crash or an interrupted run")
+	embedImages := flags.Bool("embed-images", s.config.EmbedImages, "Inline images into the HTML report so it is a single self-contained file")
+	groupBy := flags.String("group-by", s.config.ReportGroupBy, "Group the tests of the HTML report into collapsible sections: tag (by primary tag)")
+	thumbnailWidth := flags.Int("thumbnail-width", s.config.ThumbnailMaxWidth, "Show captures wider than this as thumbnails in the HTML report (0: 640, -1: never)")
+	reportTemplate := flags.String("report-template", "", "html/template file overriding blocks of the HTML report (head, header, test-card, footer) or all of it")
+	allureDir := flags.String("allure", s.config.AllureDir, "Write Allure result files for the run to this directory (e.g. allure-results)")
+	sarifPath := flags.String("sarif", s.config.SARIFPath, "Write the failed tests as a SARIF log to this file, for code scanning")
+	compareWith := flags.String("compare-with", s.config.CompareWith, "Compare captures with a published run: URL of its index.json or directory, or s3://, gs:// run location")
+	artifactURL := flags.String("artifact-url", s.config.ImageBaseURL, "Link images in summary.md under this URL, where the run directory is published")
+	profileNames := flags.String("profile", "", "Comma-separated run profiles to execute (\"all\" for every profile)")
+	renderer := flags.String("renderer", string(s.config.Renderer), "Render path for tests that don't select one: software or gl (requires -tags gl)")
+	strictness := flags.String("strictness", "", "Baseline comparison strictness: exact, strict, normal or loose")
+	changeBudget := flags.String("change-budget", "", "Largest diff severity that still passes, as a number and/or tag=number pairs, e.g. \"0.5,charts=2\"")
+	outputFormat := flags.String("output-format", "text", "Output format: text, ndjson (one JSON event per line on stdout), jsonl (one JSON result per finished test on stdout), tap (TAP version 13 on stdout) or teamcity (TeamCity service messages on stdout)")
+	flags.StringVar(outputFormat, "format", "text", "Alias for -output-format")
+	formatFile := flags.String("format-file", "", "Write the -format stream to this file instead of stdout, keeping the text output on stdout")
+	calibrate := flags.Int("calibrate", 0, "Capture each selected test this many times and write an ignore mask of the regions that vary next to its baseline")
+	compareRenderers := flags.Bool("compare-renderers", false, "Report pixel drift between the software and GL renderers (requires -tags gl)")
+	serveAddr := flags.String("serve", "", "Serve the report at this address after the run (e.g. :8080), rendering diff images on demand")
+	rerunFailed := flags.Bool("rerun-failed", false, "Re-run the tests that failed in the latest run and update its report in place")
+	mergeReport := flags.Bool("merge", false, "Update the latest run's report with the selected tests instead of starting a new run")
+	archiveAfter := flags.Duration("archive-after", s.config.ArchiveAfter, "Compress run directories older than this into zip archives after the run (e.g. 720h)")
+	archiveNow := flags.Bool("archive", false, "Compress run directories older than -archive-after (all runs if unset) and exit")
+	openRun := flags.String("open", "", "Serve an earlier run directory or archive with -serve instead of running tests")
+	flags.Var(s.config.Update.PatternFlag(), "update-snapshots", "Replace baselines with fresh captures; optionally only tests matching comma-separated globs (-update-snapshots=login*)")
+	flags.Var(s.config.Update.TagFlag(), "update-tag", "Replace the baselines of tests with any of these comma-separated tags")
+	historyDepth := flags.Int("history", s.config.HistoryDepth, "Keep this many previous versions of each updated baseline under <baselines>/.history")
+	variantBaselines := flags.Bool("variant-baselines", s.config.VariantBaselines, "Name baselines after the theme, window size and scale too, e.g. button@dark@375x667.png")
+	restore := flags.String("restore-baseline", "", "Restore the previous version of this test's baseline from its history and exit")
+	orphans := flags.String("orphans", string(s.config.Orphans), "Check for baselines no test produces after the run: report, delete or fail")
+	retries := flags.Int("retries", s.config.Retries, "Run failed tests again up to this many times; tests passing on a retry are reported as flaky")
+	setupThreshold := flags.Duration("setup-threshold", s.config.SetupThreshold, "Warn about tests whose Setup function takes longer than this (e.g. 200ms)")
+	aiExport := flags.Bool("ai-export", s.config.AIExport, "Write a <screenshot>.ai.json with the semantic roles of the widgets and a summary of each screen for LLM agents")
+	isolateApps := flags.Bool("isolate-apps", s.config.IsolateApps, "Render each test in a fresh app so themes and settings can't leak between tests")
+	describe := flags.String("describe", "", "Describe each captured screen with 'heuristic' or a command that reads the screen as JSON on stdin and prints a caption")
+	testTimeout := flags.Duration("test-timeout", s.config.DefaultTimeout, "Fail tests that take longer than this to set up, render and capture, and continue with the next (e.g. 30s)")
+	storage := flags.String("storage", "", "Keep baselines and runs in s3://bucket/prefix, gs://bucket/prefix or a directory (credentials from the environment)")
+	uploadWorkers := flags.Int("upload-workers", s.config.Upload.Workers, "Upload this many files of a run to -storage at once (default 4)")
+	uploadRate := flags.Int64("upload-rate", s.config.Upload.BytesPerSecond>>10, "Limit run uploads to -storage to this many KiB per second (0: unlimited)")
+	uploadDir := flags.String("upload-run", "", "Upload an earlier run directory to -storage, resuming an interrupted upload, and exit")
+	baselineRef := flags.String("baseline-ref", "", "Compare against the baselines committed at this git ref (e.g. main) instead of the working tree")
+	baselineURL := flags.String("baseline-url", "", "Fetch baselines from this HTTP URL, caching them in SuiteConfig.BaselineDir")
+	baselineRepo := flags.String("baseline-repo", "", "Fetch baselines from the root of this git repository (git LFS aware), caching them in SuiteConfig.BaselineDir")
+	baselineBranch := flags.String("baseline-branch", "", "Branch of -baseline-repo holding the baselines (default: the remote's default branch)")
+	perfBudget := flags.Duration("perf-budget", s.config.PerfBudget, "Fail if vfyne's per-test overhead, excluding render wait, exceeds this (e.g. 50ms)")
+	flag.VisitAll(func(f *flag.Flag) {
+		if flags.Lookup(f.Name) == nil {
+			flags.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	flags.Parse(os.Args[1:])
 	
 	// Apply CLI flags to config
 	s.config.OutputDir = *outputDir
@@ -227,10 +573,237 @@ func (s *Suite) RunCLI() {
 	s.config.Parallel = *parallel
 	s.config.ReportTitle = *reportTitle
 	s.config.GenerateReport = !*noReport
+	s.config.MarkdownReport = *markdown
+	s.config.CSVReport = *csvReport
+	s.config.IncrementalReport = *incremental
+	s.config.EmbedImages = *embedImages
+	s.config.ReportGroupBy = *groupBy
+	s.config.ThumbnailMaxWidth = *thumbnailWidth
+	s.config.ImageBaseURL = *artifactURL
+	s.config.AllureDir = *allureDir
+	s.config.SARIFPath = *sarifPath
+	s.config.CompareWith = *compareWith
+	s.config.Upload.Workers = *uploadWorkers
+	s.config.Upload.BytesPerSecond = *uploadRate << 10
+	s.config.PerfBudget = *perfBudget
+	s.config.SetupThreshold = *setupThreshold
+	s.config.DefaultTimeout = *testTimeout
+	s.config.AIExport = *aiExport
+	s.config.IsolateApps = *isolateApps
+	s.config.Retries = *retries
+	s.config.ArchiveAfter = *archiveAfter
+	s.config.HistoryDepth = *historyDepth
+	s.config.VariantBaselines = *variantBaselines
+	s.serveAddr = *serveAddr
 	
 	// Update runner
 	s.runner.OutputDir = s.config.OutputDir
 	s.runner.Verbose = s.config.Verbose
+	s.runner.Update = s.config.Update
+	s.runner.HistoryDepth = s.config.HistoryDepth
+	s.runner.VariantBaselines = s.config.VariantBaselines
+	s.runner.SetupThreshold = s.config.SetupThreshold
+	s.runner.DefaultTimeout = s.config.DefaultTimeout
+	s.runner.AIExport = s.config.AIExport
+	s.runner.IsolateApps = s.config.IsolateApps
+	s.runner.Retries = s.config.Retries
+	s.runner.ChangeBudgets = s.config.ChangeBudgets
+	
+	if s.config.Update.Enabled() && s.config.BaselineDir == "" {
+		s.println("❌ -update-snapshots and -update-tag need SuiteConfig.BaselineDir")
+		os.Exit(1)
+	}
+	
+	if *describe != "" {
+		describer, err := ParseDescriber(*describe)
+		if err != nil {
+			s.printf("❌ Invalid -describe: %v\n", err)
+			os.Exit(1)
+		}
+		s.config.Describer = describer
+		s.runner.Describer = describer
+	}
+	
+	if *baselineURL != "" {
+		if s.config.BaselineDir == "" {
+			s.println("❌ -baseline-url needs SuiteConfig.BaselineDir to cache baselines in")
+			os.Exit(1)
+		}
+		s.config.BaselineSource = NewHTTPBaselineSource(*baselineURL)
+		s.runner.BaselineSource = s.config.BaselineSource
+	}
+	
+	if *baselineRepo != "" {
+		if s.config.BaselineDir == "" {
+			s.println("❌ -baseline-repo needs SuiteConfig.BaselineDir to cache baselines in")
+			os.Exit(1)
+		}
+		// The clone is kept between runs, so only new commits are fetched
+		cacheDir := filepath.Join(s.config.OutputDir, ".baselines", "repo-"+sanitizeFilename(*baselineRepo))
+		s.config.BaselineSource = NewGitRepoBaselineSource(*baselineRepo, *baselineBranch, cacheDir)
+		s.runner.BaselineSource = s.config.BaselineSource
+	}
+	
+	if *baselineRef != "" {
+		if s.config.BaselineDir == "" {
+			s.println("❌ -baseline-ref needs SuiteConfig.BaselineDir")
+			os.Exit(1)
+		}
+		if s.config.Update.Enabled() {
+			s.println("❌ -baseline-ref can't be combined with -update-snapshots")
+			os.Exit(1)
+		}
+		// Committed baselines are cached apart from the working tree ones
+		s.runner.BaselineSource = NewGitBaselineSource(*baselineRef, s.config.BaselineDir)
+		s.runner.BaselineDir = filepath.Join(s.config.OutputDir, ".baselines", sanitizeFilename(*baselineRef))
+		s.printf("🔀 Comparing against baselines at %s\n", *baselineRef)
+	}
+	
+	if *storage != "" {
+		st, err := OpenStorage(*storage)
+		if err != nil {
+			s.printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		s.config.Storage = st
+	}
+	if s.config.Storage != nil && s.runner.BaselineSource == nil {
+		if s.config.BaselineDir == "" {
+			s.println("❌ -storage needs SuiteConfig.BaselineDir to cache baselines in")
+			os.Exit(1)
+		}
+		s.config.BaselineSource = NewStorageSource(s.config.Storage)
+		s.runner.BaselineSource = s.config.BaselineSource
+	}
+	
+	policy, err := ParseOrphanPolicy(*orphans)
+	if err != nil {
+		s.printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	s.config.Orphans = policy
+	
+	renderPath, err := ParseRenderPath(*renderer)
+	if err != nil {
+		s.printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	s.config.Renderer = renderPath
+	s.runner.Renderer = renderPath
+	
+	if g := s.config.ReportGroupBy; g != GroupByNone && g != GroupByTag {
+		s.printf("❌ Unknown report grouping '%s' (use tag)\n", g)
+		os.Exit(1)
+	}
+	
+	if *reportTemplate != "" {
+		data, err := os.ReadFile(*reportTemplate)
+		if err != nil {
+			s.printf("❌ Failed to read report template: %v\n", err)
+			os.Exit(1)
+		}
+		s.config.ReportTemplate = string(data)
+	}
+	
+	if *strictness != "" {
+		tol, err := StrictnessTolerance(*strictness)
+		if err != nil {
+			s.printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		s.config.Tolerance = tol
+		s.runner.Tolerance = tol
+	}
+	
+	if *changeBudget != "" {
+		budgets, err := ParseChangeBudgets(*changeBudget)
+		if err != nil {
+			s.printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		s.config.ChangeBudgets = budgets
+		s.runner.ChangeBudgets = budgets
+	}
+	
+	var stream io.Writer = os.Stdout
+	if *formatFile != "" && *outputFormat != "text" {
+		file, err := os.Create(*formatFile)
+		if err != nil {
+			s.printf("❌ Failed to create %s: %v\n", *formatFile, err)
+			os.Exit(1)
+		}
+		// Written unbuffered, so a reader tailing the file sees each line as
+		// it is encoded; the process exit closes it
+		stream = file
+	}
+	switch *outputFormat {
+	case "text":
+	case "ndjson":
+		s.runner.OnEvent = NewNDJSONWriter(stream)
+	case "jsonl":
+		s.runner.OnEvent = NewJSONLWriter(stream)
+	case "tap":
+		s.runner.OnEvent = NewTAPWriter(stream)
+	case "teamcity":
+		s.runner.OnEvent = NewTeamCityWriter(stream)
+	default:
+		s.printf("❌ Unknown output format '%s' (use text, ndjson, jsonl, tap or teamcity)\n", *outputFormat)
+		os.Exit(1)
+	}
+	if *outputFormat != "text" && stream == os.Stdout {
+		// Keep stdout for the stream; human-readable output goes to stderr
+		s.runner.Output = os.Stderr
+	}
+	
+	if *mcp {
+		if *outputFormat != "text" && stream == os.Stdout {
+			s.println("❌ -mcp needs stdout for the protocol; write -format to a -format-file")
+			os.Exit(1)
+		}
+		s.runner.Output = os.Stderr
+		if err := s.ServeMCP(os.Stdin, os.Stdout); err != nil {
+			s.printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	
+	if *replay != "" {
+		if !s.replayScenario(*replay) {
+			os.Exit(1)
+		}
+		return
+	}
+	
+	if *restore != "" {
+		s.restoreBaseline(*restore)
+		return
+	}
+	
+	if *archiveNow {
+		s.archiveRuns()
+		return
+	}
+	
+	if *uploadDir != "" {
+		if s.config.Storage == nil {
+			s.println("❌ -upload-run needs -storage")
+			os.Exit(1)
+		}
+		if !s.uploadRun(*uploadDir) {
+			os.Exit(1)
+		}
+		return
+	}
+	
+	if *openRun != "" {
+		if s.serveAddr == "" {
+			s.println("❌ -open requires -serve")
+			os.Exit(1)
+		}
+		s.serve(*openRun)
+		return
+	}
 	
 	// Handle list flags
 	if *listTests {
@@ -243,57 +816,294 @@ func (s *Suite) RunCLI() {
 		return
 	}
 	
+	if *lint {
+		if !s.lintCLI(*lintFormat, *lintStrict) {
+			os.Exit(1)
+		}
+		return
+	}
+	
+	if *profileNames != "" {
+		s.runProfilesCLI(strings.Split(*profileNames, ","))
+		return
+	}
+	
 	// Filter tests based on flags
 	testsToRun := s.tests
 	
 	if *testName != "" {
 		testsToRun = s.filterByExactName(*testName)
 		if len(testsToRun) == 0 {
-			fmt.Printf("❌ Test '%s' not found\n", *testName)
+			s.printf("❌ Test '%s' not found\n", *testName)
 			s.listTests()
 			os.Exit(1)
 		}
 	} else if *testPattern != "" {
 		testsToRun = s.FilterByName(*testPattern)
 		if len(testsToRun) == 0 {
-			fmt.Printf("❌ No tests match pattern '%s'\n", *testPattern)
+			s.printf("❌ No tests match pattern '%s'\n", *testPattern)
 			s.listTests()
 			os.Exit(1)
 		}
 	} else if *tagFilter != "" {
 		testsToRun = s.FilterByTags(*tagFilter)
 		if len(testsToRun) == 0 {
-			fmt.Printf("❌ No tests with tag '%s'\n", *tagFilter)
+			s.printf("❌ No tests with tag '%s'\n", *tagFilter)
 			s.listTags()
 			os.Exit(1)
 		}
 	}
 	
+	if *compareRenderers {
+		drifts, err := s.runner.CompareRenderers(testsToRun)
+		if err != nil {
+			s.printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		var buf bytes.Buffer
+		printRendererDrift(&buf, drifts)
+		s.runner.flush(&buf)
+		return
+	}
+	
+	if *calibrate > 0 {
+		if s.config.BaselineDir == "" {
+			s.println("❌ -calibrate needs SuiteConfig.BaselineDir")
+			os.Exit(1)
+		}
+		if !s.calibrate(testsToRun, *calibrate) {
+			os.Exit(1)
+		}
+		return
+	}
+	
+	if *rerunFailed || *mergeReport {
+		s.rerunCLI(testsToRun, *rerunFailed)
+		return
+	}
+	
 	// Print header
-	fmt.Println("🧪 Fyne Visual Test Runner")
-	fmt.Println("==========================")
-	fmt.Printf("Suite: %s\n", s.config.Name)
-	fmt.Printf("Output directory: %s\n", s.config.OutputDir)
+	s.println("🧪 Fyne Visual Test Runner")
+	s.println("==========================")
+	s.printf("Suite: %s\n", s.config.Name)
+	s.printf("Output directory: %s\n", s.config.OutputDir)
 	if s.config.Parallel {
-		fmt.Printf("Execution mode: Parallel (max %d)\n", s.config.MaxConcurrency)
+		s.printf("Execution mode: Parallel (max %d)\n", s.config.MaxConcurrency)
 	} else {
-		fmt.Println("Execution mode: Sequential")
+		s.println("Execution mode: Sequential")
 	}
-	fmt.Printf("Tests to run: %d\n", len(testsToRun))
-	fmt.Println()
+	s.printf("Tests to run: %d\n", len(testsToRun))
+	s.println()
 	
 	// Run tests
 	result, err := s.RunTests(testsToRun)
+	s.finishCLI(result, err)
+}
+
+// calibrate captures tests samples times each and writes the proposed
+// ignore masks next to their baselines. It reports false if a test could
+// not be captured or a mask not written.
+func (s *Suite) calibrate(tests []Test, samples int) bool {
+	s.printf("🎯 Calibrating %d tests with %d samples each\n\n", len(tests), samples)
+	ok := true
+	for _, cal := range s.runner.Calibrate(tests, samples) {
+		name := cal.Test.Name
+		switch {
+		case cal.Error != nil:
+			s.printf("❌ %s: %v\n", name, cal.Error)
+			ok = false
+		case cal.UnstablePixels == 0:
+			s.printf("✅ %s: stable\n", name)
+		default:
+			path := s.runner.BaselinePath(cal.Test)
+			if err := SaveBaselineMask(path, cal.Mask); err != nil {
+				s.printf("❌ %s: failed to write mask: %v\n", name, err)
+				ok = false
+				continue
+			}
+			s.printf("🎭 %s: %d pixels vary in %d regions, mask written to %s\n", name, cal.UnstablePixels, len(cal.Mask.Regions), path+MaskSuffix)
+		}
+	}
+	return ok
+}
+
+// rerunCLI runs tests, or the failures of the latest run, into the latest
+// run directory and updates its report in place.
+func (s *Suite) rerunCLI(tests []Test, failedOnly bool) {
+	runDir, err := LatestRun(s.config.OutputDir)
 	if err != nil {
-		fmt.Printf("❌ Error running tests: %v\n", err)
+		s.printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	
+	s.println("🧪 Fyne Visual Test Runner")
+	s.println("==========================")
+	s.printf("Suite: %s\n", s.config.Name)
+	s.printf("Updating run: %s\n", runDir)
+	s.println()
+	
+	var result SuiteResult
+	if failedOnly {
+		result, err = s.RerunFailed(runDir)
+	} else {
+		result, err = s.RerunTests(runDir, tests)
+	}
+	s.finishCLI(result, err)
+}
+
+// finishCLI prints the summary of a CLI run and exits with a non-zero code
+// on errors or failed tests.
+func (s *Suite) finishCLI(result SuiteResult, err error) {
+	if err != nil {
+		s.printf("❌ Error running tests: %v\n", err)
 		os.Exit(1)
 	}
 	
 	// Print summary
 	s.printSummary(result)
 	
+	orphaned := s.checkOrphans()
+	
+	if s.config.AllureDir != "" {
+		if err := ExportAllure(result.Results, s.config.Name, s.config.AllureDir); err != nil {
+			s.printf("❌ Failed to export Allure results: %v\n", err)
+		} else {
+			s.printf("📑 Allure results written to %s\n", s.config.AllureDir)
+		}
+	}
+	
+	if s.config.SARIFPath != "" {
+		if err := WriteSARIF(result.Results, s.config.SARIFPath); err != nil {
+			s.printf("❌ Failed to write SARIF log: %v\n", err)
+		} else {
+			s.printf("🛡️  SARIF log written to %s\n", s.config.SARIFPath)
+		}
+	}
+	
+	if s.config.Storage != nil {
+		s.uploadRun(result.OutputDir)
+	}
+	
+	if s.config.ArchiveAfter > 0 {
+		s.archiveRuns()
+	}
+	
+	if s.serveAddr != "" {
+		s.serve(result.OutputDir)
+		return
+	}
+	
+	// Point at Setup functions worth moving into shared fixtures
+	if slow := result.SlowSetups(s.config.SetupThreshold); len(slow) > 0 {
+		s.printf("\n🐢 Setup took longer than %v in %d tests:\n", s.config.SetupThreshold, len(slow))
+		for _, r := range slow {
+			s.printf("- %s: %v\n", r.Test.Name, r.SetupDuration.Round(time.Millisecond))
+		}
+		s.println("Consider building expensive data once in a shared fixture.")
+	}
+	
+	// Mismatches may come from a fyne upgrade rather than the code under test
+	if drifted := result.FyneVersionDrift(); len(drifted) > 0 {
+		s.printf("\n⚠️  %d baselines were created with another fyne minor version than %s:\n", len(drifted), FyneVersion())
+		for _, r := range drifted {
+			s.printf("- %s: %v\n", r.Test.Name, r.Metadata[MetadataBaselineFyneVersion])
+		}
+		s.println("Review them and refresh with -update-snapshots if the changes come from fyne.")
+	}
+	
+	// Check the framework's own overhead
+	overBudget := result.OverBudget(s.config.PerfBudget)
+	if len(overBudget) > 0 {
+		s.printf("\n⏱️  Performance budget of %v exceeded:\n", s.config.PerfBudget)
+		for _, r := range overBudget {
+			s.printf("- %s: %v overhead\n", r.Test.Name, r.Overhead)
+		}
+	}
+	
 	// Exit with error code if tests failed
-	if result.Failed() > 0 {
+	if result.Failed() > 0 || len(overBudget) > 0 || orphaned {
+		os.Exit(1)
+	}
+}
+
+// uploadRun copies the run directory to the configured Storage and reports
+// whether every file made it.
+func (s *Suite) uploadRun(runDir string) bool {
+	uploaded, err := UploadRunWithOptions(s.config.Storage, runDir, s.config.Upload)
+	if err != nil {
+		s.printf("❌ Failed to upload run (%d files uploaded): %v\n", uploaded, err)
+		s.printf("   Resume with -upload-run %s\n", runDir)
+		return false
+	}
+	s.printf("☁️  Uploaded %d files of %s\n", uploaded, runDir)
+	return true
+}
+
+// restoreBaseline reverts the baseline of the named test to its most
+// recently archived version.
+func (s *Suite) restoreBaseline(name string) {
+	tests := s.filterByExactName(name)
+	if len(tests) == 0 || s.config.BaselineDir == "" {
+		s.printf("❌ No baseline for test '%s'\n", name)
+		os.Exit(1)
+	}
+	path := s.runner.BaselinePath(tests[0])
+	versions, err := BaselineHistory(path)
+	if err != nil || len(versions) == 0 {
+		s.printf("❌ No archived versions of %s (%v)\n", path, err)
+		os.Exit(1)
+	}
+	if err := RestoreBaseline(path, versions[0], s.config.HistoryDepth); err != nil {
+		s.printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	s.printf("⏪ Restored %s from %s\n", path, versions[0])
+}
+
+// archiveRuns compresses the run directories older than ArchiveAfter.
+func (s *Suite) archiveRuns() {
+	archives, err := ArchiveRuns(s.config.OutputDir, s.config.ArchiveAfter)
+	for _, archive := range archives {
+		s.printf("📦 Archived %s\n", archive)
+	}
+	if len(archives) > 0 && s.config.GenerateReport {
+		reporter := NewReportGenerator()
+		reporter.Title = s.config.ReportTitle
+		if err := reporter.GenerateRunIndex(s.config.OutputDir); err != nil {
+			s.printf("⚠️  Failed to update the run index: %v\n", err)
+		}
+	}
+	if err != nil {
+		s.printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// serve serves a run directory or archive, rendering review images of
+// passing tests on demand, until the process is interrupted.
+func (s *Suite) serve(runPath string) {
+	handler, closer, err := s.runner.RunHandler(runPath)
+	if err != nil {
+		s.printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	defer closer.Close()
+	
+	// Every test's captures across the retained runs
+	reporter := NewReportGenerator()
+	reporter.Title = s.config.ReportTitle
+	mux := http.NewServeMux()
+	mux.Handle(TimelinePrefix, http.StripPrefix(strings.TrimSuffix(TimelinePrefix, "/"), reporter.TimelineHandler(s.config.OutputDir)))
+	mux.Handle("/", handler)
+	
+	addr := s.serveAddr
+	if strings.HasPrefix(addr, ":") {
+		addr = "localhost" + addr
+	}
+	s.printf("\n🌐 Serving report at http://%s/ (Ctrl+C to stop)\n", addr)
+	
+	if err := http.ListenAndServe(s.serveAddr, mux); err != nil {
+		s.printf("❌ %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -310,18 +1120,18 @@ func (s *Suite) filterByExactName(name string) []Test {
 }
 
 func (s *Suite) listTests() {
-	fmt.Println("Available visual tests:")
-	fmt.Println("======================")
+	s.println("Available visual tests:")
+	s.println("======================")
 	
 	for i, test := range s.tests {
-		fmt.Printf("%d. %s", i+1, test.Name)
+		s.printf("%d. %s", i+1, test.Name)
 		if test.Description != "" {
-			fmt.Printf(" - %s", test.Description)
+			s.printf(" - %s", test.Description)
 		}
 		if len(test.Tags) > 0 {
-			fmt.Printf(" [%s]", strings.Join(test.Tags, ", "))
+			s.printf(" [%s]", strings.Join(test.Tags, ", "))
 		}
-		fmt.Println()
+		s.println()
 	}
 }
 
@@ -334,12 +1144,12 @@ func (s *Suite) listTags() {
 	}
 	
 	if len(tagMap) == 0 {
-		fmt.Println("No tags defined in test suite")
+		s.println("No tags defined in test suite")
 		return
 	}
 	
-	fmt.Println("Available tags:")
-	fmt.Println("===============")
+	s.println("Available tags:")
+	s.println("===============")
 	
 	// Sort tags
 	tags := make([]string, 0, len(tagMap))
@@ -349,29 +1159,62 @@ func (s *Suite) listTags() {
 	sort.Strings(tags)
 	
 	for _, tag := range tags {
-		fmt.Printf("- %s (%d tests)\n", tag, tagMap[tag])
+		s.printf("- %s (%d tests)\n", tag, tagMap[tag])
 	}
 }
 
 func (s *Suite) printSummary(result SuiteResult) {
-	fmt.Println("\n📊 Test Summary")
-	fmt.Println("===============")
-	fmt.Printf("Total tests: %d\n", result.Total())
-	fmt.Printf("✅ Passed: %d\n", result.Passed())
-	fmt.Printf("❌ Failed: %d\n", result.Failed())
-	fmt.Printf("⏱️  Duration: %v\n", result.Duration())
-	fmt.Printf("\nScreenshots saved to: %s\n", result.OutputDir)
+	s.println("\n📊 Test Summary")
+	s.println("===============")
+	s.printf("Total tests: %d\n", result.Total())
+	s.printf("✅ Passed: %d\n", result.Passed())
+	s.printf("❌ Failed: %d\n", result.Failed())
+	if skipped := result.Skipped(); skipped > 0 {
+		s.printf("⏭️  Skipped: %d\n", skipped)
+	}
+	expected, unexpected := 0, make([]string, 0)
+	for _, r := range result.Results {
+		if r.ExpectedFailure {
+			expected++
+		}
+		if r.UnexpectedPass {
+			unexpected = append(unexpected, r.Test.Name)
+		}
+	}
+	if expected > 0 {
+		s.printf("🟡 Expected failures: %d\n", expected)
+	}
+	if len(unexpected) > 0 {
+		s.printf("👀 Needs baseline review: %d expected to fail but passed (%s)\n", len(unexpected), strings.Join(unexpected, ", "))
+	}
+	if flaky := FlakyTests(result.Results); len(flaky) > 0 {
+		s.printf("🔁 Flaky: %d (passed after a retry)\n", len(flaky))
+	}
+	s.printf("⏱️  Duration: %v\n", result.Duration())
+	s.printf("\nScreenshots saved to: %s\n", result.OutputDir)
 	
 	if result.ReportPath != "" {
-		fmt.Printf("View results: file://%s\n", result.ReportPath)
+		s.printf("View results: file://%s\n", result.ReportPath)
+	}
+	if result.MarkdownPath != "" {
+		s.printf("PR comment: %s\n", result.MarkdownPath)
+	}
+	if result.CSVPath != "" {
+		s.printf("CSV summary: %s\n", result.CSVPath)
 	}
 	
-	// List failed tests
-	if result.Failed() > 0 {
-		fmt.Println("\nFailed tests:")
+	// Many failures usually have few causes
+	if groups := GroupedFailures(result.Results); groups != nil {
+		s.printf("\n%d failure groups affecting %d tests:\n", len(groups), result.Failed())
+		for _, group := range groups {
+			s.printf("- %d × %s (e.g. %s)\n", len(group.Results), group.Summary(), group.Representative().Test.Name)
+		}
+	} else if result.Failed() > 0 {
+		// List failed tests
+		s.println("\nFailed tests:")
 		for _, r := range result.Results {
 			if !r.Success {
-				fmt.Printf("- %s: %v\n", r.Test.Name, r.Error)
+				s.printf("- %s: %v\n", r.Test.Name, r.Error)
 			}
 		}
 	}
@@ -394,6 +1237,12 @@ type SuiteResult struct {
 	EndTime    time.Time
 	OutputDir  string
 	ReportPath string
+	
+	// MarkdownPath is the Markdown summary, when SuiteConfig.MarkdownReport is set
+	MarkdownPath string
+	
+	// CSVPath is the CSV summary, when SuiteConfig.CSVReport is set
+	CSVPath string
 }
 
 // Total returns the total number of tests run.
@@ -405,7 +1254,18 @@ func (sr SuiteResult) Total() int {
 func (sr SuiteResult) Passed() int {
 	count := 0
 	for _, r := range sr.Results {
-		if r.Success {
+		if r.Success && !r.Skipped {
+			count++
+		}
+	}
+	return count
+}
+
+// Skipped returns the number of tests that were skipped.
+func (sr SuiteResult) Skipped() int {
+	count := 0
+	for _, r := range sr.Results {
+		if r.Skipped {
 			count++
 		}
 	}
@@ -414,7 +1274,7 @@ func (sr SuiteResult) Passed() int {
 
 // Failed returns the number of tests that failed.
 func (sr SuiteResult) Failed() int {
-	return sr.Total() - sr.Passed()
+	return sr.Total() - sr.Passed() - sr.Skipped()
 }
 
 // Duration returns how long the suite took to run.
@@ -422,10 +1282,61 @@ func (sr SuiteResult) Duration() time.Duration {
 	return sr.EndTime.Sub(sr.StartTime)
 }
 
-// PassRate returns the percentage of tests that passed.
+// OverBudget returns the results whose Overhead exceeds budget. A zero
+// budget disables the check.
+func (sr SuiteResult) OverBudget(budget time.Duration) []Result {
+	if budget <= 0 {
+		return nil
+	}
+	
+	over := make([]Result, 0)
+	for _, r := range sr.Results {
+		if r.Overhead > budget {
+			over = append(over, r)
+		}
+	}
+	return over
+}
+
+// SlowSetups returns the results whose SetupDuration exceeds threshold. A
+// zero threshold disables the check.
+func (sr SuiteResult) SlowSetups(threshold time.Duration) []Result {
+	if threshold <= 0 {
+		return nil
+	}
+	
+	slow := make([]Result, 0)
+	for _, r := range sr.Results {
+		if r.SetupDuration > threshold {
+			slow = append(slow, r)
+		}
+	}
+	return slow
+}
+
+// FyneVersionDrift returns the results compared against baselines created
+// with another fyne minor version.
+func (sr SuiteResult) FyneVersionDrift() []Result {
+	drifted := make([]Result, 0)
+	for _, r := range sr.Results {
+		if _, ok := r.Metadata[MetadataBaselineFyneVersion]; ok {
+			drifted = append(drifted, r)
+		}
+	}
+	return drifted
+}
+
+// PassRate returns the percentage of the tests run, not skipped, that
+// passed.
 func (sr SuiteResult) PassRate() float64 {
-	if sr.Total() == 0 {
+	run := sr.Total() - sr.Skipped()
+	if run == 0 {
 		return 0
 	}
-	return float64(sr.Passed()) / float64(sr.Total()) * 100
+	return float64(sr.Passed()) / float64(run) * 100
+}
+
+// ImageStats returns aggregate capture and diff statistics for the run.
+func (sr SuiteResult) ImageStats() ImageStats {
+	return ComputeImageStats(sr.Results)
 }
\ No newline at end of file
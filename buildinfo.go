@@ -0,0 +1,111 @@
+package fynetest
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+)
+
+// BuildInfo records the build and environment a run was made with, so its
+// results can be traced back to an exact commit.
+type BuildInfo struct {
+	// Commit is the git commit SHA of the code under test
+	Commit string `json:"commit,omitempty"`
+
+	// Branch is the git branch, empty on a detached HEAD
+	Branch string `json:"branch,omitempty"`
+
+	// Dirty is set when tracked files had uncommitted changes
+	Dirty bool `json:"dirty,omitempty"`
+
+	GoVersion   string `json:"go_version"`
+	FyneVersion string `json:"fyne_version,omitempty"`
+	OS          string `json:"os"`
+	Arch        string `json:"arch"`
+
+	// Scale is the display scale of the fyne app the tests ran in
+	Scale float32 `json:"scale,omitempty"`
+}
+
+var (
+	buildInfoOnce sync.Once
+	buildInfo     BuildInfo
+)
+
+// CollectBuildInfo returns the build and environment of the running
+// process. The commit and branch come from CI variables (GitHub Actions,
+// GitLab CI, Jenkins), else from git in the working directory, else from
+// the version control stamp of the binary.
+func CollectBuildInfo() BuildInfo {
+	buildInfoOnce.Do(func() {
+		buildInfo = BuildInfo{
+			Commit:      firstEnv("GITHUB_SHA", "CI_COMMIT_SHA", "GIT_COMMIT"),
+			Branch:      firstEnv("GITHUB_HEAD_REF", "GITHUB_REF_NAME", "CI_COMMIT_REF_NAME", "GIT_BRANCH"),
+			GoVersion:   runtime.Version(),
+			FyneVersion: FyneVersion(),
+			OS:          runtime.GOOS,
+			Arch:        runtime.GOARCH,
+		}
+		if buildInfo.Commit == "" {
+			buildInfo.Commit = gitOutput("rev-parse", "HEAD")
+			if status := gitOutput("status", "--porcelain", "--untracked-files=no"); status != "" {
+				buildInfo.Dirty = true
+			}
+		}
+		if buildInfo.Branch == "" {
+			if branch := gitOutput("rev-parse", "--abbrev-ref", "HEAD"); branch != "HEAD" {
+				buildInfo.Branch = branch
+			}
+		}
+		if buildInfo.Commit == "" {
+			if info, ok := debug.ReadBuildInfo(); ok {
+				for _, setting := range info.Settings {
+					switch setting.Key {
+					case "vcs.revision":
+						buildInfo.Commit = setting.Value
+					case "vcs.modified":
+						buildInfo.Dirty = setting.Value == "true"
+					}
+				}
+			}
+		}
+	})
+
+	info := buildInfo
+	if app := fyne.CurrentApp(); app != nil {
+		info.Scale = app.Settings().Scale()
+	}
+	return info
+}
+
+// ShortCommit returns the first 12 characters of the commit SHA.
+func (b BuildInfo) ShortCommit() string {
+	if len(b.Commit) > 12 {
+		return b.Commit[:12]
+	}
+	return b.Commit
+}
+
+// firstEnv returns the value of the first of keys that is set.
+func firstEnv(keys ...string) string {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// gitOutput runs git in the working directory and returns its trimmed
+// output, or "" if it fails.
+func gitOutput(args ...string) string {
+	out, err := runGit("", nil, args...)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
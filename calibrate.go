@@ -0,0 +1,195 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+)
+
+// MaskSuffix is appended to a baseline path to name its calibrated ignore
+// mask, which is committed next to the baseline.
+const MaskSuffix = ".mask.json"
+
+// DefaultCalibrationSamples is how often Calibrate captures a test when no
+// sample count is given.
+const DefaultCalibrationSamples = 10
+
+// maskCellSize is the side, in pixels, of the cells unstable pixels are
+// grouped into, so a blinking cursor or an animated spinner is covered by
+// one rectangle rather than dozens.
+const maskCellSize = 8
+
+// BaselineMask is an ignore mask proposed by Calibrate: the pixel regions of
+// a capture that change between renders of the same test. Comparisons
+// against the baseline leave them out when the capture has the same size.
+type BaselineMask struct {
+	Width   int       `json:"width"`
+	Height  int       `json:"height"`
+	Samples int       `json:"samples"`
+	Regions []JSONBox `json:"regions"`
+}
+
+// Rects returns the regions of the mask as pixel rectangles.
+func (m BaselineMask) Rects() []image.Rectangle {
+	rects := make([]image.Rectangle, len(m.Regions))
+	for i, box := range m.Regions {
+		rects[i] = image.Rect(box.X, box.Y, box.X+box.Width, box.Y+box.Height)
+	}
+	return rects
+}
+
+// Fits reports whether the mask was calibrated on captures the size of img.
+func (m BaselineMask) Fits(img image.Image) bool {
+	return m.Width == img.Bounds().Dx() && m.Height == img.Bounds().Dy()
+}
+
+// ReadBaselineMask returns the calibrated mask stored next to the baseline
+// at path, if any.
+func ReadBaselineMask(path string) (BaselineMask, bool) {
+	var mask BaselineMask
+	data, err := os.ReadFile(path + MaskSuffix)
+	if err != nil || json.Unmarshal(data, &mask) != nil {
+		return BaselineMask{}, false
+	}
+	return mask, true
+}
+
+// SaveBaselineMask stores mask next to the baseline at path.
+func SaveBaselineMask(path string, mask BaselineMask) error {
+	data, err := json.MarshalIndent(mask, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+MaskSuffix, append(data, '\n'), 0644)
+}
+
+// Calibration is the outcome of calibrating one test.
+type Calibration struct {
+	Test Test
+
+	// Mask covers the unstable regions; it has none for a stable test
+	Mask BaselineMask
+
+	// UnstablePixels is the number of pixels that varied between samples
+	UnstablePixels int
+
+	// Error is set when the test could not be captured
+	Error error
+}
+
+// Calibrate captures each test samples times (0 uses
+// DefaultCalibrationSamples) and proposes an ignore mask covering the pixels
+// that vary between the captures by more than the tolerance's ColorDelta:
+// clocks, cursors, animations and other inherently unstable regions. Save a
+// mask with SaveBaselineMask to have comparisons skip those regions. GL
+// tests are captured in one GL app, as with RunTests.
+func (r *Runner) Calibrate(tests []Test, samples int) []Calibration {
+	if samples <= 0 {
+		samples = DefaultCalibrationSamples
+	}
+	if r.glApp == nil && r.needsGL(tests) {
+		var calibrations []Calibration
+		if err := r.RunWithGL(func() { calibrations = r.Calibrate(tests, samples) }); err == nil {
+			return calibrations
+		}
+	}
+	calibrations := make([]Calibration, len(tests))
+	for i, test := range tests {
+		calibrations[i] = r.calibrate(test, samples)
+	}
+	return calibrations
+}
+
+func (r *Runner) calibrate(test Test, samples int) Calibration {
+	cal := Calibration{Test: test}
+	var first image.Image
+	var unstable []bool
+	for n := 0; n < samples; n++ {
+		c := r.render(test, 1)
+		if c.result.Error != nil {
+			cal.Error = c.result.Error
+			return cal
+		}
+		if first == nil {
+			first = c.img
+			unstable = make([]bool, first.Bounds().Dx()*first.Bounds().Dy())
+			continue
+		}
+		if c.img.Bounds().Size() != first.Bounds().Size() {
+			cal.Error = fmt.Errorf("capture size changed between samples: %v and %v", first.Bounds().Size(), c.img.Bounds().Size())
+			return cal
+		}
+		markUnstable(unstable, first, c.img, r.Tolerance)
+	}
+
+	w, h := first.Bounds().Dx(), first.Bounds().Dy()
+	cal.Mask = BaselineMask{Width: w, Height: h, Samples: samples, Regions: make([]JSONBox, 0)}
+	for _, u := range unstable {
+		if u {
+			cal.UnstablePixels++
+		}
+	}
+	for _, rect := range maskRegions(unstable, w, h) {
+		cal.Mask.Regions = append(cal.Mask.Regions, JSONBox{X: rect.Min.X, Y: rect.Min.Y, Width: rect.Dx(), Height: rect.Dy()})
+	}
+	return cal
+}
+
+// markUnstable marks the pixels of sample that differ from first.
+func markUnstable(unstable []bool, first, sample image.Image, tol Tolerance) {
+	e, a := newPixelBuffer(first), newPixelBuffer(sample)
+	defer e.release()
+	defer a.release()
+	for y := 0; y < e.h; y++ {
+		for x := 0; x < e.w; x++ {
+			ep, ap := e.at(x, y), a.at(x, y)
+			if tol.differs(ep, ap, maxChannelDelta(ep, ap)) {
+				unstable[y*e.w+x] = true
+			}
+		}
+	}
+}
+
+// maskRegions groups the unstable pixels of a w×h capture into cells of
+// maskCellSize and returns the bounding rectangle of each connected group
+// of unstable cells.
+func maskRegions(unstable []bool, w, h int) []image.Rectangle {
+	cols, rows := (w+maskCellSize-1)/maskCellSize, (h+maskCellSize-1)/maskCellSize
+	cells := make([]bool, cols*rows)
+	for i, u := range unstable {
+		if u {
+			x, y := i%w, i/w
+			cells[(y/maskCellSize)*cols+x/maskCellSize] = true
+		}
+	}
+
+	bounds := image.Rect(0, 0, w, h)
+	seen := make([]bool, len(cells))
+	regions := make([]image.Rectangle, 0)
+	for start, set := range cells {
+		if !set || seen[start] {
+			continue
+		}
+		var rect image.Rectangle
+		stack := []int{start}
+		seen[start] = true
+		for len(stack) > 0 {
+			cell := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			cx, cy := cell%cols, cell/cols
+			rect = rect.Union(image.Rect(cx*maskCellSize, cy*maskCellSize, (cx+1)*maskCellSize, (cy+1)*maskCellSize))
+			for _, n := range [][2]int{{cx - 1, cy}, {cx + 1, cy}, {cx, cy - 1}, {cx, cy + 1}} {
+				if n[0] < 0 || n[1] < 0 || n[0] >= cols || n[1] >= rows {
+					continue
+				}
+				if i := n[1]*cols + n[0]; cells[i] && !seen[i] {
+					seen[i] = true
+					stack = append(stack, i)
+				}
+			}
+		}
+		regions = append(regions, rect.Intersect(bounds))
+	}
+	return regions
+}
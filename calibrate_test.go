@@ -0,0 +1,83 @@
+package fynetest
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+)
+
+func TestMaskRegionsGroupsNeighbouringCells(t *testing.T) {
+	unstable := make([]bool, 20*20)
+	for _, p := range []image.Point{{1, 1}, {2, 9}, {18, 18}} {
+		unstable[p.Y*20+p.X] = true
+	}
+	got := maskRegions(unstable, 20, 20)
+	want := []image.Rectangle{image.Rect(0, 0, 8, 16), image.Rect(16, 16, 20, 20)}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("maskRegions() = %v, want %v", got, want)
+	}
+}
+
+// blinkingTest returns a test whose 10x10 square at 5,5 is white when on
+// is set and black otherwise. Each Setup flips on, like a cursor caught in
+// different states.
+func blinkingTest(on *bool) Test {
+	return NewTest("Blinking").
+		WithCanvasSize(60, 40).
+		WithSetup(func() fyne.CanvasObject {
+			background := canvas.NewRectangle(color.Gray{0x80})
+			background.Resize(fyne.NewSize(60, 40))
+			cursor := canvas.NewRectangle(color.Black)
+			if *on {
+				cursor.FillColor = color.White
+			}
+			cursor.Move(fyne.NewPos(5, 5))
+			cursor.Resize(fyne.NewSize(10, 10))
+			*on = !*on
+			return container.NewWithoutLayout(background, cursor)
+		}).
+		MustBuild()
+}
+
+func TestCalibratedMaskCoversUnstableRegions(t *testing.T) {
+	r := snapshotRunner(t)
+	on := false
+	test := blinkingTest(&on)
+
+	cal := r.Calibrate([]Test{test}, 3)[0]
+	if cal.Error != nil {
+		t.Fatal(cal.Error)
+	}
+	if cal.UnstablePixels != 100 || cal.Mask.Samples != 3 {
+		t.Errorf("%d unstable pixels over %d samples, want 100 over 3", cal.UnstablePixels, cal.Mask.Samples)
+	}
+	rects := cal.Mask.Rects()
+	if len(rects) != 1 || !image.Rect(5, 5, 15, 15).In(rects[0]) {
+		t.Fatalf("mask regions = %v, want one covering the square", rects)
+	}
+
+	// With the baseline taken in one state, the other only passes masked
+	on = false
+	r.Update = SnapshotUpdate{All: true}
+	if result := r.RunTest(test); !result.Success {
+		t.Fatalf("creating the baseline failed: %v", result.Error)
+	}
+	r.Update = SnapshotUpdate{}
+	if result := r.RunTest(test); result.Success {
+		t.Fatal("the unstable square didn't change the capture")
+	}
+	if err := SaveBaselineMask(r.BaselinePath(test), cal.Mask); err != nil {
+		t.Fatal(err)
+	}
+	if mask, ok := ReadBaselineMask(r.BaselinePath(test)); !ok || len(mask.Regions) != 1 {
+		t.Fatalf("ReadBaselineMask() = %+v, %v", mask, ok)
+	}
+	on = true
+	if result := r.RunTest(test); !result.Success {
+		t.Errorf("the masked capture failed: %v", result.Error)
+	}
+}
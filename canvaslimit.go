@@ -0,0 +1,54 @@
+package fynetest
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+
+	"fyne.io/fyne/v2"
+)
+
+// ErrCanvasTooLarge is returned when a test requests a window larger than
+// the driver can render. It is never silently clamped.
+var ErrCanvasTooLarge = errors.New("canvas too large")
+
+// DefaultMaxCaptureSize is the largest capture, in pixels, that the runner
+// renders by default. It matches the maximum texture size of common GPU
+// drivers, so captures stay reproducible under the GL renderer too.
+var DefaultMaxCaptureSize = image.Pt(8192, 8192)
+
+// capturePixels returns the size in pixels of a window of size at scale.
+func capturePixels(size fyne.Size, scale float32) image.Point {
+	if scale <= 0 {
+		scale = 1
+	}
+	return image.Pt(int(math.Ceil(float64(size.Width*scale))), int(math.Ceil(float64(size.Height*scale))))
+}
+
+// CheckCanvasSize fails before rendering when a window of size at scale
+// exceeds limit, in pixels (DefaultMaxCaptureSize when zero).
+func CheckCanvasSize(size fyne.Size, scale float32, limit image.Point) error {
+	if limit == (image.Point{}) {
+		limit = DefaultMaxCaptureSize
+	}
+	requested := capturePixels(size, scale)
+	if requested.X > limit.X || requested.Y > limit.Y {
+		return fmt.Errorf("%w: requested %dx%d exceeds max canvas %dx%d", ErrCanvasTooLarge,
+			requested.X, requested.Y, limit.X, limit.Y)
+	}
+	return nil
+}
+
+// CheckCaptured fails when the driver captured less than the window size it
+// was asked for, as drivers limited by the screen or texture size do. A
+// pixel of rounding is allowed.
+func CheckCaptured(size fyne.Size, scale float32, img image.Image) error {
+	requested := capturePixels(size, scale)
+	got := img.Bounds().Size()
+	if got.X < requested.X-1 || got.Y < requested.Y-1 {
+		return fmt.Errorf("%w: requested %dx%d exceeds max canvas, driver rendered %dx%d", ErrCanvasTooLarge,
+			requested.X, requested.Y, got.X, got.Y)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package fynetest
+
+import "syscall"
+
+// dup2 makes newfd a copy of oldfd. Some Linux ports have no dup2 syscall.
+func dup2(oldfd, newfd int) error {
+	return syscall.Dup3(oldfd, newfd, 0)
+}
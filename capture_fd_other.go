@@ -0,0 +1,23 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package fynetest
+
+import (
+	"errors"
+	"os"
+)
+
+// redirectStdio is unsupported here; Setup output isn't captured.
+func redirectStdio(w *os.File) (*os.File, func(), error) {
+	return nil, nil, errors.New("redirecting stdio is not supported on this platform")
+}
+
+// divertStdout points os.Stdout at stderr, so Go code printing to it can't
+// corrupt a protocol spoken on stdout; output written to the file descriptor
+// directly isn't diverted. It returns the original stdout, to speak the
+// protocol on, and restore, which puts os.Stdout back.
+func divertStdout() (*os.File, func(), error) {
+	original := os.Stdout
+	os.Stdout = os.Stderr
+	return original, func() { os.Stdout = original }, nil
+}
@@ -0,0 +1,61 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package fynetest
+
+import (
+	"os"
+	"syscall"
+)
+
+// redirectStdio points the stdout and stderr file descriptors at w. It
+// returns the original stdout, to write to while redirected, and restore,
+// which points both back and closes it.
+func redirectStdio(w *os.File) (*os.File, func(), error) {
+	savedOut, err := syscall.Dup(1)
+	if err != nil {
+		return nil, nil, err
+	}
+	savedErr, err := syscall.Dup(2)
+	if err != nil {
+		syscall.Close(savedOut)
+		return nil, nil, err
+	}
+	original := os.NewFile(uintptr(savedOut), "/dev/stdout")
+	restore := func() {
+		dup2(savedOut, 1)
+		dup2(savedErr, 2)
+		original.Close()
+		syscall.Close(savedErr)
+	}
+
+	fd := int(w.Fd())
+	if err := dup2(fd, 1); err != nil {
+		restore()
+		return nil, nil, err
+	}
+	if err := dup2(fd, 2); err != nil {
+		restore()
+		return nil, nil, err
+	}
+	return original, restore, nil
+}
+
+// divertStdout points the stdout file descriptor at stderr, so stray output
+// can't corrupt a protocol spoken on stdout. It returns the original stdout,
+// to speak the protocol on, and restore, which points stdout back.
+func divertStdout() (*os.File, func(), error) {
+	saved, err := syscall.Dup(1)
+	if err != nil {
+		return nil, nil, err
+	}
+	original := os.NewFile(uintptr(saved), "/dev/stdout")
+	if err := dup2(2, 1); err != nil {
+		original.Close()
+		return nil, nil, err
+	}
+	restore := func() {
+		dup2(saved, 1)
+		original.Close()
+	}
+	return original, restore, nil
+}
@@ -0,0 +1,142 @@
+package fynetest
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// maxCapturedOutput bounds the output kept per test, so a chatty Setup
+// doesn't bloat the reports.
+const maxCapturedOutput = 64 << 10
+
+// captureMu serialises output captures, since they redirect the process-wide
+// stdout and stderr.
+var captureMu sync.Mutex
+
+// terminal is the original stdout while a capture redirects it, so the
+// runner's own output isn't captured with a test's.
+var terminal atomic.Pointer[os.File]
+
+// stdout returns the process's stdout, bypassing a capture in progress.
+func stdout() *os.File {
+	if f := terminal.Load(); f != nil {
+		return f
+	}
+	return os.Stdout
+}
+
+// captureOutput runs fn with the stdout and stderr file descriptors
+// redirected, and returns what was written to them, in order: by fmt, the
+// standard logger, which fyne logs to, and C code alike. The os.Stdout,
+// os.Stderr and log variables are left alone, so goroutines using them
+// meanwhile don't race with the capture, but what they write is captured
+// too. Output past maxCapturedOutput is dropped. Where the redirect isn't
+// supported (Windows) or can't be set up, fn runs normally and nothing is
+// captured.
+func captureOutput(fn func()) string {
+	output, _ := captureOutputUntil(fn, nil)
+	return output
+}
+
+// captureOutputUntil is captureOutput giving up on fn when stop is closed:
+// the redirect is undone and the output so far returned while fn carries on
+// in the background. It reports whether fn returned. With a stop channel, fn
+// runs on a goroutine of its own and a panic in it is raised again, as a
+// capturePanic, on the caller's.
+func captureOutputUntil(fn func(), stop <-chan struct{}) (output string, finished bool) {
+	captureMu.Lock()
+	defer captureMu.Unlock()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return "", runUntil(fn, stop)
+	}
+	original, restore, err := redirectStdio(writer)
+	if err != nil {
+		reader.Close()
+		writer.Close()
+		return "", runUntil(fn, stop)
+	}
+	terminal.Store(original)
+
+	var buf bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.Copy(&limitedBuffer{buf: &buf, max: maxCapturedOutput}, reader)
+		reader.Close()
+	}()
+
+	defer func() {
+		terminal.Store(nil)
+		restore()
+		writer.Close()
+		<-done
+		output = buf.String()
+	}()
+
+	return "", runUntil(fn, stop)
+}
+
+// capturePanic carries a panic from the goroutine runUntil ran a function
+// on, with the stack it panicked at.
+type capturePanic struct {
+	value interface{}
+	stack []byte
+}
+
+// runUntil calls fn and reports whether it returned before stop was closed.
+// Without a stop channel it simply calls fn.
+func runUntil(fn func(), stop <-chan struct{}) bool {
+	if stop == nil {
+		fn()
+		return true
+	}
+	done := make(chan *capturePanic, 1)
+	go func() {
+		var p *capturePanic
+		defer func() { done <- p }()
+		defer func() {
+			if v := recover(); v != nil {
+				p = &capturePanic{value: v, stack: debug.Stack()}
+			}
+		}()
+		fn()
+	}()
+	select {
+	case p := <-done:
+		if p != nil {
+			panic(*p)
+		}
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+// limitedBuffer keeps the first max bytes written to it and discards the
+// rest, noting the truncation once.
+type limitedBuffer struct {
+	buf       *bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (l *limitedBuffer) Write(p []byte) (int, error) {
+	if room := l.max - l.buf.Len(); room > 0 {
+		if len(p) <= room {
+			l.buf.Write(p)
+			return len(p), nil
+		}
+		l.buf.Write(p[:room])
+	}
+	if !l.truncated {
+		l.truncated = true
+		l.buf.WriteString("\n[output truncated]\n")
+	}
+	return len(p), nil
+}
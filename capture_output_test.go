@@ -0,0 +1,84 @@
+package fynetest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+func outputRunner(t *testing.T) *Runner {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("output isn't captured on Windows")
+	}
+	r := NewRunner()
+	r.OutputDir = t.TempDir()
+	r.DefaultWaitDuration = 0
+	t.Cleanup(r.Cleanup)
+	return r
+}
+
+func printingTest(name string) Test {
+	return NewTest(name).
+		WithSize(100, 50).
+		WithSetup(func() fyne.CanvasObject {
+			fmt.Printf("building %s\n", name)
+			return widget.NewLabel(name)
+		}).
+		MustBuild()
+}
+
+func TestSetupOutputCaptured(t *testing.T) {
+	r := outputRunner(t)
+	for name, run := range map[string]func([]Test) []Result{
+		"RunTests":              r.RunTests,
+		"RunTestsConcurrent(1)": func(tests []Test) []Result { return r.RunTestsConcurrent(tests, 1) },
+	} {
+		results := run([]Test{printingTest("Chatty")})
+		if got := results[0].Output; got != "building Chatty\n" {
+			t.Errorf("%s: Output = %q, want %q", name, got, "building Chatty\n")
+		}
+	}
+}
+
+func TestRunTestsConcurrentSetupsOverlap(t *testing.T) {
+	r := outputRunner(t)
+
+	// Each Setup waits for the other to start, which only works when they
+	// aren't serialised by an output capture
+	var started sync.WaitGroup
+	started.Add(2)
+	overlapping := func(name string) Test {
+		return NewTest(name).
+			WithSize(100, 50).
+			WithSetup(func() fyne.CanvasObject {
+				fmt.Printf("building %s\n", name)
+				started.Done()
+				done := make(chan struct{})
+				go func() { started.Wait(); close(done) }()
+				select {
+				case <-done:
+				case <-time.After(5 * time.Second):
+					panic("Setups ran one at a time")
+				}
+				return widget.NewLabel(name)
+			}).
+			MustBuild()
+	}
+
+	results := r.RunTestsConcurrent([]Test{overlapping("A"), overlapping("B")}, 2)
+	for _, result := range results {
+		if !result.Success {
+			t.Errorf("%s failed: %v", result.Test.Name, result.Error)
+		}
+		if strings.Contains(result.Output, "building") {
+			t.Errorf("%s captured output %q of a parallel Setup", result.Test.Name, result.Output)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package fynetest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// groupSimilarity is the share of failure features two failures must have
+// in common to be grouped.
+const groupSimilarity = 0.5
+
+// minGroupedFailures is how many failures a run needs before the report
+// presents them as groups.
+const minGroupedFailures = 3
+
+// FailureGroup is a set of failures with similar causes: the same widget
+// changes, the same kind of pixel difference or the same error.
+type FailureGroup struct {
+	// Features are what the failures in the group have in common, e.g.
+	// `changed widget.Button: text "Save" -> "Submit"`
+	Features []string
+
+	// Results are the failing results, the representative example first
+	Results []Result
+}
+
+// Summary describes the group in one line.
+func (g FailureGroup) Summary() string {
+	if len(g.Features) == 0 {
+		return "unclassified failures"
+	}
+	if len(g.Features) > 3 {
+		return strings.Join(g.Features[:3], "; ") + fmt.Sprintf("; and %d more", len(g.Features)-3)
+	}
+	return strings.Join(g.Features, "; ")
+}
+
+// Representative returns the example shown for the group: a failure with
+// review images and the fewest features of its own.
+func (g FailureGroup) Representative() *Result {
+	return &g.Results[0]
+}
+
+// ClusterFailures groups the failed results by the similarity of their
+// widget changes, pixel differences and errors, largest group first, so a
+// run with 42 failures reads as a few causes rather than 42 failures.
+func ClusterFailures(results []Result) []FailureGroup {
+	type cluster struct {
+		features map[string]bool
+		results  []Result
+		sets     []map[string]bool
+	}
+	clusters := make([]*cluster, 0)
+
+	for _, result := range results {
+		if result.Success {
+			continue
+		}
+		features := failureFeatures(result)
+		var best *cluster
+		bestScore := 0.0
+		for _, c := range clusters {
+			if score := jaccard(features, c.features); score >= groupSimilarity && score > bestScore {
+				best, bestScore = c, score
+			}
+		}
+		if best == nil {
+			best = &cluster{features: features}
+			clusters = append(clusters, best)
+		} else {
+			// The group keeps what its failures share
+			for feature := range best.features {
+				if !features[feature] {
+					delete(best.features, feature)
+				}
+			}
+		}
+		best.results = append(best.results, result)
+		best.sets = append(best.sets, features)
+	}
+
+	groups := make([]FailureGroup, 0, len(clusters))
+	for _, c := range clusters {
+		group := FailureGroup{Results: c.results}
+		for feature := range c.features {
+			group.Features = append(group.Features, feature)
+		}
+		sort.Strings(group.Features)
+
+		// Prefer an example with review images and nothing else going on
+		best := -1
+		for i, result := range c.results {
+			if result.DiffAssets == nil && result.ScreenshotPath == "" {
+				continue
+			}
+			if best < 0 || len(c.sets[i]) < len(c.sets[best]) {
+				best = i
+			}
+		}
+		if best > 0 {
+			group.Results = append([]Result{c.results[best]}, append(append([]Result(nil), c.results[:best]...), c.results[best+1:]...)...)
+		}
+		groups = append(groups, group)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].Results) > len(groups[j].Results)
+	})
+	return groups
+}
+
+// GroupedFailures returns the failure groups worth presenting: nil unless
+// there are enough failures and at least two of them share a group.
+func GroupedFailures(results []Result) []FailureGroup {
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	if failed < minGroupedFailures {
+		return nil
+	}
+	groups := ClusterFailures(results)
+	if len(groups) == failed {
+		return nil
+	}
+	return groups
+}
+
+// failureFeatures describes why result failed as a set of comparable
+// features. Widget changes are described by widget type and the property
+// that changed, with text values kept, since the same text change across
+// tests is one cause. Without widget changes, the pixel difference is
+// bucketed by strength and area; other errors are normalized.
+func failureFeatures(result Result) map[string]bool {
+	features := make(map[string]bool)
+	diff := result.Diff
+	if diff == nil || diff.Match {
+		message := "unknown error"
+		if result.Error != nil {
+			message = normalizeError(result.Error.Error())
+		}
+		features["error: "+message] = true
+		return features
+	}
+
+	if hint, ok := result.Metadata[MetadataThemeHint].(string); ok {
+		features[hint] = true
+	}
+	for _, change := range result.WidgetChanges {
+		widget := change.Path[strings.LastIndex(change.Path, "/")+1:]
+		if i := strings.IndexByte(widget, '#'); i >= 0 {
+			widget = widget[:i]
+		}
+		if len(change.Details) == 0 {
+			features[change.Kind+" "+widget] = true
+		}
+		for _, detail := range change.Details {
+			if !strings.HasPrefix(detail, "text ") {
+				detail = strings.Fields(detail)[0]
+			}
+			features[change.Kind+" "+widget+": "+detail] = true
+		}
+	}
+
+	switch {
+	case diff.SizeMismatch:
+		features["image size changed"] = true
+	case len(result.WidgetChanges) > 0:
+	case diff.MaxDelta <= 32:
+		features["faint pixel differences (anti-aliasing or font hinting)"] = true
+	case diff.DiffPercent < 1:
+		features["small area of pixels changed"] = true
+	case diff.DiffPercent < 10:
+		features["moderate area of pixels changed"] = true
+	default:
+		features["large area of pixels changed"] = true
+	}
+	return features
+}
+
+var (
+	errorPathPattern   = regexp.MustCompile(`\S*[/\\]\S*`)
+	errorNumberPattern = regexp.MustCompile(`\d+(\.\d+)?`)
+)
+
+// normalizeError removes paths and numbers from an error message, which
+// differ between otherwise identical failures.
+func normalizeError(message string) string {
+	message = errorPathPattern.ReplaceAllString(message, "<path>")
+	return errorNumberPattern.ReplaceAllString(message, "N")
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	shared := 0
+	for feature := range a {
+		if b[feature] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	fynetest "github.com/jairo/vfyne"
+)
+
+// Exit statuses understood by "git bisect run".
+const (
+	bisectGood  = 0
+	bisectBad   = 1
+	bisectSkip  = 125
+	bisectAbort = 128
+)
+
+// bisect implements "fynetest bisect": it finds the first commit between
+// -good and -bad at which a test no longer matches its baseline. Every step
+// of "git bisect run" rebuilds the test plugin with -build and runs this
+// command again with -step, which runs the one test against the baseline
+// pinned at -baseline-ref, so baseline updates made along the way don't hide
+// the change. Commits that don't build, or don't have the test yet, are
+// skipped.
+func bisect(args []string) {
+	flags := flag.NewFlagSet("bisect", flag.ExitOnError)
+	testName := flags.String("test", "", "Name of the test that broke")
+	good := flags.String("good", "", "A commit where the test still passes, e.g. a release tag")
+	bad := flags.String("bad", "HEAD", "A commit where the test fails")
+	buildCmd := flags.String("build", "", "Shell command building the test plugin at -plugin, e.g. \"go build -buildmode=plugin -o /tmp/tests.so ./visualtests\"")
+	pluginPath := flags.String("plugin", "", "Path of the test plugin written by -build")
+	baselineDir := flags.String("baselines", "", "Baseline directory, relative to the repository")
+	baselineRef := flags.String("baseline-ref", "", "Commit to take the baseline from (default: -good)")
+	outputDir := flags.String("output", filepath.Join("test-screenshots", "bisect"), "Output directory for the captures of each step")
+	strictness := flags.String("strictness", "", "Capture comparison strictness: exact, strict, normal or loose (default: the runner's)")
+	step := flags.Bool("step", false, "Run one bisect step at the checked out commit (used by git bisect run)")
+	repo := flags.String("repo", "", "Directory of the repository the baselines are read from (used with -step)")
+	flags.Parse(args)
+
+	if *testName == "" || *buildCmd == "" || *pluginPath == "" || *baselineDir == "" || (*good == "" && !*step) {
+		fmt.Fprintln(os.Stderr, "Error: -test, -good, -build, -plugin and -baselines flags are required")
+		fmt.Fprintln(os.Stderr, "Usage: fynetest bisect -test <name> -good <commit> [-bad HEAD] -build <command> -plugin <path> -baselines <dir>")
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *step {
+		os.Exit(bisectStep(*testName, *buildCmd, *pluginPath, *baselineDir, *baselineRef, *repo, *outputDir, *strictness))
+	}
+
+	if *baselineRef == "" {
+		*baselineRef = *good
+	}
+	pinned, err := git("rev-parse", "--verify", "--quiet", *baselineRef+"^{commit}")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: unknown commit %q\n", *baselineRef)
+		os.Exit(1)
+	}
+	// The executable must survive the checkouts of the bisect
+	self, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	output, err := filepath.Abs(*outputDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("🔎 Visual Bisect")
+	fmt.Println("================")
+	fmt.Printf("Test:     %s\n", *testName)
+	fmt.Printf("Good:     %s\n", *good)
+	fmt.Printf("Bad:      %s\n", *bad)
+	fmt.Printf("Baseline: %s at %s\n\n", *baselineDir, pinned[:12])
+
+	if _, err := git("bisect", "start", *bad, *good); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	run := exec.Command("git", "bisect", "run", self, "bisect", "-step",
+		"-test", *testName, "-build", *buildCmd, "-plugin", *pluginPath,
+		"-baselines", *baselineDir, "-baseline-ref", pinned, "-repo", cwd,
+		"-output", output, "-strictness", *strictness)
+	run.Stdout, run.Stderr = os.Stdout, os.Stderr
+	err = run.Run()
+	var first string
+	if err == nil {
+		first, err = git("log", "-1", "--format=%H %an: %s", "refs/bisect/bad")
+	}
+	// Return to the commit checked out before, whatever the outcome
+	if _, err := git("bisect", "reset", "--quiet"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: git bisect reset failed: %v\n", err)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Bisect did not finish: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("\n🎯 First bad commit for %s:\n%s\n", *testName, first)
+}
+
+// bisectStep builds and runs one test at the checked out commit and returns
+// the exit status telling git bisect whether the commit is good.
+func bisectStep(testName, buildCmd, pluginPath, baselineDir, baselineRef, repo, outputDir, strictness string) int {
+	commit, _ := git("rev-parse", "--short", "HEAD")
+	fmt.Printf("\n🔨 %s: building\n", commit)
+	build := exec.Command("sh", "-c", buildCmd)
+	build.Stdout, build.Stderr = os.Stdout, os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Printf("⏭️  %s: build failed (%v), skipping\n", commit, err)
+		return bisectSkip
+	}
+	tests, err := loadTests(pluginPath)
+	if err != nil {
+		fmt.Printf("⏭️  %s: %v, skipping\n", commit, err)
+		return bisectSkip
+	}
+	var test *fynetest.Test
+	for i := range tests {
+		if tests[i].Name == testName {
+			test = &tests[i]
+			break
+		}
+	}
+	if test == nil {
+		fmt.Printf("⏭️  %s: test '%s' not found, skipping\n", commit, testName)
+		return bisectSkip
+	}
+
+	runner := fynetest.NewRunner()
+	runner.OutputDir = outputDir
+	runner.BaselineDir = filepath.Join(outputDir, "baseline-"+baselineRef[:12])
+	runner.BaselineSource = &fynetest.GitBaselineSource{Ref: baselineRef, Dir: baselineDir, Repo: repo}
+	if strictness != "" {
+		tol, err := fynetest.StrictnessTolerance(strictness)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return bisectAbort
+		}
+		runner.Tolerance = tol
+	}
+	defer runner.Cleanup()
+
+	result := runner.RunTest(*test)
+	switch {
+	case result.Success && result.Diff == nil:
+		fmt.Fprintf(os.Stderr, "❌ No baseline of '%s' in %s at %s\n", testName, baselineDir, baselineRef[:12])
+		return bisectAbort
+	case result.Success:
+		fmt.Printf("✅ %s: good\n", commit)
+		return bisectGood
+	}
+	fmt.Printf("❌ %s: bad: %v\n", commit, result.Error)
+	return bisectBad
+}
+
+// git runs a git command in the current directory and returns its trimmed
+// output.
+func git(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return strings.TrimSpace(string(out)), err
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	fynetest "github.com/jairo/vfyne"
+)
+
+// checkEnv implements "fynetest check-env": it verifies the capture
+// environment and prints a pass, warning or failure per check with a fix.
+// It exits with status 1 when any check fails.
+func checkEnv(args []string) {
+	flags := flag.NewFlagSet("check-env", flag.ExitOnError)
+	outputDir := flags.String("output", "test-screenshots", "Output directory to check for write access")
+	flags.Parse(args)
+
+	fmt.Println("🩺 Capture Environment Check")
+	fmt.Println("============================")
+	fmt.Printf("fyne %s\n\n", fynetest.FyneVersion())
+
+	failed := 0
+	for _, check := range fynetest.CheckEnvironment(*outputDir) {
+		icon := "✅"
+		switch check.Status {
+		case fynetest.CheckWarn:
+			icon = "⚠️ "
+		case fynetest.CheckFail:
+			icon = "❌"
+			failed++
+		}
+		fmt.Printf("%s %-17s %s\n", icon, check.Name+":", check.Detail)
+		if check.Fix != "" {
+			fmt.Printf("   %-17s %s\n", "fix:", check.Fix)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d checks failed; captures taken here are likely black, blank or missing text\n", failed)
+		os.Exit(1)
+	}
+	fmt.Println("\nThis environment can take captures")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	fynetest "github.com/jairo/vfyne"
+)
+
+// compareRuns implements "fynetest compare-runs": it compares two existing
+// runs, prints the tests that changed status, look or duration, and writes
+// an HTML comparison page. It exits with status 1 when tests newly fail, so
+// it can drive "git bisect run" or a nightly-to-nightly check.
+func compareRuns(args []string) {
+	flags := flag.NewFlagSet("compare-runs", flag.ExitOnError)
+	outputPath := flags.String("output", "comparison.html", "Path of the HTML comparison page")
+	strictness := flags.String("strictness", "exact", "Capture comparison strictness: exact, strict, normal or loose")
+	reportTitle := flags.String("title", "Fyne Visual Test Results", "Title for the comparison page")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "Usage: fynetest compare-runs [-output comparison.html] <baseline run> <current run>")
+		fmt.Fprintln(os.Stderr, "Runs are given as a run directory, run archive or index.json")
+		flags.Usage()
+		os.Exit(1)
+	}
+	tol, err := fynetest.StrictnessTolerance(*strictness)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	baseline, err := fynetest.LoadRun(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load baseline run: %v\n", err)
+		os.Exit(1)
+	}
+	current, err := fynetest.LoadRun(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load current run: %v\n", err)
+		os.Exit(1)
+	}
+	assetDir := strings.TrimSuffix(*outputPath, ".html") + "_images"
+	rc, err := fynetest.CompareRuns(baseline, current, tol, assetDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("🔀 Run Comparison")
+	fmt.Println("=================")
+	fmt.Printf("Baseline: %s (%s)\n", flags.Arg(0), baseline.Timestamp.Format("2006-01-02 15:04"))
+	fmt.Printf("Current:  %s (%s)\n", flags.Arg(1), current.Timestamp.Format("2006-01-02 15:04"))
+	printChanges("❌ Newly failing", rc.NewlyFailing(), func(c fynetest.RunChange) string {
+		if c.Current.Error == nil {
+			return ""
+		}
+		return c.Current.Error.Error()
+	})
+	printChanges("✅ Newly passing", rc.NewlyPassing(), nil)
+	printChanges("🖼️  Visually changed", rc.VisuallyChanged(), func(c fynetest.RunChange) string {
+		if c.Diff.SizeMismatch {
+			return "size changed"
+		}
+		return fmt.Sprintf("%.2f%% of pixels changed", c.Diff.DiffPercent)
+	})
+	printChanges("⏱️  Slower or faster", rc.DurationChanged(), func(c fynetest.RunChange) string {
+		delta := c.DurationDelta().Round(time.Millisecond)
+		sign := "+"
+		if delta < 0 {
+			sign = ""
+		}
+		return fmt.Sprintf("%v → %v (%s%v)", c.Baseline.Duration.Round(time.Millisecond), c.Current.Duration.Round(time.Millisecond), sign, delta)
+	})
+	printChanges("➕ Added", rc.Added(), nil)
+	printChanges("➖ Removed", rc.Removed(), nil)
+
+	reportGen := fynetest.NewReportGenerator()
+	reportGen.Title = *reportTitle
+	if err := reportGen.WriteComparisonReport(rc, *outputPath); err != nil {
+		fmt.Printf("Warning: Failed to create comparison report: %v\n", err)
+	} else {
+		fmt.Printf("\nView comparison: file://%s\n", *outputPath)
+	}
+
+	if len(rc.NewlyFailing()) > 0 {
+		os.Exit(1)
+	}
+}
+
+// printChanges lists changes under a heading, each with the detail returned
+// by detail, if any.
+func printChanges(heading string, changes []fynetest.RunChange, detail func(fynetest.RunChange) string) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Printf("\n%s (%d)\n", heading, len(changes))
+	for _, change := range changes {
+		line := "- " + change.Label()
+		if detail != nil {
+			if d := detail(change); d != "" {
+				line += ": " + d
+			}
+		}
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	fynetest "github.com/jairo/vfyne"
+)
+
+// lint implements "fynetest lint": it checks the tests of a plugin for
+// smells and exits with status 1 on errors, or on warnings too with
+// -strict, to gate CI.
+func lint(args []string) {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	pluginPath := flags.String("plugin", "", "Path to test plugin (.so file) whose tests to lint")
+	baselineDir := flags.String("baselines", "", "Flag tests without a baseline in this directory")
+	variantBaselines := flags.Bool("variant-baselines", false, "Baselines are named after the theme, window size and scale")
+	names := flags.String("names", fynetest.DefaultLintNamePattern, "Regular expression test names must match")
+	skipSetup := flags.Bool("skip-setup", false, "Don't call Setup functions, leaving out the hard-coded theme check")
+	format := flags.String("format", "text", "Output format: text or json")
+	strict := flags.Bool("strict", false, "Fail on warnings too")
+	flags.Parse(args)
+
+	if *pluginPath == "" {
+		fmt.Fprintln(os.Stderr, "Usage: fynetest lint -plugin <path-to-test-plugin> [-baselines dir] [-format json] [-strict]")
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (use text or json)\n", *format)
+		os.Exit(1)
+	}
+
+	tests, err := loadTests(*pluginPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	report, err := fynetest.LintTests(tests, fynetest.LintOptions{
+		BaselineDir:      *baselineDir,
+		VariantBaselines: *variantBaselines,
+		NamePattern:      *names,
+		SkipSetup:        *skipSetup,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *format == "json" {
+		report.WriteJSON(os.Stdout)
+	} else {
+		report.WriteText(os.Stdout)
+	}
+	if report.Failed(*strict) {
+		os.Exit(1)
+	}
+}
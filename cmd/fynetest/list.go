@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	fynetest "github.com/jairo/vfyne"
+)
+
+// list implements "fynetest list": with -flaky it scores the tests of the
+// runs kept in the output directory by how flaky they have been, flakiest
+// first, and suggests what to do about each; with -plugin it lists the
+// tests of a plugin.
+func list(args []string) {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	flaky := flags.Bool("flaky", false, "List tests by flake score over the kept runs")
+	outputDir := flags.String("output", "test-screenshots", "Output directory holding the timestamped runs")
+	minScore := flags.Float64("min", 0.1, "Smallest flake score to list")
+	lastRuns := flags.Int("runs", 0, "Score over the last N runs only (0 = all kept runs)")
+	pluginPath := flags.String("plugin", "", "Path to test plugin (.so file) whose tests to list")
+	flags.Parse(args)
+
+	if !*flaky {
+		if *pluginPath == "" {
+			fmt.Fprintln(os.Stderr, "Usage: fynetest list -flaky [-output dir] [-min 0.1] [-runs N]")
+			fmt.Fprintln(os.Stderr, "       fynetest list -plugin <path-to-test-plugin>")
+			flags.Usage()
+			os.Exit(1)
+		}
+		tests, err := loadTests(*pluginPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for i, test := range tests {
+			fmt.Printf("%d. %s - %s\n", i+1, test.Name, test.Description)
+		}
+		return
+	}
+
+	runs, err := fynetest.LoadRuns(*outputDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load runs: %v\n", err)
+		os.Exit(1)
+	}
+	if *lastRuns > 0 && len(runs) > *lastRuns {
+		runs = runs[len(runs)-*lastRuns:]
+	}
+	if len(runs) < fynetest.MinFlakeRuns {
+		fmt.Printf("Only %d runs in %s; flake scores need at least %d\n", len(runs), *outputDir, fynetest.MinFlakeRuns)
+		return
+	}
+
+	fmt.Printf("🎲 Flake scores over %d runs\n", len(runs))
+	fmt.Println("==========================")
+	listed := 0
+	for _, score := range fynetest.FlakeScores(runs) {
+		if score.Score < *minScore || score.Score == 0 {
+			break
+		}
+		listed++
+		name := score.Name
+		if score.Profile != "" {
+			name += " [" + score.Profile + "]"
+		}
+		fmt.Printf("%.2f  %s\n", score.Score, name)
+		fmt.Printf("      failed %d of %d runs, %d status flips, %d passed on retry, diff %.2f%% ± %.2f\n",
+			score.Failures, score.Runs, score.Flips, score.Retried, score.DiffMean, score.DiffStdDev)
+		fmt.Printf("      %s\n", score.Hint())
+	}
+	if listed == 0 {
+		fmt.Printf("✅ No test scored %.2f or more\n", *minScore)
+	}
+}
@@ -11,6 +11,31 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "rebaseline" {
+		rebaseline(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "check-env" {
+		checkEnv(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bisect" {
+		bisect(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "compare-runs" {
+		compareRuns(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "list" {
+		list(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		lint(os.Args[2:])
+		return
+	}
+
 	// Parse command line flags
 	outputDir := flag.String("output", "test-screenshots", "Output directory for screenshots")
 	testName := flag.String("test", "", "Run specific test by name")
@@ -27,29 +52,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Load the plugin
-	p, err := plugin.Open(*pluginPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading plugin: %v\n", err)
-		os.Exit(1)
-	}
-
-	// Look for the GetTests function
-	getTestsSymbol, err := p.Lookup("GetTests")
+	// Get all tests from the plugin
+	allTests, err := loadTests(*pluginPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: plugin must export 'GetTests' function: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	getTests, ok := getTestsSymbol.(func() []fynetest.Test)
-	if !ok {
-		fmt.Fprintln(os.Stderr, "Error: GetTests must have signature 'func() []fynetest.Test'")
-		os.Exit(1)
-	}
-
-	// Get all tests from the plugin
-	allTests := getTests()
-
 	// Handle list flag
 	if *listTests {
 		fmt.Println("Available visual tests:")
@@ -127,4 +136,23 @@ func main() {
 	if failureCount > 0 {
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
+
+// loadTests returns the tests exported by the GetTests function of a plugin.
+func loadTests(pluginPath string) ([]fynetest.Test, error) {
+	p, err := plugin.Open(pluginPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading plugin: %w", err)
+	}
+
+	getTestsSymbol, err := p.Lookup("GetTests")
+	if err != nil {
+		return nil, fmt.Errorf("plugin must export 'GetTests' function: %w", err)
+	}
+
+	getTests, ok := getTestsSymbol.(func() []fynetest.Test)
+	if !ok {
+		return nil, fmt.Errorf("GetTests must have signature 'func() []fynetest.Test'")
+	}
+	return getTests(), nil
+}
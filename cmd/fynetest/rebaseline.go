@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	fynetest "github.com/jairo/vfyne"
+)
+
+// rebaseline implements "fynetest rebaseline": it regenerates every baseline
+// into a staging directory and, unless previewing, replaces the committed
+// baselines with them. With -preview the staged baselines wait for sign-off
+// on the before/after report, are signed off with "fynetest rebaseline
+// -approve" where the approval policy of the baseline directory asks for it
+// and are applied with "fynetest rebaseline -apply". -write-policy writes
+// that policy, to be committed with the baselines.
+func rebaseline(args []string) {
+	flags := flag.NewFlagSet("rebaseline", flag.ExitOnError)
+	pluginPath := flags.String("plugin", "", "Path to test plugin (.so file)")
+	baselineDir := flags.String("baselines", "", "Directory holding the committed baselines")
+	stagingDir := flags.String("staging", "", "Directory for the regenerated baselines (default: <baselines>.staged)")
+	outputDir := flags.String("output", "test-screenshots", "Output directory for screenshots and the preview report")
+	preview := flags.Bool("preview", false, "Stage the baselines and write a before/after report without replacing the committed ones")
+	apply := flags.Bool("apply", false, "Replace the committed baselines with the previously staged ones")
+	approve := flags.Bool("approve", false, "Approve the staged baselines (all, or those of -test) as your git user.email")
+	approveTests := flags.String("test", "", "Comma-separated test names to approve (default: all staged)")
+	writePolicy := flags.Bool("write-policy", false, "Write the approval policy of -baselines from -critical-tags, -approvers and -reviewers")
+	criticalTags := flags.String("critical-tags", strings.Join(fynetest.DefaultApprovalPolicy.Tags, ","), "With -write-policy: comma-separated tags whose baselines need approvals")
+	approvers := flags.Int("approvers", fynetest.DefaultApprovalPolicy.Approvers, "With -write-policy: distinct approvals needed for baselines of tests with -critical-tags")
+	reviewers := flags.String("reviewers", "", "With -write-policy: comma-separated git emails of the people whose approvals count")
+	reportTitle := flags.String("title", "Rebaseline Preview", "Title for the preview report")
+	verbose := flags.Bool("verbose", false, "Enable verbose output")
+	flags.Parse(args)
+
+	if *baselineDir == "" {
+		fmt.Fprintln(os.Stderr, "Error: -baselines flag is required")
+		fmt.Fprintln(os.Stderr, "Usage: fynetest rebaseline -plugin <path-to-test-plugin> -baselines <dir> [-preview]")
+		fmt.Fprintln(os.Stderr, "       fynetest rebaseline -baselines <dir> -approve")
+		fmt.Fprintln(os.Stderr, "       fynetest rebaseline -baselines <dir> -apply")
+		flags.Usage()
+		os.Exit(1)
+	}
+	if *stagingDir == "" {
+		*stagingDir = filepath.Clean(*baselineDir) + ".staged"
+	}
+
+	if *writePolicy {
+		policy := fynetest.ApprovalPolicy{Tags: splitList(*criticalTags), Approvers: *approvers, Reviewers: splitList(*reviewers)}
+		if err := policy.Save(*baselineDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("📝 Wrote %s; commit it with the baselines\n", filepath.Join(*baselineDir, fynetest.ApprovalPolicyName))
+		return
+	}
+
+	if *approve {
+		approver, err := fynetest.ApproverIdentity()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		approved, err := fynetest.ApproveStagedBaselines(*stagingDir, *baselineDir, approver, splitList(*approveTests)...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✍️  %s approved %d staged baselines\n", approver, len(approved))
+		return
+	}
+
+	if *apply {
+		applyStaged(*stagingDir, *baselineDir)
+		return
+	}
+
+	if *pluginPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -plugin flag is required")
+		flags.Usage()
+		os.Exit(1)
+	}
+	tests, err := loadTests(*pluginPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	runner := fynetest.NewRunner()
+	runner.OutputDir = *outputDir
+	runner.BaselineDir = *baselineDir
+	runner.Verbose = *verbose
+	defer runner.Cleanup()
+
+	fmt.Println("📸 Regenerating baselines")
+	fmt.Println("=========================")
+	fmt.Printf("Baselines: %s\n", *baselineDir)
+	fmt.Printf("Staging:   %s\n\n", *stagingDir)
+
+	results, runDir, err := runner.StageBaselines(tests, *stagingDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	counts := make(map[string]int)
+	failed := 0
+	for _, result := range results {
+		if status, ok := result.Metadata[fynetest.MetadataRebaseline].(string); ok {
+			counts[status]++
+			if status == fynetest.RebaselineChanged {
+				fmt.Printf("🔄 %s: %v\n", result.Test.Name, result.Error)
+			}
+			continue
+		}
+		failed++
+		fmt.Printf("❌ Test '%s' failed: %v\n", result.Test.Name, result.Error)
+	}
+
+	fmt.Println("\n📊 Rebaseline Summary")
+	fmt.Println("=====================")
+	fmt.Printf("🆕 New:       %d\n", counts[fynetest.RebaselineNew])
+	fmt.Printf("🔄 Changed:   %d\n", counts[fynetest.RebaselineChanged])
+	fmt.Printf("✅ Unchanged: %d\n", counts[fynetest.RebaselineUnchanged])
+	if failed > 0 {
+		fmt.Printf("❌ Failed:    %d (not staged)\n", failed)
+	}
+
+	reportGen := fynetest.NewReportGenerator()
+	reportGen.Title = *reportTitle
+	reportPath := filepath.Join(runDir, "index.html")
+	if err := reportGen.GenerateHTMLReport(results, reportPath); err != nil {
+		fmt.Printf("Warning: Failed to create HTML report: %v\n", err)
+	} else {
+		fmt.Printf("\nBefore/after report: file://%s\n", reportPath)
+	}
+
+	if *preview {
+		fmt.Printf("\nStaged baselines are in %s. After review, apply them with:\n", *stagingDir)
+		fmt.Printf("  fynetest rebaseline -baselines %s -staging %s -apply\n", *baselineDir, *stagingDir)
+		if policy, err := fynetest.LoadApprovalPolicy(*baselineDir); err == nil && policy.Approvers > 0 && len(policy.Tags) > 0 {
+			fmt.Printf("Baselines of tests tagged %s need %d approvals first (-approve).\n", strings.Join(policy.Tags, ","), policy.Approvers)
+		}
+		return
+	}
+	applyStaged(*stagingDir, *baselineDir)
+}
+
+func applyStaged(stagingDir, baselineDir string) {
+	applied, err := fynetest.ApplyStagedBaselines(stagingDir, baselineDir)
+	if errors.Is(err, fynetest.ErrApprovalRequired) {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		fmt.Fprintf(os.Stderr, "Staged baselines are kept in %s; reviewers listed in %s approve them with -approve.\n",
+			stagingDir, filepath.Join(baselineDir, fynetest.ApprovalPolicyName))
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("\n📸 Replaced %d baselines in %s\n", len(applied), baselineDir)
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
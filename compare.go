@@ -0,0 +1,640 @@
+package fynetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sync"
+)
+
+// Tolerance describes how much two captures may differ and still be
+// considered a match.
+type Tolerance struct {
+	// ColorDelta is the largest per-channel difference (0-255) treated as equal
+	ColorDelta uint8
+
+	// MaxDiffPercent is the percentage of pixels (0-100) allowed to differ
+	MaxDiffPercent float64
+
+	// Mode selects how two pixels are compared (default: ComparePixel)
+	Mode ComparisonMode
+
+	// DeltaE is the largest CIE76 color difference treated as equal in
+	// ComparePerceptual mode (0 uses DefaultDeltaE)
+	DeltaE float64
+
+	// MinSimilarity is the SSIM score (0-1) required to pass in
+	// CompareStructural mode (0 uses DefaultSSIMThreshold)
+	MinSimilarity float64
+
+	// IgnoreAntialiasing skips differing pixels that look like anti-aliased
+	// edges in either image, absorbing text and curve rendering differences
+	IgnoreAntialiasing bool
+}
+
+// ComparisonMode selects how individual pixels are compared.
+type ComparisonMode int
+
+const (
+	// ComparePixel compares the RGBA channels against ColorDelta
+	ComparePixel ComparisonMode = iota
+
+	// ComparePerceptual compares colors in CIE Lab space against DeltaE,
+	// ignoring shifts the eye cannot see
+	ComparePerceptual
+
+	// CompareStructural scores the whole image with SSIM against
+	// MinSimilarity, catching layout changes while ignoring sub-pixel
+	// font hinting differences
+	CompareStructural
+)
+
+// DefaultSSIMThreshold is the default pass score for CompareStructural.
+const DefaultSSIMThreshold = 0.95
+
+// DefaultDeltaE is roughly one just-noticeable difference.
+const DefaultDeltaE = 2.3
+
+// differs reports whether two RGBA pixels with the given maximum channel
+// delta differ beyond the tolerance.
+func (t Tolerance) differs(a, b []byte, delta uint8) bool {
+	if delta == 0 {
+		return false
+	}
+	if t.Mode == ComparePerceptual {
+		limit := t.DeltaE
+		if limit == 0 {
+			limit = DefaultDeltaE
+		}
+		return deltaE(a, b) > limit
+	}
+	return delta > t.ColorDelta
+}
+
+// ExactTolerance requires captures to be pixel-identical.
+var ExactTolerance = Tolerance{}
+
+// VectorTolerance is tuned for anti-aliased vector output such as
+// canvas.Line, canvas.Circle and custom painters, where edge pixels vary
+// slightly between rasterizers but the shapes themselves must not move.
+var VectorTolerance = Tolerance{
+	ColorDelta:     48,
+	MaxDiffPercent: 0.5,
+}
+
+// Strictness names a preset tolerance, so a whole suite can be tightened or
+// relaxed per environment without editing individual tests.
+type Strictness string
+
+const (
+	// StrictnessExact requires pixel-identical captures
+	StrictnessExact Strictness = "exact"
+
+	// StrictnessStrict ignores only faint color noise
+	StrictnessStrict Strictness = "strict"
+
+	// StrictnessNormal tolerates font hinting and anti-aliasing differences
+	StrictnessNormal Strictness = "normal"
+
+	// StrictnessLoose suits heterogeneous CI machines and GPUs
+	StrictnessLoose Strictness = "loose"
+)
+
+var strictnessTolerances = map[Strictness]Tolerance{
+	StrictnessExact:  ExactTolerance,
+	StrictnessStrict: {ColorDelta: 8, MaxDiffPercent: 0.01},
+	StrictnessNormal: {ColorDelta: 16, MaxDiffPercent: 0.1},
+	StrictnessLoose:  {ColorDelta: 48, MaxDiffPercent: 1},
+}
+
+// StrictnessTolerance returns the tolerance preset for a strictness level
+// ("exact", "strict", "normal" or "loose").
+func StrictnessTolerance(level string) (Tolerance, error) {
+	tol, ok := strictnessTolerances[Strictness(level)]
+	if !ok {
+		return Tolerance{}, fmt.Errorf("unknown strictness %q (use exact, strict, normal or loose)", level)
+	}
+	return tol, nil
+}
+
+// DiffResult summarises the differences between two images.
+type DiffResult struct {
+	// Match reports whether the images are equal within the tolerance
+	Match bool
+
+	// SizeMismatch is set when the images have different bounds
+	SizeMismatch bool
+
+	// DiffPixels is the number of pixels exceeding the color delta
+	DiffPixels int
+
+	// TotalPixels is the number of pixels compared
+	TotalPixels int
+
+	// DiffPercent is DiffPixels as a percentage of TotalPixels
+	DiffPercent float64
+
+	// MaxDelta is the largest per-channel difference found
+	MaxDelta uint8
+
+	// Bounds is the bounding box of the differing pixels, relative to the
+	// top-left corner of the images (empty when none differ)
+	Bounds image.Rectangle
+
+	// AntialiasedPixels is the number of differing pixels ignored as
+	// anti-aliasing when Tolerance.IgnoreAntialiasing is set
+	AntialiasedPixels int
+
+	// Partial is set when CompareFile stopped reading the baseline as soon
+	// as the tolerance was exceeded; the counts then cover only the rows read
+	Partial bool
+
+	// Similarity is the structural similarity score (0-1) for chart comparisons
+	Similarity float64
+
+	// Band is the tolerance band of a chart comparison
+	Band ToleranceBand
+
+	// Severity scores the change by area and contrast, weighted by the
+	// test's importance regions (see Severity); set on baseline mismatches
+	Severity float64
+
+	// WithinBudget is set when the capture differs from the baseline by no
+	// more than the test's change budget, so the test passes regardless
+	WithinBudget bool
+}
+
+// CompareImages compares two images pixel by pixel using the given tolerance.
+// Pixels are compared on raw RGBA buffers, with rows split across CPUs.
+func CompareImages(expected, actual image.Image, tol Tolerance) DiffResult {
+	if expected.Bounds().Size() != actual.Bounds().Size() {
+		return DiffResult{SizeMismatch: true}
+	}
+	if tol.Mode == CompareStructural {
+		threshold := tol.MinSimilarity
+		if threshold == 0 {
+			threshold = DefaultSSIMThreshold
+		}
+		return CompareSSIM(expected, actual, threshold)
+	}
+
+	e, a := newPixelBuffer(expected), newPixelBuffer(actual)
+	defer e.release()
+	defer a.release()
+	result := DiffResult{TotalPixels: e.w * e.h}
+
+	// Identical images need no pixel walk
+	if bytes.Equal(e.pix, a.pix) {
+		result.Match = true
+		return result
+	}
+
+	var mu sync.Mutex
+	parallelRows(e.h, func(y0, y1 int) {
+		var band DiffResult
+		for y := y0; y < y1; y++ {
+			if bytes.Equal(e.row(y), a.row(y)) {
+				continue
+			}
+			for x := 0; x < e.w; x++ {
+				ep, ap := e.at(x, y), a.at(x, y)
+				delta := maxChannelDelta(ep, ap)
+				if delta > band.MaxDelta {
+					band.MaxDelta = delta
+				}
+				if !tol.differs(ep, ap, delta) {
+					continue
+				}
+				if tol.IgnoreAntialiasing && (antialiased(e, a, x, y) || antialiased(a, e, x, y)) {
+					band.AntialiasedPixels++
+					continue
+				}
+				band.DiffPixels++
+				band.Bounds = band.Bounds.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		result.DiffPixels += band.DiffPixels
+		result.AntialiasedPixels += band.AntialiasedPixels
+		result.Bounds = result.Bounds.Union(band.Bounds)
+		if band.MaxDelta > result.MaxDelta {
+			result.MaxDelta = band.MaxDelta
+		}
+	})
+
+	return finishDiff(result, tol)
+}
+
+// finishDiff derives the percentage and match verdict from the pixel counts.
+func finishDiff(result DiffResult, tol Tolerance) DiffResult {
+	if result.TotalPixels > 0 {
+		result.DiffPercent = float64(result.DiffPixels) / float64(result.TotalPixels) * 100
+	}
+	result.Match = result.DiffPercent <= tol.MaxDiffPercent
+
+	return result
+}
+
+// ImageDigest returns the hex SHA-256 of the image size and its 8-bit
+// premultiplied RGBA pixels. Images that look identical have the same
+// digest regardless of their in-memory format or PNG encoding.
+func ImageDigest(img image.Image) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%dx%d\n", img.Bounds().Dx(), img.Bounds().Dy())
+	p := newPixelBuffer(img)
+	defer p.release()
+	h.Write(p.pix)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DiffImage returns a copy of expected with every pixel exceeding the tolerance
+// painted in the given highlight color. It returns nil if the sizes differ.
+// The result uses a pooled buffer that can be handed back with ReleaseImage.
+func DiffImage(expected, actual image.Image, tol Tolerance, highlight color.Color) image.Image {
+	if expected.Bounds().Size() != actual.Bounds().Size() {
+		return nil
+	}
+
+	e, a := newPixelBuffer(expected), newPixelBuffer(actual)
+	defer e.release()
+	defer a.release()
+	diff := newPooledRGBA(image.Rect(0, 0, e.w, e.h))
+	copy(diff.Pix, e.pix)
+
+	hl := color.RGBAModel.Convert(highlight).(color.RGBA)
+	parallelRows(e.h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			if bytes.Equal(e.row(y), a.row(y)) {
+				continue
+			}
+			for x := 0; x < e.w; x++ {
+				ep, ap := e.at(x, y), a.at(x, y)
+				if !tol.differs(ep, ap, maxChannelDelta(ep, ap)) {
+					continue
+				}
+				if tol.IgnoreAntialiasing && (antialiased(e, a, x, y) || antialiased(a, e, x, y)) {
+					continue
+				}
+				i := diff.PixOffset(x, y)
+				diff.Pix[i], diff.Pix[i+1], diff.Pix[i+2], diff.Pix[i+3] = hl.R, hl.G, hl.B, hl.A
+			}
+		}
+	})
+
+	return diff
+}
+
+// heatmapDim is the share of brightness kept from the baseline underneath a
+// heatmap, enough to recognise the layout without competing with the heat.
+const heatmapDim = 0.3
+
+// HeatmapImage returns expected dimmed to a dark grayscale with every
+// differing pixel colored by its largest channel delta: dark red for faint
+// changes through bright red to yellow for the strongest. Unlike DiffImage it
+// shows every change, including those within the tolerance, so reviewers can
+// judge both where and how severe a regression is. It returns nil if the
+// sizes differ; the result uses a pooled buffer like DiffImage.
+func HeatmapImage(expected, actual image.Image) image.Image {
+	if expected.Bounds().Size() != actual.Bounds().Size() {
+		return nil
+	}
+
+	e, a := newPixelBuffer(expected), newPixelBuffer(actual)
+	defer e.release()
+	defer a.release()
+	heat := newPooledRGBA(image.Rect(0, 0, e.w, e.h))
+
+	parallelRows(e.h, func(y0, y1 int) {
+		for y := y0; y < y1; y++ {
+			for x := 0; x < e.w; x++ {
+				ep := e.at(x, y)
+				px := heat.Pix[heat.PixOffset(x, y):]
+				if delta := maxChannelDelta(ep, a.at(x, y)); delta > 0 {
+					px[0], px[1], px[2] = heatColor(float64(delta) / 255)
+				} else {
+					gray := uint8(brightness(ep) * heatmapDim)
+					px[0], px[1], px[2] = gray, gray, gray
+				}
+				px[3] = 0xff
+			}
+		}
+	})
+
+	return heat
+}
+
+// heatColor maps an intensity in (0, 1] to a dark red, red, yellow ramp.
+func heatColor(t float64) (r, g, b uint8) {
+	r = uint8(128 + 127*math.Min(1, 2*t))
+	g = uint8(255 * math.Max(0, 2*t-1))
+	return r, g, 0
+}
+
+// antialiased reports whether the pixel at (x, y) of img looks like part of
+// an anti-aliased edge. It follows the heuristic used by pixelmatch
+// (Vyšniauskas, 2009): the pixel must sit between a darker and a brighter
+// neighbour, with at most two identical neighbours, and one of those
+// extremes must belong to a flat area in both images.
+func antialiased(img, other pixelBuffer, x, y int) bool {
+	center := brightness(img.at(x, y))
+
+	zeroes := 0
+	if x == 0 || y == 0 || x == img.w-1 || y == img.h-1 {
+		zeroes = 1
+	}
+
+	var min, max float64
+	minX, minY, maxX, maxY := -1, -1, -1, -1
+	for ny := y - 1; ny <= y+1; ny++ {
+		for nx := x - 1; nx <= x+1; nx++ {
+			if (nx == x && ny == y) || !img.contains(nx, ny) {
+				continue
+			}
+
+			delta := center - brightness(img.at(nx, ny))
+			switch {
+			case delta == 0:
+				zeroes++
+				if zeroes > 2 {
+					return false
+				}
+			case delta < min:
+				min, minX, minY = delta, nx, ny
+			case delta > max:
+				max, maxX, maxY = delta, nx, ny
+			}
+		}
+	}
+
+	if minX < 0 || maxX < 0 {
+		return false
+	}
+	return (hasManySiblings(img, minX, minY) && hasManySiblings(other, minX, minY)) ||
+		(hasManySiblings(img, maxX, maxY) && hasManySiblings(other, maxX, maxY))
+}
+
+// hasManySiblings reports whether the pixel at (x, y) has more than two
+// identical neighbours, i.e. lies in a flat area.
+func hasManySiblings(img pixelBuffer, x, y int) bool {
+	c := img.at(x, y)
+
+	zeroes := 0
+	if x == 0 || y == 0 || x == img.w-1 || y == img.h-1 {
+		zeroes = 1
+	}
+	for ny := y - 1; ny <= y+1; ny++ {
+		for nx := x - 1; nx <= x+1; nx++ {
+			if (nx == x && ny == y) || !img.contains(nx, ny) {
+				continue
+			}
+			if bytes.Equal(c, img.at(nx, ny)) {
+				zeroes++
+				if zeroes > 2 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// brightness returns the luma of a premultiplied pixel composited over white.
+func brightness(px []byte) float64 {
+	white := 255 - float64(px[3])
+	return 0.29889531*(float64(px[0])+white) + 0.58662247*(float64(px[1])+white) + 0.11448223*(float64(px[2])+white)
+}
+
+// deltaE returns the CIE76 distance between two pixels in Lab space.
+// Translucent pixels are composited over white first, as they would appear
+// in a report.
+func deltaE(a, b []byte) float64 {
+	l1, a1, b1 := toLab(a)
+	l2, a2, b2 := toLab(b)
+	return math.Sqrt((l1-l2)*(l1-l2) + (a1-a2)*(a1-a2) + (b1-b2)*(b1-b2))
+}
+
+// toLab converts a premultiplied RGBA pixel to CIE L*a*b* using the D65
+// white point.
+func toLab(px []byte) (l, a, b float64) {
+	white := 255 - px[3]
+	lr := linearRGB[px[0]+white]
+	lg := linearRGB[px[1]+white]
+	lb := linearRGB[px[2]+white]
+
+	x := (0.4124*lr + 0.3576*lg + 0.1805*lb) / 0.95047
+	y := 0.2126*lr + 0.7152*lg + 0.0722*lb
+	z := (0.0193*lr + 0.1192*lg + 0.9505*lb) / 1.08883
+
+	fx, fy, fz := labF(x), labF(y), labF(z)
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+// linearRGB maps 8-bit sRGB values to linear intensities (0-1).
+var linearRGB = func() (table [256]float64) {
+	for i := range table {
+		table[i] = srgbToLinear(float64(i) / 255)
+	}
+	return table
+}()
+
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func labF(t float64) float64 {
+	const epsilon = 216.0 / 24389.0
+	if t > epsilon {
+		return math.Cbrt(t)
+	}
+	return (24389.0/27.0*t + 16) / 116
+}
+
+// ToleranceBand classifies a similarity score.
+type ToleranceBand string
+
+const (
+	// BandPass means the capture is within the expected noise
+	BandPass ToleranceBand = "pass"
+
+	// BandWarn means the capture drifted noticeably but not enough to fail
+	BandWarn ToleranceBand = "warn"
+
+	// BandFail means the capture regressed
+	BandFail ToleranceBand = "fail"
+)
+
+// ChartTolerance configures the structural comparison used for raster-heavy
+// custom drawing such as charts, where exact pixel matching is too brittle
+// but gross regressions must still be caught.
+type ChartTolerance struct {
+	// Downsample is the block size in pixels averaged into one sample (default 4)
+	Downsample int
+
+	// PassScore is the minimum similarity (0-1) for a clean pass
+	PassScore float64
+
+	// WarnScore is the minimum similarity (0-1) that passes with a warning
+	WarnScore float64
+}
+
+// DefaultChartTolerance suits typical anti-aliased plots.
+var DefaultChartTolerance = ChartTolerance{
+	Downsample: 4,
+	PassScore:  0.98,
+	WarnScore:  0.90,
+}
+
+// Band returns the tolerance band a similarity score falls into.
+func (c ChartTolerance) Band(score float64) ToleranceBand {
+	switch {
+	case score >= c.PassScore:
+		return BandPass
+	case score >= c.WarnScore:
+		return BandWarn
+	default:
+		return BandFail
+	}
+}
+
+// CompareChart downsamples both images to luminance blocks and compares
+// their structural similarity, classifying the score into tolerance bands.
+func CompareChart(expected, actual image.Image, tol ChartTolerance) DiffResult {
+	if expected.Bounds().Size() != actual.Bounds().Size() {
+		return DiffResult{SizeMismatch: true, Band: BandFail}
+	}
+
+	block := tol.Downsample
+	if block < 1 {
+		block = 1
+	}
+
+	score := structuralSimilarity(luminanceGrid(expected, block), luminanceGrid(actual, block))
+	band := tol.Band(score)
+
+	return DiffResult{
+		Match:       band != BandFail,
+		TotalPixels: expected.Bounds().Dx() * expected.Bounds().Dy(),
+		Similarity:  score,
+		Band:        band,
+	}
+}
+
+// CompareSSIM scores the structural similarity of two images at full
+// resolution. The images match when the score is at least threshold (0-1).
+func CompareSSIM(expected, actual image.Image, threshold float64) DiffResult {
+	return CompareChart(expected, actual, ChartTolerance{
+		Downsample: 1,
+		PassScore:  threshold,
+		WarnScore:  threshold,
+	})
+}
+
+// grayGrid is a row-major grid of luminance samples.
+type grayGrid struct {
+	w, h int
+	v    []float64
+}
+
+func (g grayGrid) at(x, y int) float64 {
+	return g.v[y*g.w+x]
+}
+
+// luminanceGrid averages the luminance of each block×block cell of img.
+func luminanceGrid(img image.Image, block int) grayGrid {
+	p := newPixelBuffer(img)
+	defer p.release()
+	g := grayGrid{w: (p.w + block - 1) / block, h: (p.h + block - 1) / block}
+	g.v = make([]float64, g.w*g.h)
+	counts := make([]int, len(g.v))
+
+	for y := 0; y < p.h; y++ {
+		for x := 0; x < p.w; x++ {
+			px := p.at(x, y)
+			lum := 0.299*float64(px[0]) + 0.587*float64(px[1]) + 0.114*float64(px[2])
+			i := (y/block)*g.w + x/block
+			g.v[i] += lum
+			counts[i]++
+		}
+	}
+
+	for i := range g.v {
+		if counts[i] > 0 {
+			g.v[i] /= float64(counts[i])
+		}
+	}
+	return g
+}
+
+// structuralSimilarity returns the mean SSIM over 8x8 windows of two
+// equally sized luminance grids.
+func structuralSimilarity(a, b grayGrid) float64 {
+	const (
+		c1 = (0.01 * 255) * (0.01 * 255)
+		c2 = (0.03 * 255) * (0.03 * 255)
+	)
+
+	win := 8
+	if a.w < win || a.h < win {
+		win = minInt(a.w, a.h)
+	}
+	if win == 0 {
+		return 1
+	}
+
+	total, windows := 0.0, 0
+	for y0 := 0; y0+win <= a.h; y0 += win {
+		for x0 := 0; x0+win <= a.w; x0 += win {
+			var sumA, sumB, sumAA, sumBB, sumAB float64
+			for y := y0; y < y0+win; y++ {
+				for x := x0; x < x0+win; x++ {
+					va, vb := a.at(x, y), b.at(x, y)
+					sumA += va
+					sumB += vb
+					sumAA += va * va
+					sumBB += vb * vb
+					sumAB += va * vb
+				}
+			}
+
+			n := float64(win * win)
+			muA, muB := sumA/n, sumB/n
+			varA := sumAA/n - muA*muA
+			varB := sumBB/n - muB*muB
+			cov := sumAB/n - muA*muB
+
+			total += ((2*muA*muB + c1) * (2*cov + c2)) / ((muA*muA + muB*muB + c1) * (varA + varB + c2))
+			windows++
+		}
+	}
+
+	return total / float64(windows)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// deltaHistogram counts pixels by their largest 8-bit channel difference.
+// Both images must have the same size.
+func deltaHistogram(expected, actual image.Image) [256]int {
+	var hist [256]int
+	e, a := newPixelBuffer(expected), newPixelBuffer(actual)
+	defer e.release()
+	defer a.release()
+	for i := 0; i < len(e.pix); i += 4 {
+		hist[maxChannelDelta(e.pix[i:i+4], a.pix[i:i+4])]++
+	}
+	return hist
+}
@@ -0,0 +1,29 @@
+package fynetest
+
+import "image"
+
+// ImageComparer decides whether a capture matches its baseline. Implement it
+// to plug custom comparison rules into the runner (Runner.Comparer) and into
+// vfyne snapshots (WithComparer) without forking the package. Tolerance and
+// ChartTolerance are the built-in implementations.
+type ImageComparer interface {
+	Compare(expected, actual image.Image) (DiffResult, error)
+}
+
+// ImageComparerFunc adapts a plain function to ImageComparer.
+type ImageComparerFunc func(expected, actual image.Image) (DiffResult, error)
+
+// Compare calls f(expected, actual).
+func (f ImageComparerFunc) Compare(expected, actual image.Image) (DiffResult, error) {
+	return f(expected, actual)
+}
+
+// Compare implements ImageComparer using CompareImages.
+func (t Tolerance) Compare(expected, actual image.Image) (DiffResult, error) {
+	return CompareImages(expected, actual, t), nil
+}
+
+// Compare implements ImageComparer using CompareChart.
+func (c ChartTolerance) Compare(expected, actual image.Image) (DiffResult, error) {
+	return CompareChart(expected, actual, c), nil
+}
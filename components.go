@@ -0,0 +1,363 @@
+package fynetest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// ComponentsReportName is the file name of the shared components page
+// written next to the HTML report.
+const ComponentsReportName = "components.html"
+
+// DefaultMinSharedTests is how many tests a widget subtree must appear in to
+// be reported as a shared component.
+const DefaultMinSharedTests = 3
+
+// minComponentNodes keeps single widgets and trivial boxes, which appear
+// everywhere, out of the shared components.
+const minComponentNodes = 4
+
+// SharedComponent is a widget subtree that appears, with the same
+// structure, in several tests: typically a header, toolbar or card reused
+// across screens. A change to it shows up in every test using it, so its
+// uses are reviewed together.
+type SharedComponent struct {
+	// Hash identifies the structure: types, names and nesting, without
+	// text, values or geometry, which vary between contexts
+	Hash string
+
+	// Type and Name are those of the subtree's root widget
+	Type string
+	Name string
+
+	// Nodes is the number of widgets in the subtree
+	Nodes int
+
+	// Uses are the places the component was rendered, in result order
+	Uses []ComponentUse
+}
+
+// ComponentUse is one place a shared component was rendered.
+type ComponentUse struct {
+	Result *Result
+
+	// Path locates the component in the widget tree, as in WidgetChange
+	Path string
+
+	// Region is where the component was drawn, in canvas coordinates
+	Region Region
+
+	// Changes are the widget changes of the result inside the component
+	Changes []WidgetChange
+}
+
+// Label names the component by its root widget.
+func (c SharedComponent) Label() string {
+	if c.Name != "" {
+		return c.Type + "[name=" + c.Name + "]"
+	}
+	return c.Type
+}
+
+// Tests returns the number of distinct tests using the component.
+func (c SharedComponent) Tests() int {
+	return countTests(c.Uses, func(ComponentUse) bool { return true })
+}
+
+// FailedTests returns the number of distinct failing tests whose widget
+// changes fall inside the component, which likely fail because of it.
+func (c SharedComponent) FailedTests() int {
+	return countTests(c.Uses, func(use ComponentUse) bool { return !use.Result.Success && len(use.Changes) > 0 })
+}
+
+func countTests(uses []ComponentUse, include func(ComponentUse) bool) int {
+	names := make(map[string]bool)
+	for _, use := range uses {
+		if include(use) {
+			names[use.Result.Test.Name] = true
+		}
+	}
+	return len(names)
+}
+
+// FindSharedComponents finds the widget subtrees that appear in at least
+// minTests distinct tests, using the widget trees recorded with results.
+// Runs of one test under several profiles count once. A component only ever
+// found inside a larger shared component is left out. Components with the
+// most failing tests come first.
+func FindSharedComponents(results []Result, minTests int) []SharedComponent {
+	byHash := make(map[string]*SharedComponent)
+	for i := range results {
+		result := &results[i]
+		if result.WidgetTree == nil {
+			continue
+		}
+		walkComponents(result.WidgetTree, "", fyne.Position{}, true, func(node *WidgetNode, hash, path string, nodes int, region Region) {
+			if nodes < minComponentNodes {
+				return
+			}
+			component := byHash[hash]
+			if component == nil {
+				component = &SharedComponent{Hash: hash, Type: node.Type, Name: node.Name, Nodes: nodes}
+				byHash[hash] = component
+			}
+			component.Uses = append(component.Uses, ComponentUse{
+				Result:  result,
+				Path:    path,
+				Region:  region,
+				Changes: changesWithin(result.WidgetChanges, path),
+			})
+		})
+	}
+
+	candidates := make([]SharedComponent, 0)
+	for _, component := range byHash {
+		if component.Tests() >= minTests {
+			candidates = append(candidates, *component)
+		}
+	}
+
+	// Keep the largest components, and smaller ones only where they are
+	// used outside of them
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Nodes != candidates[j].Nodes {
+			return candidates[i].Nodes > candidates[j].Nodes
+		}
+		return candidates[i].Hash < candidates[j].Hash
+	})
+	covered := make(map[*Result][]string)
+	components := make([]SharedComponent, 0)
+	for _, component := range candidates {
+		inside := true
+		for _, use := range component.Uses {
+			if !pathWithin(use.Path, covered[use.Result]) {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			continue
+		}
+		for _, use := range component.Uses {
+			covered[use.Result] = append(covered[use.Result], use.Path)
+		}
+		components = append(components, component)
+	}
+
+	sort.SliceStable(components, func(i, j int) bool {
+		if fi, fj := components[i].FailedTests(), components[j].FailedTests(); fi != fj {
+			return fi > fj
+		}
+		return components[i].Tests() > components[j].Tests()
+	})
+	return components
+}
+
+// walkComponents visits every subtree of node with its structural hash,
+// path, widget count and canvas region, and returns the hash and count of
+// node itself. The root of a test is skipped: a whole screen is not a
+// component.
+func walkComponents(node *WidgetNode, path string, origin fyne.Position, root bool,
+	visit func(node *WidgetNode, hash, path string, nodes int, region Region)) (string, int) {
+	pos := fyne.NewPos(origin.X+node.Position.X, origin.Y+node.Position.Y)
+	if root {
+		path = node.Type
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", node.Type, node.Name)
+	nodes := 1
+	keys, byKey := keyNodes(node.Children)
+	for _, key := range keys {
+		childHash, childNodes := walkComponents(byKey[key], joinPath(path, key), pos, false, visit)
+		fmt.Fprintf(h, "(%s)", childHash)
+		nodes += childNodes
+	}
+	hash := hex.EncodeToString(h.Sum(nil))[:16]
+
+	if !root && !node.Hidden {
+		visit(node, hash, path, nodes, Region{X: pos.X, Y: pos.Y, Width: node.Size.Width, Height: node.Size.Height})
+	}
+	return hash, nodes
+}
+
+// changesWithin returns the changes at or below path.
+func changesWithin(changes []WidgetChange, path string) []WidgetChange {
+	within := make([]WidgetChange, 0)
+	for _, change := range changes {
+		if pathWithin(change.Path, []string{path}) {
+			within = append(within, change)
+		}
+	}
+	return within
+}
+
+// pathWithin reports whether path is one of parents or below one of them.
+func pathWithin(path string, parents []string) bool {
+	for _, parent := range parents {
+		if path == parent || strings.HasPrefix(path, parent+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateComponentsReport writes a page with one section per shared
+// component, showing the component cropped from every test it appears in
+// and the widget changes inside it. A change to a shared header that breaks
+// forty tests is reviewed there once.
+func (g *ReportGenerator) GenerateComponentsReport(components []SharedComponent, outputPath string) error {
+	dir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	tmpl, err := g.createTemplate(dir)
+	if err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+	if tmpl, err = tmpl.New("components").Parse(componentsTemplate); err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+
+	data := componentsData{Title: g.Title, StyleSheet: g.StyleSheet, Timestamp: time.Now()}
+	for _, component := range components {
+		section := componentSection{SharedComponent: component, ID: "component-" + component.Hash}
+		for _, use := range component.Uses {
+			section.Contexts = append(section.Contexts, componentContext{ComponentUse: use, Crop: componentCrop(use)})
+		}
+		data.Components = append(data.Components, section)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create components report: %w", err)
+	}
+	defer file.Close()
+	if err := tmpl.ExecuteTemplate(file, "components", data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return nil
+}
+
+type componentsData struct {
+	Title      string
+	StyleSheet string
+	Timestamp  time.Time
+	Components []componentSection
+}
+
+type componentSection struct {
+	SharedComponent
+	ID       string
+	Contexts []componentContext
+}
+
+type componentContext struct {
+	ComponentUse
+	Crop *pixelCrop
+}
+
+// pixelCrop positions a screenshot so only the component shows.
+type pixelCrop struct {
+	Screenshot string
+	Alt        string
+	Rect       image.Rectangle
+	ImageWidth int
+}
+
+// componentCrop returns the pixel rectangle of use in its screenshot, or nil
+// when the screenshot can't be mapped to canvas coordinates.
+func componentCrop(use ComponentUse) *pixelCrop {
+	result := use.Result
+	window, ok := result.Metadata["window_size"].(fyne.Size)
+	if !ok || window.Width <= 0 || result.ScreenshotPath == "" || result.ImageSize.Width <= 0 {
+		return nil
+	}
+
+	region := use.Region
+	size := window
+	if result.Test.CropToContent && result.WidgetTree != nil {
+		// The screenshot starts at the content, not the window
+		region.X -= result.WidgetTree.Position.X
+		region.Y -= result.WidgetTree.Position.Y
+		size = result.WidgetTree.Size
+	}
+	scale := float64(result.ImageSize.Width) / float64(size.Width)
+	rect := image.Rect(
+		int(math.Floor(float64(region.X)*scale)),
+		int(math.Floor(float64(region.Y)*scale)),
+		int(math.Ceil(float64(region.X+region.Width)*scale)),
+		int(math.Ceil(float64(region.Y+region.Height)*scale)),
+	).Intersect(image.Rect(0, 0, int(result.ImageSize.Width), int(result.ImageSize.Height)))
+	if rect.Empty() {
+		return nil
+	}
+	return &pixelCrop{
+		Screenshot: result.ScreenshotPath,
+		Alt:        result.Test.AltText(),
+		Rect:       rect,
+		ImageWidth: int(result.ImageSize.Width),
+	}
+}
+
+const componentsTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Shared components · {{.Title}}</title>
+    <style>
+{{.StyleSheet}}
+        .component { margin-bottom: 32px; }
+        .component-contexts { display: flex; flex-wrap: wrap; gap: 16px; }
+        .component-context { margin: 0; max-width: 100%; }
+        .component-crop { overflow: hidden; max-width: 100%; border: 1px solid #ddd; }
+        .component-crop img { display: block; max-width: none; }
+        .component-context.failed .component-crop { border-color: #dc2626; }
+    </style>
+</head>
+<body>
+    <header class="header">
+        <h1>Shared components</h1>
+        <p><a href="index.html">← {{.Title}}</a></p>
+        <p class="timestamp">Generated: <time datetime="{{.Timestamp.Format "2006-01-02T15:04:05Z07:00"}}">{{formatTime .Timestamp}}</time></p>
+    </header>
+    <main class="tests">
+        {{if not .Components}}<p>No widget subtree appears in enough tests to count as shared.</p>{{end}}
+        {{range .Components}}
+        <section class="section component" id="{{.ID}}" aria-labelledby="{{.ID}}-title">
+            <h2 id="{{.ID}}-title">{{.Label}}</h2>
+            <p>{{.Nodes}} widgets, used in {{.Tests}} tests{{with .FailedTests}}, <strong>changed in {{.}} failing tests</strong>{{end}}</p>
+            <div class="component-contexts">
+                {{range .Contexts}}
+                <figure class="component-context{{if not .Result.Success}} failed{{end}}">
+                    {{with .Crop}}
+                    <div class="component-crop" style="width: {{.Rect.Dx}}px; height: {{.Rect.Dy}}px">
+                        <img src="{{imgsrc .Screenshot}}" alt="{{.Alt}}" loading="lazy" style="width: {{.ImageWidth}}px; margin: -{{.Rect.Min.Y}}px 0 0 -{{.Rect.Min.X}}px">
+                    </div>
+                    {{end}}
+                    <figcaption>
+                        {{if .Result.Success}}✅{{else}}❌{{end}} {{.Result.Test.Name}}{{with .Result.Profile}} ({{.}}){{end}}
+                        <br><code>{{.Path}}</code>
+                        {{with .Changes}}<ul>{{range .}}<li>{{.String}}</li>{{end}}</ul>{{end}}
+                    </figcaption>
+                </figure>
+                {{end}}
+            </div>
+        </section>
+        {{end}}
+    </main>
+</body>
+</html>
+`
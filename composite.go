@@ -0,0 +1,62 @@
+package fynetest
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/jairo/vfyne/internal/bitmapfont"
+)
+
+const (
+	compositeGap   = 8
+	compositeScale = 2
+)
+
+var (
+	compositeBackground = color.NRGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+	compositeLabel      = color.NRGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
+)
+
+// CompositeImage lays expected, actual and diff out side by side, each under
+// a label, so a mismatch can be reviewed from a single CI artifact. A nil
+// diff, e.g. when the sizes differ, leaves its panel out.
+func CompositeImage(expected, actual, diff image.Image) image.Image {
+	panels := []struct {
+		label string
+		img   image.Image
+	}{
+		{"EXPECTED", expected},
+		{"ACTUAL", actual},
+		{"DIFF", diff},
+	}
+
+	labelHeight := bitmapfont.GlyphHeight*compositeScale + 2*compositeGap
+	width, height := compositeGap, 0
+	for _, p := range panels {
+		if p.img == nil {
+			continue
+		}
+		b := p.img.Bounds()
+		width += b.Dx() + compositeGap
+		if b.Dy() > height {
+			height = b.Dy()
+		}
+	}
+	height += labelHeight + compositeGap
+
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(out, out.Bounds(), image.NewUniform(compositeBackground), image.Point{}, draw.Src)
+
+	x := compositeGap
+	for _, p := range panels {
+		if p.img == nil {
+			continue
+		}
+		b := p.img.Bounds()
+		bitmapfont.Draw(out, x, compositeGap, compositeScale, p.label, compositeLabel)
+		draw.Draw(out, image.Rect(x, labelHeight, x+b.Dx(), labelHeight+b.Dy()), p.img, b.Min, draw.Over)
+		x += b.Dx() + compositeGap
+	}
+	return out
+}
@@ -0,0 +1,157 @@
+// Package contrib provides ready-made content factories that reproduce common
+// application layouts. They are intended for checking theme and size changes
+// against realistic screens without writing the UI by hand.
+//
+// Every factory returns a setup function, so it plugs straight into a builder:
+//
+//	fynetest.NewTest("login_dark").
+//		WithTheme(theme.DarkTheme()).
+//		WithSetup(contrib.LoginForm())
+package contrib
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/jairo/vfyne/faker"
+)
+
+// sampleSeed keeps generated template data identical across runs.
+const sampleSeed = 1
+
+// LoginForm returns a setup function for a typical sign-in screen with
+// username and password entries, a remember-me check and a login button.
+func LoginForm() func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		username := widget.NewEntry()
+		username.SetPlaceHolder("Username or email")
+
+		password := widget.NewPasswordEntry()
+		password.SetPlaceHolder("Password")
+
+		remember := widget.NewCheck("Remember me", func(bool) {})
+
+		login := widget.NewButtonWithIcon("Sign in", theme.LoginIcon(), func() {})
+		login.Importance = widget.HighImportance
+
+		return container.NewVBox(
+			widget.NewLabelWithStyle("Welcome back", fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+			widget.NewForm(
+				widget.NewFormItem("Username", username),
+				widget.NewFormItem("Password", password),
+			),
+			remember,
+			login,
+			widget.NewButton("Forgot password?", func() {}),
+		)
+	}
+}
+
+// SettingsPage returns a setup function for a preferences screen combining
+// the most common input widgets grouped into cards.
+func SettingsPage() func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		notifications := widget.NewCheck("Enable notifications", func(bool) {})
+		notifications.SetChecked(true)
+
+		sounds := widget.NewCheck("Play sounds", func(bool) {})
+
+		language := widget.NewSelect([]string{"English", "Español", "Deutsch", "Français"}, func(string) {})
+		language.SetSelected("English")
+
+		appearance := widget.NewRadioGroup([]string{"System", "Light", "Dark"}, func(string) {})
+		appearance.SetSelected("System")
+
+		volume := widget.NewSlider(0, 100)
+		volume.SetValue(65)
+
+		storage := widget.NewProgressBar()
+		storage.SetValue(0.42)
+
+		return container.NewVBox(
+			widget.NewCard("General", "", container.NewVBox(notifications, sounds)),
+			widget.NewCard("Appearance", "", widget.NewForm(
+				widget.NewFormItem("Language", language),
+				widget.NewFormItem("Theme", appearance),
+				widget.NewFormItem("Volume", volume),
+			)),
+			widget.NewCard("Storage", "42% of 10 GB used", storage),
+			container.NewHBox(
+				layout.NewSpacer(),
+				widget.NewButton("Cancel", func() {}),
+				&widget.Button{Text: "Save", Importance: widget.HighImportance, OnTapped: func() {}},
+			),
+		)
+	}
+}
+
+// DataTable returns a setup function for a table with a header row and the
+// given number of data rows. Cell contents come from a fixed-seed faker so
+// they are identical on every run.
+func DataTable(rows int) func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		headers := []string{"ID", "Name", "Email", "Status"}
+		f := faker.New(sampleSeed)
+		data := make([][]string, rows)
+		for i := range data {
+			name := f.Name()
+			data[i] = []string{fmt.Sprintf("%04d", i+1), name, f.EmailFor(name), f.Pick("Active", "Pending", "Suspended")}
+		}
+
+		table := widget.NewTable(
+			func() (int, int) { return rows + 1, len(headers) },
+			func() fyne.CanvasObject { return widget.NewLabel("template text") },
+			func(id widget.TableCellID, cell fyne.CanvasObject) {
+				label := cell.(*widget.Label)
+				if id.Row == 0 {
+					label.TextStyle = fyne.TextStyle{Bold: true}
+					label.SetText(headers[id.Col])
+					return
+				}
+
+				label.TextStyle = fyne.TextStyle{}
+				label.SetText(data[id.Row-1][id.Col])
+			},
+		)
+		table.SetColumnWidth(0, 60)
+		table.SetColumnWidth(1, 160)
+		table.SetColumnWidth(2, 220)
+		table.SetColumnWidth(3, 100)
+
+		return table
+	}
+}
+
+// ChatList returns a setup function for a conversation list showing the
+// given number of messages alternating between two participants.
+func ChatList(messages int) func() fyne.CanvasObject {
+	return func() fyne.CanvasObject {
+		f := faker.New(sampleSeed)
+		avatars := []fyne.Resource{f.Avatar(64), f.Avatar(64)}
+		lines := make([]string, messages)
+		for i := range lines {
+			lines[i] = f.Sentence()
+		}
+
+		list := widget.NewList(
+			func() int { return len(lines) },
+			func() fyne.CanvasObject {
+				return container.NewHBox(widget.NewIcon(theme.AccountIcon()), widget.NewLabel("template message"))
+			},
+			func(id widget.ListItemID, item fyne.CanvasObject) {
+				row := item.(*fyne.Container)
+				row.Objects[0].(*widget.Icon).SetResource(avatars[id%2])
+				row.Objects[1].(*widget.Label).SetText(lines[id])
+			},
+		)
+
+		input := widget.NewEntry()
+		input.SetPlaceHolder("Type a message...")
+
+		return container.NewBorder(nil, container.NewBorder(nil, nil, nil, widget.NewButtonWithIcon("", theme.MailSendIcon(), func() {}), input), nil, nil, list)
+	}
+}
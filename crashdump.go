@@ -0,0 +1,125 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// crashSuffix is appended to a test's capture name to name its crash dump.
+const crashSuffix = "_crash.txt"
+
+// safeCapture runs capture, turning a panic into an error. It returns the
+// stack of the panicking goroutine, if any.
+func (r *Runner) safeCapture(app fyne.App, test Test) (shot windowCapture, stack []byte, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			stack = debug.Stack()
+			if cp, ok := p.(capturePanic); ok {
+				p, stack = cp.value, cp.stack
+			}
+			err = fmt.Errorf("capture panicked: %v", p)
+		}
+	}()
+	shot, err = r.capture(app, test)
+	return shot, nil, err
+}
+
+// writeCrashDump writes what is needed to diagnose a failed capture without
+// reproducing it: the error, the environment, the widget tree built so far
+// and the goroutine stacks. It returns the path of the dump in OutputDir.
+func (r *Runner) writeCrashDump(test Test, captureErr error, stack []byte, content fyne.CanvasObject, th fyne.Theme) (string, error) {
+	var b strings.Builder
+	now := time.Now()
+
+	fmt.Fprintf(&b, "vfyne crash dump\n\n")
+	fmt.Fprintf(&b, "Test:    %s\n", test.Name)
+	fmt.Fprintf(&b, "Time:    %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Error:   %v\n", captureErr)
+
+	b.WriteString("\n== Environment ==\n")
+	fmt.Fprintf(&b, "fyne:          %s\n", orUnknown(FyneVersion()))
+	fmt.Fprintf(&b, "go:            %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "GL renderer:   %t (built with gl tag: %t)\n", GLAvailable(), glSupported)
+	for _, key := range []string{"DISPLAY", "WAYLAND_DISPLAY", "FYNE_SCALE", "FYNE_THEME", "FYNE_FONT", "LIBGL_ALWAYS_SOFTWARE"} {
+		if value, ok := os.LookupEnv(key); ok {
+			fmt.Fprintf(&b, "%-14s %s\n", key+":", value)
+		}
+	}
+	fmt.Fprintf(&b, "theme:         %s\n", getThemeName(th))
+	if th != nil {
+		for _, style := range []fyne.TextStyle{{}, {Bold: true}, {Italic: true}, {Monospace: true}} {
+			if font := th.Font(style); font != nil {
+				fmt.Fprintf(&b, "font %-9s %s (%d bytes)\n", fontStyleName(style)+":", font.Name(), len(font.Content()))
+			}
+		}
+	}
+	if test.Size != nil {
+		fmt.Fprintf(&b, "window size:   %gx%g\n", test.Size.Width, test.Size.Height)
+	}
+	if test.Scale > 0 {
+		fmt.Fprintf(&b, "scale:         %g\n", test.Scale)
+	}
+
+	b.WriteString("\n== Widget tree ==\n")
+	b.WriteString(partialTree(content))
+
+	if len(stack) > 0 {
+		b.WriteString("\n== Panic stack ==\n")
+		b.Write(stack)
+	}
+
+	b.WriteString("\n== Goroutines ==\n")
+	buf := make([]byte, 1<<20)
+	b.Write(buf[:runtime.Stack(buf, true)])
+
+	path := filepath.Join(r.OutputDir, fmt.Sprintf("%s_%s%s", sanitizeFilename(test.Name), now.Format(runTimestamp), crashSuffix))
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// partialTree describes the content built by Setup. Widgets that are half
+// initialised may panic when inspected, so whatever was recorded is kept.
+func partialTree(content fyne.CanvasObject) (tree string) {
+	if content == nil {
+		return "(Setup did not return any content)\n"
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tree = fmt.Sprintf("(reading the widget tree panicked: %v)\n", p)
+		}
+	}()
+	data, err := json.MarshalIndent(CaptureWidgetTree(content), "", "  ")
+	if err != nil {
+		return fmt.Sprintf("(%v)\n", err)
+	}
+	return string(data) + "\n"
+}
+
+func fontStyleName(style fyne.TextStyle) string {
+	switch {
+	case style.Bold:
+		return "bold"
+	case style.Italic:
+		return "italic"
+	case style.Monospace:
+		return "monospace"
+	}
+	return "regular"
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
@@ -0,0 +1,38 @@
+package fynetest
+
+import (
+	"image"
+	"image/draw"
+
+	"fyne.io/fyne/v2"
+)
+
+// CropToObject crops img, a capture of canvas c, to the pixels obj is
+// rendered at and moves the ignored rectangles into the cropped image, so
+// window padding and empty space around a small widget neither dilute its
+// diffs nor bloat its baseline. The result always starts at the origin.
+// When obj is not visible on the capture img is returned unchanged.
+func CropToObject(c fyne.Canvas, img image.Image, obj fyne.CanvasObject, ignored []image.Rectangle) (image.Image, []image.Rectangle) {
+	crop, ok := contentCrop(c, img, obj)
+	if !ok {
+		return img, ignored
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, crop.Dx(), crop.Dy()))
+	draw.Draw(out, out.Bounds(), img, crop.Min, draw.Src)
+
+	rects := make([]image.Rectangle, 0, len(ignored))
+	for _, rect := range ignored {
+		if rect = rect.Intersect(crop); !rect.Empty() {
+			rects = append(rects, rect.Sub(crop.Min))
+		}
+	}
+	return out, rects
+}
+
+// contentCrop returns the pixel rectangle of img CropToObject crops obj to,
+// reporting false when it leaves img unchanged.
+func contentCrop(c fyne.Canvas, img image.Image, obj fyne.CanvasObject) (image.Rectangle, bool) {
+	crop := PixelRect(c, img, RegionOf(obj))
+	return crop, !crop.Empty() && crop != img.Bounds()
+}
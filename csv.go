@@ -0,0 +1,77 @@
+package fynetest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CSVReportName is the file name of the CSV report in a run directory.
+const CSVReportName = "summary.csv"
+
+// csvHeader names the columns of the CSV report.
+var csvHeader = []string{"name", "profile", "tags", "status", "duration_ms", "width", "height", "diff_pixels", "diff_percent", "error"}
+
+// GenerateCSVReport writes one row per test to outputPath: name, profile,
+// tags (separated by ";"), status, duration in milliseconds, image size,
+// changed pixels and error, for tracking visual test metrics in a
+// spreadsheet or BI tool. The diff columns are empty for tests that were
+// not compared with a baseline.
+func (g *ReportGenerator) GenerateCSVReport(results []Result, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV report: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write(csvHeader)
+	for _, result := range results {
+		w.Write(csvRow(result))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV report: %w", err)
+	}
+	return file.Close()
+}
+
+func csvRow(result Result) []string {
+	status := "passed"
+	switch {
+	case result.Skipped:
+		status = "skipped"
+	case result.ExpectedFailure:
+		status = "expected failure"
+	case result.UnexpectedPass:
+		status = "unexpected pass"
+	case !result.Success:
+		status = "failed"
+	}
+	row := []string{
+		result.Test.Name,
+		result.Profile,
+		strings.Join(result.Test.Tags, ";"),
+		status,
+		strconv.FormatFloat(float64(result.Duration.Microseconds())/1000, 'f', 3, 64),
+		strconv.Itoa(int(result.ImageSize.Width)),
+		strconv.Itoa(int(result.ImageSize.Height)),
+		"",
+		"",
+		"",
+	}
+	if result.Diff != nil {
+		row[7] = strconv.Itoa(result.Diff.DiffPixels)
+		row[8] = strconv.FormatFloat(result.Diff.DiffPercent, 'f', 4, 64)
+	}
+	if result.Error != nil {
+		row[9] = result.Error.Error()
+	}
+	return row
+}
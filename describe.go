@@ -0,0 +1,96 @@
+package fynetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// MetadataScreenSummary is the result metadata key holding the description
+// of the captured screen written by Runner.Describer.
+const MetadataScreenSummary = "screen_summary"
+
+// ScreenDescriber writes a short description of a captured screen, such as a
+// draft caption for documentation. Implement it to plug in a local heuristic
+// or a call to an LLM (Runner.Describer). The result it gets has its
+// screenshot, written to ScreenshotPath, and its widget tree.
+type ScreenDescriber interface {
+	DescribeScreen(result Result) (string, error)
+}
+
+// ScreenDescriberFunc adapts a plain function to ScreenDescriber.
+type ScreenDescriberFunc func(result Result) (string, error)
+
+// DescribeScreen calls f(result).
+func (f ScreenDescriberFunc) DescribeScreen(result Result) (string, error) {
+	return f(result)
+}
+
+// HeuristicDescriber describes screens locally from their widget tree, with
+// the summary of NewAIScreen, e.g. `Screen "Login" (400x300) shows 2 text
+// inputs ("Email", "Password") and 1 button ("Sign in").`
+var HeuristicDescriber ScreenDescriber = ScreenDescriberFunc(func(result Result) (string, error) {
+	if result.WidgetTree == nil {
+		return "", fmt.Errorf("no widget tree to describe")
+	}
+	return NewAIScreen(result.Test, result.Tree()).Summary, nil
+})
+
+// CommandDescriber describes screens by running an external command, such as
+// a script calling an LLM. The command gets the screen's AIScreen as JSON on
+// stdin and the absolute screenshot path in VFYNE_SCREENSHOT, and prints the
+// description; surrounding whitespace is trimmed. A command that exits with
+// an error fails the description, not the test.
+func CommandDescriber(name string, args ...string) ScreenDescriber {
+	return ScreenDescriberFunc(func(result Result) (string, error) {
+		screen := NewAIScreen(result.Test, result.Tree())
+		screenshot := result.ScreenshotPath
+		if abs, err := filepath.Abs(screenshot); err == nil && screenshot != "" {
+			screenshot = abs
+		}
+		screen.Screenshot = screenshot
+		input, err := json.Marshal(screen)
+		if err != nil {
+			return "", err
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(name, args...)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		cmd.Env = append(os.Environ(), "VFYNE_SCREENSHOT="+screenshot)
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("%s: %w: %s", name, err, msg)
+			}
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		return strings.TrimSpace(stdout.String()), nil
+	})
+}
+
+// ParseDescriber returns the describer named by a -describe value:
+// "heuristic" for HeuristicDescriber, else a command line for
+// CommandDescriber, split on spaces.
+func ParseDescriber(value string) (ScreenDescriber, error) {
+	fields := strings.Fields(value)
+	switch {
+	case len(fields) == 0:
+		return nil, fmt.Errorf("empty describer")
+	case len(fields) == 1 && fields[0] == "heuristic":
+		return HeuristicDescriber, nil
+	}
+	return CommandDescriber(fields[0], fields[1:]...), nil
+}
+
+// ScreenSummary returns the description of the captured screen written by
+// Runner.Describer, if any.
+func (r Result) ScreenSummary() string {
+	summary, _ := r.Metadata[MetadataScreenSummary].(string)
+	return summary
+}
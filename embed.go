@@ -0,0 +1,35 @@
+package fynetest
+
+import (
+	"encoding/base64"
+	"html/template"
+	"mime"
+	"os"
+	"path/filepath"
+)
+
+// imageSource returns a src attribute for the image at path: a data URI
+// when EmbedImages is set, so the report works as a single file, else the
+// path relative to baseDir. Images that can't be read keep their relative
+// path. Encoded images are cached, since a screenshot may be shown twice.
+func (g *ReportGenerator) imageSource(baseDir string, cache map[string]template.URL) func(string) template.URL {
+	return func(path string) template.URL {
+		if !g.EmbedImages || path == "" {
+			return template.URL(relativePath(baseDir, path))
+		}
+		if uri, ok := cache[path]; ok {
+			return uri
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return template.URL(relativePath(baseDir, path))
+		}
+		mimeType := mime.TypeByExtension(filepath.Ext(path))
+		if mimeType == "" {
+			mimeType = "image/png"
+		}
+		uri := template.URL("data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data))
+		cache[path] = uri
+		return uri
+	}
+}
@@ -0,0 +1,235 @@
+package fynetest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+)
+
+// CheckStatus is the outcome of an environment check.
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// EnvCheck is the result of one capture environment check.
+type EnvCheck struct {
+	// Name is what was checked, e.g. "display"
+	Name string
+
+	Status CheckStatus
+
+	// Detail describes what was found
+	Detail string
+
+	// Fix suggests how to resolve a warning or failure
+	Fix string
+}
+
+// CheckEnvironment verifies that this process can take reliable captures:
+// display availability, the renderer actually drawing pixels and text,
+// fontconfig, write access to outputDir and the locale. Captures that come
+// out black or without text almost always fail one of these.
+func CheckEnvironment(outputDir string) []EnvCheck {
+	return []EnvCheck{
+		checkDisplay(),
+		checkRenderer(),
+		checkText(),
+		checkFontconfig(),
+		checkWritable(outputDir),
+		checkLocale(),
+	}
+}
+
+func checkDisplay() EnvCheck {
+	check := EnvCheck{Name: "display"}
+	switch {
+	case runtime.GOOS != "linux" && runtime.GOOS != "freebsd" && runtime.GOOS != "openbsd" && runtime.GOOS != "netbsd":
+		check.Status, check.Detail = CheckPass, "native display on "+runtime.GOOS
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		check.Status, check.Detail = CheckPass, "WAYLAND_DISPLAY="+os.Getenv("WAYLAND_DISPLAY")
+	case os.Getenv("DISPLAY") != "":
+		check.Status, check.Detail = CheckPass, "DISPLAY="+os.Getenv("DISPLAY")
+	case glSupported:
+		check.Status, check.Detail = CheckFail, "no DISPLAY or WAYLAND_DISPLAY, but built with -tags gl"
+		check.Fix = "run under a virtual display: xvfb-run -a fynetest ..., or build without -tags gl"
+	default:
+		check.Status, check.Detail = CheckPass, "headless; the software renderer needs no display"
+	}
+	return check
+}
+
+// probeColor is drawn by checkRenderer; black or transparent captures are
+// what a broken renderer produces.
+var probeColor = color.NRGBA{R: 0xe5, G: 0x39, B: 0x35, A: 0xff}
+
+func checkRenderer() EnvCheck {
+	check := EnvCheck{Name: "renderer"}
+	renderer := "software"
+	img, err := probeCapture(func() fyne.CanvasObject { return canvas.NewRectangle(probeColor) })
+	if err != nil {
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("%s renderer failed: %v", renderer, err)
+		check.Fix = "check that fyne's test driver works here: go test fyne.io/fyne/v2/test"
+		return check
+	}
+	b := img.Bounds()
+	got := color.NRGBAModel.Convert(img.At(b.Min.X+b.Dx()/2, b.Min.Y+b.Dy()/2)).(color.NRGBA)
+	if channelDelta(got, probeColor) > 8 {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("%s renderer drew #%02x%02x%02x%02x instead of #%02x%02x%02x%02x",
+			renderer, got.R, got.G, got.B, got.A, probeColor.R, probeColor.G, probeColor.B, probeColor.A)
+		check.Fix = "screenshots will come out black or empty; check FYNE_THEME and FYNE_SCALE, and that the fyne module isn't replaced by a broken fork"
+		return check
+	}
+	check.Status, check.Detail = CheckPass, fmt.Sprintf("%s renderer drew a %dx%d probe correctly (GL available: %t)", renderer, b.Dx(), b.Dy(), GLAvailable())
+	return check
+}
+
+func checkText() EnvCheck {
+	check := EnvCheck{Name: "text"}
+	img, err := probeCapture(func() fyne.CanvasObject {
+		text := canvas.NewText("Hg", color.Black)
+		text.TextSize = 24
+		return container.NewStack(canvas.NewRectangle(color.White), container.NewCenter(text))
+	})
+	if err != nil {
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("text capture failed: %v", err)
+		check.Fix = "check that fyne's test driver works here: go test fyne.io/fyne/v2/test"
+		return check
+	}
+
+	inked := 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c := color.GrayModel.Convert(img.At(x, y)).(color.Gray); c.Y < 128 {
+				inked++
+			}
+		}
+	}
+	if inked == 0 {
+		check.Status, check.Detail = CheckFail, "text rendered no glyphs"
+		check.Fix = "check FYNE_FONT points to a readable TTF file, or unset it to use the bundled fonts"
+		return check
+	}
+	check.Status, check.Detail = CheckPass, fmt.Sprintf("text rendered %d dark pixels", inked)
+	if font := os.Getenv("FYNE_FONT"); font != "" {
+		check.Detail += ", FYNE_FONT=" + font
+	}
+	return check
+}
+
+// probeCapture captures the content of setup in a fixed-size, unpadded
+// window with a throwaway runner.
+func probeCapture(setup func() fyne.CanvasObject) (image.Image, error) {
+	r := NewRunner()
+	defer r.Cleanup()
+
+	size := fyne.NewSize(64, 64)
+	shot, _, err := r.safeCapture(r.ensureApp(), Test{Name: "check-env", Setup: setup, Size: &size, Unpadded: true})
+	if err != nil {
+		return nil, err
+	}
+	return shot.img, nil
+}
+
+// channelDelta returns the largest difference between the channels of a and b.
+func channelDelta(a, b color.NRGBA) uint8 {
+	delta := uint8(0)
+	for _, pair := range [][2]uint8{{a.R, b.R}, {a.G, b.G}, {a.B, b.B}, {a.A, b.A}} {
+		d := pair[0] - pair[1]
+		if pair[1] > pair[0] {
+			d = pair[1] - pair[0]
+		}
+		if d > delta {
+			delta = d
+		}
+	}
+	return delta
+}
+
+func checkFontconfig() EnvCheck {
+	check := EnvCheck{Name: "fontconfig"}
+	if runtime.GOOS != "linux" && runtime.GOOS != "freebsd" {
+		check.Status, check.Detail = CheckPass, "not used on "+runtime.GOOS
+		return check
+	}
+	if _, err := exec.LookPath("fc-list"); err != nil {
+		check.Status, check.Detail = CheckWarn, "fc-list not found"
+		check.Fix = "install fontconfig and fonts (e.g. apt install fontconfig fonts-dejavu-core); fyne's bundled fonts still work, but system font fallback for other scripts and emoji won't"
+		return check
+	}
+	out, err := exec.Command("fc-list", "--format", "%{family}\n").Output()
+	if err != nil {
+		check.Status, check.Detail = CheckWarn, fmt.Sprintf("fc-list failed: %v", err)
+		check.Fix = "run fc-cache -f and check the fontconfig configuration"
+		return check
+	}
+	families := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			families[line] = true
+		}
+	}
+	if len(families) == 0 {
+		check.Status, check.Detail = CheckWarn, "no system fonts installed"
+		check.Fix = "install fonts (e.g. apt install fonts-dejavu-core fonts-noto) and run fc-cache -f"
+		return check
+	}
+	check.Status, check.Detail = CheckPass, fmt.Sprintf("%d font families installed", len(families))
+	return check
+}
+
+func checkWritable(dir string) EnvCheck {
+	check := EnvCheck{Name: "output directory"}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("cannot create %s: %v", dir, err)
+		check.Fix = "choose a writable directory with -output, or fix the permissions of " + dir
+		return check
+	}
+	file, err := os.CreateTemp(dir, ".check-env-*")
+	if err != nil {
+		check.Status, check.Detail = CheckFail, fmt.Sprintf("cannot write to %s: %v", dir, err)
+		check.Fix = "choose a writable directory with -output, or fix the permissions of " + dir
+		return check
+	}
+	file.Close()
+	os.Remove(file.Name())
+	check.Status, check.Detail = CheckPass, dir+" is writable"
+	return check
+}
+
+func checkLocale() EnvCheck {
+	check := EnvCheck{Name: "locale"}
+	locale := ""
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale = os.Getenv(key); locale != "" {
+			break
+		}
+	}
+	upper := strings.ToUpper(locale)
+	switch {
+	case runtime.GOOS == "windows":
+		check.Status, check.Detail = CheckPass, "taken from the system on windows"
+	case locale == "":
+		check.Status, check.Detail = CheckWarn, "LANG, LC_ALL and LC_CTYPE are unset"
+		check.Fix = "export LANG=en_US.UTF-8 so translated text and number formats match the machine that recorded the baselines"
+	case !strings.Contains(upper, "UTF-8") && !strings.Contains(upper, "UTF8"):
+		check.Status, check.Detail = CheckWarn, "non-UTF-8 locale "+locale
+		check.Fix = "export LANG=en_US.UTF-8; non-UTF-8 locales can garble text in captures"
+	default:
+		check.Status, check.Detail = CheckPass, locale
+	}
+	return check
+}
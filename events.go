@@ -0,0 +1,107 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventType identifies a step in the lifecycle of a run.
+type EventType string
+
+const (
+	// EventSuiteStart is emitted before the first test of a suite runs
+	EventSuiteStart EventType = "suite-start"
+	
+	// EventTestStart is emitted before a test is rendered
+	EventTestStart EventType = "test-start"
+	
+	// EventTestFinish is emitted after a test completes, with its result
+	EventTestFinish EventType = "test-finish"
+	
+	// EventSuiteFinish is emitted after all tests ran and the report was written
+	EventSuiteFinish EventType = "suite-finish"
+)
+
+// Event describes one lifecycle step. Only the fields relevant to the event
+// type are set.
+type Event struct {
+	Type       EventType   `json:"type"`
+	Time       time.Time   `json:"time"`
+	Suite      string      `json:"suite,omitempty"`
+	Test       string      `json:"test,omitempty"`
+	Profile    string      `json:"profile,omitempty"`
+	Total      int         `json:"total,omitempty"`
+	Result     *JSONResult `json:"result,omitempty"`
+	Summary    *Summary    `json:"summary,omitempty"`
+	OutputDir  string      `json:"output_dir,omitempty"`
+	ReportPath string      `json:"report_path,omitempty"`
+}
+
+// NewNDJSONWriter returns an event handler that writes each event to w as a
+// single line of JSON. It is safe for concurrent use.
+func NewNDJSONWriter(w io.Writer) func(Event) {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+	
+	return func(event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		
+		encoder.Encode(event)
+	}
+}
+
+// NewJSONLWriter returns an event handler that writes the result of each
+// finished test to w as a single line of JSON, as soon as the test
+// completes. Unlike NewNDJSONWriter it leaves out the other lifecycle
+// events, so every line has the same shape. It is safe for concurrent use.
+func NewJSONLWriter(w io.Writer) func(Event) {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+	
+	return func(event Event) {
+		if event.Type != EventTestFinish || event.Result == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		
+		encoder.Encode(event.Result)
+	}
+}
+
+// emit stamps and forwards an event to OnEvent, if set, one at a time.
+func (r *Runner) emit(event Event) {
+	if r.OnEvent == nil {
+		return
+	}
+	r.eventMu.Lock()
+	defer r.eventMu.Unlock()
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	r.OnEvent(event)
+}
+
+func newTestFinishEvent(result Result) Event {
+	jsonResult := newJSONResult(result)
+	return Event{
+		Type:    EventTestFinish,
+		Test:    result.Test.Name,
+		Profile: result.Profile,
+		Result:  &jsonResult,
+	}
+}
+
+func newSuiteFinishEvent(result SuiteResult) Event {
+	summary := newSummary(result.Results)
+	return Event{
+		Type:       EventSuiteFinish,
+		Suite:      result.Name,
+		Summary:    &summary,
+		OutputDir:  result.OutputDir,
+		ReportPath: result.ReportPath,
+	}
+}
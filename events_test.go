@@ -0,0 +1,96 @@
+package fynetest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// writerEvents returns the events of a suite covering every outcome the
+// event writers map: a pass, a failure with output and review images, a
+// skip, an expected failure and an unexpected pass. Names and messages hold
+// the characters each format has to escape.
+func writerEvents() []Event {
+	stamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	results := []Result{
+		{
+			Test:           Test{Name: "Login #1"},
+			Success:        true,
+			Profile:        "dark",
+			ScreenshotPath: "out/login.png",
+			Duration:       12 * time.Millisecond,
+			Timestamp:      stamp,
+		},
+		{
+			Test:           Test{Name: "Form's [draft]"},
+			Error:          errors.New("capture differs:\n2.00% changed"),
+			ScreenshotPath: "out/form.png",
+			Output:         "loading|done\n",
+			Diff:           &DiffResult{DiffPixels: 20, TotalPixels: 1000, DiffPercent: 2},
+			DiffAssets:     &DiffAssets{Diff: "out/form_diff.png"},
+			Duration:       30 * time.Millisecond,
+			Timestamp:      stamp,
+		},
+		{
+			Test:       Test{Name: "Mobile"},
+			Success:    true,
+			Skipped:    true,
+			SkipReason: "not on\nlinux",
+			Timestamp:  stamp,
+		},
+		{
+			Test:            Test{Name: "Known bug"},
+			Success:         true,
+			ExpectedFailure: true,
+			Error:           errors.New("capture differs"),
+			Duration:        5 * time.Millisecond,
+			Timestamp:       stamp,
+		},
+		{
+			Test:           Test{Name: "Fixed"},
+			Success:        true,
+			UnexpectedPass: true,
+			Diff:           &DiffResult{Match: true, TotalPixels: 1000},
+			Duration:       7 * time.Millisecond,
+			Timestamp:      stamp,
+		},
+	}
+
+	events := []Event{{Type: EventSuiteStart, Suite: "Visual", Total: len(results)}}
+	for _, result := range results {
+		events = append(events,
+			Event{Type: EventTestStart, Test: result.Test.Name, Profile: result.Profile},
+			newTestFinishEvent(result))
+	}
+	return append(events, newSuiteFinishEvent(SuiteResult{Name: "Visual", Results: results}))
+}
+
+// writeEvents runs events through the writer newWriter returns and returns
+// what it wrote.
+func writeEvents(newWriter func(w *bytes.Buffer) func(Event), events []Event) string {
+	var out bytes.Buffer
+	write := newWriter(&out)
+	for _, event := range events {
+		write(event)
+	}
+	return out.String()
+}
+
+// checkGolden fails t when got differs from want.
+func checkGolden(t *testing.T, got, want string) {
+	t.Helper()
+	if got != want {
+		t.Errorf("output differs\n got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestJSONLWriterGolden(t *testing.T) {
+	got := writeEvents(func(w *bytes.Buffer) func(Event) { return NewJSONLWriter(w) }, writerEvents())
+	checkGolden(t, got, `{"name":"Login #1","success":true,"screenshot_path":"out/login.png","image_size":{"Width":0,"Height":0},"duration":12000000,"overhead":0,"setup_duration":0,"timestamp":"2024-05-01T12:00:00Z","profile":"dark","alt_text":"Login #1 screenshot"}
+{"name":"Form's [draft]","success":false,"error":"capture differs:\n2.00% changed","screenshot_path":"out/form.png","image_size":{"Width":0,"Height":0},"duration":30000000,"overhead":0,"setup_duration":0,"output":"loading|done\n","timestamp":"2024-05-01T12:00:00Z","alt_text":"Form's [draft] screenshot","diff_assets":{"diff":"out/form_diff.png","composite":""},"diff":{"match":false,"diff_pixels":20,"total_pixels":1000,"diff_percent":2,"max_delta":0}}
+{"name":"Mobile","success":true,"skipped":true,"skip_reason":"not on\nlinux","image_size":{"Width":0,"Height":0},"duration":0,"overhead":0,"setup_duration":0,"timestamp":"2024-05-01T12:00:00Z","alt_text":"Mobile screenshot"}
+{"name":"Known bug","success":true,"expected_failure":true,"error":"capture differs","image_size":{"Width":0,"Height":0},"duration":5000000,"overhead":0,"setup_duration":0,"timestamp":"2024-05-01T12:00:00Z","alt_text":"Known bug screenshot"}
+{"name":"Fixed","success":true,"unexpected_pass":true,"image_size":{"Width":0,"Height":0},"duration":7000000,"overhead":0,"setup_duration":0,"timestamp":"2024-05-01T12:00:00Z","alt_text":"Fixed screenshot","diff":{"match":true,"diff_pixels":0,"total_pixels":1000,"diff_percent":0,"max_delta":0}}
+`)
+}
@@ -7,6 +7,7 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	fynetest "github.com/jairo/vfyne"
+	"github.com/jairo/vfyne/faker"
 )
 
 func main() {
@@ -247,13 +248,10 @@ func main() {
 		AddBuilder(
 			fynetest.NewTest("list_simple").
 				WithDescription("Simple list with items").
-				WithSetup(func() fyne.CanvasObject {
-					data := []string{
-						"First Item",
-						"Second Item",
-						"Third Item",
-						"Fourth Item",
-						"Fifth Item",
+				WithFakerSetup(func(f *faker.Faker) fyne.CanvasObject {
+					data := make([]string, 5)
+					for i := range data {
+						data[i] = f.Sentence()
 					}
 					
 					list := widget.NewList(
@@ -298,7 +296,7 @@ func main() {
 			fynetest.NewTest("mobile_layout").
 				WithDescription("UI optimized for mobile screen size").
 				WithSize(375, 667). // iPhone SE size
-				WithSetup(func() fyne.CanvasObject {
+				WithFakerSetup(func(f *faker.Faker) fyne.CanvasObject {
 					header := widget.NewToolbar(
 						widget.NewToolbarAction(theme.MenuIcon(), func() {}),
 						widget.NewToolbarSpacer(),
@@ -308,8 +306,15 @@ func main() {
 					searchEntry := widget.NewEntry()
 					searchEntry.SetPlaceHolder("Search...")
 					
+					contacts := make([]string, 10)
+					times := make([]string, 10)
+					for i := range contacts {
+						contacts[i] = f.Name()
+						times[i] = f.Time()
+					}
+					
 					list := widget.NewList(
-						func() int { return 10 },
+						func() int { return len(contacts) },
 						func() fyne.CanvasObject {
 							return container.NewHBox(
 								widget.NewIcon(theme.AccountIcon()),
@@ -320,8 +325,8 @@ func main() {
 						},
 						func(i widget.ListItemID, o fyne.CanvasObject) {
 							container := o.(*fyne.Container)
-							container.Objects[1].(*widget.Label).SetText("Contact " + string(rune('A'+i)))
-							container.Objects[3].(*widget.Label).SetText("2:30 PM")
+							container.Objects[1].(*widget.Label).SetText(contacts[i])
+							container.Objects[3].(*widget.Label).SetText(times[i])
 						},
 					)
 					
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"fmt"
+	
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/jairo/vfyne/faker"
 )
 
+// sampleSeed keeps the generated sample data stable between runs
+const sampleSeed = 42
+
 // CreateLoginForm creates a sample login form
 func CreateLoginForm() fyne.CanvasObject {
 	username := widget.NewEntry()
@@ -40,7 +46,11 @@ func CreateDashboard() fyne.CanvasObject {
 	progressCard := widget.NewCard("Progress", "70% Complete", progress)
 	
 	// List widget
-	data := []string{"Item 1", "Item 2", "Item 3", "Item 4", "Item 5"}
+	f := faker.New(sampleSeed)
+	data := make([]string, 5)
+	for i := range data {
+		data[i] = f.Sentence()
+	}
 	list := widget.NewList(
 		func() int { return len(data) },
 		func() fyne.CanvasObject {
@@ -145,8 +155,14 @@ func CreateComplexLayout() fyne.CanvasObject {
 
 // CreateDataTable creates a sample data table
 func CreateDataTable() fyne.CanvasObject {
+	f := faker.New(sampleSeed)
+	data := make([][]string, 4)
+	for i := range data {
+		data[i] = []string{f.Name(), fmt.Sprint(f.IntBetween(18, 70)), f.City()}
+	}
+	
 	table := widget.NewTable(
-		func() (int, int) { return 5, 3 },
+		func() (int, int) { return len(data) + 1, 3 },
 		func() fyne.CanvasObject {
 			return widget.NewLabel("Cell")
 		},
@@ -157,12 +173,6 @@ func CreateDataTable() fyne.CanvasObject {
 				label.SetText(headers[i.Col])
 				label.TextStyle = fyne.TextStyle{Bold: true}
 			} else {
-				data := [][]string{
-					{"John Doe", "30", "New York"},
-					{"Jane Smith", "25", "London"},
-					{"Bob Johnson", "35", "Paris"},
-					{"Alice Brown", "28", "Tokyo"},
-				}
 				label.SetText(data[i.Row-1][i.Col])
 				label.TextStyle = fyne.TextStyle{}
 			}
@@ -10,6 +10,7 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/jairo/vfyne/faker"
 	vfyne "github.com/jairo/vfyne/testing"
 )
 
@@ -49,9 +50,11 @@ func TestFormValidation(t *testing.T) {
 	})
 	
 	t.Run("ValidForm", func(t *testing.T) {
+		f := faker.New(faker.SeedFromString(t.Name()))
+		name := f.Name()
 		form := createValidationForm()
-		form.(*widget.Form).Items[0].Widget.(*widget.Entry).SetText("John Doe")
-		form.(*widget.Form).Items[1].Widget.(*widget.Entry).SetText("john@example.com")
+		form.(*widget.Form).Items[0].Widget.(*widget.Entry).SetText(name)
+		form.(*widget.Form).Items[1].Widget.(*widget.Entry).SetText(f.EmailFor(name))
 		vt.Snapshot("form_valid", form)
 	})
 }
@@ -0,0 +1,443 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	fynetest "fyne.io/fyne/v2/test"
+)
+
+// ScenarioSuffix names the scenario files written by exploration sessions.
+const ScenarioSuffix = ".scenario.json"
+
+// Scenario step actions.
+const (
+	// ActionTap taps the widget matched by Selector, or the canvas at X, Y
+	ActionTap = "tap"
+
+	// ActionDoubleTap double-taps the widget matched by Selector
+	ActionDoubleTap = "double_tap"
+
+	// ActionSecondaryTap right-clicks the widget matched by Selector
+	ActionSecondaryTap = "secondary_tap"
+
+	// ActionType focuses the widget matched by Selector and types Text into
+	// it; without a selector Text goes to the focused widget
+	ActionType = "type"
+
+	// ActionKey presses the key named Key, a fyne.KeyName such as "Return",
+	// "Tab" or "Down", on the focused widget
+	ActionKey = "key"
+
+	// ActionFocusNext moves the focus to the next widget, as Tab does
+	ActionFocusNext = "focus_next"
+
+	// ActionScroll scrolls by DX, DY over the widget matched by Selector, or
+	// over the canvas at X, Y
+	ActionScroll = "scroll"
+
+	// ActionDrag drags by DX, DY from the widget matched by Selector, or
+	// from the canvas at X, Y
+	ActionDrag = "drag"
+
+	// ActionResize resizes the window to Width x Height
+	ActionResize = "resize"
+
+	// ActionCapture saves a screenshot named Name
+	ActionCapture = "capture"
+)
+
+// ScenarioStep is one interaction with a test window.
+type ScenarioStep struct {
+	Action string `json:"action"`
+
+	// Selector picks the widget to act on (see Selector); it must match
+	// exactly one visible widget
+	Selector string `json:"selector,omitempty"`
+
+	Text string `json:"text,omitempty"`
+	Key  string `json:"key,omitempty"`
+	Name string `json:"name,omitempty"`
+
+	// X and Y locate actions without a selector, in canvas coordinates
+	X float32 `json:"x,omitempty"`
+	Y float32 `json:"y,omitempty"`
+
+	DX float32 `json:"dx,omitempty"`
+	DY float32 `json:"dy,omitempty"`
+
+	Width  float32 `json:"width,omitempty"`
+	Height float32 `json:"height,omitempty"`
+}
+
+// Scenario is a recorded exploration of a test: the interactions an agent or
+// a person performed on its window and the screens they captured, in order.
+// ReplayScenario performs it again.
+type Scenario struct {
+	Test  string         `json:"test"`
+	Steps []ScenarioStep `json:"steps"`
+}
+
+// LoadScenario reads a scenario saved with Save.
+func LoadScenario(path string) (Scenario, error) {
+	var scenario Scenario
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return Scenario{}, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return scenario, nil
+}
+
+// Save writes the scenario to path as JSON.
+func (s Scenario) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ExploreCapture is a screen captured during an exploration.
+type ExploreCapture struct {
+	Name string
+	Path string
+
+	Image image.Image
+	Tree  *WidgetTree
+}
+
+// ExploreSession keeps the window of a test open so an external agent can
+// interact with it step by step and capture it at will, as exploratory
+// testing does. Every successful step is recorded in the session's
+// Scenario. Sessions render with the software renderer; they are safe for
+// concurrent use, one step at a time.
+type ExploreSession struct {
+	runner  *Runner
+	test    Test
+	dir     string
+	window  fyne.Window
+	content fyne.CanvasObject
+
+	mu       sync.Mutex
+	scenario Scenario
+	captures int
+	closed   bool
+}
+
+// Explore opens the window of test for an exploration session. Captures are
+// saved to OutputDir as it is when the session opens. Close the session when
+// done.
+func (r *Runner) Explore(test Test) (session *ExploreSession, err error) {
+	if err := test.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid test configuration: %w", err)
+	}
+	if err := os.MkdirAll(r.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	app := r.ensureApp()
+	r.applyTheme(app, test)
+
+	defer func() {
+		if p := recover(); p != nil {
+			session, err = nil, fmt.Errorf("test panicked: %v", p)
+		}
+	}()
+	window, content, _, err := r.showWindow(app, test)
+	if err != nil {
+		return nil, err
+	}
+	return &ExploreSession{
+		runner:   r,
+		test:     test,
+		dir:      r.OutputDir,
+		window:   window,
+		content:  content,
+		scenario: Scenario{Test: test.Name, Steps: make([]ScenarioStep, 0)},
+	}, nil
+}
+
+// Do performs step on the window and records it. Capture steps return the
+// capture; other steps return nil. A failed step is not recorded.
+func (s *ExploreSession) Do(step ScenarioStep) (capture *ExploreCapture, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, fmt.Errorf("exploration of %s is closed", s.test.Name)
+	}
+
+	// A panicking widget callback fails the step, not the agent's process
+	defer func() {
+		if p := recover(); p != nil {
+			capture, err = nil, fmt.Errorf("%s panicked: %v", step.Action, p)
+		}
+	}()
+	if step.Action == ActionCapture {
+		c, err := s.capture(step.Name)
+		if err != nil {
+			return nil, err
+		}
+		capture = &c
+	} else if err := s.perform(step); err != nil {
+		return nil, fmt.Errorf("%s: %w", step.Action, err)
+	}
+	s.scenario.Steps = append(s.scenario.Steps, step)
+	return capture, nil
+}
+
+// Capture saves a screenshot of the window, named name, and records it.
+func (s *ExploreSession) Capture(name string) (ExploreCapture, error) {
+	capture, err := s.Do(ScenarioStep{Action: ActionCapture, Name: name})
+	if err != nil {
+		return ExploreCapture{}, err
+	}
+	return *capture, nil
+}
+
+// Tree returns the current widget tree of the window.
+func (s *ExploreSession) Tree() *WidgetTree {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return NewWidgetTree(CaptureWidgetTree(s.content))
+}
+
+// Scenario returns the steps recorded so far.
+func (s *ExploreSession) Scenario() Scenario {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scenario := s.scenario
+	scenario.Steps = append([]ScenarioStep(nil), s.scenario.Steps...)
+	return scenario
+}
+
+// Save writes the scenario recorded so far next to the session's captures,
+// as <test>.scenario.json, and returns its path.
+func (s *ExploreSession) Save() (string, error) {
+	path := filepath.Join(s.dir, sanitizeFilename(s.test.Name)+ScenarioSuffix)
+	if err := s.Scenario().Save(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Close closes the window.
+func (s *ExploreSession) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		s.window.Close()
+	}
+}
+
+// perform carries out an interaction step.
+func (s *ExploreSession) perform(step ScenarioStep) error {
+	canvas := s.window.Canvas()
+	switch step.Action {
+	case ActionTap:
+		if step.Selector == "" {
+			fynetest.TapCanvas(canvas, fyne.NewPos(step.X, step.Y))
+			return nil
+		}
+		obj, err := s.target(step.Selector)
+		if err != nil {
+			return err
+		}
+		tappable, ok := obj.(fyne.Tappable)
+		if !ok {
+			return fmt.Errorf("%s can't be tapped", typeName(obj))
+		}
+		fynetest.Tap(tappable)
+	case ActionDoubleTap:
+		obj, err := s.target(step.Selector)
+		if err != nil {
+			return err
+		}
+		tappable, ok := obj.(fyne.DoubleTappable)
+		if !ok {
+			return fmt.Errorf("%s can't be double-tapped", typeName(obj))
+		}
+		fynetest.DoubleTap(tappable)
+	case ActionSecondaryTap:
+		obj, err := s.target(step.Selector)
+		if err != nil {
+			return err
+		}
+		tappable, ok := obj.(fyne.SecondaryTappable)
+		if !ok {
+			return fmt.Errorf("%s has no secondary tap", typeName(obj))
+		}
+		fynetest.TapSecondary(tappable)
+	case ActionType:
+		if step.Selector == "" {
+			if canvas.Focused() == nil {
+				return fmt.Errorf("no widget has the focus; give a selector")
+			}
+			fynetest.TypeOnCanvas(canvas, step.Text)
+			return nil
+		}
+		obj, err := s.target(step.Selector)
+		if err != nil {
+			return err
+		}
+		focusable, ok := obj.(fyne.Focusable)
+		if !ok {
+			return fmt.Errorf("%s doesn't take text", typeName(obj))
+		}
+		canvas.Focus(focusable)
+		fynetest.Type(focusable, step.Text)
+	case ActionKey:
+		focused := canvas.Focused()
+		if focused == nil {
+			return fmt.Errorf("no widget has the focus")
+		}
+		if step.Key == "" {
+			return fmt.Errorf("missing key")
+		}
+		focused.TypedKey(&fyne.KeyEvent{Name: fyne.KeyName(step.Key)})
+	case ActionFocusNext:
+		fynetest.FocusNext(canvas)
+	case ActionScroll, ActionDrag:
+		pos := fyne.NewPos(step.X, step.Y)
+		if step.Selector != "" {
+			obj, err := s.target(step.Selector)
+			if err != nil {
+				return err
+			}
+			pos = center(obj)
+		}
+		if step.Action == ActionScroll {
+			fynetest.Scroll(canvas, pos, step.DX, step.DY)
+		} else {
+			fynetest.Drag(canvas, pos, step.DX, step.DY)
+		}
+	case ActionResize:
+		if step.Width <= 0 || step.Height <= 0 {
+			return fmt.Errorf("invalid size %gx%g", step.Width, step.Height)
+		}
+		size := fyne.NewSize(step.Width, step.Height)
+		if err := CheckCanvasSize(size, canvas.Scale(), s.runner.MaxCaptureSize); err != nil {
+			return err
+		}
+		s.window.Resize(size)
+	default:
+		return fmt.Errorf("unknown action %q", step.Action)
+	}
+	return nil
+}
+
+// target returns the one visible widget the selector matches.
+func (s *ExploreSession) target(selector string) (fyne.CanvasObject, error) {
+	if selector == "" {
+		return nil, fmt.Errorf("missing selector")
+	}
+	sel, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]fyne.CanvasObject, 0, 1)
+	for _, obj := range sel.Objects(s.content) {
+		if obj.Visible() {
+			visible = append(visible, obj)
+		}
+	}
+	switch len(visible) {
+	case 0:
+		return nil, fmt.Errorf("selector %q matched no visible widget", selector)
+	case 1:
+		return visible[0], nil
+	}
+	return nil, fmt.Errorf("selector %q matched %d widgets; narrow it, e.g. with [text=...] or [name=...]", selector, len(visible))
+}
+
+// center returns the canvas position of the middle of obj.
+func center(obj fyne.CanvasObject) fyne.Position {
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(obj)
+	size := obj.Size()
+	return fyne.NewPos(pos.X+size.Width/2, pos.Y+size.Height/2)
+}
+
+// capture saves a screenshot of the window next to the test's captures.
+func (s *ExploreSession) capture(name string) (ExploreCapture, error) {
+	canvas := s.window.Canvas()
+	img := canvas.Capture()
+	if img == nil {
+		return ExploreCapture{}, fmt.Errorf("failed to capture canvas image")
+	}
+
+	s.captures++
+	if name == "" {
+		name = fmt.Sprintf("step%d", len(s.scenario.Steps)+1)
+	}
+	filename := fmt.Sprintf("%s_explore%02d_%s.png", sanitizeFilename(s.test.Name), s.captures, sanitizeFilename(name))
+	path := filepath.Join(s.dir, filename)
+	if err := s.runner.saveImage(img, path); err != nil {
+		return ExploreCapture{}, fmt.Errorf("failed to save capture: %w", err)
+	}
+	return ExploreCapture{Name: name, Path: path, Image: img, Tree: NewWidgetTree(CaptureWidgetTree(s.content))}, nil
+}
+
+// ReplayScenario opens the window of test and performs the steps of
+// scenario on it, returning the screens it captures. It stops at the first
+// step that fails.
+func (r *Runner) ReplayScenario(test Test, scenario Scenario) ([]ExploreCapture, error) {
+	if scenario.Test != "" && scenario.Test != test.Name {
+		return nil, fmt.Errorf("scenario was recorded for %s, not %s", scenario.Test, test.Name)
+	}
+	session, err := r.Explore(test)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	captures := make([]ExploreCapture, 0)
+	for i, step := range scenario.Steps {
+		capture, err := session.Do(step)
+		if err != nil {
+			return captures, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		if capture != nil {
+			captures = append(captures, *capture)
+		}
+	}
+	return captures, nil
+}
+
+// replayScenario replays the scenario saved at path against the suite's test
+// of the same name, saving its captures to a timestamped run directory, and
+// reports whether it played through.
+func (s *Suite) replayScenario(path string) bool {
+	scenario, err := LoadScenario(path)
+	if err != nil {
+		s.printf("❌ %v\n", err)
+		return false
+	}
+	tests := s.filterByExactName(scenario.Test)
+	if len(tests) == 0 {
+		s.printf("❌ Test '%s' of the scenario not found\n", scenario.Test)
+		return false
+	}
+
+	outputDir := s.runner.OutputDir
+	s.runner.OutputDir = filepath.Join(outputDir, time.Now().Format("20060102-150405"))
+	defer func() { s.runner.OutputDir = outputDir }()
+
+	s.printf("🧭 Replaying %d steps of %s\n", len(scenario.Steps), scenario.Test)
+	captures, err := s.runner.ReplayScenario(tests[0], scenario)
+	for _, capture := range captures {
+		s.printf("📸 %s: %s\n", capture.Name, capture.Path)
+	}
+	if err != nil {
+		s.printf("❌ %v\n", err)
+		return false
+	}
+	return true
+}
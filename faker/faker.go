@@ -0,0 +1,193 @@
+// Package faker generates realistic but deterministic sample data for visual
+// tests. A Faker created from the same seed always produces the same sequence
+// of values, so lists and tables look lifelike while screenshots stay stable
+// between runs.
+//
+// Basic usage:
+//
+//	f := faker.New(42)
+//	name := f.Name()
+//	email := f.EmailFor(name)
+package faker
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+// Faker produces deterministic fake data from a seeded random source.
+// A Faker is not safe for concurrent use.
+type Faker struct {
+	seed int64
+	rnd  *rand.Rand
+}
+
+// New creates a faker seeded with the given value.
+func New(seed int64) *Faker {
+	return &Faker{
+		seed: seed,
+		rnd:  rand.New(rand.NewSource(seed)),
+	}
+}
+
+// SeedFromString derives a stable seed from a string, such as a test name.
+func SeedFromString(s string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return int64(h.Sum64() & 0x7fffffffffffffff)
+}
+
+// Seed returns the seed this faker was created with.
+func (f *Faker) Seed() int64 {
+	return f.seed
+}
+
+// IntBetween returns a number in the inclusive range [min, max].
+func (f *Faker) IntBetween(min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + f.rnd.Intn(max-min+1)
+}
+
+// Bool returns a random boolean.
+func (f *Faker) Bool() bool {
+	return f.rnd.Intn(2) == 1
+}
+
+// Pick returns one of the given options.
+func (f *Faker) Pick(options ...string) string {
+	if len(options) == 0 {
+		return ""
+	}
+	return options[f.rnd.Intn(len(options))]
+}
+
+// FirstName returns a random given name.
+func (f *Faker) FirstName() string {
+	return f.Pick(firstNames...)
+}
+
+// LastName returns a random family name.
+func (f *Faker) LastName() string {
+	return f.Pick(lastNames...)
+}
+
+// Name returns a random full name.
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Email returns an email address for a newly generated name.
+func (f *Faker) Email() string {
+	return f.EmailFor(f.Name())
+}
+
+// EmailFor returns an email address derived from the given name.
+func (f *Faker) EmailFor(name string) string {
+	user := strings.ToLower(strings.Join(strings.Fields(name), "."))
+	return fmt.Sprintf("%s@%s", user, f.Pick(domains...))
+}
+
+// City returns a random city name.
+func (f *Faker) City() string {
+	return f.Pick(cities...)
+}
+
+// Word returns a single random word.
+func (f *Faker) Word() string {
+	return f.Pick(words...)
+}
+
+// Words returns n random words separated by spaces.
+func (f *Faker) Words(n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = f.Word()
+	}
+	return strings.Join(parts, " ")
+}
+
+// Sentence returns a capitalised sentence of 4 to 12 words.
+func (f *Faker) Sentence() string {
+	s := f.Words(f.IntBetween(4, 12))
+	return strings.ToUpper(s[:1]) + s[1:] + "."
+}
+
+// Paragraph returns 2 to 5 sentences.
+func (f *Faker) Paragraph() string {
+	parts := make([]string, f.IntBetween(2, 5))
+	for i := range parts {
+		parts[i] = f.Sentence()
+	}
+	return strings.Join(parts, " ")
+}
+
+// Time returns a clock time such as "2:30 PM".
+func (f *Faker) Time() string {
+	return fmt.Sprintf("%d:%02d %s", f.IntBetween(1, 12), f.IntBetween(0, 59), f.Pick("AM", "PM"))
+}
+
+// Price returns a currency amount such as "$45.99".
+func (f *Faker) Price(max int) string {
+	return fmt.Sprintf("$%d.%02d", f.IntBetween(1, max), f.IntBetween(0, 99))
+}
+
+// Avatar returns a square PNG resource of the given pixel size showing the
+// initials of a newly generated name.
+func (f *Faker) Avatar(size int) fyne.Resource {
+	return f.InitialsAvatar(f.Name(), size)
+}
+
+func encodeResource(name string, img image.Image) fyne.Resource {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		// Encoding an in-memory NRGBA image cannot fail
+		panic(err)
+	}
+	return fyne.NewStaticResource(name, buf.Bytes())
+}
+
+var palette = []color.NRGBA{
+	{R: 0xe5, G: 0x39, B: 0x35, A: 0xff},
+	{R: 0x8e, G: 0x24, B: 0xaa, A: 0xff},
+	{R: 0x39, G: 0x49, B: 0xab, A: 0xff},
+	{R: 0x03, G: 0x9b, B: 0xe5, A: 0xff},
+	{R: 0x00, G: 0x89, B: 0x7b, A: 0xff},
+	{R: 0x43, G: 0xa0, B: 0x47, A: 0xff},
+	{R: 0xf4, G: 0x51, B: 0x1e, A: 0xff},
+	{R: 0x6d, G: 0x4c, B: 0x41, A: 0xff},
+}
+
+var firstNames = []string{
+	"Alice", "Bob", "Carla", "David", "Eva", "Farid", "Grace", "Hiro",
+	"Ingrid", "Jamal", "Keiko", "Liam", "Maria", "Noah", "Olga", "Pedro",
+	"Quinn", "Rosa", "Sven", "Tara", "Umar", "Vera", "Wei", "Yara",
+}
+
+var lastNames = []string{
+	"Martin", "Chen", "Diaz", "Okafor", "Novak", "Haddad", "Smith", "Tanaka",
+	"Berg", "Johnson", "Rossi", "Kowalski", "Silva", "Nguyen", "Müller", "Brown",
+}
+
+var domains = []string{"example.com", "example.org", "mail.test", "corp.example"}
+
+var cities = []string{
+	"Amsterdam", "Berlin", "Buenos Aires", "Cairo", "Lisbon", "London",
+	"Madrid", "Nairobi", "New York", "Osaka", "Paris", "Seoul", "Sydney", "Toronto",
+}
+
+var words = []string{
+	"account", "update", "design", "report", "theme", "window", "button",
+	"layout", "project", "meeting", "release", "review", "color", "screen",
+	"quickly", "simple", "shared", "latest", "weekly", "draft", "final",
+	"team", "build", "change", "issue", "feature", "preview", "status",
+}
@@ -0,0 +1,104 @@
+package faker
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+	"github.com/jairo/vfyne/internal/bitmapfont"
+)
+
+// InitialsAvatar returns a square PNG resource showing the initials of name
+// on a colored circle. The color is derived from the name and the faker seed,
+// so the same person always gets the same avatar. A size below 1 gives an
+// empty resource, without image data.
+func (f *Faker) InitialsAvatar(name string, size int) fyne.Resource {
+	if size < 1 {
+		return emptyResource("avatar")
+	}
+	key := SeedFromString(fmt.Sprintf("%d/%s", f.seed, name))
+	fill := palette[key%int64(len(palette))]
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+
+	r := float64(size) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)+0.5-r, float64(y)+0.5-r
+			if dx*dx+dy*dy <= r*r {
+				img.SetNRGBA(x, y, fill)
+			}
+		}
+	}
+
+	drawText(img, initials(name), color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+	return encodeResource(fmt.Sprintf("avatar_%s_%d.png", strings.ToLower(initials(name)), key), img)
+}
+
+// Thumbnail returns a PNG resource of the given size filled with a linear
+// gradient between two palette colors, useful as a stand-in for photos. A
+// width or height below 1 gives an empty resource, without image data.
+func (f *Faker) Thumbnail(width, height int) fyne.Resource {
+	if width < 1 || height < 1 {
+		return emptyResource("thumbnail")
+	}
+	start := palette[f.rnd.Intn(len(palette))]
+	end := palette[f.rnd.Intn(len(palette))]
+	angle := f.rnd.Float64() * math.Pi
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	dx, dy := math.Cos(angle), math.Sin(angle)
+	span := math.Abs(dx)*float64(width) + math.Abs(dy)*float64(height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			t := (float64(x)*math.Abs(dx) + float64(y)*math.Abs(dy)) / span
+			img.SetNRGBA(x, y, lerp(start, end, t))
+		}
+	}
+
+	return encodeResource(fmt.Sprintf("thumbnail_%dx%d_%d.png", width, height, f.rnd.Int63()), img)
+}
+
+// emptyResource stands in for an image of no pixels, which PNG can't encode.
+func emptyResource(kind string) fyne.Resource {
+	return fyne.NewStaticResource(kind+"_empty.png", nil)
+}
+
+func initials(name string) string {
+	var out []rune
+	for _, part := range strings.Fields(name) {
+		out = append(out, unicode.ToUpper([]rune(part)[0]))
+		if len(out) == 2 {
+			break
+		}
+	}
+	return string(out)
+}
+
+func lerp(a, b color.NRGBA, t float64) color.NRGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t)
+	}
+	return color.NRGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 0xff}
+}
+
+// drawText renders text centered on img using the built-in bitmap font,
+// scaled to roughly a third of the image height.
+func drawText(img *image.NRGBA, text string, c color.NRGBA) {
+	if text == "" {
+		return
+	}
+
+	bounds := img.Bounds()
+	scale := bounds.Dy() / 3 / bitmapfont.GlyphHeight
+	if scale < 1 {
+		scale = 1
+	}
+
+	x0 := (bounds.Dx() - bitmapfont.TextWidth(text, scale)) / 2
+	y0 := (bounds.Dy() - bitmapfont.GlyphHeight*scale) / 2
+	bitmapfont.Draw(img, bounds.Min.X+x0, bounds.Min.Y+y0, scale, text, c)
+}
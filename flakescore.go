@@ -0,0 +1,156 @@
+package fynetest
+
+import (
+	"math"
+	"sort"
+)
+
+// MinFlakeRuns is the number of runs a test needs before it gets a flake
+// score; fewer runs say nothing about its stability.
+const MinFlakeRuns = 3
+
+// maxReportFlakes is how many of the flakiest tests the report lists.
+const maxReportFlakes = 10
+
+// flakeDiffSpread is the standard deviation of the changed pixel percentage,
+// across runs, at which diff variance counts fully towards the flake score.
+const flakeDiffSpread = 1.0
+
+// FlakeScore summarises the history of one test across retained runs, so
+// tests that need an ignore mask, a frozen clock or a redesign stand out
+// from those that fail for good reasons.
+type FlakeScore struct {
+	Name    string `json:"name"`
+	Profile string `json:"profile,omitempty"`
+
+	// Runs is the number of runs the test took part in, and Failures how
+	// many of them it failed
+	Runs     int `json:"runs"`
+	Failures int `json:"failures"`
+
+	// Flips is how often the test changed between passing and failing from
+	// one run to the next
+	Flips int `json:"flips"`
+
+	// Retried is the number of runs it passed only after a retry
+	Retried int `json:"retried"`
+
+	// DiffMean and DiffStdDev are the mean and standard deviation of the
+	// percentage of pixels that differed from the baseline
+	DiffMean   float64 `json:"diff_mean"`
+	DiffStdDev float64 `json:"diff_stddev"`
+
+	// Score is between 0, stable, and 1, as flaky as it gets. A test that
+	// always fails the same way is not flaky and scores 0.
+	Score float64 `json:"score"`
+}
+
+// Hint suggests what to do about a flaky test, going by what contributes
+// most to its score.
+func (f FlakeScore) Hint() string {
+	flips, retries, diffs := f.components()
+	switch {
+	case f.Score == 0:
+		return ""
+	case diffs >= flips && diffs >= retries:
+		return "its pixels vary between runs: calibrate an ignore mask or use focus regions"
+	case retries >= flips:
+		return "it passes on retry: freeze clocks and animations, or wait for the UI to settle"
+	default:
+		return "it flips between passing and failing: check for timing or state shared with other tests"
+	}
+}
+
+// components returns the weighted parts of the score: status flips, passes
+// after a retry and diff variance.
+func (f FlakeScore) components() (flips, retries, diffs float64) {
+	if f.Runs < 2 {
+		return 0, 0, 0
+	}
+	flips = 0.5 * float64(f.Flips) / float64(f.Runs-1)
+	retries = 0.3 * float64(f.Retried) / float64(f.Runs)
+	diffs = 0.2 * math.Min(1, f.DiffStdDev/flakeDiffSpread)
+	return flips, retries, diffs
+}
+
+// FlakeScores scores every test of runs, given oldest first as returned by
+// LoadRuns, by how often it flipped between passing and failing, passed
+// only after a retry, and how much its diff against the baseline varied.
+// Tests with fewer than MinFlakeRuns runs are left out. The result is
+// sorted by score, flakiest first.
+func FlakeScores(runs []*RunData) []FlakeScore {
+	type history struct {
+		score  FlakeScore
+		passed bool
+		diffs  []float64
+	}
+	var order []string
+	tests := make(map[string]*history)
+	for _, run := range runs {
+		for _, result := range run.Results {
+			if result.Skipped {
+				continue
+			}
+			key := result.Profile + "\x00" + result.Test.Name
+			h, ok := tests[key]
+			if !ok {
+				h = &history{score: FlakeScore{Name: result.Test.Name, Profile: result.Profile}}
+				tests[key] = h
+				order = append(order, key)
+			} else if h.passed != result.Success {
+				h.score.Flips++
+			}
+			h.passed = result.Success
+			h.score.Runs++
+			if !result.Success {
+				h.score.Failures++
+			}
+			if result.Flaky() {
+				h.score.Retried++
+			}
+			if d := result.Diff; d != nil && !d.SizeMismatch {
+				h.diffs = append(h.diffs, d.DiffPercent)
+			}
+		}
+	}
+
+	scores := make([]FlakeScore, 0, len(order))
+	for _, key := range order {
+		h := tests[key]
+		if h.score.Runs < MinFlakeRuns {
+			continue
+		}
+		h.score.DiffMean, h.score.DiffStdDev = meanStdDev(h.diffs)
+		flips, retries, diffs := h.score.components()
+		h.score.Score = math.Min(1, flips+retries+diffs)
+		scores = append(scores, h.score)
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+	return scores
+}
+
+// flakyScores returns the leading scores of at least min, and above 0, from
+// scores sorted flakiest first.
+func flakyScores(scores []FlakeScore, min float64) []FlakeScore {
+	for i, score := range scores {
+		if score.Score < min || score.Score == 0 {
+			return scores[:i]
+		}
+	}
+	return scores
+}
+
+// meanStdDev returns the mean and population standard deviation of values.
+func meanStdDev(values []float64) (mean, stddev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		stddev += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(stddev / float64(len(values)))
+}
@@ -0,0 +1,79 @@
+package fynetest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// FlakyTest is a test that failed at first and passed when retried.
+type FlakyTest struct {
+	Name     string `json:"name"`
+	Profile  string `json:"profile,omitempty"`
+	Attempts int    `json:"attempts"`
+}
+
+// Flaky reports whether the test passed only after being retried.
+func (r Result) Flaky() bool {
+	return r.Success && r.Attempts > 1
+}
+
+// FlakyTests returns the tests of results that passed only after a retry.
+func FlakyTests(results []Result) []FlakyTest {
+	var flaky []FlakyTest
+	for _, result := range results {
+		if result.Flaky() {
+			flaky = append(flaky, FlakyTest{Name: result.Test.Name, Profile: result.Profile, Attempts: result.Attempts})
+		}
+	}
+	return flaky
+}
+
+// retryable reports whether a result may pass if its test runs again. A
+// test expected to fail that passed needs its baseline reviewed instead, and
+// a timed out test would leave another capture hanging in the background.
+func retryable(result Result) bool {
+	return !result.Success && !result.Skipped && !result.UnexpectedPass && !errors.Is(result.Error, ErrTimeout)
+}
+
+// retryFailed runs every retryable failure of results again, up to Retries
+// times, until it passes. The last attempt replaces the result. Each round
+// renders its GL tests in one batch, so they share one GL app.
+func (r *Runner) retryFailed(tests []Test, results []Result) {
+	for attempt := 2; attempt <= r.Retries+1; attempt++ {
+		var software, gl []int
+		for i, result := range results {
+			if !retryable(result) {
+				continue
+			}
+			if r.renderPath(tests[i]) == RenderGL {
+				gl = append(gl, i)
+			} else {
+				software = append(software, i)
+			}
+		}
+		if len(software) == 0 && len(gl) == 0 {
+			return
+		}
+
+		retry := func(i int, c *capturedTest) {
+			var log bytes.Buffer
+			if r.Verbose {
+				fmt.Fprintf(&log, "🔁 Retrying %s (attempt %d of %d)\n", tests[i].Name, attempt, r.Retries+1)
+			}
+			c.retries = r.Retries + 1 - attempt
+			results[i] = r.process(c, &log)
+			r.flush(&log)
+		}
+		for _, i := range software {
+			retry(i, r.render(tests[i], attempt))
+		}
+		if len(gl) > 0 {
+			glTests := make([]Test, len(gl))
+			for k, i := range gl {
+				glTests[k] = tests[i]
+			}
+			r.renderGL(glTests, attempt, func(k int, c *capturedTest) { retry(gl[k], c) })
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package fynetest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// flakyRunner returns a runner writing to a temporary directory that
+// retries failures twice.
+func flakyRunner(t *testing.T) *Runner {
+	t.Helper()
+	r := NewRunner()
+	r.OutputDir = t.TempDir()
+	r.BaselineDir = t.TempDir()
+	r.DefaultWaitDuration = 0
+	r.Retries = 2
+	t.Cleanup(r.Cleanup)
+	return r
+}
+
+// tapLines returns the plan and test lines of TAP output, leaving out the
+// YAML diagnostics.
+func tapLines(out string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "1..") || strings.HasPrefix(line, "ok ") || strings.HasPrefix(line, "not ok ") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestRetriedFailureReportedOnce(t *testing.T) {
+	r := flakyRunner(t)
+	var tap bytes.Buffer
+	r.OnEvent = NewTAPWriter(&tap)
+
+	runs := 0
+	test := NewTest("Flaky").
+		WithSize(100, 50).
+		WithSetup(func() fyne.CanvasObject {
+			runs++
+			if runs == 1 {
+				panic("first attempt fails")
+			}
+			return widget.NewLabel("Flaky")
+		}).
+		MustBuild()
+
+	r.emit(Event{Type: EventSuiteStart, Suite: "retries", Total: 1})
+	results := r.RunTests([]Test{test})
+	r.emit(Event{Type: EventSuiteFinish, Suite: "retries"})
+
+	if !results[0].Success || results[0].Attempts != 2 {
+		t.Fatalf("result: success %v after %d attempts, want success after 2: %v", results[0].Success, results[0].Attempts, results[0].Error)
+	}
+	want := []string{"1..1", "ok 1 - Flaky"}
+	if got := tapLines(tap.String()); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("TAP output lines = %q, want %q\n%s", got, want, tap.String())
+	}
+}
+
+func TestRetriedFailureReportsLastAttempt(t *testing.T) {
+	r := flakyRunner(t)
+	starts, finishes := 0, make([]Event, 0)
+	r.OnEvent = func(event Event) {
+		switch event.Type {
+		case EventTestStart:
+			starts++
+		case EventTestFinish:
+			finishes = append(finishes, event)
+		}
+	}
+
+	test := NewTest("Broken").
+		WithSetup(func() fyne.CanvasObject { panic("always fails") }).
+		MustBuild()
+	results := r.RunTestsConcurrent([]Test{test}, 2)
+
+	if results[0].Success || results[0].Attempts != 3 {
+		t.Fatalf("result: success %v after %d attempts, want failure after 3", results[0].Success, results[0].Attempts)
+	}
+	if starts != 1 || len(finishes) != 1 {
+		t.Fatalf("got %d test-start and %d test-finish events, want 1 of each", starts, len(finishes))
+	}
+	if finishes[0].Result.Attempts != 3 {
+		t.Errorf("test-finish reports attempt %d, want 3", finishes[0].Result.Attempts)
+	}
+}
+
+func TestUnexpectedPassNotRetried(t *testing.T) {
+	r := flakyRunner(t)
+	runs := 0
+	build := func() Test {
+		return NewTest("Fixed").
+			WithSize(100, 50).
+			WithSetup(func() fyne.CanvasObject {
+				runs++
+				return widget.NewLabel("Fixed")
+			}).
+			WithExpectedToFail().
+			MustBuild()
+	}
+
+	r.Update = SnapshotUpdate{All: true}
+	r.RunTests([]Test{build()})
+	r.Update = SnapshotUpdate{}
+	runs = 0
+
+	results := r.RunTests([]Test{build()})
+	if !results[0].UnexpectedPass {
+		t.Fatalf("result isn't an unexpected pass: %+v", results[0])
+	}
+	if runs != 1 || results[0].Attempts != 1 {
+		t.Errorf("Setup ran %d times over %d attempts, want 1", runs, results[0].Attempts)
+	}
+}
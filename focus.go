@@ -0,0 +1,135 @@
+package fynetest
+
+import (
+	"fmt"
+	"image"
+
+	"fyne.io/fyne/v2"
+)
+
+// FocusRegion is a region of interest, such as the primary button or the
+// chart area, compared with its own tolerance while the rest of the capture
+// uses the runner's. This matches how reviewers look at a screen: a shifted
+// background gradient is noise, a changed button label is not.
+type FocusRegion struct {
+	// Region is the area in canvas coordinates, unless Selector is set
+	Region Region
+
+	// Selector picks the widgets to focus on from the widget tree at
+	// capture time instead, following them when the layout changes
+	Selector string
+
+	// Tolerance applies inside the region; the zero value requires
+	// pixel-identical captures
+	Tolerance Tolerance
+}
+
+// FocusRect is a FocusRegion converted to the pixels of a capture.
+type FocusRect struct {
+	Rect      image.Rectangle
+	Tolerance Tolerance
+}
+
+// FocusRects converts focus regions into pixel rectangles of img, a capture
+// of canvas c whose widget tree is tree. A selector matching no visible
+// widget is an error, so a focus region can't silently stop being checked.
+func FocusRects(c fyne.Canvas, img image.Image, tree *WidgetNode, focus []FocusRegion) ([]FocusRect, error) {
+	rects := make([]FocusRect, 0, len(focus))
+	for _, f := range focus {
+		regions := []Region{f.Region}
+		if f.Selector != "" {
+			sel, err := ParseSelector(f.Selector)
+			if err != nil {
+				return nil, err
+			}
+			if regions = sel.Regions(tree); len(regions) == 0 {
+				return nil, fmt.Errorf("focus selector %q matched no visible widget", f.Selector)
+			}
+		}
+		for _, region := range regions {
+			if rect := PixelRect(c, img, region); !rect.Empty() {
+				rects = append(rects, FocusRect{Rect: rect, Tolerance: f.Tolerance})
+			}
+		}
+	}
+	return rects, nil
+}
+
+// cropFocus moves focus rectangles into a capture cropped to crop.
+func cropFocus(focus []FocusRect, crop image.Rectangle) []FocusRect {
+	rects := make([]FocusRect, 0, len(focus))
+	for _, f := range focus {
+		if rect := f.Rect.Intersect(crop); !rect.Empty() {
+			rects = append(rects, FocusRect{Rect: rect.Sub(crop.Min), Tolerance: f.Tolerance})
+		}
+	}
+	return rects
+}
+
+// CompareFocus compares expected and actual inside every focus rectangle
+// with its tolerance and returns the diff of each, with Bounds relative to
+// the images rather than the rectangle.
+func CompareFocus(expected, actual image.Image, focus []FocusRect) []DiffResult {
+	diffs := make([]DiffResult, len(focus))
+	for i, f := range focus {
+		diffs[i] = CompareImages(subImage(expected, f.Rect), subImage(actual, f.Rect), f.Tolerance)
+		if !diffs[i].Bounds.Empty() {
+			diffs[i].Bounds = diffs[i].Bounds.Add(f.Rect.Min)
+		}
+	}
+	return diffs
+}
+
+// subImage returns the part of img at rect, relative to its top-left corner.
+func subImage(img image.Image, rect image.Rectangle) image.Image {
+	rect = rect.Add(img.Bounds().Min)
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	out := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			out.Set(x-rect.Min.X, y-rect.Min.Y, img.At(x, y))
+		}
+	}
+	return out
+}
+
+// compareFocus compares the focus rectangles of img with the decoded
+// baseline expected and adds their differing pixels to diff, which holds
+// the complete counts of the rest of the capture. It returns an error
+// naming the first region that differs beyond its tolerance.
+func compareFocus(expected, img image.Image, ignored []image.Rectangle, focus []FocusRect, diff *DiffResult) error {
+	if expected.Bounds().Size() != img.Bounds().Size() {
+		return nil
+	}
+	if len(ignored) > 0 {
+		expected, img = maskedPair(expected, img, ignored)
+		defer ReleaseImage(expected)
+		defer ReleaseImage(img)
+	}
+
+	var failure error
+	for i, d := range CompareFocus(expected, img, focus) {
+		diff.DiffPixels += d.DiffPixels
+		diff.AntialiasedPixels += d.AntialiasedPixels
+		diff.Bounds = diff.Bounds.Union(d.Bounds)
+		if d.MaxDelta > diff.MaxDelta {
+			diff.MaxDelta = d.MaxDelta
+		}
+		if !d.Match && failure == nil {
+			rect := focus[i].Rect
+			failure = fmt.Errorf("focus region %d at %d,%d %dx%d differs from baseline: %.2f%% of its pixels changed",
+				i+1, rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), d.DiffPercent)
+		}
+	}
+	if diff.TotalPixels > 0 {
+		diff.DiffPercent = float64(diff.DiffPixels) / float64(diff.TotalPixels) * 100
+	}
+	if failure != nil {
+		diff.Match = false
+	}
+	return failure
+}
@@ -0,0 +1,65 @@
+package fynetest
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// focusImages returns a uniform baseline and a capture differing from it
+// in a 30x30 square and a 5x5 square.
+func focusImages() (*image.RGBA, *image.RGBA) {
+	expected := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	draw.Draw(expected, expected.Rect, image.NewUniform(color.Gray{0x80}), image.Point{}, draw.Src)
+	actual := image.NewRGBA(expected.Rect)
+	copy(actual.Pix, expected.Pix)
+	changed := image.NewUniform(color.RGBA{R: 0xff, A: 0xff})
+	draw.Draw(actual, image.Rect(10, 10, 40, 40), changed, image.Point{}, draw.Src)
+	draw.Draw(actual, image.Rect(70, 70, 75, 75), changed, image.Point{}, draw.Src)
+	return expected, actual
+}
+
+func TestCompareBaselineFocusCountsWholeCapture(t *testing.T) {
+	r := NewRunner()
+	r.BaselineDir = t.TempDir()
+	test := Test{Name: "Focus"}
+	expected, actual := focusImages()
+	if err := SaveBaseline(filepath.Join(r.BaselineDir, "Focus.png"), expected); err != nil {
+		t.Fatal(err)
+	}
+
+	focus := []FocusRect{{Rect: image.Rect(60, 60, 100, 100), Tolerance: ExactTolerance}}
+	diff, err := r.compareBaseline(test, actual, nil, nil, focus)
+	if err == nil || !strings.Contains(err.Error(), "focus region 1 at 60,60 40x40") {
+		t.Fatalf("error = %v, want one naming focus region 1", err)
+	}
+	if diff.Partial {
+		t.Error("diff is Partial; focus counts must be added to complete ones")
+	}
+	if diff.DiffPixels != 30*30+5*5 {
+		t.Errorf("DiffPixels = %d, want %d", diff.DiffPixels, 30*30+5*5)
+	}
+	if diff.Severity <= 0 {
+		t.Errorf("Severity = %v, want it scored", diff.Severity)
+	}
+}
+
+func TestCompareBaselineFocusWithinTolerance(t *testing.T) {
+	r := NewRunner()
+	r.BaselineDir = t.TempDir()
+	test := Test{Name: "Focus"}
+	expected, actual := focusImages()
+	if err := SaveBaseline(filepath.Join(r.BaselineDir, "Focus.png"), expected); err != nil {
+		t.Fatal(err)
+	}
+
+	// The focus region is clean; only the rest of the capture changed
+	focus := []FocusRect{{Rect: image.Rect(0, 50, 50, 100), Tolerance: ExactTolerance}}
+	_, err := r.compareBaseline(test, actual, nil, nil, focus)
+	if err == nil || strings.Contains(err.Error(), "focus region") {
+		t.Errorf("error = %v, want the capture-wide mismatch", err)
+	}
+}
@@ -0,0 +1,86 @@
+package fynetest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Freeze replaces what the widgets matching Selector display with a fixed
+// Value just before capture. Unlike an ignore mask, the widget is still
+// drawn and compared, and keeps its place in the layout; only the value that
+// changes from run to run is pinned.
+//
+// Value is the text of labels, buttons, entries, checks and canvas text,
+// the selected option of selects and radio groups, and a number for
+// progress bars and sliders. Spinners (ProgressBarInfinite) are stopped so
+// they don't animate during capture, and Value is ignored.
+type Freeze struct {
+	Selector string
+	Value    string
+}
+
+// FreezeWidgets applies freezes to the widgets under root, which must be
+// shown. A selector matching nothing, or a widget that can't display a
+// fixed value, is an error, so a freeze can't silently stop applying.
+func FreezeWidgets(root fyne.CanvasObject, freezes []Freeze) error {
+	for _, freeze := range freezes {
+		sel, err := ParseSelector(freeze.Selector)
+		if err != nil {
+			return err
+		}
+		objects := sel.Objects(root)
+		if len(objects) == 0 {
+			return fmt.Errorf("freeze selector %q matched no widget", freeze.Selector)
+		}
+		for _, obj := range objects {
+			if err := freezeObject(obj, freeze.Value); err != nil {
+				return fmt.Errorf("cannot freeze %q: %w", freeze.Selector, err)
+			}
+		}
+	}
+	return nil
+}
+
+// freezeObject sets the value obj displays. Fields are set directly rather
+// than through setters, so change callbacks of the app don't fire.
+func freezeObject(obj fyne.CanvasObject, value string) error {
+	switch o := obj.(type) {
+	case *widget.Label:
+		o.Text = value
+	case *widget.Button:
+		o.Text = value
+	case *widget.Entry:
+		o.Text = value
+	case *widget.Check:
+		o.Text = value
+	case *widget.Select:
+		o.Selected = value
+	case *widget.RadioGroup:
+		o.Selected = value
+	case *canvas.Text:
+		o.Text = value
+	case *widget.ProgressBar:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("progress bar value %q is not a number", value)
+		}
+		o.Value = number
+	case *widget.Slider:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("slider value %q is not a number", value)
+		}
+		o.Value = number
+	case *widget.ProgressBarInfinite:
+		o.Stop()
+	default:
+		return fmt.Errorf("%s has no value to freeze; mask it with an ignore selector instead", typeName(obj))
+	}
+	obj.Refresh()
+	return nil
+}
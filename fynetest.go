@@ -23,11 +23,14 @@
 package fynetest
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"image"
-	"image/png"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -35,6 +38,7 @@ import (
 	"fyne.io/fyne/v2"
 	fynetest "fyne.io/fyne/v2/test"
 	"fyne.io/fyne/v2/theme"
+	"github.com/jairo/vfyne/faker"
 )
 
 // Test represents a visual test case for a Fyne UI component.
@@ -57,11 +61,142 @@ type Test struct {
 	// Theme optionally specifies a custom theme for this test
 	Theme fyne.Theme
 	
+	// Scale optionally renders the canvas at a different DPI scale (0 keeps the default)
+	Scale float32
+	
+	// Unpadded removes window padding so only the content itself is captured
+	Unpadded bool
+	
+	// Renderer selects the render path for this test (default: the runner's)
+	Renderer RenderPath
+	
 	// WaitDuration specifies how long to wait after showing the window (default: 100ms)
 	WaitDuration time.Duration
 	
+	// Timeout aborts the test when Setup, rendering and capture together
+	// take longer, failing it with ErrTimeout (default: the runner's
+	// DefaultTimeout)
+	Timeout time.Duration
+	
 	// Metadata allows storing additional information about the test
 	Metadata map[string]interface{}
+	
+	// Seed drives deterministic fake data for this test (0 derives one from Name)
+	Seed int64
+	
+	// IgnoreRegions are excluded from baseline comparisons (canvas coordinates)
+	IgnoreRegions []Region
+	
+	// IgnoreObjects are excluded from baseline comparisons at their rendered bounds
+	IgnoreObjects []fyne.CanvasObject
+	
+	// IgnoreSelectors pick the widgets excluded from baseline comparisons
+	// from the widget tree at capture time (see Selector)
+	IgnoreSelectors []string
+	
+	// FocusRegions are compared with their own, usually strict, tolerance
+	// and left out of the comparison of the rest of the capture, which uses
+	// the runner's tolerance
+	FocusRegions []FocusRegion
+	
+	// Importance weights changes to regions when scoring their severity
+	// (canvas coordinates, see Severity)
+	Importance []Importance
+	
+	// ChangeBudget is the severity up to which a capture may differ from
+	// its baseline and still pass, so micro-shifts don't fail the test
+	// while large changes do (0 uses the runner's ChangeBudgets)
+	ChangeBudget float64
+	
+	// Freezes pin the values of dynamic widgets, such as clocks, just before
+	// capture (see Freeze)
+	Freezes []Freeze
+	
+	// CropToContent saves and compares only the pixels covered by the content
+	// returned by Setup instead of the whole window
+	CropToContent bool
+	
+	// Skip leaves the test out of the run; it is reported as skipped with
+	// SkipReason instead of being rendered
+	Skip       bool
+	SkipReason string
+	
+	// SkipFunc decides at run time whether to skip the test and why, e.g.
+	// on an OS it doesn't apply to or when a dependency is missing
+	SkipFunc func() (bool, string)
+	
+	// SkipPlatforms skips the test on platforms it is known to be unstable
+	// on, given as GOOS ("windows") or GOOS/GOARCH ("darwin/arm64")
+	SkipPlatforms []string
+	
+	// PlatformTolerances loosens the baseline comparison on platforms with
+	// known renderer quirks: the fraction of pixels (0-1) allowed to differ,
+	// by platform as in SkipPlatforms (see Runner.ToleranceFor)
+	PlatformTolerances map[string]float64
+	
+	// ExpectedToFail keeps a known visual regression in the suite without
+	// breaking CI: the test passes when its capture doesn't match the
+	// baseline, and matching is flagged as needing a baseline review. Other
+	// failures, such as a crash or a timeout, still fail it.
+	ExpectedToFail bool
+	
+	// SourceFile and SourceLine locate the code that registered the test,
+	// recorded by NewTest and Suite.Add, for reports such as SARIF
+	SourceFile string
+	SourceLine int
+}
+
+// EffectiveSeed returns the seed used for fake data, deriving one from the
+// test name when no explicit seed is set.
+func (t *Test) EffectiveSeed() int64 {
+	if t.Seed != 0 {
+		return t.Seed
+	}
+	return faker.SeedFromString(t.Name)
+}
+
+// Metadata keys with special meaning in reports.
+const (
+	// MetadataCaption is the caption shown under the screenshot
+	MetadataCaption = "caption"
+	
+	// MetadataAltText is the alternative text describing the screenshot
+	MetadataAltText = "alt_text"
+)
+
+// Caption returns the screenshot caption from the test metadata, if any.
+func (t *Test) Caption() string {
+	caption, _ := t.Metadata[MetadataCaption].(string)
+	return caption
+}
+
+// AltText returns the text describing the screenshot for assistive
+// technology: the alt_text metadata, else the description, else the name.
+func (t *Test) AltText() string {
+	if text, ok := t.Metadata[MetadataAltText].(string); ok && text != "" {
+		return text
+	}
+	if t.Description != "" {
+		return t.Description
+	}
+	return t.Name + " screenshot"
+}
+
+// Skipped reports whether the test is to be skipped, and why: when Skip is
+// set, on one of SkipPlatforms, or when SkipFunc says so.
+func (t *Test) Skipped() (bool, string) {
+	if t.Skip {
+		return true, t.SkipReason
+	}
+	for _, platform := range t.SkipPlatforms {
+		if onPlatform(platform) {
+			return true, "unstable on " + platform
+		}
+	}
+	if t.SkipFunc != nil {
+		return t.SkipFunc()
+	}
+	return false, ""
 }
 
 // Validate checks if the test configuration is valid
@@ -86,6 +221,46 @@ func (t *Test) Validate() error {
 		return fmt.Errorf("wait duration cannot be negative")
 	}
 	
+	if t.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	
+	if t.Scale < 0 {
+		return fmt.Errorf("scale cannot be negative")
+	}
+	
+	if t.ChangeBudget < 0 {
+		return fmt.Errorf("change budget cannot be negative")
+	}
+	for platform, fraction := range t.PlatformTolerances {
+		if fraction < 0 || fraction > 1 {
+			return fmt.Errorf("tolerance on %s must be a fraction of pixels between 0 and 1", platform)
+		}
+	}
+	for _, focus := range t.FocusRegions {
+		if focus.Selector != "" {
+			if _, err := ParseSelector(focus.Selector); err != nil {
+				return err
+			}
+		}
+	}
+	for _, imp := range t.Importance {
+		if imp.Weight < 0 {
+			return fmt.Errorf("importance weight cannot be negative")
+		}
+	}
+	
+	for _, selector := range t.IgnoreSelectors {
+		if _, err := ParseSelector(selector); err != nil {
+			return err
+		}
+	}
+	for _, freeze := range t.Freezes {
+		if _, err := ParseSelector(freeze.Selector); err != nil {
+			return err
+		}
+	}
+	
 	return nil
 }
 
@@ -109,7 +284,9 @@ type Result struct {
 	// ImageSize is the size of the captured image
 	ImageSize fyne.Size
 	
-	// Duration is how long the test took to run
+	// Duration is how long the test took to run, from the start of its
+	// render to the end of its comparison. In RunTests it includes the time
+	// the capture queued for a pipeline worker (see Overhead).
 	Duration time.Duration
 	
 	// Timestamp is when the test was run
@@ -117,6 +294,69 @@ type Result struct {
 	
 	// Metadata contains additional information about the test run
 	Metadata map[string]interface{}
+	
+	// Profile is the name of the run profile that produced this result, if any
+	Profile string
+	
+	// Diff is the comparison against the baseline capture, if one was made
+	Diff *DiffResult
+	
+	// DiffAssets are the review images written when the baseline comparison failed
+	DiffAssets *DiffAssets
+	
+	// Overhead is the time spent in vfyne itself: Duration without the
+	// render wait, Setup and the time queued for a pipeline worker
+	Overhead time.Duration
+	
+	// SetupDuration is the time spent in the test's Setup function
+	SetupDuration time.Duration
+	
+	// Output is what was written to the stdout and stderr file descriptors
+	// while Setup ran, including the standard logger, which fyne logs to.
+	// Setups run one at a time so their output can't mix, but other
+	// goroutines writing meanwhile end up in it too. It is empty on Windows,
+	// where output isn't captured, and for tests RunTestsConcurrent runs in
+	// parallel.
+	Output string
+	
+	// CrashDump is the file describing the environment, widget tree and
+	// goroutines when the capture failed or panicked
+	CrashDump string
+	
+	// WidgetChanges is the structural diff against the widget tree stored
+	// with the baseline, set when the capture does not match it
+	WidgetChanges []WidgetChange
+	
+	// WidgetTree is the widget tree of the captured content
+	WidgetTree *WidgetNode
+	
+	// Attempts is how often the test ran, more than once when it failed and
+	// Runner.Retries allowed running it again
+	Attempts int
+	
+	// Skipped is set for tests that were skipped (see Test.Skip) instead of
+	// run. They count as successful, so they never fail a run.
+	Skipped    bool
+	SkipReason string
+	
+	// ExpectedFailure is set when the capture of a test expected to fail
+	// (see Test.ExpectedToFail) didn't match its baseline. It counts as
+	// successful; Error says how it differed.
+	ExpectedFailure bool
+	
+	// UnexpectedPass is set when a test expected to fail passed: the
+	// regression may be fixed and its baseline needs a review
+	UnexpectedPass bool
+	
+	// ignored are the rectangles left out of the baseline comparison, so
+	// StageBaselines compares with the committed baseline the same way
+	ignored []image.Rectangle
+}
+
+// mismatched reports whether the test failed because its capture doesn't
+// match the baseline.
+func (r Result) mismatched() bool {
+	return !r.Success && r.Diff != nil && !r.Diff.Match
 }
 
 // Runner manages the execution of visual tests.
@@ -130,17 +370,147 @@ type Runner struct {
 	// DefaultSize is the default window size for tests that don't specify one
 	DefaultSize fyne.Size
 	
+	// MaxCaptureSize is the largest capture in pixels; tests requesting a
+	// larger window fail with ErrCanvasTooLarge (default: DefaultMaxCaptureSize)
+	MaxCaptureSize image.Point
+	
 	// DefaultWaitDuration is the default time to wait for window rendering
 	DefaultWaitDuration time.Duration
 	
+	// IsolateApps renders each test in a fresh app, so a theme or
+	// preference set for one test can't leak into the next and results
+	// don't depend on test order. Isolated tests render one at a time, also
+	// in RunTestsConcurrent. GL tests share one app either way. A shared app
+	// gets the default theme back before each test that sets none.
+	IsolateApps bool
+	
+	// AIExport writes a description of each capture for LLM agents next to
+	// its screenshot (see WriteAIExport)
+	AIExport bool
+	
+	// DefaultTimeout aborts tests without a Timeout of their own that take
+	// longer to set up, render and capture, so a hanging Setup or a window
+	// that never renders fails its test instead of blocking the run
+	// (0 waits forever). The timed out test's window is closed, but its
+	// Setup can't be stopped: one that never returns keeps running in the
+	// background. The tests after it therefore get a fresh app instead of
+	// the shared one, except GL tests, which all share one GL app. Timed
+	// out tests aren't retried.
+	DefaultTimeout time.Duration
+	
+	// Renderer is the render path of tests that don't select one (default:
+	// RenderSoftware). GL tests are rendered together after the others and
+	// need the main goroutine.
+	Renderer RenderPath
+	
 	// Verbose enables detailed logging
 	Verbose bool
 	
+	// BaselineDir holds reference captures named "<test>.png". When set,
+	// captures with a baseline are compared against it and fail on mismatch.
+	BaselineDir string
+	
+	// VariantBaselines names baselines and captures after the theme, size
+	// and scale too, as "<test>@dark@375x667.png" (see BaselineName)
+	VariantBaselines bool
+	
+	// BaselineSource, when set, provides the baselines and BaselineDir
+	// caches them
+	BaselineSource BaselineSource
+	
+	// Tolerance is used when comparing captures against baselines
+	Tolerance Tolerance
+	
+	// Comparer, when set, replaces Tolerance with custom comparison logic
+	Comparer ImageComparer
+	
+	// Describer, when set, describes every captured screen; the text is
+	// stored in the result metadata under MetadataScreenSummary and shown as
+	// a draft caption in the report
+	Describer ScreenDescriber
+	
+	// ChangeBudgets maps tags to the severity up to which their tests may
+	// differ from the baseline and still pass; the "" entry applies to all
+	// tests (see ChangeBudget)
+	ChangeBudgets map[string]float64
+	
+	// SetupThreshold warns about tests whose Setup takes longer (0 disables)
+	SetupThreshold time.Duration
+	
+	// Retries is how often RunTests runs a failed test again before it
+	// counts as failed; tests passing on a retry are flaky (0 disables).
+	// Only the last attempt emits a test-finish event.
+	Retries int
+	
+	// Update selects tests whose baseline is replaced by the capture instead
+	// of compared against it
+	Update SnapshotUpdate
+	
+	// HistoryDepth is the number of previous versions kept under HistoryDir
+	// when a baseline is updated (0 keeps none)
+	HistoryDepth int
+	
+	// OnEvent, when set, is called at the start and end of every test. Calls
+	// are serialised, so it needn't be safe for concurrent use even though
+	// pipeline workers and parallel runs finish tests concurrently.
+	OnEvent func(Event)
+	
+	// OnResult, when set, is called with the result of every finished test
+	// attempt, concurrently when tests run in parallel
+	OnResult func(Result)
+	
+	// BeforeEach, when set, is called before every test attempt renders, on
+	// the goroutine rendering it, e.g. to seed fixtures or start a fake
+	// server. An error fails the attempt without rendering it. Skipped
+	// tests don't get it.
+	BeforeEach func(Test) error
+	
+	// AfterEach, when set, is called with the result of every test attempt,
+	// even one BeforeEach failed but not skipped ones, before OnResult. RunTests compares a
+	// capture while the next test renders, so AfterEach of a test may run
+	// after BeforeEach of the next.
+	AfterEach func(Result)
+	
+	// PipelineWorkers is the number of workers encoding and comparing
+	// captures in RunTests while the next test renders (default: GOMAXPROCS)
+	PipelineWorkers int
+	
+	// Output receives verbose logging (default: os.Stdout). Output of each
+	// test is buffered and written in one piece, so concurrent runs don't interleave.
+	Output io.Writer
+	
 	// app instance (reused across tests for efficiency)
 	app fyne.App
 	
 	// mutex for thread safety
 	mu sync.Mutex
+	
+	// outMu serialises writes to Output
+	outMu sync.Mutex
+	
+	// eventMu serialises calls to OnEvent
+	eventMu sync.Mutex
+	
+	// isolateMu serialises renders on isolated apps, since each one
+	// becomes the current fyne app while it renders
+	isolateMu sync.Mutex
+	
+	// parallel is set while RunTestsConcurrent renders tests in parallel,
+	// whose output isn't captured
+	parallel bool
+	
+	// profile is the name of the run profile currently executing
+	profile string
+	
+	// manifest caches the verified baseline manifest of the current run
+	manifest *manifestCache
+	
+	// glApp is the GL app RunWithGL holds open, if any
+	glApp fyne.App
+	
+	// staging is set while StageBaselines writes to a staging directory,
+	// whose baselines the approval policy covers when they are applied
+	staging bool
 }
 
 // NewRunner creates a new test runner with sensible defaults.
@@ -165,86 +535,208 @@ func (r *Runner) ensureApp() fyne.App {
 	return r.app
 }
 
-// RunTest executes a single visual test and captures a screenshot.
+// RunTest executes a single visual test and captures a screenshot. A GL
+// test fails with ErrGLSession unless RunWithGL holds a GL app open.
 func (r *Runner) RunTest(test Test) Result {
-	startTime := time.Now()
-	result := Result{
-		Test:      test,
-		Success:   false,
-		Timestamp: startTime,
-		Metadata:  make(map[string]interface{}),
+	var log bytes.Buffer
+	defer r.flush(&log)
+	
+	return r.runTest(test, &log)
+}
+
+// runTest executes a test, writing its verbose output to log.
+func (r *Runner) runTest(test Test, log io.Writer) Result {
+	return r.process(r.render(test, 1), log)
+}
+
+// renderFirst renders the first attempt of a test that retryFailed may run
+// again.
+func (r *Runner) renderFirst(test Test) *capturedTest {
+	c := r.render(test, 1)
+	c.retries = r.Retries
+	return c
+}
+
+// capturedTest carries a test from the render stage to the processing stage.
+type capturedTest struct {
+	test    Test
+	result  Result
+	img     image.Image
+	size    fyne.Size
+	ignored []image.Rectangle
+	weights []WeightedRect
+	focus   []FocusRect
+	tree    *WidgetNode
+	theme   fyne.Theme
+	
+	// retries is how often the test runs again if this attempt fails
+	retries int
+	
+	// overhead is the render stage time without the render wait
+	overhead time.Duration
+}
+
+// render is the first pipeline stage: it validates the test and captures its
+// content with its render path. Failures are recorded in the result and
+// leave img nil.
+func (r *Runner) render(test Test, attempt int) *capturedTest {
+	if r.renderPath(test) == RenderGL {
+		if r.glApp == nil && GLAvailable() {
+			c := r.renderOn(nil, test, attempt)
+			if errors.Is(c.result.Error, ErrGLUnavailable) {
+				c.result.Error = ErrGLSession
+			}
+			return c
+		}
+		var c *capturedTest
+		r.renderGL([]Test{test}, attempt, func(_ int, captured *capturedTest) { c = captured })
+		return c
+	}
+	if r.IsolateApps {
+		return r.renderIsolated(test, attempt)
+	}
+	return r.renderOn(r.ensureApp(), test, attempt)
+}
+
+// renderIsolated renders test on a fresh test app, then makes the shared app
+// current again.
+func (r *Runner) renderIsolated(test Test, attempt int) *capturedTest {
+	r.isolateMu.Lock()
+	defer r.isolateMu.Unlock()
+	
+	shared := r.ensureApp()
+	isolated := fynetest.NewApp()
+	defer func() {
+		isolated.Quit()
+		fyne.SetCurrentApp(shared)
+	}()
+	return r.renderOn(isolated, test, attempt)
+}
+
+// renderOn renders test on app, which is nil when the test's render path is
+// unavailable.
+func (r *Runner) renderOn(testApp fyne.App, test Test, attempt int) *capturedTest {
+	c := &capturedTest{
+		test: test,
+		result: Result{
+			Test:      test,
+			Success:   false,
+			Timestamp: time.Now(),
+			Metadata:  make(map[string]interface{}),
+			Profile:   r.profile,
+			Attempts:  attempt,
+		},
+	}
+	
+	// A retry continues the test the first attempt started
+	if attempt <= 1 {
+		r.emit(Event{Type: EventTestStart, Test: test.Name, Profile: r.profile})
 	}
+	defer func() { c.overhead += time.Since(c.result.Timestamp) }()
 	
 	// Validate test
 	if err := test.Validate(); err != nil {
-		result.Error = fmt.Errorf("invalid test configuration: %w", err)
-		result.Duration = time.Since(startTime)
-		return result
+		c.result.Error = fmt.Errorf("invalid test configuration: %w", err)
+		return c
+	}
+	if skip, reason := test.Skipped(); skip {
+		c.result.Skipped, c.result.SkipReason = true, reason
+		return c
 	}
 	
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(r.OutputDir, 0755); err != nil {
-		result.Error = fmt.Errorf("failed to create output directory: %w", err)
-		result.Duration = time.Since(startTime)
-		return result
+		c.result.Error = fmt.Errorf("failed to create output directory: %w", err)
+		return c
 	}
 	
-	// Get or create app instance
-	testApp := r.ensureApp()
-	
-	// Set theme
-	theme := test.Theme
-	if theme == nil {
-		theme = r.DefaultTheme
+	c.result.Metadata[MetadataRenderer] = string(r.renderPath(test))
+	if testApp == nil {
+		c.result.Error = ErrGLUnavailable
+		return c
 	}
-	if theme != nil {
-		testApp.Settings().SetTheme(theme)
+	if r.BeforeEach != nil {
+		if err := r.BeforeEach(test); err != nil {
+			c.result.Error = fmt.Errorf("BeforeEach: %w", err)
+			return c
+		}
 	}
+	c.theme = r.applyTheme(testApp, test)
 	
-	// Create window
-	window := testApp.NewWindow(test.Name)
-	defer window.Close()
-	
-	// Get the content to test
-	content := test.Setup()
-	if content == nil {
-		result.Error = fmt.Errorf("test setup returned nil content")
-		result.Duration = time.Since(startTime)
-		return result
+	// Time Setup separately; it is user code, not render or capture time.
+	// Its output is captured with the result; the runner's own output
+	// goes to the terminal meanwhile.
+	// A timed out capture abandons its Setup, taking what it printed so far.
+	setup, guard := test.Setup, newSetupGuard(r.timeout(test) > 0, !r.parallel)
+	test.Setup = func() fyne.CanvasObject {
+		return guard.run(setup)
 	}
 	
-	// Set window content
-	window.SetContent(content)
-	
-	// Calculate appropriate size
-	size := r.calculateWindowSize(test, content)
-	window.Resize(size)
-	
-	// Center window on screen (helps with consistency)
-	window.CenterOnScreen()
-	
-	// Show the window to ensure it's rendered
-	window.Show()
+	shot, stack, err := r.timedCapture(testApp, test, guard)
+	run := guard.result()
+	c.result.Output, c.result.SetupDuration = run.output, run.duration
+	built := run.content
+	c.overhead -= c.result.SetupDuration
+	if err != nil {
+		c.result.Error = err
+		if path, dumpErr := r.writeCrashDump(test, err, stack, built, c.theme); dumpErr == nil {
+			c.result.CrashDump = path
+		}
+		return c
+	}
+	c.img, c.size, c.ignored, c.weights, c.focus, c.tree = shot.img, shot.size, shot.ignored, shot.weights, shot.focus, shot.tree
+	c.overhead -= r.waitDuration(test)
+	return c
+}
+
+// process is the second pipeline stage: it encodes the capture, compares it
+// against the baseline and completes the result. It only uses the CPU, so it
+// can run on a worker while the next test renders.
+func (r *Runner) process(c *capturedTest, log io.Writer) (result Result) {
+	start := time.Now()
+	test, img := c.test, c.img
+	result = c.result
+	if result.Attempts == 0 {
+		result.Attempts = 1
+	}
+	defer func() {
+		result.Overhead = c.overhead + time.Since(start)
+		if test.ExpectedToFail && !result.Skipped {
+			switch {
+			case result.Success && result.Diff != nil:
+				result.UnexpectedPass = true
+				fmt.Fprintf(log, "👀 %s was expected to fail but passed; review its baseline\n", test.Name)
+			case result.mismatched():
+				result.Success, result.ExpectedFailure = true, true
+			}
+		}
+		if r.AfterEach != nil && !result.Skipped {
+			r.AfterEach(result)
+		}
+		// An attempt that is retried isn't reported; the last one is
+		if c.retries == 0 || !retryable(result) {
+			r.emit(newTestFinishEvent(result))
+		}
+		if r.OnResult != nil {
+			r.OnResult(result)
+		}
+	}()
 	
-	// Wait for rendering
-	waitDuration := test.WaitDuration
-	if waitDuration == 0 {
-		waitDuration = r.DefaultWaitDuration
+	if r.SetupThreshold > 0 && result.SetupDuration > r.SetupThreshold {
+		fmt.Fprintf(log, "🐢 Setup of %s took %v (threshold %v); consider moving expensive work into a shared fixture\n",
+			test.Name, result.SetupDuration.Round(time.Millisecond), r.SetupThreshold)
 	}
-	time.Sleep(waitDuration)
 	
-	// Capture the image
-	canvas := window.Canvas()
-	if canvas == nil {
-		result.Error = fmt.Errorf("failed to get canvas from window")
-		result.Duration = time.Since(startTime)
+	if result.Skipped {
+		result.Success = true
+		result.Duration = time.Since(result.Timestamp)
+		if r.Verbose {
+			r.logTestResult(log, result)
+		}
 		return result
 	}
-	
-	img := canvas.Capture()
 	if img == nil {
-		result.Error = fmt.Errorf("failed to capture canvas image")
-		result.Duration = time.Since(startTime)
+		result.Duration = time.Since(result.Timestamp)
 		return result
 	}
 	
@@ -252,12 +744,12 @@ func (r *Runner) RunTest(test Test) Result {
 	
 	// Save the image
 	timestamp := time.Now().Format("20060102-150405")
-	filename := fmt.Sprintf("%s_%s.png", sanitizeFilename(test.Name), timestamp)
+	filename := fmt.Sprintf("%s_%s.png", r.BaselineName(test), timestamp)
 	filepath := filepath.Join(r.OutputDir, filename)
 	
 	if err := r.saveImage(img, filepath); err != nil {
 		result.Error = fmt.Errorf("failed to save screenshot: %w", err)
-		result.Duration = time.Since(startTime)
+		result.Duration = time.Since(result.Timestamp)
 		return result
 	}
 	
@@ -265,36 +757,319 @@ func (r *Runner) RunTest(test Test) Result {
 	result.Success = true
 	result.ScreenshotPath = filepath
 	result.ImageSize = fyne.NewSize(float32(img.Bounds().Dx()), float32(img.Bounds().Dy()))
-	result.Duration = time.Since(startTime)
+	result.ignored = c.ignored
+	
+	// Replace the baseline when selected for update
+	if r.BaselineDir != "" && r.Update.Matches(test.Name, test.Tags) {
+		if err := r.updateBaseline(test, img, c.tree); err != nil {
+			result.Success = false
+			result.Error = fmt.Errorf("failed to update baseline: %w", err)
+		} else {
+			result.Metadata["baseline_updated"] = true
+			fmt.Fprintf(log, "📸 Updated baseline: %s\n", r.BaselinePath(test))
+		}
+	}
+	
+	// A calibrated mask hides regions that vary between identical renders
+	if mask, ok := ReadBaselineMask(r.BaselinePath(test)); ok && mask.Fits(img) {
+		c.ignored = append(append([]image.Rectangle(nil), c.ignored...), mask.Rects()...)
+	}
+	
+	// Compare against the baseline, if any
+	diff, err := r.compareBaseline(test, img, c.ignored, c.weights, c.focus)
+	if err != nil {
+		result.Success = false
+		result.Error = err
+	}
+	result.Diff = diff
+	if diff != nil && diff.WithinBudget {
+		fmt.Fprintf(log, "🪙 %s changed within its budget (severity %.2f of %.2f)\n", test.Name, diff.Severity, r.ChangeBudget(test))
+	}
+	
+	// Rendering often changes between fyne minor versions
+	if diff != nil {
+		if meta, ok := ReadBaselineMeta(r.BaselinePath(test)); ok && meta.CrossesFyneMinor(FyneVersion()) {
+			result.Metadata[MetadataBaselineFyneVersion] = meta.FyneVersion
+			fmt.Fprintf(log, "⚠️  Baseline of %s was created with fyne %s, comparing with %s\n", test.Name, meta.FyneVersion, FyneVersion())
+			if result.Error != nil {
+				result.Error = fmt.Errorf("%w (baseline created with fyne %s)", result.Error, meta.FyneVersion)
+			}
+		}
+	}
+	
+	// Review images are only written for failures; AssetHandler renders
+	// them on demand for passing captures
+	if diff != nil && !diff.Match {
+		assets, err := r.writeDiffAssets(test, filepath, img, c.ignored)
+		if err != nil {
+			fmt.Fprintf(log, "⚠️  Failed to write diff images for %s: %v\n", test.Name, err)
+		}
+		result.DiffAssets = assets
+		
+		// The structural diff often points at the offending code faster
+		result.WidgetChanges = r.widgetChanges(test, c.tree)
+		if n := len(result.WidgetChanges); n > 0 && result.Error != nil {
+			result.Error = fmt.Errorf("%w (%d widget changes)", result.Error, n)
+		}
+		
+		// A diff dominated by one color swap is usually a theme change
+		if hint := r.themeHint(test, img, c.ignored, c.theme); hint != nil {
+			result.Metadata[MetadataThemeHint] = hint.String()
+			if result.Error != nil {
+				result.Error = fmt.Errorf("%w (%s)", result.Error, hint)
+			}
+		}
+	}
+	result.WidgetTree = c.tree
+	if r.AIExport && c.tree != nil {
+		if path, err := WriteAIExport(result); err != nil {
+			fmt.Fprintf(log, "⚠️  Failed to write AI export for %s: %v\n", test.Name, err)
+		} else {
+			result.Metadata[MetadataAIExport] = path
+		}
+	}
+	if r.Describer != nil {
+		if summary, err := r.Describer.DescribeScreen(result); err != nil {
+			fmt.Fprintf(log, "⚠️  Failed to describe %s: %v\n", test.Name, err)
+		} else if summary != "" {
+			result.Metadata[MetadataScreenSummary] = summary
+		}
+	}
+	result.Duration = time.Since(result.Timestamp)
 	
 	// Add metadata
-	result.Metadata["theme"] = getThemeName(theme)
-	result.Metadata["window_size"] = size
+	result.Metadata["theme"] = getThemeName(c.theme)
+	result.Metadata["window_size"] = c.size
+	result.Metadata["seed"] = test.EffectiveSeed()
+	if test.Scale > 0 {
+		result.Metadata["scale"] = test.Scale
+	}
 	
 	if r.Verbose {
-		r.logTestResult(result)
+		r.logTestResult(log, result)
 	}
 	
 	return result
 }
 
-// RunTests executes multiple visual tests sequentially.
+// applyTheme sets the test's theme (or the runner default) on app and
+// returns the theme that was applied.
+func (r *Runner) applyTheme(app fyne.App, test Test) fyne.Theme {
+	th := test.Theme
+	if th == nil {
+		th = r.DefaultTheme
+	}
+	if th != nil {
+		app.Settings().SetTheme(th)
+	} else {
+		// Unless isolated, the app is shared; undo the previous test's theme
+		app.Settings().SetTheme(theme.DefaultTheme())
+	}
+	return th
+}
+
+// windowCapture is what capture records of a rendered test.
+type windowCapture struct {
+	img     image.Image
+	size    fyne.Size
+	ignored []image.Rectangle
+	weights []WeightedRect
+	focus   []FocusRect
+	tree    *WidgetNode
+}
+
+// capture renders the test content in a new window of app and returns the
+// captured image together with the window size used, the pixel rectangles
+// to ignore when comparing and the widget tree of the content.
+func (r *Runner) capture(app fyne.App, test Test) (windowCapture, error) {
+	window, content, size, err := r.showWindow(app, test)
+	if err != nil {
+		return windowCapture{}, err
+	}
+	defer window.Close()
+	
+	// Pin dynamic values after the wait, so timers can't overwrite them
+	if err := FreezeWidgets(content, test.Freezes); err != nil {
+		return windowCapture{}, err
+	}
+	
+	// Capture the image
+	canvas := window.Canvas()
+	if canvas == nil {
+		return windowCapture{}, fmt.Errorf("failed to get canvas from window")
+	}
+	
+	img := canvas.Capture()
+	if img == nil {
+		return windowCapture{}, fmt.Errorf("failed to capture canvas image")
+	}
+	if err := CheckCaptured(size, canvas.Scale(), img); err != nil {
+		return windowCapture{}, err
+	}
+	
+	// Record the widget tree while the window is still open
+	tree := CaptureWidgetTree(content)
+	
+	regions := test.IgnoreRegions
+	if len(test.IgnoreSelectors) > 0 {
+		selected, err := SelectorRegions(tree, test.IgnoreSelectors)
+		if err != nil {
+			return windowCapture{}, err
+		}
+		regions = append(append([]Region(nil), regions...), selected...)
+	}
+	ignored := IgnoreRects(canvas, img, regions, test.IgnoreObjects)
+	weights := WeightedRects(canvas, img, test.Importance)
+	focus, err := FocusRects(canvas, img, tree, test.FocusRegions)
+	if err != nil {
+		return windowCapture{}, err
+	}
+	if test.CropToContent {
+		if crop, ok := contentCrop(canvas, img, content); ok {
+			weights = cropWeights(weights, crop)
+			focus = cropFocus(focus, crop)
+		}
+		img, ignored = CropToObject(canvas, img, content, ignored)
+	}
+	return windowCapture{img: img, size: size, ignored: ignored, weights: weights, focus: focus, tree: tree}, nil
+}
+
+// showWindow shows the content of test in a new window of app, sized and
+// scaled for the test, and waits for it to render. The caller closes the
+// window.
+func (r *Runner) showWindow(app fyne.App, test Test) (fyne.Window, fyne.CanvasObject, fyne.Size, error) {
+	// Create window
+	window := newCaptureWindow(app, test.Name)
+	
+	// Get the content to test
+	content := test.Setup()
+	if content == nil {
+		window.Close()
+		return nil, nil, fyne.Size{}, fmt.Errorf("test setup returned nil content")
+	}
+	
+	// Set window content
+	window.SetContent(content)
+	if test.Unpadded {
+		window.SetPadded(false)
+	}
+	
+	// Apply DPI scale for variant captures
+	if test.Scale > 0 {
+		scaler, ok := window.Canvas().(interface{ SetScale(float32) })
+		if !ok {
+			window.Close()
+			return nil, nil, fyne.Size{}, fmt.Errorf("canvas does not support scaling")
+		}
+		scaler.SetScale(test.Scale)
+	}
+	
+	// Calculate appropriate size
+	size := r.calculateWindowSize(test, content)
+	if err := CheckCanvasSize(size, window.Canvas().Scale(), r.MaxCaptureSize); err != nil {
+		window.Close()
+		return nil, nil, fyne.Size{}, err
+	}
+	window.Resize(size)
+	
+	// Center window on screen (helps with consistency)
+	window.CenterOnScreen()
+	
+	// Show the window to ensure it's rendered
+	window.Show()
+	
+	// Wait for rendering
+	time.Sleep(r.waitDuration(test))
+	
+	return window, content, size, nil
+}
+
+// waitDuration returns how long to wait for test to render.
+func (r *Runner) waitDuration(test Test) time.Duration {
+	if test.WaitDuration == 0 {
+		return r.DefaultWaitDuration
+	}
+	return test.WaitDuration
+}
+
+// RunTests executes multiple visual tests, rendering them one at a time.
+// Encoding and baseline comparison of each capture run on PipelineWorkers
+// background workers, overlapping with the render wait of the next test.
+// GL tests render last, all in one GL app that stays open for their
+// retries; with any, call it from the main goroutine or inside RunWithGL.
+// Results are returned in test order.
 func (r *Runner) RunTests(tests []Test) []Result {
-	results := make([]Result, 0, len(tests))
+	// One GL app renders the GL tests and their retries
+	if r.glApp == nil && r.needsGL(tests) {
+		var results []Result
+		if err := r.RunWithGL(func() { results = r.RunTests(tests) }); err == nil {
+			return results
+		}
+	}
+	r.resetManifest()
+	results := make([]Result, len(tests))
 	
-	for i, test := range tests {
+	type job struct {
+		index    int
+		captured *capturedTest
+		log      *bytes.Buffer
+	}
+	
+	workers := r.PipelineWorkers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	jobs := make(chan job, workers)
+	
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results[j.index] = r.process(j.captured, j.log)
+				r.flush(j.log)
+			}
+		}()
+	}
+	
+	newLog := func(i int) *bytes.Buffer {
+		log := &bytes.Buffer{}
 		if r.Verbose {
-			fmt.Printf("[%d/%d] Running test: %s\n", i+1, len(tests), test.Name)
+			fmt.Fprintf(log, "[%d/%d] Running test: %s\n", i+1, len(tests), tests[i].Name)
 		}
-		result := r.RunTest(test)
-		results = append(results, result)
+		return log
+	}
+	
+	// GL tests share one GL app, rendered after the software ones
+	gl := make([]int, 0)
+	for i, test := range tests {
+		if r.renderPath(test) == RenderGL {
+			gl = append(gl, i)
+			continue
+		}
+		log := newLog(i)
+		jobs <- job{index: i, captured: r.renderFirst(test), log: log}
 		
 		// Small delay between tests to ensure clean state
 		if i < len(tests)-1 {
 			time.Sleep(50 * time.Millisecond)
 		}
 	}
+	if len(gl) > 0 {
+		glTests := make([]Test, len(gl))
+		for k, i := range gl {
+			glTests[k] = tests[i]
+		}
+		r.renderGL(glTests, 1, func(k int, captured *capturedTest) {
+			captured.retries = r.Retries
+			jobs <- job{index: gl[k], captured: captured, log: newLog(gl[k])}
+		})
+	}
+	close(jobs)
+	wg.Wait()
 	
+	r.retryFailed(tests, results)
 	return results
 }
 
@@ -311,30 +1086,68 @@ func (r *Runner) RunTestsWithTimestamp(tests []Test) ([]Result, string) {
 }
 
 // RunTestsConcurrent executes tests in parallel with a specified concurrency level.
+// Capturing a Setup's output redirects the process-wide stdout and stderr,
+// which would make Setups run one at a time and take in the output of the
+// tests running beside them, so with a concurrency above one Result.Output
+// stays empty. GL tests and retries still run one at a time and capture it.
 func (r *Runner) RunTestsConcurrent(tests []Test, maxConcurrency int) []Result {
 	if maxConcurrency <= 0 {
 		maxConcurrency = 1
 	}
+	if r.glApp == nil && r.needsGL(tests) {
+		var results []Result
+		if err := r.RunWithGL(func() { results = r.RunTestsConcurrent(tests, maxConcurrency) }); err == nil {
+			return results
+		}
+	}
 	
+	r.resetManifest()
 	results := make([]Result, len(tests))
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, maxConcurrency)
 	
+	// GL tests can't render concurrently; they share one GL app first
+	gl := make([]int, 0)
+	glTests := make([]Test, 0)
+	for i, test := range tests {
+		if r.renderPath(test) == RenderGL {
+			gl = append(gl, i)
+			glTests = append(glTests, test)
+		}
+	}
+	if len(gl) > 0 {
+		r.renderGL(glTests, 1, func(k int, captured *capturedTest) {
+			captured.retries = r.Retries
+			var log bytes.Buffer
+			defer r.flush(&log)
+			results[gl[k]] = r.process(captured, &log)
+		})
+	}
+	
+	r.parallel = maxConcurrency > 1
 	for i, test := range tests {
+		if r.renderPath(test) == RenderGL {
+			continue
+		}
 		wg.Add(1)
 		go func(index int, t Test) {
 			defer wg.Done()
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 			
+			var log bytes.Buffer
+			defer r.flush(&log)
+			
 			if r.Verbose {
-				fmt.Printf("Running test (concurrent): %s\n", t.Name)
+				fmt.Fprintf(&log, "Running test (concurrent): %s\n", t.Name)
 			}
-			results[index] = r.RunTest(t)
+			results[index] = r.process(r.renderFirst(t), &log)
 		}(i, test)
 	}
 	
 	wg.Wait()
+	r.parallel = false
+	r.retryFailed(tests, results)
 	return results
 }
 
@@ -374,29 +1187,57 @@ func (r *Runner) saveImage(img image.Image, filepath string) error {
 	}
 	defer file.Close()
 	
-	return png.Encode(file, img)
+	return EncodePNG(file, img)
 }
 
-func (r *Runner) logTestResult(result Result) {
+func (r *Runner) logTestResult(w io.Writer, result Result) {
 	status := "✅ PASS"
-	if !result.Success {
+	if result.Skipped {
+		fmt.Fprintf(w, "⏭️  SKIP Test '%s'\n", result.Test.Name)
+		if result.SkipReason != "" {
+			fmt.Fprintf(w, "   Reason: %s\n", result.SkipReason)
+		}
+		return
+	}
+	// Logged before an expected failure counts as a pass
+	switch {
+	case result.Test.ExpectedToFail && result.mismatched():
+		status = "🟡 XFAIL"
+	case !result.Success:
 		status = "❌ FAIL"
+	case result.Test.ExpectedToFail && result.Diff != nil:
+		status = "👀 XPASS"
 	}
 	
-	fmt.Printf("%s Test '%s' completed in %v\n", status, result.Test.Name, result.Duration)
+	fmt.Fprintf(w, "%s Test '%s' completed in %v\n", status, result.Test.Name, result.Duration)
 	
 	if result.Test.Description != "" {
-		fmt.Printf("   Description: %s\n", result.Test.Description)
+		fmt.Fprintf(w, "   Description: %s\n", result.Test.Description)
 	}
 	
 	if result.Success {
-		fmt.Printf("   Screenshot: %s\n", result.ScreenshotPath)
-		fmt.Printf("   Size: %dx%d pixels\n", int(result.ImageSize.Width), int(result.ImageSize.Height))
+		fmt.Fprintf(w, "   Screenshot: %s\n", result.ScreenshotPath)
+		fmt.Fprintf(w, "   Size: %dx%d pixels\n", int(result.ImageSize.Width), int(result.ImageSize.Height))
+	} else if result.Test.ExpectedToFail && result.mismatched() {
+		fmt.Fprintf(w, "   Expected failure: %v\n", result.Error)
 	} else {
-		fmt.Printf("   Error: %v\n", result.Error)
+		fmt.Fprintf(w, "   Error: %v\n", result.Error)
+		if result.CrashDump != "" {
+			fmt.Fprintf(w, "   Crash dump: %s\n", result.CrashDump)
+		}
+		for _, change := range result.WidgetChanges {
+			fmt.Fprintf(w, "   - %s\n", change)
+		}
+	}
+	
+	if result.Output != "" {
+		fmt.Fprintf(w, "   Output:\n")
+		for _, line := range strings.Split(strings.TrimRight(result.Output, "\n"), "\n") {
+			fmt.Fprintf(w, "     %s\n", line)
+		}
 	}
 	
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 func sanitizeFilename(name string) string {
@@ -0,0 +1,120 @@
+package fynetest
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestThemeDoesNotLeakIntoNextTest(t *testing.T) {
+	r := NewRunner()
+	r.OutputDir = t.TempDir()
+	r.DefaultWaitDuration = 0
+	r.DefaultTheme = nil
+	t.Cleanup(r.Cleanup)
+
+	dark := theme.DarkTheme()
+	var used fyne.Theme
+	tests := []Test{
+		NewTest("Dark").
+			WithSize(100, 50).
+			WithTheme(dark).
+			WithSetup(func() fyne.CanvasObject { return widget.NewLabel("Dark") }).
+			MustBuild(),
+		NewTest("Unthemed").
+			WithSize(100, 50).
+			WithSetup(func() fyne.CanvasObject {
+				used = fyne.CurrentApp().Settings().Theme()
+				return widget.NewLabel("Unthemed")
+			}).
+			MustBuild(),
+	}
+
+	for _, result := range r.RunTests(tests) {
+		if !result.Success {
+			t.Fatalf("%s failed: %v", result.Test.Name, result.Error)
+		}
+	}
+	if used == dark {
+		t.Error("a test without a theme rendered with the previous test's theme")
+	}
+}
+
+func TestSkippedTestsReportedAsSkipped(t *testing.T) {
+	r := snapshotRunner(t)
+	var setups atomic.Int32
+	build := func(name string) *TestBuilder {
+		return NewTest(name).
+			WithSize(100, 50).
+			WithSetup(func() fyne.CanvasObject {
+				setups.Add(1)
+				return widget.NewLabel(name)
+			})
+	}
+	tests := []Test{
+		build("Skipped").WithSkip("waiting for the redesign").MustBuild(),
+		build("SkipFunc").WithSkipFunc(func() (bool, string) { return true, "no printer" }).MustBuild(),
+		build("Runs").WithSkipFunc(func() (bool, string) { return false, "" }).MustBuild(),
+	}
+
+	results := r.RunTests(tests)
+	for i, reason := range []string{"waiting for the redesign", "no printer"} {
+		if !results[i].Skipped || !results[i].Success || results[i].SkipReason != reason {
+			t.Errorf("%s: skipped %v, success %v, reason %q; want skipped with %q",
+				results[i].Test.Name, results[i].Skipped, results[i].Success, results[i].SkipReason, reason)
+		}
+	}
+	if results[2].Skipped || !results[2].Success {
+		t.Errorf("Runs: skipped %v, success %v; want it run", results[2].Skipped, results[2].Success)
+	}
+	if n := setups.Load(); n != 1 {
+		t.Errorf("Setup ran %d times, want only for the test that isn't skipped", n)
+	}
+
+	suite := SuiteResult{Results: results}
+	if suite.Skipped() != 2 || suite.Passed() != 1 || suite.Failed() != 0 {
+		t.Errorf("suite counts %d skipped, %d passed, %d failed; want 2, 1, 0", suite.Skipped(), suite.Passed(), suite.Failed())
+	}
+}
+
+func TestExpectedFailures(t *testing.T) {
+	r := snapshotRunner(t)
+	text := "Before"
+	xfail := func(name string) Test {
+		return NewTest(name).
+			WithSize(120, 40).
+			WithExpectedToFail().
+			WithSetup(func() fyne.CanvasObject { return widget.NewLabel(text) }).
+			MustBuild()
+	}
+	tests := []Test{xfail("Regressed"), xfail("Fixed")}
+	r.Update = SnapshotUpdate{All: true}
+	r.RunTests(tests)
+	r.Update = SnapshotUpdate{}
+
+	text = "After"
+	regressed := r.RunTest(tests[0])
+	if !regressed.Success || !regressed.ExpectedFailure || regressed.UnexpectedPass || regressed.Error == nil {
+		t.Errorf("a mismatch: success %v, expected failure %v, unexpected pass %v, error %v; want an expected failure with its error",
+			regressed.Success, regressed.ExpectedFailure, regressed.UnexpectedPass, regressed.Error)
+	}
+
+	text = "Before"
+	fixed := r.RunTest(tests[1])
+	if !fixed.Success || fixed.ExpectedFailure || !fixed.UnexpectedPass {
+		t.Errorf("a match: success %v, expected failure %v, unexpected pass %v; want it flagged for a baseline review",
+			fixed.Success, fixed.ExpectedFailure, fixed.UnexpectedPass)
+	}
+
+	// Only a mismatch is the expected failure; a crash still fails
+	crashing := NewTest("Crashing").
+		WithExpectedToFail().
+		WithSetup(func() fyne.CanvasObject { panic("broken") }).
+		MustBuild()
+	if crashed := r.RunTest(crashing); crashed.Success || crashed.ExpectedFailure {
+		t.Errorf("a crash: success %v, expected failure %v; want it failed", crashed.Success, crashed.ExpectedFailure)
+	}
+}
@@ -0,0 +1,142 @@
+package fynetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// lfsPointerPrefix starts every git LFS pointer file.
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/v1\n"
+
+// maxLFSPointerSize bounds the blobs inspected for an LFS pointer; real
+// pointers are around 130 bytes.
+const maxLFSPointerSize = 1024
+
+// GitRepoBaselineSource provides the baselines of a dedicated git
+// repository, so baseline churn stays out of the application repository.
+// The repository is fetched into CacheDir on first use, once per run, with
+// a shallow fetch of Branch. Baselines stored with git LFS are resolved
+// through "git lfs smudge", which needs git-lfs installed and access to the
+// LFS server.
+type GitRepoBaselineSource struct {
+	// URL is the repository to clone, e.g.
+	// "https://github.com/acme/myapp-goldens.git"
+	URL string
+
+	// Branch holds the baselines (default: the remote's default branch)
+	Branch string
+
+	// Dir is the baseline directory inside the repository (default: its root)
+	Dir string
+
+	// CacheDir receives the shallow clone; it is reused across runs
+	CacheDir string
+
+	once    sync.Once
+	repo    *GitBaselineSource
+	syncErr error
+}
+
+// NewGitRepoBaselineSource returns a source reading the baselines at the
+// root of branch in the repository at url, cloned into cacheDir.
+func NewGitRepoBaselineSource(url, branch, cacheDir string) *GitRepoBaselineSource {
+	return &GitRepoBaselineSource{URL: url, Branch: branch, CacheDir: cacheDir}
+}
+
+// sync fetches the latest commit of Branch into CacheDir, once.
+func (g *GitRepoBaselineSource) sync() error {
+	g.once.Do(func() {
+		if err := os.MkdirAll(g.CacheDir, 0755); err != nil {
+			g.syncErr = err
+			return
+		}
+		if _, err := os.Stat(filepath.Join(g.CacheDir, ".git")); err != nil {
+			if _, err := runGit(g.CacheDir, nil, "init", "--quiet"); err != nil {
+				g.syncErr = err
+				return
+			}
+		}
+		// git lfs smudge finds the LFS server through the origin remote
+		if _, err := runGit(g.CacheDir, nil, "remote", "set-url", "origin", g.URL); err != nil {
+			if _, err := runGit(g.CacheDir, nil, "remote", "add", "origin", g.URL); err != nil {
+				g.syncErr = err
+				return
+			}
+		}
+		ref := g.Branch
+		if ref == "" {
+			ref = "HEAD"
+		}
+		if _, err := runGit(g.CacheDir, nil, "fetch", "--quiet", "--depth", "1", "origin", ref); err != nil {
+			g.syncErr = fmt.Errorf("failed to fetch baselines from %s: %w", g.URL, err)
+			return
+		}
+		g.repo = &GitBaselineSource{Ref: "FETCH_HEAD", Dir: g.Dir, Repo: g.CacheDir}
+	})
+	return g.syncErr
+}
+
+// Fetch implements BaselineSource. A cached copy identical to the committed
+// baseline, or to the LFS object it points to, is kept.
+func (g *GitRepoBaselineSource) Fetch(name, dst string) (bool, error) {
+	if err := g.sync(); err != nil {
+		return false, err
+	}
+	pointer, oid := g.lfsPointer(name)
+	if pointer == nil {
+		// Missing baselines are reported by the plain git source
+		return g.repo.Fetch(name, dst)
+	}
+
+	if local, err := os.ReadFile(dst); err == nil {
+		if sum := sha256.Sum256(local); hex.EncodeToString(sum[:]) == oid {
+			return false, nil
+		}
+	}
+	data, err := runGit(g.CacheDir, pointer, "lfs", "smudge", "--", name)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve LFS baseline %s (is git-lfs installed?): %w", name, err)
+	}
+	if err := writeFileAtomic(dst, bytes.NewReader(data)); err != nil {
+		return false, fmt.Errorf("failed to cache baseline %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// lfsPointer returns the committed baseline and the sha256 object ID it
+// points to when it is an LFS pointer, or nil otherwise.
+func (g *GitRepoBaselineSource) lfsPointer(name string) ([]byte, string) {
+	object := g.repo.object(name)
+	size, err := g.repo.git("cat-file", "-s", object)
+	if err != nil {
+		return nil, ""
+	}
+	if n, err := strconv.Atoi(string(size)); err != nil || n > maxLFSPointerSize {
+		return nil, ""
+	}
+	data, err := g.repo.git("show", "--no-textconv", object)
+	if err != nil || !bytes.HasPrefix(data, []byte(lfsPointerPrefix)) {
+		return nil, ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if oid, ok := strings.CutPrefix(line, "oid sha256:"); ok {
+			return data, oid
+		}
+	}
+	return nil, ""
+}
+
+// Names lists the baseline files in Dir on Branch.
+func (g *GitRepoBaselineSource) Names() ([]string, error) {
+	if err := g.sync(); err != nil {
+		return nil, err
+	}
+	return g.repo.Names()
+}
@@ -0,0 +1,75 @@
+package fynetest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lfsFixture commits a baseline stored as an LFS pointer to a new
+// repository and returns the repository and the object's content.
+func lfsFixture(t *testing.T) (string, []byte) {
+	t.Helper()
+	repo := t.TempDir()
+	object := []byte("baseline image bytes")
+	sum := sha256.Sum256(object)
+	pointer := fmt.Sprintf("%soid sha256:%s\nsize %d\n", lfsPointerPrefix, hex.EncodeToString(sum[:]), len(object))
+	if err := os.WriteFile(filepath.Join(repo, "Login.png"), []byte(pointer), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "Login.png"},
+		{"-c", "user.name=vfyne", "-c", "user.email=vfyne@example.com", "commit", "--quiet", "-m", "baselines"},
+	} {
+		if _, err := runGit(repo, nil, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return repo, object
+}
+
+func TestGitRepoBaselineSourceLFSPointer(t *testing.T) {
+	if _, err := runGit(".", nil, "--version"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo, object := lfsFixture(t)
+	source := NewGitRepoBaselineSource(repo, "", filepath.Join(t.TempDir(), "cache"))
+
+	names, err := source.Names()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "Login.png" {
+		t.Fatalf("Names() = %v, want [Login.png]", names)
+	}
+
+	// Without a remote, git lfs smudge cannot locate the LFS server
+	url, err := runGit(source.CacheDir, nil, "remote", "get-url", "origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(url)); got != repo {
+		t.Errorf("origin = %q, want %q", got, repo)
+	}
+
+	pointer, oid := source.lfsPointer("Login.png")
+	sum := sha256.Sum256(object)
+	if pointer == nil || oid != hex.EncodeToString(sum[:]) {
+		t.Fatalf("lfsPointer() = %q, %q; want the pointer to %x", pointer, oid, sum)
+	}
+
+	// A cached copy of the LFS object is kept without smudging
+	dst := filepath.Join(t.TempDir(), "Login.png")
+	if err := os.WriteFile(dst, object, 0644); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := source.Fetch("Login.png", dst)
+	if err != nil || updated {
+		t.Fatalf("Fetch() = %v, %v; want false, nil", updated, err)
+	}
+}
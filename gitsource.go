@@ -0,0 +1,141 @@
+package fynetest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GitBaselineSource provides the baselines committed at a git ref, e.g. to
+// compare a branch's captures against the baselines on main without merging
+// baseline updates first. Baselines are read with "git show", so the ref
+// needs to be available locally (fetch it first in shallow CI clones).
+type GitBaselineSource struct {
+	// Ref is the commit, branch or tag to read baselines from, e.g. "main"
+	// or "origin/main"
+	Ref string
+
+	// Dir is the baseline directory relative to Repo, e.g. "testdata/goldens"
+	Dir string
+
+	// Repo is a directory inside the work tree (default: the current directory)
+	Repo string
+}
+
+// NewGitBaselineSource returns a source reading the baselines in dir, a
+// path relative to the current directory, as committed at ref.
+func NewGitBaselineSource(ref, dir string) *GitBaselineSource {
+	return &GitBaselineSource{Ref: ref, Dir: dir}
+}
+
+// object returns the "<ref>:./<path>" name of a baseline file.
+func (g *GitBaselineSource) object(name string) string {
+	return g.Ref + ":./" + path.Join(filepath.ToSlash(g.Dir), name)
+}
+
+// Fetch implements BaselineSource. A cached copy identical to the committed
+// blob is kept.
+func (g *GitBaselineSource) Fetch(name, dst string) (bool, error) {
+	object := g.object(name)
+	blob, err := g.git("rev-parse", "--verify", "--quiet", object)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return false, err
+		}
+		// A mistyped ref must not pass for a ref without baselines
+		if _, err := g.git("rev-parse", "--verify", "--quiet", g.Ref+"^{commit}"); err != nil {
+			return false, fmt.Errorf("unknown git ref %q", g.Ref)
+		}
+		return false, fmt.Errorf("%s: %w", object, fs.ErrNotExist)
+	}
+
+	if abs, err := filepath.Abs(dst); err == nil {
+		dst = abs
+	}
+	if _, err := os.Stat(dst); err == nil {
+		if local, err := g.git("hash-object", dst); err == nil && bytes.Equal(local, blob) {
+			return false, nil
+		}
+	}
+
+	data, err := g.git("show", "--no-textconv", object)
+	if err != nil {
+		return false, err
+	}
+	if err := writeFileAtomic(dst, bytes.NewReader(data)); err != nil {
+		return false, fmt.Errorf("failed to cache baseline %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// Names lists the baseline files committed in Dir at Ref.
+func (g *GitBaselineSource) Names() ([]string, error) {
+	out, err := g.git("ls-tree", "--name-only", g.Ref+":./"+filepath.ToSlash(g.Dir))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0)
+	for _, name := range strings.Split(string(out), "\n") {
+		if strings.HasSuffix(name, ".png") {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// ExportGitBaselines writes every baseline committed in dir at ref into
+// dst, which can then serve as a runner's BaselineDir, and returns the
+// files written.
+func ExportGitBaselines(ref, dir, dst string) ([]string, error) {
+	source := NewGitBaselineSource(ref, dir)
+	names, err := source.Names()
+	if err != nil {
+		return nil, err
+	}
+	written := make([]string, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dst, name)
+		if _, err := source.Fetch(name, path); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// git runs a git command in Repo and returns its trimmed output. Output of
+// "git show" is returned as is, since it is file content.
+func (g *GitBaselineSource) git(args ...string) ([]byte, error) {
+	out, err := runGit(g.Repo, nil, args...)
+	if err != nil || args[0] == "show" {
+		return out, err
+	}
+	return bytes.TrimSpace(out), nil
+}
+
+// runGit runs a git command in dir, feeding it stdin if set, and returns
+// its output.
+func runGit(dir string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
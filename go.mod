@@ -1,8 +1,11 @@
 module github.com/jairo/vfyne
 
-go 1.21
+go 1.22
 
-require fyne.io/fyne/v2 v2.4.3
+require (
+	fyne.io/fyne/v2 v2.4.3
+	github.com/klauspost/compress v1.18.0
+)
 
 require (
 	fyne.io/systray v1.10.1-0.20231115130155-104f5ef7839e // indirect
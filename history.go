@@ -0,0 +1,130 @@
+package fynetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistoryDir is the directory inside a baseline directory that keeps the
+// previous versions of updated baselines, as "<name>/<timestamp>.png".
+const HistoryDir = ".history"
+
+// historyTimestamp names archived versions; it sorts chronologically.
+const historyTimestamp = "20060102-150405.000"
+
+// historyDir returns the directory holding the previous versions of the
+// baseline at path.
+func historyDir(path string) string {
+	return filepath.Join(filepath.Dir(path), HistoryDir, strings.TrimSuffix(filepath.Base(path), ".png"))
+}
+
+// ArchiveBaseline copies the baseline at path into its history before it is
+// replaced, keeping the depth most recent versions. It returns the archived
+// copy, or "" when there is no baseline yet.
+func ArchiveBaseline(path string, depth int) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	dir := historyDir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	// Versions archived in the same millisecond get a counter that sorts
+	// after the first one, since "_" sorts after "."
+	stamp := time.Now().Format(historyTimestamp)
+	archived := filepath.Join(dir, stamp+".png")
+	for n := 2; fileExists(archived); n++ {
+		archived = filepath.Join(dir, fmt.Sprintf("%s_%03d.png", stamp, n))
+	}
+	if err := os.WriteFile(archived, data, 0644); err != nil {
+		return "", err
+	}
+	return archived, pruneHistory(path, depth)
+}
+
+// BaselineHistory returns the archived versions of the baseline at path,
+// newest first.
+func BaselineHistory(path string) ([]string, error) {
+	entries, err := os.ReadDir(historyDir(path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".png") {
+			versions = append(versions, filepath.Join(historyDir(path), entry.Name()))
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	return versions, nil
+}
+
+// pruneHistory removes all but the depth newest versions of a baseline.
+func pruneHistory(path string, depth int) error {
+	if depth <= 0 {
+		return nil
+	}
+	versions, err := BaselineHistory(path)
+	if err != nil {
+		return err
+	}
+	for len(versions) > depth {
+		if err := os.Remove(versions[len(versions)-1]); err != nil {
+			return err
+		}
+		versions = versions[:len(versions)-1]
+	}
+	return nil
+}
+
+// RestoreBaseline replaces the baseline at path with an archived version
+// returned by BaselineHistory, e.g. to revert a bad approval. The current
+// baseline is archived first, so the restore can be undone too. Digest,
+// metadata and manifest entry are rewritten for the restored image; the
+// stored widget tree is removed, since it describes the replaced version.
+// Baselines covered by the directory's approval policy can't be restored
+// this way; they only change through staging and ApplyStagedBaselines.
+func RestoreBaseline(path, version string, depth int) error {
+	img, err := decodePNGFile(version)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", version, err)
+	}
+
+	manifest, err := LoadManifest(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	entry := manifest.Baselines[filepath.Base(path)]
+	if err := CheckBaselineWrite(filepath.Dir(path), entry.Test, entry.Tags); err != nil {
+		return err
+	}
+
+	if _, err := ArchiveBaseline(path, depth); err != nil {
+		return err
+	}
+	if err := SaveBaseline(path, img); err != nil {
+		return err
+	}
+	if err := os.Remove(path + TreeSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return RecordBaseline(path, entry.Test, entry.Tags...)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,80 @@
+package fynetest
+
+import (
+	"image"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveBaselineKeepsNewestVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Login.png")
+	if archived, err := ArchiveBaseline(path, 2); err != nil || archived != "" {
+		t.Fatalf("ArchiveBaseline() without a baseline = %q, %v; want nothing archived", archived, err)
+	}
+
+	// Versions are told apart by size; all are archived within milliseconds
+	for size := 1; size <= 4; size++ {
+		if size > 1 {
+			if _, err := ArchiveBaseline(path, 2); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := SaveBaseline(path, image.NewRGBA(image.Rect(0, 0, size, size))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := BaselineHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("history holds %d versions, want 2: %v", len(versions), versions)
+	}
+	for i, want := range []int{3, 2} {
+		img, err := decodePNGFile(versions[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := img.Bounds().Dx(); got != want {
+			t.Errorf("version %d is %dpx wide, want %d (newest first)", i, got, want)
+		}
+	}
+}
+
+func TestRunnerArchivesReplacedBaselines(t *testing.T) {
+	r := snapshotRunner(t)
+	r.HistoryDepth = 5
+	r.Update = SnapshotUpdate{All: true}
+	text := "First"
+	test := labelTest("Greeting", &text)
+	path := r.BaselinePath(test)
+
+	// Creating and rewriting an identical baseline archives nothing
+	r.RunTest(test)
+	r.RunTest(test)
+	if versions, _ := BaselineHistory(path); len(versions) != 0 {
+		t.Fatalf("history = %v, want it empty", versions)
+	}
+
+	text = "Second"
+	r.RunTest(test)
+	versions, _ := BaselineHistory(path)
+	if len(versions) != 1 {
+		t.Fatalf("history = %v, want the replaced version", versions)
+	}
+
+	// Restoring the first version archives the second
+	if err := RestoreBaseline(path, versions[0], r.HistoryDepth); err != nil {
+		t.Fatal(err)
+	}
+	if versions, _ := BaselineHistory(path); len(versions) != 2 {
+		t.Errorf("history after restoring = %v, want 2 versions", versions)
+	}
+	r.Update = SnapshotUpdate{}
+	text = "First"
+	r.resetManifest()
+	if result := r.RunTest(test); !result.Success {
+		t.Errorf("the restored baseline doesn't match the first version: %v", result.Error)
+	}
+}
@@ -0,0 +1,82 @@
+package fynetest
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultIncrementalInterval is how often an IncrementalReport is rewritten
+// at most while results keep coming in.
+const DefaultIncrementalInterval = 2 * time.Second
+
+// IncrementalReport rewrites the HTML and JSON reports of a run as its
+// results come in, so the results of finished tests survive a crash or an
+// interrupted run. The pages are marked as in progress and reload
+// themselves until the final report replaces them. It is safe for
+// concurrent use.
+type IncrementalReport struct {
+	// Interval is the least time between two rewrites; results added in
+	// between are written with the next one or by Flush
+	// (default: DefaultIncrementalInterval)
+	Interval time.Duration
+
+	generator ReportGenerator
+	path      string
+
+	mu      sync.Mutex
+	results []Result
+	index   map[string]int
+	written time.Time
+	pending bool
+}
+
+// NewIncrementalReport returns an incremental report written to
+// outputPath with the settings of g.
+func (g *ReportGenerator) NewIncrementalReport(outputPath string) *IncrementalReport {
+	generator := *g
+	generator.InProgress = true
+	return &IncrementalReport{
+		Interval:  DefaultIncrementalInterval,
+		generator: generator,
+		path:      outputPath,
+		index:     make(map[string]int),
+	}
+}
+
+// Add records a finished result, replacing an earlier attempt of the same
+// test and profile, and rewrites the report unless it was rewritten less
+// than Interval ago.
+func (ir *IncrementalReport) Add(result Result) error {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+
+	key := result.Profile + "\x00" + result.Test.Name
+	if i, ok := ir.index[key]; ok {
+		ir.results[i] = result
+	} else {
+		ir.index[key] = len(ir.results)
+		ir.results = append(ir.results, result)
+	}
+	ir.pending = true
+	if time.Since(ir.written) < ir.Interval {
+		return nil
+	}
+	return ir.write()
+}
+
+// Flush rewrites the report with every result added so far, if any were
+// added since the last rewrite.
+func (ir *IncrementalReport) Flush() error {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+
+	if !ir.pending {
+		return nil
+	}
+	return ir.write()
+}
+
+func (ir *IncrementalReport) write() error {
+	ir.written, ir.pending = time.Now(), false
+	return ir.generator.GenerateHTMLReport(ir.results, ir.path)
+}
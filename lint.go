@@ -0,0 +1,296 @@
+package fynetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// DefaultLintNamePattern is the naming convention LintTests enforces by
+// default: lowercase words joined by underscores or dashes, e.g.
+// "login_form" or "settings-dark".
+const DefaultLintNamePattern = `^[a-z][a-z0-9]*([_-][a-z0-9]+)*$`
+
+// LintSeverity tells whether a lint issue should fail CI.
+type LintSeverity string
+
+const (
+	// LintError marks suites that are broken: tests that overwrite each
+	// other's captures or can't run
+	LintError LintSeverity = "error"
+
+	// LintWarning marks smells that make the suite harder to maintain or
+	// miss regressions
+	LintWarning LintSeverity = "warning"
+)
+
+// Lint rules.
+const (
+	LintInvalid           = "invalid-test"
+	LintDuplicateName     = "duplicate-name"
+	LintFilenameCollision = "filename-collision"
+	LintNameConvention    = "name-convention"
+	LintNoDescription     = "missing-description"
+	LintNoTags            = "missing-tags"
+	LintNoSizeVariant     = "no-size-variant"
+	LintMissingBaseline   = "missing-baseline"
+	LintHardcodedTheme    = "hardcoded-theme"
+)
+
+// LintIssue is a smell found in one test of a suite.
+type LintIssue struct {
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	Test     string       `json:"test"`
+	Message  string       `json:"message"`
+}
+
+// LintReport lists the issues found by LintTests, by test and rule.
+type LintReport struct {
+	Tests    int         `json:"tests"`
+	Errors   int         `json:"errors"`
+	Warnings int         `json:"warnings"`
+	Issues   []LintIssue `json:"issues"`
+}
+
+// Failed reports whether the report should fail a CI job: with errors, or
+// with warnings too when strict.
+func (r LintReport) Failed(strict bool) bool {
+	return r.Errors > 0 || strict && r.Warnings > 0
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r LintReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes the report for people, one line per issue.
+func (r LintReport) WriteText(w io.Writer) {
+	for _, issue := range r.Issues {
+		icon := "⚠️ "
+		if issue.Severity == LintError {
+			icon = "❌"
+		}
+		fmt.Fprintf(w, "%s %s [%s] %s\n", icon, issue.Test, issue.Rule, issue.Message)
+	}
+	if len(r.Issues) == 0 {
+		fmt.Fprintf(w, "✅ Linted %d tests: no issues\n", r.Tests)
+		return
+	}
+	fmt.Fprintf(w, "\n🔍 Linted %d tests: %d errors, %d warnings\n", r.Tests, r.Errors, r.Warnings)
+}
+
+// LintOptions configures LintTests.
+type LintOptions struct {
+	// BaselineDir, when set, flags tests without a baseline in it
+	BaselineDir string
+
+	// VariantBaselines names baselines after their variant, as
+	// Runner.VariantBaselines does
+	VariantBaselines bool
+
+	// Profiles are the suite's run profiles, which give the tests they
+	// select size variants and baselines of their own
+	Profiles []Profile
+
+	// NamePattern is the regular expression test names must match
+	// (default: DefaultLintNamePattern)
+	NamePattern string
+
+	// SkipSetup leaves out the checks that call each test's Setup, such as
+	// the one for hard-coded themes
+	SkipSetup bool
+}
+
+// LintTests analyzes tests for smells: invalid or clashing tests, names off
+// the naming convention, tests without a description, tags or size variant,
+// tests without a baseline and Setup functions that set the app theme
+// instead of Test.Theme. Unless options.SkipSetup is set, the Setup of every
+// test is called once to find hard-coded themes.
+func LintTests(tests []Test, options LintOptions) (LintReport, error) {
+	r := NewRunner()
+	r.BaselineDir = options.BaselineDir
+	r.VariantBaselines = options.VariantBaselines
+	return r.lint(tests, options)
+}
+
+// Lint analyzes the registered tests with LintTests, using the suite's
+// baselines and profiles.
+func (s *Suite) Lint() (LintReport, error) {
+	return s.runner.lint(s.tests, LintOptions{
+		BaselineDir:      s.config.BaselineDir,
+		VariantBaselines: s.config.VariantBaselines,
+		Profiles:         s.config.Profiles,
+	})
+}
+
+func (r *Runner) lint(tests []Test, options LintOptions) (LintReport, error) {
+	pattern := options.NamePattern
+	if pattern == "" {
+		pattern = DefaultLintNamePattern
+	}
+	names, err := regexp.Compile(pattern)
+	if err != nil {
+		return LintReport{}, fmt.Errorf("invalid name pattern: %w", err)
+	}
+
+	report := LintReport{Tests: len(tests), Issues: make([]LintIssue, 0)}
+	add := func(test string, severity LintSeverity, rule, format string, args ...interface{}) {
+		report.Issues = append(report.Issues, LintIssue{Rule: rule, Severity: severity, Test: test, Message: fmt.Sprintf(format, args...)})
+		if severity == LintError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+
+	seen := make(map[string]int)
+	files := make(map[string]string)
+	for _, test := range tests {
+		if err := test.Validate(); err != nil {
+			add(test.Name, LintError, LintInvalid, "%v", err)
+		}
+		if seen[test.Name]++; seen[test.Name] > 1 {
+			// The rest was reported for the first test of that name
+			if seen[test.Name] == 2 {
+				add(test.Name, LintError, LintDuplicateName, "more than one test has this name; they overwrite each other's baseline")
+			}
+			continue
+		}
+		file := sanitizeFilename(test.Name)
+		if other, ok := files[file]; ok && other != test.Name {
+			add(test.Name, LintError, LintFilenameCollision, "saves its captures as %s.png, like %q; rename one of them", file, other)
+		}
+		files[file] = test.Name
+
+		if test.Name != "" && !names.MatchString(test.Name) {
+			add(test.Name, LintWarning, LintNameConvention, "name doesn't match %s", pattern)
+		}
+		if strings.TrimSpace(test.Description) == "" {
+			add(test.Name, LintWarning, LintNoDescription, "no description says what the test covers")
+		}
+		if len(test.Tags) == 0 {
+			add(test.Name, LintWarning, LintNoTags, "no tags; profiles, -tag and report filters can't select it")
+		}
+
+		profiles := make([]Profile, 0)
+		sized := test.Size != nil
+		for _, profile := range options.Profiles {
+			if len(profile.Select([]Test{test})) == 1 {
+				profiles = append(profiles, profile)
+				sized = sized || profile.Size != nil
+			}
+		}
+		if !sized {
+			add(test.Name, LintWarning, LintNoSizeVariant, "renders only at the default window size; set a Size or select it in a profile with one")
+		}
+		if r.BaselineDir != "" {
+			if missing := r.missingBaselines(test, profiles); len(missing) > 0 {
+				add(test.Name, LintWarning, LintMissingBaseline, "no baseline %s; run with -update-snapshots to create it", strings.Join(missing, ", "))
+			}
+		}
+	}
+
+	if !options.SkipSetup {
+		for _, test := range tests {
+			if test.Setup != nil && r.setupSetsTheme(test) {
+				add(test.Name, LintWarning, LintHardcodedTheme, "Setup sets the app theme; use Test.Theme (WithTheme) so profiles, variant baselines and reports know it")
+			}
+		}
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Severity != b.Severity {
+			return a.Severity == LintError
+		}
+		return a.Test < b.Test
+	})
+	return report, nil
+}
+
+// missingBaselines returns the names of the baselines of test, or of its
+// variants in profiles, that don't exist.
+func (r *Runner) missingBaselines(test Test, profiles []Profile) []string {
+	variants := []Test{test}
+	if len(profiles) > 0 {
+		variants = variants[:0]
+		for _, profile := range profiles {
+			variants = append(variants, profile.Apply(test))
+		}
+	}
+	missing := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, variant := range variants {
+		path := r.BaselinePath(variant)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			missing = append(missing, r.BaselineName(variant)+".png")
+		}
+	}
+	return missing
+}
+
+// lintTheme marks the app theme while a Setup runs, so a Setup replacing it
+// can be told apart.
+type lintTheme struct {
+	fyne.Theme
+}
+
+// setupSetsTheme calls the Setup of test and reports whether it changed the
+// app theme. Its output is discarded and a panic counts as no change.
+func (r *Runner) setupSetsTheme(test Test) bool {
+	app := r.ensureApp()
+	if app == nil {
+		return false
+	}
+	settings := app.Settings()
+	previous := settings.Theme()
+	marker := &lintTheme{Theme: theme.DefaultTheme()}
+	settings.SetTheme(marker)
+	defer settings.SetTheme(previous)
+
+	changed := false
+	captureOutput(func() {
+		defer func() { recover() }()
+		test.Setup()
+		current, ok := settings.Theme().(*lintTheme)
+		changed = !ok || current != marker
+	})
+	return changed
+}
+
+// lintCLI prints the lint report of the suite in format, text or json, and
+// reports whether it passes.
+func (s *Suite) lintCLI(format string, strict bool) bool {
+	report, err := s.Lint()
+	if err != nil {
+		s.printf("❌ %v\n", err)
+		return false
+	}
+	var buf bytes.Buffer
+	switch format {
+	case "text":
+		report.WriteText(&buf)
+	case "json":
+		report.WriteJSON(&buf)
+	default:
+		s.printf("❌ Unknown lint format '%s' (use text or json)\n", format)
+		return false
+	}
+	s.runner.flush(&buf)
+	return !report.Failed(strict)
+}
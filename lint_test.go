@@ -0,0 +1,215 @@
+package fynetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// lintRules returns the rules reported for each test, sorted.
+func lintRules(report LintReport) map[string][]string {
+	rules := make(map[string][]string)
+	for _, issue := range report.Issues {
+		rules[issue.Test] = append(rules[issue.Test], issue.Rule)
+	}
+	for _, list := range rules {
+		sort.Strings(list)
+	}
+	return rules
+}
+
+// writeBaseline creates an empty baseline file for test in dir.
+func writeBaseline(t *testing.T, dir string, test Test) {
+	t.Helper()
+	r := NewRunner()
+	r.BaselineDir = dir
+	if err := os.WriteFile(r.BaselinePath(test), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLintFlagsSmells(t *testing.T) {
+	dir := t.TempDir()
+	clean := NewTest("login_form").
+		WithDescription("The login form").
+		WithTags("forms").
+		WithSize(200, 100).
+		WithSetup(func() fyne.CanvasObject { return widget.NewLabel("Login") }).
+		MustBuild()
+	writeBaseline(t, dir, clean)
+
+	smelly := NewTest("Settings Dark").
+		WithSetup(func() fyne.CanvasObject {
+			fyne.CurrentApp().Settings().SetTheme(theme.DarkTheme())
+			return widget.NewLabel("Settings")
+		}).
+		MustBuild()
+
+	report, err := LintTests([]Test{clean, smelly}, LintOptions{BaselineDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := lintRules(report)
+	if got := rules["login_form"]; len(got) != 0 {
+		t.Errorf("clean test reported %v", got)
+	}
+	want := []string{LintHardcodedTheme, LintMissingBaseline, LintNoDescription, LintNameConvention, LintNoSizeVariant, LintNoTags}
+	sort.Strings(want)
+	if got := rules["Settings Dark"]; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("smelly test reported %v, want %v", got, want)
+	}
+	if report.Tests != 2 || report.Errors != 0 || report.Warnings != len(want) {
+		t.Errorf("report counts %d tests, %d errors, %d warnings; want 2, 0, %d", report.Tests, report.Errors, report.Warnings, len(want))
+	}
+	if report.Failed(false) || !report.Failed(true) {
+		t.Errorf("warnings only: Failed(false) = %v, Failed(true) = %v; want false, true", report.Failed(false), report.Failed(true))
+	}
+}
+
+func TestLintSkipSetup(t *testing.T) {
+	calls := 0
+	test := NewTest("themed").
+		WithDescription("Sets its own theme").
+		WithTags("theme").
+		WithSize(100, 50).
+		WithSetup(func() fyne.CanvasObject {
+			calls++
+			fyne.CurrentApp().Settings().SetTheme(theme.DarkTheme())
+			return widget.NewLabel("Themed")
+		}).
+		MustBuild()
+
+	report, err := LintTests([]Test{test}, LintOptions{SkipSetup: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 || len(report.Issues) != 0 {
+		t.Errorf("Setup ran %d times and %d issues were reported, want none: %+v", calls, len(report.Issues), report.Issues)
+	}
+}
+
+func TestLintClashingNames(t *testing.T) {
+	build := func(name string) Test {
+		return NewTest(name).
+			WithDescription("Clashes").
+			WithTags("clash").
+			WithSize(100, 50).
+			WithSetup(func() fyne.CanvasObject { return widget.NewLabel(name) }).
+			MustBuild()
+	}
+	tests := []Test{build("dialog"), build("dialog"), build("dialog"), build("menu open"), build("menu_open"), {Name: "unset", Description: "No Setup", Tags: []string{"clash"}, Size: &fyne.Size{Width: 100, Height: 50}}}
+
+	report, err := LintTests(tests, LintOptions{NamePattern: `.`, SkipSetup: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := lintRules(report)
+	if got := rules["dialog"]; strings.Join(got, ",") != LintDuplicateName {
+		t.Errorf("tests named dialog reported %v, want one %s", got, LintDuplicateName)
+	}
+	if got := rules["menu_open"]; strings.Join(got, ",") != LintFilenameCollision {
+		t.Errorf("menu_open reported %v, want %s", got, LintFilenameCollision)
+	}
+	if got := rules["unset"]; strings.Join(got, ",") != LintInvalid {
+		t.Errorf("test without Setup reported %v, want %s", got, LintInvalid)
+	}
+	if report.Errors != 3 || !report.Failed(false) {
+		t.Errorf("clashing names don't fail the report: %+v", report)
+	}
+	if report.Issues[0].Severity != LintError {
+		t.Errorf("errors aren't listed first: %+v", report.Issues)
+	}
+}
+
+func TestLintInvalidPattern(t *testing.T) {
+	if _, err := LintTests(nil, LintOptions{NamePattern: "("}); err == nil {
+		t.Error("an invalid name pattern isn't an error")
+	}
+}
+
+func TestLintProfiles(t *testing.T) {
+	dir := t.TempDir()
+	mobile := fyne.NewSize(360, 640)
+	profiles := []Profile{
+		{Name: "desktop", Tags: []string{"home"}},
+		{Name: "mobile", Tags: []string{"home"}, Size: &mobile},
+	}
+	test := NewTest("home").
+		WithDescription("The home screen").
+		WithTags("home").
+		WithSetup(func() fyne.CanvasObject { return widget.NewLabel("Home") }).
+		MustBuild()
+
+	r := NewRunner()
+	r.BaselineDir = dir
+	r.VariantBaselines = true
+	desktop := r.BaselineName(profiles[0].Apply(test)) + ".png"
+	missing := r.BaselineName(profiles[1].Apply(test)) + ".png"
+	if err := os.WriteFile(filepath.Join(dir, desktop), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := LintTests([]Test{test}, LintOptions{
+		BaselineDir:      dir,
+		VariantBaselines: true,
+		Profiles:         profiles,
+		SkipSetup:        true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := lintRules(report)["home"]; strings.Join(got, ",") != LintMissingBaseline {
+		t.Fatalf("home reported %v, want only %s", got, LintMissingBaseline)
+	}
+	if message := report.Issues[0].Message; !strings.Contains(message, missing) || strings.Contains(message, desktop) {
+		t.Errorf("missing baseline message %q should name only %s", message, missing)
+	}
+	if _, err := os.Stat(filepath.Join(dir, missing)); !os.IsNotExist(err) {
+		t.Errorf("lint created the missing baseline %s", missing)
+	}
+}
+
+func TestLintCLIJSON(t *testing.T) {
+	s := NewSuiteWithConfig(SuiteConfig{BaselineDir: t.TempDir()})
+	var out bytes.Buffer
+	s.runner.Output = &out
+	s.Add(NewTest("Untidy").
+		WithSetup(func() fyne.CanvasObject { return widget.NewLabel("Untidy") }).
+		MustBuild())
+
+	if !s.lintCLI("json", false) {
+		t.Error("warnings fail the lint without -lint-strict")
+	}
+	var report LintReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("lint output isn't JSON: %v\n%s", err, out.String())
+	}
+	if report.Tests != 1 || report.Warnings == 0 || len(report.Issues) != report.Warnings {
+		t.Errorf("decoded report %+v", report)
+	}
+	for _, issue := range report.Issues {
+		if issue.Test != "Untidy" || issue.Severity != LintWarning || issue.Rule == "" || issue.Message == "" {
+			t.Errorf("decoded issue %+v", issue)
+		}
+	}
+
+	out.Reset()
+	if s.lintCLI("json", true) {
+		t.Error("warnings pass the lint with -lint-strict")
+	}
+	out.Reset()
+	if s.lintCLI("yaml", false) || !strings.Contains(out.String(), "Unknown lint format") {
+		t.Errorf("an unknown format isn't rejected: %q", out.String())
+	}
+}
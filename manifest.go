@@ -0,0 +1,244 @@
+package fynetest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ManifestName is the name of the manifest file kept in a baseline directory.
+const ManifestName = "snapshots.manifest.json"
+
+var (
+	// ErrBaselineModified is returned for baselines whose content no longer
+	// matches the manifest, e.g. edited by hand or corrupted
+	ErrBaselineModified = errors.New("baseline does not match the manifest")
+
+	// ErrBaselineMissing is returned for baselines listed in the manifest
+	// that no longer exist
+	ErrBaselineMissing = errors.New("baseline listed in the manifest is missing")
+)
+
+// ManifestEntry records one baseline file as it was written by vfyne.
+type ManifestEntry struct {
+	SHA256  string    `json:"sha256"`
+	Size    int64     `json:"size"`
+	Test    string    `json:"test"`
+	Tags    []string  `json:"tags,omitempty"`
+	Updated time.Time `json:"updated"`
+
+	// Approvals is the sign-off trail of a baseline applied from staging
+	Approvals []Approval `json:"approvals,omitempty"`
+}
+
+// Manifest lists the baselines of a directory by file name, so that files
+// changed outside of vfyne can be detected.
+type Manifest struct {
+	Baselines map[string]ManifestEntry `json:"baselines"`
+}
+
+// manifestMu serialises read-modify-write cycles of manifest files.
+var manifestMu sync.Mutex
+
+// LoadManifest reads the manifest of a baseline directory. A directory
+// without a manifest yields an empty one.
+func LoadManifest(dir string) (*Manifest, error) {
+	manifest := &Manifest{Baselines: make(map[string]ManifestEntry)}
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest in %s: %w", dir, err)
+	}
+	if manifest.Baselines == nil {
+		manifest.Baselines = make(map[string]ManifestEntry)
+	}
+	return manifest, nil
+}
+
+// Save writes the manifest into the baseline directory.
+func (m *Manifest) Save(dir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestName), append(data, '\n'), 0644)
+}
+
+// Check verifies the baseline file named name in dir against its entry. It
+// returns nil for files the manifest doesn't list, such as baselines written
+// before the manifest existed.
+func (m *Manifest) Check(dir, name string) error {
+	entry, ok := m.Baselines[name]
+	if !ok {
+		return nil
+	}
+	sum, size, err := fileChecksum(filepath.Join(dir, name))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", name, ErrBaselineMissing)
+	}
+	if err != nil {
+		return err
+	}
+	if sum != entry.SHA256 || size != entry.Size {
+		return fmt.Errorf("%s: %w (edited by hand or corrupted; run -update-snapshots to accept it)", name, ErrBaselineModified)
+	}
+	return nil
+}
+
+// Verify checks every baseline listed in the manifest and returns the
+// problems found, keyed by file name.
+func (m *Manifest) Verify(dir string) map[string]error {
+	problems := make(map[string]error)
+	for name := range m.Baselines {
+		if err := m.Check(dir, name); err != nil {
+			problems[name] = err
+		}
+	}
+	return problems
+}
+
+// VerifyManifest loads the manifest of a baseline directory and verifies
+// every baseline it lists.
+func VerifyManifest(dir string) (map[string]error, error) {
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Verify(dir), nil
+}
+
+// CheckBaseline verifies a single baseline against the manifest of its
+// directory.
+func CheckBaseline(path string) error {
+	manifest, err := LoadManifest(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	return manifest.Check(filepath.Dir(path), filepath.Base(path))
+}
+
+// RecordBaseline adds or refreshes the manifest entry of the baseline at
+// path, created by the test named testName with the given tags.
+func RecordBaseline(path, testName string, tags ...string) error {
+	sum, size, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	dir := filepath.Dir(path)
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		return err
+	}
+	manifest.Baselines[filepath.Base(path)] = ManifestEntry{
+		SHA256:  sum,
+		Size:    size,
+		Test:    testName,
+		Tags:    tags,
+		Updated: time.Now().UTC(),
+	}
+	return manifest.Save(dir)
+}
+
+// ForgetBaselines removes the manifest entries of deleted baselines. All
+// paths must be in the same directory.
+func ForgetBaselines(paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	dir := filepath.Dir(paths[0])
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		delete(manifest.Baselines, filepath.Base(path))
+	}
+	return manifest.Save(dir)
+}
+
+func fileChecksum(path string) (string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// manifestCache holds the verification of a baseline directory's manifest
+// for the duration of a run.
+type manifestCache struct {
+	dir      string
+	problems map[string]error
+	err      error
+}
+
+// baselineProblem returns the manifest problem of the baseline at path, if
+// any. The manifest is verified once per run, when the first baseline is
+// compared.
+func (r *Runner) baselineProblem(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.manifest == nil || r.manifest.dir != r.BaselineDir {
+		r.manifest = &manifestCache{dir: r.BaselineDir}
+		r.manifest.problems, r.manifest.err = VerifyManifest(r.BaselineDir)
+	}
+	if r.manifest.err != nil {
+		return r.manifest.err
+	}
+	return r.manifest.problems[filepath.Base(path)]
+}
+
+// recordBaseline adds the baseline of test at path to the manifest and
+// clears any problem verified for it earlier in the run.
+func (r *Runner) recordBaseline(path string, test Test) error {
+	if err := RecordBaseline(path, test.Name, test.Tags...); err != nil {
+		return err
+	}
+	r.forgetProblem(path)
+	return nil
+}
+
+// forgetProblem clears the problem verified for the baseline at path.
+func (r *Runner) forgetProblem(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.manifest != nil && r.manifest.problems != nil {
+		delete(r.manifest.problems, filepath.Base(path))
+	}
+}
+
+// resetManifest makes the next comparison verify the manifest again, so
+// files changed between runs are detected.
+func (r *Runner) resetManifest() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manifest = nil
+}
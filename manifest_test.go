@@ -0,0 +1,104 @@
+package fynetest
+
+import (
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// snapshotRunner returns a runner with temporary output and baseline
+// directories that doesn't wait for rendering.
+func snapshotRunner(t *testing.T) *Runner {
+	t.Helper()
+	r := NewRunner()
+	r.OutputDir = t.TempDir()
+	r.BaselineDir = t.TempDir()
+	r.DefaultWaitDuration = 0
+	t.Cleanup(r.Cleanup)
+	return r
+}
+
+// labelTest returns a small test showing text, tagged with tags.
+func labelTest(name string, text *string, tags ...string) Test {
+	return NewTest(name).
+		WithSize(120, 40).
+		WithTags(tags...).
+		WithSetup(func() fyne.CanvasObject { return widget.NewLabel(*text) }).
+		MustBuild()
+}
+
+func TestManifestDetectsChangedBaselines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Login.png")
+	if err := SaveBaseline(path, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	if err := RecordBaseline(path, "Login", "critical"); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := manifest.Baselines["Login.png"]
+	info, _ := os.Stat(path)
+	if entry.Test != "Login" || len(entry.Tags) != 1 || entry.Size != info.Size() || len(entry.SHA256) != 64 || entry.Updated.IsZero() {
+		t.Errorf("entry = %+v, want the test, tags, size, checksum and update time", entry)
+	}
+	if problems, err := VerifyManifest(dir); err != nil || len(problems) != 0 {
+		t.Fatalf("VerifyManifest() = %v, %v; want no problems", problems, err)
+	}
+
+	if err := SaveBaseline(path, image.NewRGBA(image.Rect(0, 0, 5, 5))); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckBaseline(path); !errors.Is(err, ErrBaselineModified) {
+		t.Errorf("edited baseline: CheckBaseline() = %v, want ErrBaselineModified", err)
+	}
+	os.Remove(path)
+	if err := CheckBaseline(path); !errors.Is(err, ErrBaselineMissing) {
+		t.Errorf("deleted baseline: CheckBaseline() = %v, want ErrBaselineMissing", err)
+	}
+	if err := manifest.Check(dir, "Unlisted.png"); err != nil {
+		t.Errorf("unlisted baseline: Check() = %v, want nil", err)
+	}
+}
+
+func TestRunnerRejectsEditedBaseline(t *testing.T) {
+	r := snapshotRunner(t)
+	text := "Welcome"
+	test := labelTest("Welcome", &text)
+
+	r.Update = SnapshotUpdate{All: true}
+	if result := r.RunTest(test); !result.Success {
+		t.Fatalf("creating the baseline failed: %v", result.Error)
+	}
+	r.Update = SnapshotUpdate{}
+
+	// Replacing the baseline by hand is caught even though it now matches
+	text = "Edited"
+	edited := r.RunTest(labelTest("Edited", &text))
+	data, err := os.ReadFile(edited.ScreenshotPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(r.BaselinePath(test), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	r.resetManifest()
+	if result := r.RunTest(test); !errors.Is(result.Error, ErrBaselineModified) {
+		t.Errorf("error = %v, want ErrBaselineModified", result.Error)
+	}
+
+	// Accepting it with an update records it again
+	r.Update = SnapshotUpdate{All: true}
+	if result := r.RunTest(test); !result.Success {
+		t.Errorf("updating the edited baseline failed: %v", result.Error)
+	}
+}
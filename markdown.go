@@ -0,0 +1,137 @@
+package fynetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MarkdownReportName is the file name of the Markdown report in a run
+// directory.
+const MarkdownReportName = "summary.md"
+
+// maxMarkdownPreviews bounds the images embedded in the Markdown report, so
+// a broad regression doesn't produce a comment too long to post.
+const maxMarkdownPreviews = 10
+
+// GenerateMarkdownReport writes a Markdown summary of results, meant to be
+// posted as a pull-request comment: pass/fail counts, a table of the failed
+// tests and previews of their diffs. Images are linked relative to the
+// report, or under ImageBaseURL when the run is uploaded as an artifact.
+func (g *ReportGenerator) GenerateMarkdownReport(results []Result, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	markdown := g.markdown(results, filepath.Dir(outputPath))
+	if err := os.WriteFile(outputPath, []byte(markdown), 0644); err != nil {
+		return fmt.Errorf("failed to create Markdown report: %w", err)
+	}
+	return nil
+}
+
+func (g *ReportGenerator) markdown(results []Result, dir string) string {
+	var b strings.Builder
+	summary := g.createSummary(results)
+
+	if summary.Failed == 0 && summary.Skipped > 0 {
+		fmt.Fprintf(&b, "## ✅ %s: %d passed, %d skipped\n\n", g.Title, summary.Passed, summary.Skipped)
+	} else if summary.Failed == 0 {
+		fmt.Fprintf(&b, "## ✅ %s: all %d passed\n\n", g.Title, summary.Total)
+	} else {
+		fmt.Fprintf(&b, "## ❌ %s: %d of %d failed\n\n", g.Title, summary.Failed, summary.Total)
+	}
+	b.WriteString("| ✅ Passed | ❌ Failed | Total | Pass rate | Duration |\n")
+	b.WriteString("|---:|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %.1f%% | %s |\n", summary.Passed, summary.Failed, summary.Total,
+		summary.PassRate, formatDuration(summary.Duration))
+
+	if summary.UnexpectedPasses > 0 {
+		b.WriteString("\n### 👀 Needs baseline review\n\nExpected to fail but passed; the regression may be fixed:\n\n")
+		for _, result := range results {
+			if result.UnexpectedPass {
+				name := result.Test.Name
+				if result.Profile != "" {
+					name += " (" + result.Profile + ")"
+				}
+				fmt.Fprintf(&b, "- %s\n", markdownCell(name))
+			}
+		}
+	}
+
+	failed := make([]Result, 0)
+	for _, result := range results {
+		if !result.Success {
+			failed = append(failed, result)
+		}
+	}
+	if len(failed) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("\n### Failed tests\n\n")
+	b.WriteString("| Test | Error | Images |\n")
+	b.WriteString("|---|---|---|\n")
+	for _, result := range failed {
+		name := result.Test.Name
+		if result.Profile != "" {
+			name += " (" + result.Profile + ")"
+		}
+		links := make([]string, 0, 4)
+		link := func(label, path string) {
+			if path != "" {
+				links = append(links, fmt.Sprintf("[%s](%s)", label, g.imageURL(dir, path)))
+			}
+		}
+		link("screenshot", result.ScreenshotPath)
+		if assets := result.DiffAssets; assets != nil {
+			link("diff", assets.Diff)
+			link("heatmap", assets.Heatmap)
+			link("side by side", assets.Composite)
+		}
+		link("crash dump", result.CrashDump)
+
+		errText := ""
+		if result.Error != nil {
+			errText = result.Error.Error()
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", markdownCell(name), markdownCell(errText), strings.Join(links, " · "))
+	}
+
+	previews := 0
+	for _, result := range failed {
+		if result.DiffAssets == nil || result.DiffAssets.Composite == "" {
+			continue
+		}
+		if previews == 0 {
+			b.WriteString("\n<details>\n<summary>Expected · actual · diff</summary>\n\n")
+		}
+		if previews == maxMarkdownPreviews {
+			fmt.Fprintf(&b, "…and more; see the full report.\n\n")
+			break
+		}
+		previews++
+		fmt.Fprintf(&b, "#### %s\n\n![%s](%s)\n\n", markdownCell(result.Test.Name),
+			markdownCell(result.Test.AltText()), g.imageURL(dir, result.DiffAssets.Composite))
+	}
+	if previews > 0 {
+		b.WriteString("</details>\n")
+	}
+	return b.String()
+}
+
+// imageURL links path from a report in dir: relative to it, or under
+// ImageBaseURL when set.
+func (g *ReportGenerator) imageURL(dir, path string) string {
+	rel := relativePath(dir, path)
+	if g.ImageBaseURL == "" {
+		return strings.ReplaceAll(rel, " ", "%20")
+	}
+	return strings.TrimSuffix(g.ImageBaseURL, "/") + "/" + strings.ReplaceAll(rel, " ", "%20")
+}
+
+// markdownCell escapes text for a single table cell.
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,102 @@
+package fynetest
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"fyne.io/fyne/v2"
+)
+
+// Region is a rectangle in canvas coordinates (device independent units)
+// that is excluded from baseline comparisons.
+type Region struct {
+	X, Y, Width, Height float32
+}
+
+// NewRegion creates a region from its position and size.
+func NewRegion(x, y, width, height float32) Region {
+	return Region{X: x, Y: y, Width: width, Height: height}
+}
+
+// RegionOf returns the region currently covered by obj on its canvas.
+func RegionOf(obj fyne.CanvasObject) Region {
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(obj)
+	size := obj.Size()
+	return Region{X: pos.X, Y: pos.Y, Width: size.Width, Height: size.Height}
+}
+
+// IgnoreRects converts regions and the current bounds of objects into pixel
+// rectangles of img, a capture of canvas c. Captures are usually larger than
+// the canvas on high DPI scales, so coordinates are scaled accordingly.
+func IgnoreRects(c fyne.Canvas, img image.Image, regions []Region, objects []fyne.CanvasObject) []image.Rectangle {
+	if len(regions) == 0 && len(objects) == 0 {
+		return nil
+	}
+	
+	all := append([]Region(nil), regions...)
+	for _, obj := range objects {
+		all = append(all, RegionOf(obj))
+	}
+	
+	rects := make([]image.Rectangle, 0, len(all))
+	for _, region := range all {
+		if rect := PixelRect(c, img, region); !rect.Empty() {
+			rects = append(rects, rect)
+		}
+	}
+	return rects
+}
+
+// PixelRect converts a region in canvas coordinates into the pixel rectangle
+// of img, a capture of canvas c, clipped to the image bounds.
+func PixelRect(c fyne.Canvas, img image.Image, region Region) image.Rectangle {
+	bounds := img.Bounds()
+	scaleX, scaleY := float64(1), float64(1)
+	if size := c.Size(); size.Width > 0 && size.Height > 0 {
+		scaleX = float64(bounds.Dx()) / float64(size.Width)
+		scaleY = float64(bounds.Dy()) / float64(size.Height)
+	}
+	
+	return image.Rect(
+		int(math.Floor(float64(region.X)*scaleX)),
+		int(math.Floor(float64(region.Y)*scaleY)),
+		int(math.Ceil(float64(region.X+region.Width)*scaleX)),
+		int(math.Ceil(float64(region.Y+region.Height)*scaleY)),
+	).Add(bounds.Min).Intersect(bounds)
+}
+
+// MaskRegions returns a copy of img with every rectangle painted black, so
+// dynamic content such as clocks or progress bars doesn't affect diffs.
+// The image is returned unchanged when there is nothing to mask.
+func MaskRegions(img image.Image, rects []image.Rectangle) image.Image {
+	if len(rects) == 0 {
+		return img
+	}
+	
+	bounds := img.Bounds()
+	masked := newPooledRGBA(bounds)
+	draw.Draw(masked, bounds, img, bounds.Min, draw.Src)
+	
+	black := image.NewUniform(color.Black)
+	for _, rect := range rects {
+		draw.Draw(masked, rect.Intersect(bounds), black, image.Point{}, draw.Src)
+	}
+	return masked
+}
+
+// maskedPair masks the same rectangles in both images, translating them to
+// the bounds of each.
+func maskedPair(expected, actual image.Image, rects []image.Rectangle) (image.Image, image.Image) {
+	if len(rects) == 0 {
+		return expected, actual
+	}
+	
+	offset := expected.Bounds().Min.Sub(actual.Bounds().Min)
+	shifted := make([]image.Rectangle, len(rects))
+	for i, rect := range rects {
+		shifted[i] = rect.Add(offset)
+	}
+	return MaskRegions(expected, shifted), MaskRegions(actual, rects)
+}
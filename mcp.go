@@ -0,0 +1,627 @@
+package fynetest
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MCPProtocolVersion is the Model Context Protocol revision ServeMCP speaks.
+const MCPProtocolVersion = "2024-11-05"
+
+// JSON-RPC error codes used by ServeMCP.
+const (
+	mcpParseError     = -32700
+	mcpInvalidRequest = -32600
+	mcpMethodNotFound = -32601
+	mcpInvalidParams  = -32602
+)
+
+// mcpRequest is a JSON-RPC request or, without an ID, a notification.
+type mcpRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type mcpResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *mcpError       `json:"error,omitempty"`
+}
+
+type mcpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool describes a tool in the tools/list response.
+type mcpTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// mcpContent is a text or image block of a tool result.
+type mcpContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+type mcpToolResult struct {
+	Content []mcpContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+// mcpArgs are the arguments of every tool; each uses some of them.
+type mcpArgs struct {
+	Name     string `json:"name"`
+	Tag      string `json:"tag"`
+	Pattern  string `json:"pattern"`
+	Image    string `json:"image"`
+	Semantic bool   `json:"semantic"`
+
+	Step *ScenarioStep `json:"step"`
+}
+
+// mcpNameSchema is the input schema of tools taking a test name.
+func mcpNameSchema(extra map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{
+		"name": map[string]interface{}{"type": "string", "description": "Test name, as returned by list_tests"},
+	}
+	for key, value := range extra {
+		properties[key] = value
+	}
+	return map[string]interface{}{"type": "object", "properties": properties, "required": []string{"name"}}
+}
+
+var mcpTools = []mcpTool{
+	{
+		Name:        "list_tests",
+		Description: "List the visual tests of the suite with their description and tags, optionally filtered by tag or name pattern.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"tag":     map[string]interface{}{"type": "string", "description": "Only tests with this tag"},
+				"pattern": map[string]interface{}{"type": "string", "description": "Only tests whose name contains this"},
+			},
+		},
+	},
+	{
+		Name:        "run_test",
+		Description: "Render a test, save its screenshot and compare it against its baseline. Returns the result as in the JSON report.",
+		InputSchema: mcpNameSchema(nil),
+	},
+	{
+		Name:        "get_screenshot",
+		Description: "Return an image of the last run of a test: its screenshot, its baseline or, after a mismatch, the diff, heatmap or side-by-side composite. The test is run first if it hasn't been.",
+		InputSchema: mcpNameSchema(map[string]interface{}{
+			"image": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"screenshot", "baseline", "diff", "heatmap", "composite"},
+				"description": "Which image to return (default: screenshot)",
+			},
+		}),
+	},
+	{
+		Name:        "get_widget_tree",
+		Description: "Return the widget tree captured with the last run of a test as JSON: type, name, text, properties and geometry of every widget. With semantic, return the widgets' roles, labels, states and bounds and a summary of the screen instead.",
+		InputSchema: mcpNameSchema(map[string]interface{}{
+			"semantic": map[string]interface{}{"type": "boolean", "description": "Describe the screen for agents rather than return the raw tree"},
+		}),
+	},
+	{
+		Name:        "explore_start",
+		Description: "Open the window of a test for exploration, closing any exploration in progress, and return its widgets with their roles, labels and bounds. Then act on it with explore_step and finish with explore_stop.",
+		InputSchema: mcpNameSchema(nil),
+	},
+	{
+		Name: "explore_step",
+		Description: "Interact with the explored window and return the screen afterwards. Widgets are picked by selector, a type with optional conditions such as Button[text=\"Save\"] or Entry[name=email], which must match one visible widget. " +
+			"Actions: tap (selector, or x and y), double_tap, secondary_tap, type (text into selector, or into the focused widget), key (key such as Return, Tab or Down, on the focused widget), focus_next, scroll and drag (dx, dy over selector or at x, y), resize (width, height) and capture (name), which returns a screenshot.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"step": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"action": map[string]interface{}{
+							"type": "string",
+							"enum": []string{ActionTap, ActionDoubleTap, ActionSecondaryTap, ActionType, ActionKey, ActionFocusNext, ActionScroll, ActionDrag, ActionResize, ActionCapture},
+						},
+						"selector": map[string]interface{}{"type": "string"},
+						"text":     map[string]interface{}{"type": "string"},
+						"key":      map[string]interface{}{"type": "string"},
+						"name":     map[string]interface{}{"type": "string", "description": "Name of a capture"},
+						"x":        map[string]interface{}{"type": "number"},
+						"y":        map[string]interface{}{"type": "number"},
+						"dx":       map[string]interface{}{"type": "number"},
+						"dy":       map[string]interface{}{"type": "number"},
+						"width":    map[string]interface{}{"type": "number"},
+						"height":   map[string]interface{}{"type": "number"},
+					},
+					"required": []string{"action"},
+				},
+			},
+			"required": []string{"step"},
+		},
+	},
+	{
+		Name:        "explore_stop",
+		Description: "Close the explored window and save the steps taken as a scenario file, which `-replay` plays back to reproduce the captures.",
+		InputSchema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+	},
+	{
+		Name:        "compare_to_baseline",
+		Description: "Run a test and compare its capture against its baseline. Returns whether it matches, the share of changed pixels, the widgets that changed and, on a mismatch, the side-by-side composite of baseline, capture and diff.",
+		InputSchema: mcpNameSchema(nil),
+	},
+}
+
+// mcpServer holds the state of one ServeMCP session.
+type mcpServer struct {
+	suite  *Suite
+	runDir string
+
+	// last holds the latest result of each test run in the session
+	mu   sync.Mutex
+	last map[string]Result
+
+	// exploration is the window opened by explore_start, if any
+	exploration *ExploreSession
+}
+
+// ServeMCP serves the suite to AI agents over the Model Context Protocol,
+// reading JSON-RPC messages from in, one per line, and answering on out until
+// in ends, as with the stdio transport. Agents get the tools list_tests,
+// run_test, get_screenshot, get_widget_tree and compare_to_baseline, and
+// explore a test's window with explore_start, explore_step and explore_stop
+// (see ExploreSession). Captures
+// go to a timestamped run directory under the output directory, as with Run,
+// and runner output defaults to stderr, keeping out for the protocol. When
+// out is os.Stdout, stdout is pointed at stderr for the session, so what the
+// tested apps print can't corrupt the protocol either. The session counts
+// as a run for the BeforeSuite and AfterSuite hooks.
+func (s *Suite) ServeMCP(in io.Reader, out io.Writer) error {
+	if s.runner.Output == nil {
+		s.runner.Output = os.Stderr
+	}
+	if f, ok := out.(*os.File); ok && f == os.Stdout {
+		protocol, restore, err := divertStdout()
+		if err != nil {
+			return fmt.Errorf("failed to divert stdout: %w", err)
+		}
+		defer restore()
+		out = protocol
+	}
+	if err := s.beforeSuite(); err != nil {
+		return err
+	}
+	startTime := time.Now()
+	server := &mcpServer{
+		suite:  s,
+		runDir: filepath.Join(s.runner.OutputDir, startTime.Format("20060102-150405")),
+		last:   make(map[string]Result),
+	}
+	defer func() { s.afterSuite(server.suiteResult(startTime)) }()
+	defer server.stopExploring()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	encoder := json.NewEncoder(out)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if response := server.handle([]byte(line)); response != nil {
+			if err := encoder.Encode(response); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// suiteResult returns the latest result of every test run in the session.
+func (m *mcpServer) suiteResult(startTime time.Time) SuiteResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	results := make([]Result, 0, len(m.last))
+	for _, test := range m.suite.tests {
+		if result, ok := m.last[test.Name]; ok {
+			results = append(results, result)
+		}
+	}
+	return SuiteResult{
+		Name:      m.suite.config.Name,
+		Results:   results,
+		StartTime: startTime,
+		EndTime:   time.Now(),
+		OutputDir: m.runDir,
+	}
+}
+
+// handle answers one message, or returns nil for notifications.
+func (m *mcpServer) handle(message []byte) *mcpResponse {
+	var request mcpRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return mcpFailure(json.RawMessage("null"), mcpParseError, err.Error())
+	}
+	if request.ID == nil {
+		// Notifications, such as notifications/initialized, need no answer
+		return nil
+	}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		return mcpFailure(request.ID, mcpInvalidRequest, "invalid JSON-RPC 2.0 request")
+	}
+
+	switch request.Method {
+	case "initialize":
+		return mcpSuccess(request.ID, map[string]interface{}{
+			"protocolVersion": MCPProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": "vfyne", "version": orUnknown(moduleVersion())},
+			"instructions":    fmt.Sprintf("Visual tests of a Fyne UI (suite %q). Run tests, inspect their screenshots and widget trees, and compare them against approved baselines.", m.suite.config.Name),
+		})
+	case "ping":
+		return mcpSuccess(request.ID, map[string]interface{}{})
+	case "tools/list":
+		return mcpSuccess(request.ID, map[string]interface{}{"tools": mcpTools})
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return mcpFailure(request.ID, mcpInvalidParams, err.Error())
+		}
+		var args mcpArgs
+		if len(params.Arguments) > 0 {
+			if err := json.Unmarshal(params.Arguments, &args); err != nil {
+				return mcpFailure(request.ID, mcpInvalidParams, err.Error())
+			}
+		}
+		result, ok := m.call(params.Name, args)
+		if !ok {
+			return mcpFailure(request.ID, mcpInvalidParams, fmt.Sprintf("unknown tool %q", params.Name))
+		}
+		return mcpSuccess(request.ID, result)
+	}
+	return mcpFailure(request.ID, mcpMethodNotFound, fmt.Sprintf("method %q not found", request.Method))
+}
+
+// moduleVersion returns the version of vfyne linked into the binary, or ""
+// when it cannot be determined.
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	path := reflect.TypeOf(Suite{}).PkgPath()
+	if info.Main.Path == path {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+func mcpSuccess(id json.RawMessage, result interface{}) *mcpResponse {
+	return &mcpResponse{JSONRPC: "2.0", ID: id, Result: result}
+}
+
+func mcpFailure(id json.RawMessage, code int, message string) *mcpResponse {
+	return &mcpResponse{JSONRPC: "2.0", ID: id, Error: &mcpError{Code: code, Message: message}}
+}
+
+// call runs the named tool. Failures of the tool itself, such as an unknown
+// test, are reported in the result for the agent to read; ok is false only
+// for unknown tools.
+func (m *mcpServer) call(tool string, args mcpArgs) (result mcpToolResult, ok bool) {
+	var content []mcpContent
+	var err error
+	switch tool {
+	case "list_tests":
+		content, err = m.listTests(args)
+	case "run_test":
+		content, err = m.runTest(args)
+	case "get_screenshot":
+		content, err = m.screenshot(args)
+	case "get_widget_tree":
+		content, err = m.widgetTree(args)
+	case "compare_to_baseline":
+		content, err = m.compare(args)
+	case "explore_start":
+		content, err = m.exploreStart(args)
+	case "explore_step":
+		content, err = m.exploreStep(args)
+	case "explore_stop":
+		content, err = m.exploreStop()
+	default:
+		return mcpToolResult{}, false
+	}
+	if err != nil {
+		return mcpToolResult{Content: []mcpContent{{Type: "text", Text: err.Error()}}, IsError: true}, true
+	}
+	return mcpToolResult{Content: content}, true
+}
+
+func (m *mcpServer) listTests(args mcpArgs) ([]mcpContent, error) {
+	type listedTest struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description,omitempty"`
+		Tags        []string `json:"tags,omitempty"`
+	}
+	tests := m.suite.tests
+	if args.Tag != "" {
+		tests = m.suite.FilterByTags(args.Tag)
+	}
+	listed := make([]listedTest, 0, len(tests))
+	for _, test := range tests {
+		if args.Pattern != "" && !strings.Contains(test.Name, args.Pattern) {
+			continue
+		}
+		listed = append(listed, listedTest{Name: test.Name, Description: test.Description, Tags: test.Tags})
+	}
+	sort.Slice(listed, func(i, j int) bool { return listed[i].Name < listed[j].Name })
+	return mcpJSON(listed)
+}
+
+// test returns the registered test with the given name.
+func (m *mcpServer) test(name string) (Test, error) {
+	if name == "" {
+		return Test{}, fmt.Errorf("missing test name")
+	}
+	tests := m.suite.filterByExactName(name)
+	if len(tests) == 0 {
+		return Test{}, fmt.Errorf("test %q not found; use list_tests to see the available tests", name)
+	}
+	return tests[0], nil
+}
+
+// run renders the named test into the session's run directory.
+func (m *mcpServer) run(name string) (Result, error) {
+	test, err := m.test(name)
+	if err != nil {
+		return Result{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := m.suite.runner
+	outputDir := r.OutputDir
+	r.OutputDir = m.runDir
+	result := r.RunTest(test)
+	r.OutputDir = outputDir
+
+	m.last[name] = result
+	return result, nil
+}
+
+// lastRun returns the latest result of the named test, running it if it
+// hasn't run in the session.
+func (m *mcpServer) lastRun(name string) (Result, error) {
+	m.mu.Lock()
+	result, ok := m.last[name]
+	m.mu.Unlock()
+	if ok {
+		return result, nil
+	}
+	return m.run(name)
+}
+
+func (m *mcpServer) runTest(args mcpArgs) ([]mcpContent, error) {
+	result, err := m.run(args.Name)
+	if err != nil {
+		return nil, err
+	}
+	// The tree is served by get_widget_tree; it would drown the result
+	jsonResult := newJSONResult(result)
+	jsonResult.WidgetTree = nil
+	return mcpJSON(jsonResult)
+}
+
+func (m *mcpServer) screenshot(args mcpArgs) ([]mcpContent, error) {
+	result, err := m.lastRun(args.Name)
+	if err != nil {
+		return nil, err
+	}
+	path := ""
+	switch args.Image {
+	case "", "screenshot":
+		path = result.ScreenshotPath
+	case "baseline":
+		if m.suite.runner.BaselineDir == "" {
+			return nil, fmt.Errorf("the suite has no baselines")
+		}
+		path = m.suite.runner.BaselinePath(result.Test)
+	case "diff", "heatmap", "composite":
+		if result.Diff == nil || result.Diff.Match {
+			return nil, fmt.Errorf("test %q matches its baseline; there is no %s", args.Name, args.Image)
+		}
+		path = AssetPath(result.ScreenshotPath, args.Image)
+	default:
+		return nil, fmt.Errorf("unknown image %q (use screenshot, baseline, diff, heatmap or composite)", args.Image)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("test %q has no screenshot: %v", args.Name, result.Error)
+	}
+	image, err := mcpImage(path)
+	if err != nil {
+		return nil, err
+	}
+	return []mcpContent{image}, nil
+}
+
+func (m *mcpServer) widgetTree(args mcpArgs) ([]mcpContent, error) {
+	result, err := m.lastRun(args.Name)
+	if err != nil {
+		return nil, err
+	}
+	if result.WidgetTree == nil {
+		return nil, fmt.Errorf("test %q has no widget tree: %v", args.Name, result.Error)
+	}
+	if args.Semantic {
+		screen := NewAIScreen(result.Test, result.Tree())
+		screen.Screenshot = result.ScreenshotPath
+		return mcpJSON(screen)
+	}
+	return mcpJSON(result.Tree())
+}
+
+func (m *mcpServer) compare(args mcpArgs) ([]mcpContent, error) {
+	if m.suite.runner.BaselineDir == "" {
+		return nil, fmt.Errorf("the suite has no baselines")
+	}
+	result, err := m.run(args.Name)
+	if err != nil {
+		return nil, err
+	}
+	if result.Diff == nil {
+		return nil, fmt.Errorf("test %q was not compared: %v", args.Name, result.Error)
+	}
+
+	comparison := struct {
+		Match         bool           `json:"match"`
+		Error         string         `json:"error,omitempty"`
+		Baseline      string         `json:"baseline"`
+		Diff          *JSONDiff      `json:"diff"`
+		WidgetChanges []WidgetChange `json:"widget_changes,omitempty"`
+	}{
+		Match:         result.Diff.Match,
+		Baseline:      m.suite.runner.BaselinePath(result.Test),
+		Diff:          newJSONDiff(result.Diff),
+		WidgetChanges: result.WidgetChanges,
+	}
+	if result.Error != nil {
+		comparison.Error = result.Error.Error()
+	}
+	content, err := mcpJSON(comparison)
+	if err != nil || comparison.Match {
+		return content, err
+	}
+	if image, err := mcpImage(AssetPath(result.ScreenshotPath, "composite")); err == nil {
+		content = append(content, image)
+	}
+	return content, nil
+}
+
+func (m *mcpServer) exploreStart(args mcpArgs) ([]mcpContent, error) {
+	test, err := m.test(args.Name)
+	if err != nil {
+		return nil, err
+	}
+	m.stopExploring()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := m.suite.runner
+	outputDir := r.OutputDir
+	r.OutputDir = m.runDir
+	session, err := r.Explore(test)
+	r.OutputDir = outputDir
+	if err != nil {
+		return nil, err
+	}
+	m.exploration = session
+	return mcpJSON(NewAIScreen(test, session.Tree()))
+}
+
+func (m *mcpServer) exploreStep(args mcpArgs) ([]mcpContent, error) {
+	m.mu.Lock()
+	session := m.exploration
+	m.mu.Unlock()
+	if session == nil {
+		return nil, fmt.Errorf("no exploration in progress; start one with explore_start")
+	}
+	if args.Step == nil {
+		return nil, fmt.Errorf("missing step")
+	}
+
+	capture, err := session.Do(*args.Step)
+	if err != nil {
+		return nil, err
+	}
+	screen := NewAIScreen(session.test, session.Tree())
+	if capture == nil {
+		return []mcpContent{{Type: "text", Text: "Done. " + screen.Summary}}, nil
+	}
+	screen.Screenshot = capture.Path
+	content, err := mcpJSON(screen)
+	if err != nil {
+		return nil, err
+	}
+	image, err := mcpImage(capture.Path)
+	if err != nil {
+		return nil, err
+	}
+	return append(content, image), nil
+}
+
+func (m *mcpServer) exploreStop() ([]mcpContent, error) {
+	m.mu.Lock()
+	session := m.exploration
+	m.mu.Unlock()
+	if session == nil {
+		return nil, fmt.Errorf("no exploration in progress")
+	}
+	path, err := m.stopExploring()
+	if err != nil {
+		return nil, err
+	}
+	scenario, err := json.MarshalIndent(session.Scenario(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []mcpContent{{Type: "text", Text: fmt.Sprintf("Saved the scenario to %s:\n%s", path, scenario)}}, nil
+}
+
+// stopExploring saves the scenario of the exploration in progress, if any,
+// closes its window and returns the scenario's path.
+func (m *mcpServer) stopExploring() (string, error) {
+	m.mu.Lock()
+	session := m.exploration
+	m.exploration = nil
+	m.mu.Unlock()
+	if session == nil {
+		return "", nil
+	}
+	defer session.Close()
+	return session.Save()
+}
+
+// mcpJSON returns v as an indented JSON text block.
+func mcpJSON(v interface{}) ([]mcpContent, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []mcpContent{{Type: "text", Text: string(data)}}, nil
+}
+
+// mcpImage returns the PNG at path as an image block.
+func mcpImage(path string) (mcpContent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return mcpContent{}, err
+	}
+	return mcpContent{Type: "image", Data: base64.StdEncoding.EncodeToString(data), MimeType: "image/png"}, nil
+}
@@ -0,0 +1,140 @@
+package fynetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// OrphanPolicy decides what a CLI run does with orphaned baselines.
+type OrphanPolicy string
+
+const (
+	// OrphansIgnore skips the check
+	OrphansIgnore OrphanPolicy = ""
+
+	// OrphansReport lists orphaned baselines after the run
+	OrphansReport OrphanPolicy = "report"
+
+	// OrphansDelete removes orphaned baselines and their digests
+	OrphansDelete OrphanPolicy = "delete"
+
+	// OrphansFail lists orphaned baselines and fails the run if there are any
+	OrphansFail OrphanPolicy = "fail"
+)
+
+// ParseOrphanPolicy returns the policy for "report", "delete" or "fail".
+func ParseOrphanPolicy(value string) (OrphanPolicy, error) {
+	switch policy := OrphanPolicy(value); policy {
+	case OrphansIgnore, OrphansReport, OrphansDelete, OrphansFail:
+		return policy, nil
+	}
+	return OrphansIgnore, fmt.Errorf("unknown orphan policy %q (use report, delete or fail)", value)
+}
+
+// OrphanedBaselines returns the baseline files in BaselineDir that no
+// registered test produces any more, typically left behind by renamed or
+// deleted tests. Any variant of a registered test, "<test>@...", is kept.
+// It returns nil when no baseline directory is configured.
+func (s *Suite) OrphanedBaselines() ([]string, error) {
+	if s.config.BaselineDir == "" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(s.config.BaselineDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Tests are matched on their base name, so the baselines of variants
+	// this run doesn't render (other themes, sizes or "@gl") stay known
+	known := make(map[string]bool, len(s.tests))
+	for _, test := range s.tests {
+		known[sanitizeFilename(test.Name)] = true
+		// Profiles can rename the tests they apply to
+		for _, profile := range s.config.Profiles {
+			known[sanitizeFilename(profile.Apply(test).Name)] = true
+		}
+	}
+
+	orphans := make([]string, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".png") || knownBaseline(known, strings.TrimSuffix(name, ".png")) {
+			continue
+		}
+		orphans = append(orphans, filepath.Join(s.config.BaselineDir, name))
+	}
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// knownBaseline reports whether the baseline named name, without extension,
+// belongs to a known test: it is the test's name or the name followed by
+// variant suffixes, as in "<test>@dark@375x667".
+func knownBaseline(known map[string]bool, name string) bool {
+	for i := len(name); i >= 0; i = strings.LastIndex(name[:i], VariantSeparator) {
+		if known[name[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// DeleteOrphanedBaselines removes the baselines returned by
+// OrphanedBaselines together with their digest, widget tree and metadata
+// files and their manifest entries, and returns them.
+func (s *Suite) DeleteOrphanedBaselines() ([]string, error) {
+	orphans, err := s.OrphanedBaselines()
+	if err != nil {
+		return nil, err
+	}
+	for i, path := range orphans {
+		if err := os.Remove(path); err != nil {
+			return orphans[:i], err
+		}
+		for _, suffix := range []string{DigestSuffix, TreeSuffix, MetaSuffix, MaskSuffix} {
+			if err := os.Remove(path + suffix); err != nil && !os.IsNotExist(err) {
+				return orphans[:i+1], err
+			}
+		}
+	}
+	return orphans, ForgetBaselines(orphans...)
+}
+
+// checkOrphans applies the configured orphan policy after a CLI run and
+// reports whether the run should fail.
+func (s *Suite) checkOrphans() bool {
+	var orphans []string
+	var err error
+	switch s.config.Orphans {
+	case OrphansIgnore:
+		return false
+	case OrphansDelete:
+		orphans, err = s.DeleteOrphanedBaselines()
+	default:
+		orphans, err = s.OrphanedBaselines()
+	}
+	if err != nil {
+		s.printf("❌ Failed to check orphaned baselines: %v\n", err)
+		return true
+	}
+	if len(orphans) == 0 {
+		return false
+	}
+
+	if s.config.Orphans == OrphansDelete {
+		s.printf("\n🧹 Deleted %d orphaned baselines:\n", len(orphans))
+	} else {
+		s.printf("\n🧹 %d orphaned baselines (no registered test produces them):\n", len(orphans))
+	}
+	for _, path := range orphans {
+		s.printf("- %s\n", path)
+	}
+	return s.config.Orphans == OrphansFail
+}
@@ -0,0 +1,53 @@
+package fynetest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// output returns the writer for log output, defaulting to stdout, which
+// stays the terminal while a test's output is captured.
+func (r *Runner) output() io.Writer {
+	if r.Output == nil {
+		return stdout()
+	}
+	return r.Output
+}
+
+// printf writes formatted output atomically.
+func (r *Runner) printf(format string, args ...interface{}) {
+	r.outMu.Lock()
+	defer r.outMu.Unlock()
+	
+	fmt.Fprintf(r.output(), format, args...)
+}
+
+// println writes a line atomically.
+func (r *Runner) println(args ...interface{}) {
+	r.outMu.Lock()
+	defer r.outMu.Unlock()
+	
+	fmt.Fprintln(r.output(), args...)
+}
+
+// flush writes buffered output in one piece and resets the buffer.
+func (r *Runner) flush(buf *bytes.Buffer) {
+	if buf.Len() == 0 {
+		return
+	}
+	
+	r.outMu.Lock()
+	defer r.outMu.Unlock()
+	
+	r.output().Write(buf.Bytes())
+	buf.Reset()
+}
+
+func (s *Suite) printf(format string, args ...interface{}) {
+	s.runner.printf(format, args...)
+}
+
+func (s *Suite) println(args ...interface{}) {
+	s.runner.println(args...)
+}
@@ -0,0 +1,99 @@
+package fynetest
+
+import (
+	"image"
+	"image/draw"
+	"runtime"
+	"sync"
+)
+
+// pixelBuffer is a tightly packed 8-bit premultiplied RGBA view of an image,
+// indexed from (0, 0) regardless of the image bounds.
+type pixelBuffer struct {
+	pix    []byte
+	w, h   int
+	pooled bool
+}
+
+// newPixelBuffer returns the pixels of img. An *image.RGBA without row
+// padding is used directly; other images are converted into a pooled buffer,
+// which release hands back.
+func newPixelBuffer(img image.Image) pixelBuffer {
+	b := img.Bounds()
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Stride == 4*b.Dx() {
+		return pixelBuffer{pix: rgba.Pix[:4*b.Dx()*b.Dy()], w: b.Dx(), h: b.Dy()}
+	}
+
+	rgba := newPooledRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return pixelBuffer{pix: rgba.Pix, w: b.Dx(), h: b.Dy(), pooled: true}
+}
+
+// release returns a converted buffer to the pool.
+func (p pixelBuffer) release() {
+	if p.pooled {
+		putPix(p.pix)
+	}
+}
+
+// at returns the four RGBA bytes of the pixel at (x, y).
+func (p pixelBuffer) at(x, y int) []byte {
+	i := (y*p.w + x) * 4
+	return p.pix[i : i+4 : i+4]
+}
+
+// row returns the bytes of row y.
+func (p pixelBuffer) row(y int) []byte {
+	return p.pix[y*p.w*4 : (y+1)*p.w*4]
+}
+
+func (p pixelBuffer) contains(x, y int) bool {
+	return x >= 0 && y >= 0 && x < p.w && y < p.h
+}
+
+// maxChannelDelta returns the largest difference between two RGBA pixels.
+func maxChannelDelta(a, b []byte) uint8 {
+	var max uint8
+	for i := 0; i < 4; i++ {
+		d := a[i] - b[i]
+		if b[i] > a[i] {
+			d = b[i] - a[i]
+		}
+		if d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+// minRowsPerBand keeps small images on a single goroutine, where the
+// scheduling overhead would outweigh the work.
+const minRowsPerBand = 64
+
+// parallelRows splits the rows [0, h) into contiguous bands, one per CPU,
+// calls fn for each band concurrently and waits for all of them.
+func parallelRows(h int, fn func(y0, y1 int)) {
+	bands := runtime.GOMAXPROCS(0)
+	if max := (h + minRowsPerBand - 1) / minRowsPerBand; bands > max {
+		bands = max
+	}
+	if bands <= 1 {
+		fn(0, h)
+		return
+	}
+
+	var wg sync.WaitGroup
+	step := (h + bands - 1) / bands
+	for y0 := 0; y0 < h; y0 += step {
+		y1 := y0 + step
+		if y1 > h {
+			y1 = h
+		}
+		wg.Add(1)
+		go func(y0, y1 int) {
+			defer wg.Done()
+			fn(y0, y1)
+		}(y0, y1)
+	}
+	wg.Wait()
+}
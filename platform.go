@@ -0,0 +1,32 @@
+package fynetest
+
+import (
+	"runtime"
+	"strings"
+)
+
+// onPlatform reports whether platform, given as GOOS or GOOS/GOARCH, is
+// the one running.
+func onPlatform(platform string) bool {
+	goos, goarch, found := strings.Cut(strings.ToLower(strings.TrimSpace(platform)), "/")
+	return goos == runtime.GOOS && (!found || goarch == runtime.GOARCH)
+}
+
+// ToleranceFor returns the tolerance captures of test are compared with:
+// the runner's Tolerance, loosened on a platform in test.PlatformTolerances
+// to let that fraction of pixels differ. A GOOS/GOARCH entry wins over a
+// GOOS one. Tolerances never tighten the runner's, and a custom Comparer
+// ignores them.
+func (r *Runner) ToleranceFor(test Test) Tolerance {
+	tol := r.Tolerance
+	fraction, found := 0.0, false
+	for platform, f := range test.PlatformTolerances {
+		if onPlatform(platform) && (!found || strings.Contains(platform, "/")) {
+			fraction, found = f, true
+		}
+	}
+	if found && fraction*100 > tol.MaxDiffPercent {
+		tol.MaxDiffPercent = fraction * 100
+	}
+	return tol
+}
@@ -0,0 +1,67 @@
+package fynetest
+
+import (
+	"runtime"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestSkipOnPlatform(t *testing.T) {
+	here, arch := runtime.GOOS, runtime.GOOS+"/"+runtime.GOARCH
+	other := "plan9"
+	if here == other {
+		other = "windows"
+	}
+
+	tests := []struct {
+		platforms []string
+		skip      bool
+		reason    string
+	}{
+		{[]string{other}, false, ""},
+		{[]string{other, here}, true, "unstable on " + here},
+		{[]string{arch}, true, "unstable on " + arch},
+		{[]string{here + "/notanarch"}, false, ""},
+	}
+	for _, tt := range tests {
+		test := NewTest("Platform").
+			WithSetup(func() fyne.CanvasObject { return widget.NewLabel("") }).
+			WithSkipOnPlatform(tt.platforms...).
+			MustBuild()
+		if skip, reason := test.Skipped(); skip != tt.skip || reason != tt.reason {
+			t.Errorf("WithSkipOnPlatform(%v): Skipped() = %v, %q; want %v, %q", tt.platforms, skip, reason, tt.skip, tt.reason)
+		}
+	}
+}
+
+func TestLooseToleranceOn(t *testing.T) {
+	r := NewRunner()
+	r.Tolerance = Tolerance{MaxDiffPercent: 1}
+	here, arch := runtime.GOOS, runtime.GOOS+"/"+runtime.GOARCH
+	other := "plan9"
+	if here == other {
+		other = "windows"
+	}
+
+	tests := []struct {
+		name       string
+		tolerances map[string]float64
+		want       float64
+	}{
+		{"other platform", map[string]float64{other: 0.05}, 1},
+		{"this platform", map[string]float64{here: 0.05}, 5},
+		{"architecture wins", map[string]float64{here: 0.05, arch: 0.1}, 10},
+		{"never tightens", map[string]float64{here: 0.001}, 1},
+	}
+	for _, tt := range tests {
+		builder := NewTest("Platform").WithSetup(func() fyne.CanvasObject { return widget.NewLabel("") })
+		for platform, fraction := range tt.tolerances {
+			builder.WithLooseToleranceOn(platform, fraction)
+		}
+		if got := r.ToleranceFor(builder.MustBuild()).MaxDiffPercent; got != tt.want {
+			t.Errorf("%s: MaxDiffPercent = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package fynetest
+
+import (
+	"image"
+	"image/png"
+	"io"
+	"sync"
+)
+
+// pixPools recycles pixel buffers between captures, diffs and masks. Pools
+// are keyed by buffer size, so a suite whose captures share a window size
+// reuses a handful of buffers instead of allocating one per image.
+var pixPools sync.Map // int -> *sync.Pool of *[]byte
+
+func pixPool(n int) *sync.Pool {
+	if pool, ok := pixPools.Load(n); ok {
+		return pool.(*sync.Pool)
+	}
+	pool, _ := pixPools.LoadOrStore(n, &sync.Pool{
+		New: func() interface{} {
+			pix := make([]byte, n)
+			return &pix
+		},
+	})
+	return pool.(*sync.Pool)
+}
+
+// getPix returns a buffer of n bytes with undefined contents.
+func getPix(n int) []byte {
+	return *pixPool(n).Get().(*[]byte)
+}
+
+// putPix returns a buffer obtained from getPix to its pool.
+func putPix(pix []byte) {
+	if cap(pix) == 0 {
+		return
+	}
+	pix = pix[:cap(pix)]
+	pixPool(len(pix)).Put(&pix)
+}
+
+// newPooledRGBA returns an RGBA image backed by a pooled buffer. Its pixels
+// are undefined, so callers must overwrite all of them.
+func newPooledRGBA(r image.Rectangle) *image.RGBA {
+	return &image.RGBA{
+		Pix:    getPix(4 * r.Dx() * r.Dy()),
+		Stride: 4 * r.Dx(),
+		Rect:   r,
+	}
+}
+
+// ReleaseImage hands the pixel buffer of an image returned by DiffImage or
+// MaskRegions back to the pool for later comparisons. The image must not be
+// used afterwards. Images of other types are ignored.
+func ReleaseImage(img image.Image) {
+	rgba, ok := img.(*image.RGBA)
+	if !ok || rgba.Stride != 4*rgba.Rect.Dx() || len(rgba.Pix) != 4*rgba.Rect.Dx()*rgba.Rect.Dy() {
+		return
+	}
+	putPix(rgba.Pix)
+}
+
+// encoderPool shares zlib and scanline buffers between PNG encodes.
+type encoderPool struct {
+	pool sync.Pool
+}
+
+func (p *encoderPool) Get() *png.EncoderBuffer {
+	buf, _ := p.pool.Get().(*png.EncoderBuffer)
+	return buf
+}
+
+func (p *encoderPool) Put(buf *png.EncoderBuffer) {
+	p.pool.Put(buf)
+}
+
+var pngEncoder = png.Encoder{BufferPool: &encoderPool{}}
+
+// EncodePNG writes img to w as a PNG, reusing the encoder's compression
+// buffers across calls.
+func EncodePNG(w io.Writer, img image.Image) error {
+	return pngEncoder.Encode(w, img)
+}
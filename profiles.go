@@ -0,0 +1,215 @@
+package fynetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// Profile is a named run configuration. Several profiles can be executed in
+// one invocation, each with its own test selection and rendering settings,
+// producing a single combined report with one section per profile.
+type Profile struct {
+	// Name identifies the profile, e.g. "smoke@light" or "full@dark-mobile"
+	Name string
+	
+	// Tags selects tests having any of these tags (empty selects all)
+	Tags []string
+	
+	// Pattern selects tests whose names contain this substring (case-insensitive)
+	Pattern string
+	
+	// Theme overrides the theme of every selected test
+	Theme fyne.Theme
+	
+	// Size overrides the window size of every selected test
+	Size *fyne.Size
+	
+	// Scale overrides the canvas scale of every selected test
+	Scale float32
+	
+	// Renderer overrides the render path of every selected test
+	Renderer RenderPath
+}
+
+// Select returns the tests matching the profile's tag and pattern filters.
+func (p Profile) Select(tests []Test) []Test {
+	pattern := strings.ToLower(p.Pattern)
+	selected := make([]Test, 0)
+	
+	for _, test := range tests {
+		if pattern != "" && !strings.Contains(strings.ToLower(test.Name), pattern) {
+			continue
+		}
+		if len(p.Tags) > 0 && !hasAnyTag(test, p.Tags) {
+			continue
+		}
+		selected = append(selected, test)
+	}
+	return selected
+}
+
+// Apply returns a copy of test with the profile's settings applied.
+func (p Profile) Apply(test Test) Test {
+	if p.Theme != nil {
+		test.Theme = p.Theme
+	}
+	if p.Size != nil {
+		test.Size = p.Size
+	}
+	if p.Scale > 0 {
+		test.Scale = p.Scale
+	}
+	if p.Renderer != "" {
+		test.Renderer = p.Renderer
+	}
+	return test
+}
+
+// Profile returns the configured profile with the given name.
+func (s *Suite) Profile(name string) (Profile, bool) {
+	for _, profile := range s.config.Profiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// RunProfiles executes the named profiles in order and returns one combined
+// result. Passing no names, or "all", runs every configured profile.
+// Screenshots of each profile are stored in their own subdirectory.
+func (s *Suite) RunProfiles(names ...string) (SuiteResult, error) {
+	profiles, err := s.resolveProfiles(names)
+	if err != nil {
+		return SuiteResult{}, err
+	}
+	if err := s.beforeSuite(); err != nil {
+		return SuiteResult{}, err
+	}
+	
+	startTime := time.Now()
+	runDir := filepath.Join(s.config.OutputDir, startTime.Format("20060102-150405"))
+	
+	originalOutputDir := s.runner.OutputDir
+	defer func() {
+		s.runner.OutputDir = originalOutputDir
+		s.runner.profile = ""
+	}()
+	
+	selections := make([][]Test, len(profiles))
+	total := 0
+	applied := make([]Test, 0)
+	for i, profile := range profiles {
+		selections[i] = profile.Select(s.tests)
+		for j := range selections[i] {
+			selections[i][j] = profile.Apply(selections[i][j])
+		}
+		applied = append(applied, selections[i]...)
+		total += len(selections[i])
+	}
+	s.runner.emit(Event{Type: EventSuiteStart, Suite: s.config.Name, Total: total})
+	
+	results := make([]Result, 0, total)
+	stop := s.startIncrementalReport(runDir)
+	runProfiles := func() {
+		for i, profile := range profiles {
+			s.runner.OutputDir = filepath.Join(runDir, sanitizeFilename(profile.Name))
+			s.runner.profile = profile.Name
+			
+			tests := selections[i]
+			if s.config.Verbose {
+				s.printf("▶ Profile %s (%d tests)\n", profile.Name, len(tests))
+			}
+			
+			results = append(results, s.runner.RunTests(tests)...)
+		}
+	}
+	// Profiles rendering with GL share one GL app
+	if !s.runner.needsGL(applied) || s.runner.RunWithGL(runProfiles) != nil {
+		runProfiles()
+	}
+	stop()
+	
+	suiteResult := SuiteResult{
+		Name:      s.config.Name,
+		Results:   results,
+		StartTime: startTime,
+		EndTime:   time.Now(),
+		OutputDir: runDir,
+	}
+	defer func() { s.afterSuite(suiteResult) }()
+	
+	if err := s.generateReport(&suiteResult); err != nil {
+		return suiteResult, err
+	}
+	
+	s.runner.emit(newSuiteFinishEvent(suiteResult))
+	return suiteResult, nil
+}
+
+func (s *Suite) resolveProfiles(names []string) ([]Profile, error) {
+	if len(names) == 0 || (len(names) == 1 && names[0] == "all") {
+		if len(s.config.Profiles) == 0 {
+			return nil, fmt.Errorf("no profiles configured")
+		}
+		return s.config.Profiles, nil
+	}
+	
+	profiles := make([]Profile, 0, len(names))
+	for _, name := range names {
+		profile, ok := s.Profile(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q", name)
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+func (s *Suite) runProfilesCLI(names []string) {
+	profiles, err := s.resolveProfiles(names)
+	if err != nil {
+		s.printf("❌ %v\n", err)
+		s.listProfiles()
+		os.Exit(1)
+	}
+	
+	s.println("🧪 Fyne Visual Test Runner")
+	s.println("==========================")
+	s.printf("Suite: %s\n", s.config.Name)
+	s.printf("Output directory: %s\n", s.config.OutputDir)
+	for _, profile := range profiles {
+		s.printf("Profile %s: %d tests\n", profile.Name, len(profile.Select(s.tests)))
+	}
+	s.println()
+	
+	result, err := s.RunProfiles(names...)
+	s.finishCLI(result, err)
+}
+
+func (s *Suite) listProfiles() {
+	if len(s.config.Profiles) == 0 {
+		s.println("No profiles defined in test suite")
+		return
+	}
+	
+	s.println("Available profiles:")
+	s.println("===================")
+	for _, profile := range s.config.Profiles {
+		s.printf("- %s (%d tests)\n", profile.Name, len(profile.Select(s.tests)))
+	}
+}
+
+func hasAnyTag(test Test, tags []string) bool {
+	for _, tag := range tags {
+		if contains(test.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
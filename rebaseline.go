@@ -0,0 +1,167 @@
+package fynetest
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MetadataRebaseline is set in Result.Metadata by StageBaselines to one of
+// RebaselineNew, RebaselineChanged or RebaselineUnchanged.
+const MetadataRebaseline = "rebaseline"
+
+// Outcomes of staging a baseline, compared with the committed one.
+const (
+	RebaselineNew       = "new"
+	RebaselineChanged   = "changed"
+	RebaselineUnchanged = "unchanged"
+)
+
+// StageBaselines regenerates the baselines of tests into stagingDir instead
+// of BaselineDir, so they can be reviewed before ApplyStagedBaselines
+// replaces the committed ones. Captures are saved in a timestamped run
+// directory, which is returned. Each result is compared with its committed
+// baseline, ignoring the same regions as a regular run: changed ones are
+// marked failed, with review images, so a report of the results lists what
+// signing off would change. Any earlier staging in stagingDir is replaced.
+func (r *Runner) StageBaselines(tests []Test, stagingDir string) ([]Result, string, error) {
+	committed := r.BaselineDir
+	if committed == "" {
+		return nil, "", fmt.Errorf("staging baselines needs a BaselineDir")
+	}
+	if filepath.Clean(stagingDir) == filepath.Clean(committed) {
+		return nil, "", fmt.Errorf("staging directory must differ from the baseline directory")
+	}
+	if err := clearStaging(stagingDir); err != nil {
+		return nil, "", err
+	}
+
+	// Staged baselines are checked against the policy when applied
+	update := r.Update
+	r.BaselineDir, r.Update, r.staging = stagingDir, SnapshotUpdate{All: true}, true
+	results, runDir := r.RunTestsWithTimestamp(tests)
+	r.BaselineDir, r.Update, r.staging = committed, update, false
+
+	for i := range results {
+		result := &results[i]
+		if !result.Success {
+			continue
+		}
+
+		// Mask the test's ignored regions and the committed calibration mask,
+		// as compareBaseline does
+		path := r.BaselinePath(result.Test)
+		ignored := result.ignored
+		if mask, ok := ReadBaselineMask(path); ok && mask.Fits(result.Screenshot) {
+			ignored = append(append([]image.Rectangle(nil), ignored...), mask.Rects()...)
+		}
+		diff, err := CompareFile(path, result.Screenshot, r.ToleranceFor(result.Test), ignored)
+		switch {
+		case os.IsNotExist(err):
+			result.Metadata[MetadataRebaseline] = RebaselineNew
+			continue
+		case err != nil:
+			result.Success = false
+			result.Error = fmt.Errorf("failed to compare with committed baseline: %w", err)
+			continue
+		}
+
+		result.Diff = &diff
+		if diff.Match {
+			result.Metadata[MetadataRebaseline] = RebaselineUnchanged
+			continue
+		}
+		result.Metadata[MetadataRebaseline] = RebaselineChanged
+		result.Success = false
+		result.Error = fmt.Errorf("baseline would change: %.2f%% of pixels differ", diff.DiffPercent)
+		if assets, err := r.writeDiffAssets(result.Test, result.ScreenshotPath, result.Screenshot, ignored); err == nil {
+			result.DiffAssets = assets
+		}
+	}
+	return results, runDir, nil
+}
+
+// clearStaging empties a staging directory, refusing directories that hold
+// files but no manifest, which were not written by StageBaselines.
+func clearStaging(stagingDir string) error {
+	entries, err := os.ReadDir(stagingDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(stagingDir, ManifestName)); err != nil {
+		return fmt.Errorf("%s is not empty and holds no staged baselines", stagingDir)
+	}
+	return os.RemoveAll(stagingDir)
+}
+
+// ApplyStagedBaselines moves the baselines staged by StageBaselines, with
+// their digest, widget tree and metadata files, into baselineDir, records
+// them in its manifest together with their approval trail and removes the
+// staging directory. It returns the baselines replaced or added. Nothing is
+// applied while any staged baseline lacks the approvals policy requires.
+func ApplyStagedBaselines(stagingDir, baselineDir string) ([]string, error) {
+	policy, err := LoadApprovalPolicy(baselineDir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	staged, err := LoadManifest(stagingDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(staged.Baselines) == 0 {
+		return nil, fmt.Errorf("no staged baselines in %s", stagingDir)
+	}
+	if pending := pendingApprovals(staged, policy); len(pending) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrApprovalRequired, strings.Join(pending, ", "))
+	}
+	if err := os.MkdirAll(baselineDir, 0755); err != nil {
+		return nil, err
+	}
+
+	manifest, err := LoadManifest(baselineDir)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]string, 0, len(staged.Baselines))
+	for name, entry := range staged.Baselines {
+		if err := staged.Check(stagingDir, name); err != nil {
+			return applied, err
+		}
+		// The PNG goes first so its digest file isn't older than it
+		for _, suffix := range []string{"", DigestSuffix, TreeSuffix, MetaSuffix} {
+			err := copyFile(filepath.Join(stagingDir, name+suffix), filepath.Join(baselineDir, name+suffix))
+			if err != nil && !(suffix != "" && os.IsNotExist(err)) {
+				return applied, err
+			}
+		}
+		manifest.Baselines[name] = entry
+		applied = append(applied, filepath.Join(baselineDir, name))
+	}
+	sort.Strings(applied)
+	if err := manifest.Save(baselineDir); err != nil {
+		return applied, err
+	}
+	return applied, os.RemoveAll(stagingDir)
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
@@ -0,0 +1,86 @@
+package fynetest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStageAndApplyBaselines(t *testing.T) {
+	r := snapshotRunner(t)
+	same, changed, added := "Same", "Before", "Added"
+	tests := []Test{labelTest("Same", &same), labelTest("Changed", &changed)}
+
+	r.Update = SnapshotUpdate{All: true}
+	for _, result := range r.RunTests(tests) {
+		if !result.Success {
+			t.Fatalf("creating %s failed: %v", result.Test.Name, result.Error)
+		}
+	}
+	r.Update = SnapshotUpdate{}
+	committed, err := os.ReadFile(r.BaselinePath(tests[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed = "After"
+	tests = append(tests, labelTest("Added", &added))
+	staging := filepath.Join(t.TempDir(), "staging")
+	results, _, err := r.StageBaselines(tests, staging)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"Same": RebaselineUnchanged, "Changed": RebaselineChanged, "Added": RebaselineNew}
+	for _, result := range results {
+		if got := result.Metadata[MetadataRebaseline]; got != want[result.Test.Name] {
+			t.Errorf("%s staged as %v, want %s", result.Test.Name, got, want[result.Test.Name])
+		}
+		if review := result.Test.Name == "Changed"; result.Success == review {
+			t.Errorf("%s: success %v, want only the changed baseline to fail for review", result.Test.Name, result.Success)
+		}
+	}
+	if results[1].DiffAssets == nil {
+		t.Error("the changed baseline has no review images")
+	}
+	if data, _ := os.ReadFile(r.BaselinePath(tests[1])); !bytes.Equal(data, committed) {
+		t.Error("staging replaced the committed baseline")
+	}
+
+	applied, err := ApplyStagedBaselines(staging, r.BaselineDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 3 {
+		t.Errorf("applied %v, want all 3 staged baselines", applied)
+	}
+	if _, err := os.Stat(staging); !os.IsNotExist(err) {
+		t.Error("the staging directory was left behind")
+	}
+	for _, result := range r.RunTests(tests) {
+		if !result.Success || result.Diff == nil {
+			t.Errorf("%s doesn't match its applied baseline: %v", result.Test.Name, result.Error)
+		}
+	}
+}
+
+func TestStageBaselinesRefusesForeignDirectories(t *testing.T) {
+	r := snapshotRunner(t)
+	text := "Hello"
+	tests := []Test{labelTest("Hello", &text)}
+
+	if _, _, err := r.StageBaselines(tests, r.BaselineDir); err == nil {
+		t.Error("staging into the baseline directory succeeded")
+	}
+	foreign := t.TempDir()
+	if err := os.WriteFile(filepath.Join(foreign, "notes.txt"), []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.StageBaselines(tests, foreign); err == nil {
+		t.Error("staging cleared a directory it didn't create")
+	}
+	if _, err := os.Stat(filepath.Join(foreign, "notes.txt")); err != nil {
+		t.Error("staging removed a file it didn't create")
+	}
+}
@@ -0,0 +1,235 @@
+package fynetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Metadata keys set by CompareWithRun.
+const (
+	// MetadataReferenceDiff is the percentage of pixels that differ from the
+	// reference run's capture of the test
+	MetadataReferenceDiff = "reference_diff"
+
+	// MetadataReferenceImage is the composite of the reference capture, the
+	// current capture and their difference
+	MetadataReferenceImage = "reference_image"
+)
+
+// AssetReference is the kind of the review image written next to a capture
+// that differs from the reference run (see AssetPath).
+const AssetReference = "reference"
+
+// ReferenceDir is the directory inside a run directory that receives the
+// reference run fetched for -compare-with.
+const ReferenceDir = "reference"
+
+// ReferenceChange is a test whose capture differs from the one in the
+// reference run, or that the reference run doesn't have.
+type ReferenceChange struct {
+	Result Result
+
+	// Reference is the result of the test in the reference run, nil for a
+	// new test
+	Reference *Result
+
+	// Diff compares the reference capture with the current one
+	Diff *DiffResult
+
+	// Composite shows the reference capture, the current capture and the
+	// differing pixels side by side, when the sizes allow it
+	Composite string
+}
+
+// FetchRun downloads the JSON report of a published run and the captures it
+// lists into dir, and loads it. location is the URL of the run's JSON report
+// or run directory, e.g.
+// "https://ci.example.com/runs/main/latest/index.json", or a storage
+// location of the run directory as accepted by OpenStorage, e.g.
+// "s3://bucket/runs/main/latest". Captures that cannot be downloaded are
+// left out of the loaded run.
+func FetchRun(location, dir string) (*RunData, error) {
+	base, name := location, reportJSON
+	if strings.HasSuffix(location, ".json") {
+		i := strings.LastIndex(location, "/")
+		base, name = location[:i+1], location[i+1:]
+	}
+	base = strings.TrimSuffix(base, "/")
+
+	var get func(key string) ([]byte, error)
+	if u, err := url.Parse(base); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		client := &http.Client{Timeout: 30 * time.Second}
+		get = func(key string) ([]byte, error) {
+			return httpGet(client, base+"/"+(&url.URL{Path: key}).EscapedPath())
+		}
+	} else {
+		storage, err := OpenStorage(base)
+		if err != nil {
+			return nil, err
+		}
+		get = storage.Get
+	}
+
+	data, err := get(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch run report: %w", err)
+	}
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid run report at %s: %w", location, err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	for i, result := range report.Results {
+		key := path.Clean(result.ScreenshotPath)
+		if result.ScreenshotPath == "" || path.IsAbs(key) || strings.HasPrefix(key, "..") {
+			report.Results[i].ScreenshotPath = ""
+			continue
+		}
+		data, err := get(key)
+		if err == nil {
+			err = writeFileAtomic(filepath.Join(dir, filepath.FromSlash(key)), bytes.NewReader(data))
+		}
+		if err != nil {
+			report.Results[i].ScreenshotPath = ""
+		}
+	}
+
+	// Keep the report as LoadRun expects it, without the captures that
+	// could not be fetched
+	data, err = json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, reportJSON), data, 0644); err != nil {
+		return nil, err
+	}
+	return LoadRun(dir)
+}
+
+func httpGet(client *http.Client, target string) ([]byte, error) {
+	resp, err := client.Get(target)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", target, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// CompareWithRun compares the captures of results with those of the same
+// tests in reference, such as the latest run of the main branch, instead of
+// with baselines. Each differing capture gets a composite review image next
+// to it, and its result metadata records the difference. It returns the
+// tests whose capture changed or that reference doesn't have.
+func CompareWithRun(reference *RunData, results []Result, tol Tolerance) ([]ReferenceChange, error) {
+	changes := make([]ReferenceChange, 0)
+	for _, result := range results {
+		if result.ScreenshotPath == "" {
+			continue
+		}
+		previous, ok := reference.Find(result.Test.Name, result.Profile)
+		if !ok || previous.ScreenshotPath == "" {
+			changes = append(changes, ReferenceChange{Result: result})
+			continue
+		}
+
+		expected, err := reference.Image(previous.ScreenshotPath)
+		if err != nil {
+			return changes, fmt.Errorf("failed to load reference capture of %s: %w", result.Test.Name, err)
+		}
+		actual, err := decodePNGFile(result.ScreenshotPath)
+		if err != nil {
+			return changes, fmt.Errorf("failed to load capture of %s: %w", result.Test.Name, err)
+		}
+		diff := CompareImages(expected, actual, tol)
+		if result.Metadata != nil {
+			result.Metadata[MetadataReferenceDiff] = diff.DiffPercent
+		}
+		if diff.Match {
+			continue
+		}
+
+		change := ReferenceChange{Result: result, Reference: &previous, Diff: &diff}
+		if composite := renderAsset(AssetComposite, expected, actual, tol); composite != nil {
+			change.Composite = AssetPath(result.ScreenshotPath, AssetReference)
+			err := writeImage(composite, change.Composite)
+			ReleaseImage(composite)
+			if err != nil {
+				return changes, fmt.Errorf("failed to save reference diff of %s: %w", result.Test.Name, err)
+			}
+			if result.Metadata != nil {
+				result.Metadata[MetadataReferenceImage] = filepath.Base(change.Composite)
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func writeImage(img image.Image, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := EncodePNG(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// compareWithReference fetches the run at SuiteConfig.CompareWith and
+// compares the captures of the suite result with it. Problems are reported
+// as warnings; the run itself is unaffected.
+func (s *Suite) compareWithReference(suiteResult *SuiteResult) {
+	w := s.runner.output()
+	reference, err := FetchRun(s.config.CompareWith, filepath.Join(suiteResult.OutputDir, ReferenceDir))
+	if err != nil {
+		fmt.Fprintf(w, "⚠️  Failed to fetch the reference run: %v\n", err)
+		return
+	}
+	changes, err := CompareWithRun(reference, suiteResult.Results, s.config.Tolerance)
+	if err != nil {
+		fmt.Fprintf(w, "⚠️  Failed to compare with the reference run: %v\n", err)
+		return
+	}
+
+	label := reference.Title
+	if !reference.Timestamp.IsZero() {
+		label += " of " + reference.Timestamp.Format("2006-01-02 15:04")
+	}
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "🔭 No visual changes from the reference run (%s)\n", label)
+		return
+	}
+	fmt.Fprintf(w, "🔭 %d captures differ from the reference run (%s):\n", len(changes), label)
+	for _, change := range changes {
+		name := change.Result.Test.Name
+		if change.Result.Profile != "" {
+			name += " [" + change.Result.Profile + "]"
+		}
+		switch {
+		case change.Reference == nil:
+			fmt.Fprintf(w, "- %s: new test\n", name)
+		case change.Diff.SizeMismatch:
+			fmt.Fprintf(w, "- %s: size changed\n", name)
+		default:
+			fmt.Fprintf(w, "- %s: %.2f%% of pixels changed\n", name, change.Diff.DiffPercent)
+		}
+	}
+}
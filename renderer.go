@@ -0,0 +1,313 @@
+package fynetest
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+
+	"fyne.io/fyne/v2"
+)
+
+// ErrGLUnavailable is returned when a GL capture is requested but the binary
+// was built without the "gl" build tag or no display is available.
+var ErrGLUnavailable = errors.New("GL renderer unavailable (build with -tags gl and run with a display)")
+
+// RenderPath selects the renderer that captures a test.
+type RenderPath string
+
+const (
+	// RenderSoftware captures with fyne's software test driver (default)
+	RenderSoftware RenderPath = "software"
+	
+	// RenderGL captures with the real GL driver; it needs the "gl" build tag,
+	// a display and the main goroutine
+	RenderGL RenderPath = "gl"
+)
+
+// MetadataRenderer is the result metadata key recording the RenderPath that
+// produced a capture.
+const MetadataRenderer = "renderer"
+
+// ParseRenderPath parses a -renderer flag value.
+func ParseRenderPath(value string) (RenderPath, error) {
+	switch path := RenderPath(value); path {
+	case "", RenderSoftware:
+		return RenderSoftware, nil
+	case RenderGL:
+		return path, nil
+	}
+	return RenderSoftware, fmt.Errorf("unknown renderer %q (use software or gl)", value)
+}
+
+// renderPath returns the renderer for test: its own, else the runner's.
+func (r *Runner) renderPath(test Test) RenderPath {
+	if test.Renderer != "" {
+		return test.Renderer
+	}
+	if r.Renderer != "" {
+		return r.Renderer
+	}
+	return RenderSoftware
+}
+
+// ErrGLSession is returned for a GL test rendered on its own, e.g. by
+// RunTest, outside RunWithGL. Starting a GL app per test would break most
+// drivers, which can only run one per process.
+var ErrGLSession = errors.New("GL tests render in batches (RunTests) or inside Runner.RunWithGL")
+
+// RunWithGL runs fn while one GL app stays open for every GL test rendered
+// meanwhile, including retries and single tests run with RunTest. The GL
+// driver takes over the calling goroutine, so call it from the main
+// goroutine; fn runs on another one. Nested calls reuse the open app. It
+// returns ErrGLUnavailable, without calling fn, when GL is unavailable.
+func (r *Runner) RunWithGL(fn func()) error {
+	if r.glApp != nil {
+		fn()
+		return nil
+	}
+	testApp := r.ensureApp()
+	err := withGLApp(func(glApp fyne.App) {
+		// Starting the GL app made it the current app; software tests
+		// render on the test app
+		fyne.SetCurrentApp(testApp)
+		r.glApp = glApp
+		defer func() { r.glApp = nil }()
+		fn()
+	})
+	fyne.SetCurrentApp(testApp)
+	return err
+}
+
+// needsGL reports whether any of tests renders with GL.
+func (r *Runner) needsGL(tests []Test) bool {
+	for _, test := range tests {
+		if r.renderPath(test) == RenderGL {
+			return true
+		}
+	}
+	return false
+}
+
+// renderGL renders tests with the GL driver, in order, passing each capture
+// to done. All tests share one GL app, since most drivers can only start one
+// per process: the one RunWithGL holds open, or else one opened for this
+// batch. When GL is unavailable every test fails with ErrGLUnavailable.
+func (r *Runner) renderGL(tests []Test, attempt int, done func(i int, c *capturedTest)) {
+	if r.glApp == nil {
+		if err := r.RunWithGL(func() { r.renderGL(tests, attempt, done) }); err != nil {
+			for i, test := range tests {
+				done(i, r.renderOn(nil, test, attempt))
+			}
+		}
+		return
+	}
+	
+	testApp := r.ensureApp()
+	fyne.SetCurrentApp(r.glApp)
+	defer fyne.SetCurrentApp(testApp)
+	for i, test := range tests {
+		done(i, r.renderOn(r.glApp, test, attempt))
+	}
+}
+
+// RendererDrift reports how far the GL driver's capture of a test drifted
+// from the software renderer's capture of the same test.
+type RendererDrift struct {
+	// Test is the test that was captured
+	Test Test
+	
+	// Software is the capture produced by the software (test) renderer
+	Software image.Image
+	
+	// GL is the capture produced by the real GL driver
+	GL image.Image
+	
+	// Diff holds the exact pixel comparison of the two captures
+	Diff DiffResult
+	
+	// Suggested is the tightest tolerance under which both captures match
+	Suggested Tolerance
+	
+	// Error contains any error from either capture
+	Error error
+}
+
+// GLAvailable reports whether GL captures can be taken in this process.
+func GLAvailable() bool {
+	return glSupported && displayAvailable()
+}
+
+// CompareRenderers captures each test with the software renderer and with
+// the real GL driver, and reports the pixel drift between them. The drift
+// helps choose safe tolerances per widget class.
+//
+// Because the GL driver takes over the calling goroutine while it runs,
+// this must be called from the main goroutine.
+func (r *Runner) CompareRenderers(tests []Test) ([]RendererDrift, error) {
+	if !GLAvailable() {
+		return nil, ErrGLUnavailable
+	}
+	
+	drifts := make([]RendererDrift, len(tests))
+	testApp := r.ensureApp()
+	for i, test := range tests {
+		drifts[i].Test = test
+		if err := test.Validate(); err != nil {
+			drifts[i].Error = fmt.Errorf("invalid test configuration: %w", err)
+			continue
+		}
+		r.applyTheme(testApp, test)
+		var shot windowCapture
+		shot, drifts[i].Error = r.capture(testApp, test)
+		drifts[i].Software = shot.img
+	}
+	
+	glImages, glErrors := captureWithGL(r, tests)
+	fyne.SetCurrentApp(testApp)
+	
+	for i := range drifts {
+		if drifts[i].Error != nil {
+			continue
+		}
+		if glErrors[i] != nil {
+			drifts[i].Error = fmt.Errorf("GL capture failed: %w", glErrors[i])
+			continue
+		}
+		
+		drifts[i].GL = glImages[i]
+		drifts[i].Diff = CompareImages(drifts[i].Software, drifts[i].GL, ExactTolerance)
+		if drifts[i].Diff.SizeMismatch {
+			drifts[i].Error = fmt.Errorf("captures differ in size: software %v, GL %v",
+				drifts[i].Software.Bounds().Size(), drifts[i].GL.Bounds().Size())
+			continue
+		}
+		drifts[i].Suggested = suggestTolerance(deltaHistogram(drifts[i].Software, drifts[i].GL))
+	}
+	
+	return drifts, nil
+}
+
+// captureWithGL renders the tests with the real GL driver. It blocks the
+// calling goroutine, which must be the main goroutine, until all captures
+// are done.
+func captureWithGL(r *Runner, tests []Test) ([]image.Image, []error) {
+	images := make([]image.Image, len(tests))
+	errs := make([]error, len(tests))
+	
+	err := r.RunWithGL(func() {
+		testApp := r.ensureApp()
+		fyne.SetCurrentApp(r.glApp)
+		defer fyne.SetCurrentApp(testApp)
+		for i, test := range tests {
+			if test.Validate() != nil {
+				continue
+			}
+			r.applyTheme(r.glApp, test)
+			var shot windowCapture
+			shot, errs[i] = r.capture(r.glApp, test)
+			images[i] = shot.img
+		}
+	})
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+	}
+	return images, errs
+}
+
+// DriftByTag merges renderer drifts by each test's primary tag and returns
+// the loosest suggested tolerance per tag, giving one recommendation per
+// widget class. Untagged tests are grouped under "untagged".
+func DriftByTag(drifts []RendererDrift) map[string]Tolerance {
+	byTag := make(map[string]Tolerance)
+	for _, drift := range drifts {
+		if drift.Error != nil {
+			continue
+		}
+		
+		tag := "untagged"
+		if len(drift.Test.Tags) > 0 {
+			tag = drift.Test.Tags[0]
+		}
+		
+		current := byTag[tag]
+		if drift.Suggested.ColorDelta > current.ColorDelta {
+			current.ColorDelta = drift.Suggested.ColorDelta
+		}
+		if drift.Suggested.MaxDiffPercent > current.MaxDiffPercent {
+			current.MaxDiffPercent = drift.Suggested.MaxDiffPercent
+		}
+		byTag[tag] = current
+	}
+	return byTag
+}
+
+// suggestedOutlierPercent is the share of pixels allowed to exceed the
+// suggested color delta, so a handful of outliers don't inflate it.
+const suggestedOutlierPercent = 0.5
+
+// suggestTolerance picks the smallest color delta that leaves at most
+// suggestedOutlierPercent of the pixels differing.
+func suggestTolerance(hist [256]int) Tolerance {
+	total := 0
+	for _, n := range hist {
+		total += n
+	}
+	if total == 0 {
+		return ExactTolerance
+	}
+	
+	above := total
+	for delta := 0; delta < 256; delta++ {
+		above -= hist[delta]
+		percent := float64(above) / float64(total) * 100
+		if percent <= suggestedOutlierPercent {
+			if above == 0 {
+				return Tolerance{ColorDelta: uint8(delta)}
+			}
+			return Tolerance{ColorDelta: uint8(delta), MaxDiffPercent: suggestedOutlierPercent}
+		}
+	}
+	return Tolerance{ColorDelta: 255}
+}
+
+// printRendererDrift writes a drift table followed by per-tag recommendations.
+func printRendererDrift(w io.Writer, drifts []RendererDrift) {
+	fmt.Fprintln(w, "🖥️  Renderer drift (software vs GL)")
+	fmt.Fprintln(w, "==================================")
+	for _, drift := range drifts {
+		if drift.Error != nil {
+			fmt.Fprintf(w, "❌ %s: %v\n", drift.Test.Name, drift.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s: %.2f%% pixels differ, max delta %d → suggest delta %d / %.1f%%\n",
+			drift.Test.Name, drift.Diff.DiffPercent, drift.Diff.MaxDelta,
+			drift.Suggested.ColorDelta, drift.Suggested.MaxDiffPercent)
+	}
+	
+	byTag := DriftByTag(drifts)
+	tags := make([]string, 0, len(byTag))
+	for tag := range byTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	
+	fmt.Fprintln(w, "\nSuggested tolerance per tag:")
+	for _, tag := range tags {
+		fmt.Fprintf(w, "- %s: ColorDelta %d, MaxDiffPercent %.1f\n", tag, byTag[tag].ColorDelta, byTag[tag].MaxDiffPercent)
+	}
+}
+
+func displayAvailable() bool {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+	default:
+		return true
+	}
+}
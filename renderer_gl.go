@@ -0,0 +1,29 @@
+//go:build gl
+
+package fynetest
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+)
+
+const glSupported = true
+
+// withGLApp runs fn on a goroutine of a GL app with the real driver. It
+// blocks the calling goroutine, which must be the main goroutine, until fn
+// returns.
+func withGLApp(fn func(glApp fyne.App)) error {
+	if !displayAvailable() {
+		return ErrGLUnavailable
+	}
+	
+	glApp := app.NewWithID("io.vfyne.gl")
+	glApp.Lifecycle().SetOnStarted(func() {
+		go func() {
+			defer glApp.Quit()
+			fn(glApp)
+		}()
+	})
+	glApp.Run()
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !gl
+
+package fynetest
+
+import "fyne.io/fyne/v2"
+
+const glSupported = false
+
+func withGLApp(fn func(glApp fyne.App)) error {
+	return ErrGLUnavailable
+}
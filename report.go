@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -25,15 +27,86 @@ type ReportGenerator struct {
 	
 	// CompactMode reduces report size by omitting some details
 	CompactMode bool
+	
+	// Output receives warnings (default: os.Stdout)
+	Output io.Writer
+	
+	// LazyAssets links review images for every compared test, for reports
+	// served with Runner.AssetHandler, which renders them on first request
+	LazyAssets bool
+	
+	// PageSize is how many cards of each section are rendered up front; the
+	// rest are added as the reader scrolls (0 renders every card at once)
+	PageSize int
+	
+	// ImageBaseURL, when set, is where the run directory is published, e.g.
+	// an uploaded CI artifact; the Markdown report links images under it
+	ImageBaseURL string
+	
+	// MinSharedTests is how many tests a widget subtree must appear in for
+	// the report to list it as a shared component (0 disables the
+	// components page)
+	MinSharedTests int
+	
+	// HistoryDir is the output root holding previous runs; when set, the
+	// report opens with trend charts over the last HistoryRuns of them and
+	// lists the flakiest tests among them
+	HistoryDir  string
+	HistoryRuns int
+	
+	// EmbedImages inlines screenshots and review images into the HTML as
+	// data URIs, so the report is a single file that can be emailed or
+	// attached to a ticket
+	EmbedImages bool
+	
+	// GroupBy splits the tests of each profile into collapsible groups with
+	// their own pass/fail counts (default: GroupByNone)
+	GroupBy string
+	
+	// Template is html/template text parsed over the default report
+	// template. Its {{define}} blocks replace the default blocks of the same
+	// name: "head" (extra tags in <head>), "header", "test-card" and
+	// "footer"; a non-empty body outside them replaces the whole page
+	Template string
+	
+	// TemplateFuncs are extra functions available to Template
+	TemplateFuncs template.FuncMap
+	
+	// ThumbnailMaxWidth is the widest capture the report shows at full
+	// size; wider ones are shown as thumbnails of this width, written next
+	// to them, that link to the full image (0 shows every image at full size)
+	ThumbnailMaxWidth int
+	
+	// Build is shown in the report's build panel and written to the JSON
+	// report (nil collects it with CollectBuildInfo)
+	Build *BuildInfo
+	
+	// InProgress marks the report as covering a run that hasn't finished:
+	// the page says so and reloads itself (see IncrementalReport)
+	InProgress bool
 }
 
+// Groupings of the report selectable with ReportGenerator.GroupBy.
+const (
+	// GroupByNone lists the tests of each profile in one flat list
+	GroupByNone = ""
+	
+	// GroupByTag groups tests by their primary tag, the first one they
+	// declare; untagged tests come last
+	GroupByTag = "tag"
+)
+
 // NewReportGenerator creates a new report generator with default settings.
 func NewReportGenerator() *ReportGenerator {
 	return &ReportGenerator{
-		Title:           "Fyne Visual Test Results",
-		StyleSheet:      defaultCSS,
-		IncludeMetadata: true,
-		CompactMode:     false,
+		Title:             "Fyne Visual Test Results",
+		StyleSheet:        defaultCSS,
+		IncludeMetadata:   true,
+		CompactMode:       false,
+		PageSize:          100,
+		MinSharedTests:    DefaultMinSharedTests,
+		HistoryRuns:       DefaultHistoryRuns,
+		ThumbnailMaxWidth: DefaultThumbnailMaxWidth,
 	}
 }
 
@@ -51,12 +124,31 @@ func (g *ReportGenerator) GenerateHTMLReport(results []Result, outputPath string
 	}
 	defer file.Close()
 	
-	tmpl, err := g.createTemplate()
+	tmpl, err := g.createTemplate(dir)
 	if err != nil {
 		return fmt.Errorf("failed to create template: %w", err)
 	}
 	
 	data := g.prepareTemplateData(results)
+	history := g.loadHistory(dir)
+	trend := trendPoints(results, history)
+	data.Trend, data.TrendRuns = trendCharts(trend), len(trend)
+	data.Flakiest = flakyScores(flakeScores(results, history), 0)
+	if len(data.Flakiest) > maxReportFlakes {
+		data.Flakiest = data.Flakiest[:maxReportFlakes]
+	}
+	
+	// Shared components get a page of their own, linked from the header
+	if g.MinSharedTests > 0 {
+		data.Components = FindSharedComponents(results, g.MinSharedTests)
+		if len(data.Components) > 0 {
+			if err := g.GenerateComponentsReport(data.Components, filepath.Join(dir, ComponentsReportName)); err != nil {
+				// Non-fatal error
+				fmt.Fprintf(g.output(), "Warning: Failed to generate components report: %v\n", err)
+				data.Components = nil
+			}
+		}
+	}
 	
 	if err := tmpl.Execute(file, data); err != nil {
 		return fmt.Errorf("failed to execute template: %w", err)
@@ -66,7 +158,7 @@ func (g *ReportGenerator) GenerateHTMLReport(results []Result, outputPath string
 	jsonPath := strings.TrimSuffix(outputPath, ".html") + ".json"
 	if err := g.GenerateJSONReport(results, jsonPath); err != nil {
 		// Non-fatal error
-		fmt.Printf("Warning: Failed to generate JSON report: %v\n", err)
+		fmt.Fprintf(g.output(), "Warning: Failed to generate JSON report: %v\n", err)
 	}
 	
 	return nil
@@ -84,58 +176,149 @@ func (g *ReportGenerator) GenerateJSONReport(results []Result, outputPath string
 	encoder.SetIndent("", "  ")
 	
 	report := JSONReport{
-		Title:     g.Title,
-		Timestamp: time.Now(),
-		Results:   make([]JSONResult, len(results)),
-		Summary:   g.createSummary(results),
+		Title:      g.Title,
+		Timestamp:  time.Now(),
+		Results:    make([]JSONResult, len(results)),
+		Summary:    g.createSummary(results),
+		Stats:      ComputeImageStats(results),
+		Flaky:      FlakyTests(results),
+		Build:      g.buildInfo(),
+		InProgress: g.InProgress,
 	}
+	report.FlakeScores = flakeScores(results, g.loadHistory(filepath.Dir(outputPath)))
 	
 	for i, result := range results {
-		report.Results[i] = JSONResult{
-			Name:           result.Test.Name,
-			Description:    result.Test.Description,
-			Tags:           result.Test.Tags,
-			Success:        result.Success,
-			Error:          "",
-			ScreenshotPath: filepath.Base(result.ScreenshotPath),
-			ImageSize:      result.ImageSize,
-			Duration:       result.Duration,
-			Timestamp:      result.Timestamp,
-			Metadata:       result.Metadata,
-		}
-		
-		if result.Error != nil {
-			report.Results[i].Error = result.Error.Error()
+		report.Results[i] = newJSONResult(result)
+		report.Results[i].ScreenshotPath = relativePath(filepath.Dir(outputPath), result.ScreenshotPath)
+		report.Results[i].CrashDump = relativePath(filepath.Dir(outputPath), result.CrashDump)
+		if assets := result.DiffAssets; assets != nil {
+			report.Results[i].DiffAssets = &DiffAssets{
+				Diff:      relativePath(filepath.Dir(outputPath), assets.Diff),
+				Heatmap:   relativePath(filepath.Dir(outputPath), assets.Heatmap),
+				Composite: relativePath(filepath.Dir(outputPath), assets.Composite),
+			}
 		}
 	}
 	
 	return encoder.Encode(report)
 }
 
-func (g *ReportGenerator) createTemplate() (*template.Template, error) {
+// newJSONResult converts a result into its JSON representation.
+func newJSONResult(result Result) JSONResult {
+	jsonResult := JSONResult{
+		Name:            result.Test.Name,
+		Description:     result.Test.Description,
+		Tags:            result.Test.Tags,
+		Success:         result.Success,
+		Skipped:         result.Skipped,
+		SkipReason:      result.SkipReason,
+		ExpectedFailure: result.ExpectedFailure,
+		UnexpectedPass:  result.UnexpectedPass,
+		Error:           "",
+		ScreenshotPath:  result.ScreenshotPath,
+		ImageSize:       result.ImageSize,
+		Duration:        result.Duration,
+		Overhead:        result.Overhead,
+		SetupDuration:   result.SetupDuration,
+		Output:          result.Output,
+		CrashDump:       result.CrashDump,
+		Timestamp:       result.Timestamp,
+		Metadata:        result.Metadata,
+		Profile:         result.Profile,
+		Caption:         result.Test.Caption(),
+		ScreenSummary:   result.ScreenSummary(),
+		AltText:         result.Test.AltText(),
+		Diff:            newJSONDiff(result.Diff),
+		WidgetChanges:   result.WidgetChanges,
+		WidgetTree:      result.WidgetTree,
+		Attempts:        result.Attempts,
+		Flaky:           result.Flaky(),
+		DiffAssets:      result.DiffAssets,
+	}
+	
+	if result.Error != nil {
+		jsonResult.Error = result.Error.Error()
+	}
+	return jsonResult
+}
+
+func (g *ReportGenerator) output() io.Writer {
+	if g.Output == nil {
+		return os.Stdout
+	}
+	return g.Output
+}
+
+func (g *ReportGenerator) createTemplate(baseDir string) (*template.Template, error) {
 	funcMap := template.FuncMap{
 		"formatDuration": formatDuration,
 		"formatTime":     formatTime,
+		"formatDelta":    formatDelta,
 		"basename":       filepath.Base,
+		"relpath":        func(path string) string { return relativePath(baseDir, path) },
 		"jsonify":        jsonify,
+		"asset":          AssetPath,
+		"imgsrc":         g.imageSource(baseDir, make(map[string]template.URL)),
+		"thumb":          g.thumbnailSource(make(map[string]string)),
+	}
+	for name, fn := range g.TemplateFuncs {
+		funcMap[name] = fn
 	}
 	
-	return template.New("report").Funcs(funcMap).Parse(htmlTemplate)
+	tmpl, err := template.New("report").Funcs(funcMap).Parse(htmlTemplate)
+	if err != nil || g.Template == "" {
+		return tmpl, err
+	}
+	// Blocks defined by the custom template replace the default ones
+	if _, err := tmpl.Parse(g.Template); err != nil {
+		return nil, fmt.Errorf("invalid custom template: %w", err)
+	}
+	return tmpl, nil
 }
 
 func (g *ReportGenerator) prepareTemplateData(results []Result) templateData {
+	sections := g.createSections(results)
+	for i := range sections {
+		if g.GroupBy == GroupByTag {
+			sections[i].Groups = g.createGroups(i, sections[i].Results)
+			continue
+		}
+		sections[i].Pages = g.paginate(fmt.Sprintf("test-%d", i), sections[i].Results)
+	}
+	
 	return templateData{
 		Title:           g.Title,
 		StyleSheet:      g.StyleSheet,
 		Timestamp:       time.Now(),
 		Results:         results,
+		Sections:        sections,
+		Overview:        g.createOverview(results),
 		Summary:         g.createSummary(results),
+		FailureGroups:   GroupedFailures(results),
+		Flaky:           FlakyTests(results),
+		Tags:            countTags(results),
 		IncludeMetadata: g.IncludeMetadata,
 		CompactMode:     g.CompactMode,
+		LazyAssets:      g.LazyAssets,
+		Build:           g.buildInfo(),
+		InProgress:      g.InProgress,
+	}
+}
+
+// buildInfo returns the build shown in the report.
+func (g *ReportGenerator) buildInfo() *BuildInfo {
+	if g.Build != nil {
+		return g.Build
 	}
+	info := CollectBuildInfo()
+	return &info
 }
 
 func (g *ReportGenerator) createSummary(results []Result) Summary {
+	return newSummary(results)
+}
+
+func newSummary(results []Result) Summary {
 	summary := Summary{
 		Total:    len(results),
 		Passed:   0,
@@ -144,21 +327,161 @@ func (g *ReportGenerator) createSummary(results []Result) Summary {
 	}
 	
 	for _, result := range results {
-		if result.Success {
+		switch {
+		case result.Skipped:
+			summary.Skipped++
+		case result.Success:
 			summary.Passed++
-		} else {
+		default:
 			summary.Failed++
 		}
+		if result.Flaky() {
+			summary.Flaky++
+		}
+		if result.ExpectedFailure {
+			summary.ExpectedFailures++
+		}
+		if result.UnexpectedPass {
+			summary.UnexpectedPasses++
+		}
 		summary.Duration += result.Duration
 	}
 	
-	if summary.Total > 0 {
-		summary.PassRate = float64(summary.Passed) / float64(summary.Total) * 100
+	// Skipped tests neither pass nor fail
+	if run := summary.Total - summary.Skipped; run > 0 {
+		summary.PassRate = float64(summary.Passed) / float64(run) * 100
 	}
 	
 	return summary
 }
 
+// createSections groups results by profile, keeping the order in which
+// profiles first appear. Runs without profiles produce a single unnamed section.
+func (g *ReportGenerator) createSections(results []Result) []reportSection {
+	sections := make([]reportSection, 0)
+	index := make(map[string]int)
+	
+	for _, result := range results {
+		i, ok := index[result.Profile]
+		if !ok {
+			i = len(sections)
+			index[result.Profile] = i
+			sections = append(sections, reportSection{Name: result.Profile})
+		}
+		sections[i].Results = append(sections[i].Results, result)
+	}
+	
+	for i := range sections {
+		sections[i].Summary = g.createSummary(sections[i].Results)
+	}
+	return sections
+}
+
+// createGroups splits the results of section by primary tag, sorted by tag
+// with untagged results last.
+func (g *ReportGenerator) createGroups(section int, results []Result) []reportGroup {
+	byTag := make(map[string][]Result)
+	names := make([]string, 0)
+	for _, result := range results {
+		tag := ""
+		if len(result.Test.Tags) > 0 {
+			tag = result.Test.Tags[0]
+		}
+		if _, ok := byTag[tag]; !ok {
+			names = append(names, tag)
+		}
+		byTag[tag] = append(byTag[tag], result)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == "" || names[j] == "" {
+			return names[j] == ""
+		}
+		return names[i] < names[j]
+	})
+	
+	groups := make([]reportGroup, len(names))
+	for i, name := range names {
+		id := fmt.Sprintf("group-%d-%d", section, i)
+		groups[i] = reportGroup{
+			Name:    name,
+			ID:      id,
+			Summary: g.createSummary(byTag[name]),
+			Pages:   g.paginate("test-"+id, byTag[name]),
+		}
+	}
+	return groups
+}
+
+// paginate splits results into pages of PageSize cards, with IDs starting
+// with prefix. Only the first page is part of the initial DOM; the report
+// script renders the others as they scroll into view, so huge suites stay
+// responsive.
+func (g *ReportGenerator) paginate(prefix string, results []Result) [][]reportCard {
+	size := g.PageSize
+	if size <= 0 {
+		size = len(results)
+	}
+	
+	pages := make([][]reportCard, 0)
+	for start := 0; start < len(results); start += size {
+		end := start + size
+		if end > len(results) {
+			end = len(results)
+		}
+		page := make([]reportCard, 0, end-start)
+		for j := start; j < end; j++ {
+			page = append(page, reportCard{
+				Result:          results[j],
+				ID:              fmt.Sprintf("%s-%d", prefix, j),
+				LazyAssets:      g.LazyAssets,
+				IncludeMetadata: g.IncludeMetadata,
+				CompactMode:     g.CompactMode,
+			})
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// createOverview builds the test × profile grid shown on the "All profiles"
+// tab. It returns nil when the results were not produced by named profiles.
+func (g *ReportGenerator) createOverview(results []Result) *overviewGrid {
+	grid := &overviewGrid{}
+	profileIndex := make(map[string]int)
+	rowIndex := make(map[string]int)
+	
+	for _, result := range results {
+		if result.Profile == "" {
+			continue
+		}
+		if _, ok := profileIndex[result.Profile]; !ok {
+			profileIndex[result.Profile] = len(grid.Profiles)
+			grid.Profiles = append(grid.Profiles, result.Profile)
+		}
+	}
+	if len(grid.Profiles) == 0 {
+		return nil
+	}
+	
+	for i := range results {
+		result := &results[i]
+		if result.Profile == "" {
+			continue
+		}
+		r, ok := rowIndex[result.Test.Name]
+		if !ok {
+			r = len(grid.Rows)
+			rowIndex[result.Test.Name] = r
+			grid.Rows = append(grid.Rows, overviewRow{
+				Name:  result.Test.Name,
+				Cells: make([]*Result, len(grid.Profiles)),
+			})
+		}
+		grid.Rows[r].Cells[profileIndex[result.Profile]] = result
+	}
+	return grid
+}
+
 // Template data structures
 
 type templateData struct {
@@ -166,17 +489,99 @@ type templateData struct {
 	StyleSheet      string
 	Timestamp       time.Time
 	Results         []Result
+	Sections        []reportSection
+	Overview        *overviewGrid
 	Summary         Summary
 	IncludeMetadata bool
 	CompactMode     bool
+	LazyAssets      bool
+	Components      []SharedComponent
+	FailureGroups   []FailureGroup
+	Flaky           []FlakyTest
+	Tags            []tagCount
+	Trend           []trendChart
+	TrendRuns       int
+	Flakiest        []FlakeScore
+	Build           *BuildInfo
+	InProgress      bool
+}
+
+// tagCount is a tag offered as a filter in the report, with the number of
+// results carrying it.
+type tagCount struct {
+	Name  string
+	Count int
+}
+
+// countTags returns the tags of results sorted by name.
+func countTags(results []Result) []tagCount {
+	counts := make(map[string]int)
+	for _, result := range results {
+		for _, tag := range result.Test.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]tagCount, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, tagCount{Name: name, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
+
+type reportSection struct {
+	Name    string
+	Results []Result
+	Pages   [][]reportCard
+	Summary Summary
+	
+	// Groups replace Pages when the report is grouped
+	Groups []reportGroup
+}
+
+// reportGroup is a collapsible group of cards within a section.
+type reportGroup struct {
+	Name    string
+	ID      string
+	Summary Summary
+	Pages   [][]reportCard
+}
+
+// reportCard is the data of one result card, rendered by the "test-card" template.
+type reportCard struct {
+	Result
+	ID              string
+	LazyAssets      bool
+	IncludeMetadata bool
+	CompactMode     bool
+}
+
+// overviewGrid lays out one row per test and one column per profile. Cells
+// are nil where a profile did not run the test.
+type overviewGrid struct {
+	Profiles []string
+	Rows     []overviewRow
+}
+
+type overviewRow struct {
+	Name  string
+	Cells []*Result
 }
 
 type Summary struct {
 	Total    int
 	Passed   int
 	Failed   int
+	Skipped  int
+	Flaky    int
 	PassRate float64
 	Duration time.Duration
+	
+	// ExpectedFailures counts the tests expected to fail that did, which
+	// are among Passed; UnexpectedPasses those that passed and need a
+	// baseline review
+	ExpectedFailures int
+	UnexpectedPasses int
 }
 
 // JSON report structures
@@ -186,19 +591,98 @@ type JSONReport struct {
 	Timestamp time.Time    `json:"timestamp"`
 	Results   []JSONResult `json:"results"`
 	Summary   Summary      `json:"summary"`
+	Stats     ImageStats   `json:"stats"`
+	
+	// Flaky lists the tests that passed only after a retry
+	Flaky []FlakyTest `json:"flaky,omitempty"`
+	
+	// FlakeScores scores each test over the runs in HistoryDir, flakiest
+	// first
+	FlakeScores []FlakeScore `json:"flake_scores,omitempty"`
+	
+	// Build is the commit and environment the run was made with
+	Build *BuildInfo `json:"build,omitempty"`
+	
+	// InProgress is set while the run is still adding results
+	InProgress bool `json:"in_progress,omitempty"`
 }
 
 type JSONResult struct {
-	Name           string                 `json:"name"`
-	Description    string                 `json:"description,omitempty"`
-	Tags           []string               `json:"tags,omitempty"`
-	Success        bool                   `json:"success"`
-	Error          string                 `json:"error,omitempty"`
-	ScreenshotPath string                 `json:"screenshot_path,omitempty"`
-	ImageSize      fyne.Size              `json:"image_size"`
-	Duration       time.Duration          `json:"duration"`
-	Timestamp      time.Time              `json:"timestamp"`
-	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	Name            string                 `json:"name"`
+	Description     string                 `json:"description,omitempty"`
+	Tags            []string               `json:"tags,omitempty"`
+	Success         bool                   `json:"success"`
+	Skipped         bool                   `json:"skipped,omitempty"`
+	SkipReason      string                 `json:"skip_reason,omitempty"`
+	ExpectedFailure bool                   `json:"expected_failure,omitempty"`
+	UnexpectedPass  bool                   `json:"unexpected_pass,omitempty"`
+	Error           string                 `json:"error,omitempty"`
+	ScreenshotPath  string                 `json:"screenshot_path,omitempty"`
+	ImageSize       fyne.Size              `json:"image_size"`
+	Duration        time.Duration          `json:"duration"`
+	Overhead        time.Duration          `json:"overhead"`
+	SetupDuration   time.Duration          `json:"setup_duration"`
+	Output          string                 `json:"output,omitempty"`
+	CrashDump       string                 `json:"crash_dump,omitempty"`
+	Timestamp       time.Time              `json:"timestamp"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	Profile         string                 `json:"profile,omitempty"`
+	Caption         string                 `json:"caption,omitempty"`
+	ScreenSummary   string                 `json:"screen_summary,omitempty"`
+	AltText         string                 `json:"alt_text"`
+	DiffAssets      *DiffAssets            `json:"diff_assets,omitempty"`
+	Diff            *JSONDiff              `json:"diff,omitempty"`
+	WidgetChanges   []WidgetChange         `json:"widget_changes,omitempty"`
+	WidgetTree      *WidgetNode            `json:"widget_tree,omitempty"`
+	Attempts        int                    `json:"attempts,omitempty"`
+	Flaky           bool                   `json:"flaky,omitempty"`
+}
+
+// JSONDiff holds the baseline comparison metrics of a result, so tooling can
+// rank regressions by severity.
+type JSONDiff struct {
+	Match        bool     `json:"match"`
+	SizeMismatch bool     `json:"size_mismatch,omitempty"`
+	DiffPixels   int      `json:"diff_pixels"`
+	TotalPixels  int      `json:"total_pixels"`
+	DiffPercent  float64  `json:"diff_percent"`
+	MaxDelta     uint8    `json:"max_delta"`
+	BoundingBox  *JSONBox `json:"bounding_box,omitempty"`
+	Similarity   float64  `json:"similarity,omitempty"`
+	Partial      bool     `json:"partial,omitempty"`
+	Severity     float64  `json:"severity,omitempty"`
+	WithinBudget bool     `json:"within_budget,omitempty"`
+}
+
+// JSONBox is a rectangle in image pixels.
+type JSONBox struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func newJSONDiff(diff *DiffResult) *JSONDiff {
+	if diff == nil {
+		return nil
+	}
+	
+	jsonDiff := &JSONDiff{
+		Match:        diff.Match,
+		SizeMismatch: diff.SizeMismatch,
+		DiffPixels:   diff.DiffPixels,
+		TotalPixels:  diff.TotalPixels,
+		DiffPercent:  diff.DiffPercent,
+		MaxDelta:     diff.MaxDelta,
+		Similarity:   diff.Similarity,
+		Partial:      diff.Partial,
+		Severity:     diff.Severity,
+		WithinBudget: diff.WithinBudget,
+	}
+	if b := diff.Bounds; !b.Empty() {
+		jsonDiff.BoundingBox = &JSONBox{X: b.Min.X, Y: b.Min.Y, Width: b.Dx(), Height: b.Dy()}
+	}
+	return jsonDiff
 }
 
 // Helper functions
@@ -217,6 +701,19 @@ func formatTime(t time.Time) string {
 	return t.Format("2006-01-02 15:04:05")
 }
 
+// relativePath returns path relative to baseDir using forward slashes, as
+// needed for links in the HTML report. It falls back to the base name.
+func relativePath(baseDir, path string) string {
+	if path == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(baseDir, path)
+	if err != nil {
+		return filepath.Base(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
 func jsonify(v interface{}) string {
 	b, _ := json.Marshal(v)
 	return string(b)
@@ -228,16 +725,34 @@ const htmlTemplate = `<!DOCTYPE html>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>{{.Title}}</title>
+    {{if .InProgress}}<meta http-equiv="refresh" content="5">{{end}}
     <style>
 {{.StyleSheet}}
     </style>
+    {{block "head" .}}{{end}}
 </head>
 <body>
-    <div class="header">
+    <a class="skip-link" href="#results">Skip to results</a>
+
+    {{block "header" .}}
+    <header class="header">
         <h1>{{.Title}}</h1>
-        <p class="timestamp">Generated: {{formatTime .Timestamp}}</p>
+        <p class="timestamp">Generated: <time datetime="{{.Timestamp.Format "2006-01-02T15:04:05Z07:00"}}">{{formatTime .Timestamp}}</time>{{with .Build}}{{with .Commit}} · <code title="{{.}}">{{$.Build.ShortCommit}}</code>{{end}}{{end}}</p>
+        {{with .Build}}
+        <details class="build-info">
+            <summary>Build and environment</summary>
+            <dl>
+                {{with .Commit}}<dt>Commit</dt><dd><code>{{.}}</code>{{if $.Build.Dirty}} (uncommitted changes){{end}}</dd>{{end}}
+                {{with .Branch}}<dt>Branch</dt><dd>{{.}}</dd>{{end}}
+                <dt>Go</dt><dd>{{.GoVersion}}</dd>
+                {{with .FyneVersion}}<dt>Fyne</dt><dd>{{.}}</dd>{{end}}
+                <dt>OS</dt><dd>{{.OS}}/{{.Arch}}</dd>
+                {{with .Scale}}<dt>Display scale</dt><dd>{{.}}</dd>{{end}}
+            </dl>
+        </details>
+        {{end}}
         
-        <div class="summary">
+        <section class="summary" aria-label="Summary">
             <div class="summary-card">
                 <div class="summary-value">{{.Summary.Total}}</div>
                 <div class="summary-label">Total Tests</div>
@@ -250,6 +765,30 @@ const htmlTemplate = `<!DOCTYPE html>
                 <div class="summary-value">{{.Summary.Failed}}</div>
                 <div class="summary-label">Failed</div>
             </div>
+            {{if .Summary.Skipped}}
+            <div class="summary-card skipped">
+                <div class="summary-value">{{.Summary.Skipped}}</div>
+                <div class="summary-label">Skipped</div>
+            </div>
+            {{end}}
+            {{if .Summary.ExpectedFailures}}
+            <div class="summary-card xfail">
+                <div class="summary-value">{{.Summary.ExpectedFailures}}</div>
+                <div class="summary-label">Expected Failures</div>
+            </div>
+            {{end}}
+            {{if .Summary.UnexpectedPasses}}
+            <div class="summary-card xpass">
+                <div class="summary-value">{{.Summary.UnexpectedPasses}}</div>
+                <div class="summary-label">Needs Baseline Review</div>
+            </div>
+            {{end}}
+            {{if .Summary.Flaky}}
+            <div class="summary-card flaky">
+                <div class="summary-value">{{.Summary.Flaky}}</div>
+                <div class="summary-label">Flaky</div>
+            </div>
+            {{end}}
             <div class="summary-card">
                 <div class="summary-value">{{printf "%.1f%%" .Summary.PassRate}}</div>
                 <div class="summary-label">Pass Rate</div>
@@ -258,104 +797,480 @@ const htmlTemplate = `<!DOCTYPE html>
                 <div class="summary-value">{{formatDuration .Summary.Duration}}</div>
                 <div class="summary-label">Total Duration</div>
             </div>
-        </div>
-    </div>
+        </section>
+        {{with .Trend}}
+        <section class="trends" aria-labelledby="trends-title">
+            <h2 class="trends-title" id="trends-title">Last {{$.TrendRuns}} runs</h2>
+            {{range .}}
+            <figure class="trend">
+                <figcaption><span class="summary-label">{{.Title}}</span> <strong>{{.Latest}}</strong></figcaption>
+                <svg viewBox="0 0 240 60" role="img" aria-label="{{.Title}} over the last {{$.TrendRuns}} runs, now {{.Latest}}">
+                    <polyline points="{{.Points}}"/>
+                    {{range .Dots}}<circle cx="{{.X}}" cy="{{.Y}}" r="2.5"><title>{{.Label}}</title></circle>{{end}}
+                </svg>
+            </figure>
+            {{end}}
+        </section>
+        {{end}}
+        {{with .Components}}
+        <p class="components-link"><a href="components.html">🧩 {{len .}} shared components{{with (index . 0).FailedTests}}; {{.}} failing tests share a changed one{{end}}</a></p>
+        {{end}}
+    </header>
+    {{end}}
+    {{if .InProgress}}
+    <p class="in-progress" role="status"><span aria-hidden="true">⏳</span> Run in progress: showing the {{.Summary.Total}} tests finished so far. This page reloads itself until the run ends.</p>
+    {{end}}
 
-    <div class="filters">
-        <button class="filter-btn active" onclick="filterTests('all')">All Tests</button>
-        <button class="filter-btn" onclick="filterTests('passed')">Passed Only</button>
-        <button class="filter-btn" onclick="filterTests('failed')">Failed Only</button>
+    <nav class="filters" aria-label="Filter tests by status">
+        <button type="button" class="filter-btn active" aria-pressed="true" data-filter="all" onclick="filterTests(this)">All Tests</button>
+        <button type="button" class="filter-btn" aria-pressed="false" data-filter="passed" onclick="filterTests(this)">Passed Only</button>
+        <button type="button" class="filter-btn" aria-pressed="false" data-filter="failed" onclick="filterTests(this)">Failed Only</button>
+        {{if .Summary.Skipped}}<button type="button" class="filter-btn" aria-pressed="false" data-filter="skipped" onclick="filterTests(this)">Skipped Only</button>{{end}}
+        <label class="search">
+            <span class="visually-hidden">Search tests</span>
+            <input type="search" id="search" placeholder="Search names and descriptions" oninput="applyFilters()">
+        </label>
+        <span id="filter-status" class="visually-hidden" role="status" aria-live="polite"></span>
+    </nav>
+    {{with .Tags}}
+    <div class="tag-filters" role="group" aria-label="Filter by tag">
+        {{range .}}
+        <button type="button" class="tag-btn" aria-pressed="false" data-tag="{{.Name}}" onclick="toggleTag(this.dataset.tag)">{{.Name}} <span class="tag-count">{{.Count}}</span></button>
+        {{end}}
     </div>
+    {{end}}
 
-    <div class="tests">
-        {{range .Results}}
-        <div class="test {{if .Success}}success{{else}}failure{{end}}" data-status="{{if .Success}}passed{{else}}failed{{end}}">
-            <div class="test-header">
-                <h2>{{.Test.Name}}</h2>
-                <div class="test-status-badge {{if .Success}}success{{else}}failure{{end}}">
-                    {{if .Success}}✅ PASS{{else}}❌ FAIL{{end}}
-                </div>
-            </div>
-            
-            {{if .Test.Description}}
-            <p class="description">{{.Test.Description}}</p>
-            {{end}}
-            
-            {{if .Test.Tags}}
-            <div class="tags">
-                {{range .Test.Tags}}
-                <span class="tag">{{.}}</span>
+    {{with .FailureGroups}}
+    <section class="failure-groups" aria-labelledby="failure-groups-title">
+        <h2 class="section-title" id="failure-groups-title">{{len .}} failure groups affecting {{$.Summary.Failed}} tests</h2>
+        {{range $i, $g := .}}
+        {{$r := $g.Representative}}
+        <details class="failure-group"{{if eq $i 0}} open{{end}}>
+            <summary><strong>{{len $g.Results}} {{if eq (len $g.Results) 1}}test{{else}}tests{{end}}:</strong> {{$g.Summary}}</summary>
+            <figure class="screenshot-container">
+                {{if and $r.DiffAssets $r.DiffAssets.Composite}}
+                <img src="{{imgsrc $r.DiffAssets.Composite}}" alt="Expected, actual and diff: {{$r.Test.AltText}}" loading="lazy">
+                {{else if $r.ScreenshotPath}}
+                <img src="{{imgsrc $r.ScreenshotPath}}" alt="{{$r.Test.AltText}}" loading="lazy">
                 {{end}}
-            </div>
-            {{end}}
-            
-            <div class="test-details">
-                <span class="detail">⏱️ {{formatDuration .Duration}}</span>
-                <span class="detail">📅 {{formatTime .Timestamp}}</span>
-                {{if .Success}}
-                <span class="detail">📐 {{.ImageSize.Width}}×{{.ImageSize.Height}}px</span>
-                {{end}}
-            </div>
-            
-            {{if .Success}}
-            <div class="screenshot-container">
-                <img src="{{basename .ScreenshotPath}}" alt="{{.Test.Name}} screenshot" loading="lazy">
-            </div>
-            {{else if .Error}}
-            <div class="error-box">
-                <strong>Error:</strong> {{.Error}}
-            </div>
+                <figcaption class="caption">Example: {{$r.Test.Name}}{{with $r.Profile}} ({{.}}){{end}}</figcaption>
+            </figure>
+            <ul class="failure-group-tests">
+                {{range $g.Results}}<li>{{.Test.Name}}{{with .Profile}} ({{.}}){{end}}</li>{{end}}
+            </ul>
+        </details>
+        {{end}}
+    </section>
+    {{end}}
+
+    {{with .Flaky}}
+    <section class="flaky-tests" aria-labelledby="flaky-title">
+        <h2 class="section-title" id="flaky-title"><span aria-hidden="true">🔁</span> {{len .}} flaky {{if eq (len .) 1}}test{{else}}tests{{end}} passed only after a retry</h2>
+        <ul>
+            {{range .}}<li><strong>{{.Name}}</strong>{{with .Profile}} ({{.}}){{end}} · passed on attempt {{.Attempts}}</li>{{end}}
+        </ul>
+    </section>
+    {{end}}
+
+    {{with .Flakiest}}
+    <section class="flaky-tests flake-scores" aria-labelledby="flake-scores-title">
+        <h2 class="section-title" id="flake-scores-title"><span aria-hidden="true">🎲</span> Flakiest tests over the last {{$.TrendRuns}} runs</h2>
+        <ul>
+            {{range .}}<li><strong>{{.Name}}</strong>{{with .Profile}} ({{.}}){{end}} · flake score {{printf "%.2f" .Score}} · failed {{.Failures}} of {{.Runs}} runs, {{.Flips}} status {{if eq .Flips 1}}flip{{else}}flips{{end}}{{if .Retried}}, {{.Retried}} passed on retry{{end}}{{if .DiffStdDev}}, diff {{printf "%.2f" .DiffMean}}% ± {{printf "%.2f" .DiffStdDev}}{{end}}<br><small>{{.Hint}}</small></li>{{end}}
+        </ul>
+    </section>
+    {{end}}
+
+    {{if .Overview}}
+    <div class="tabs" role="tablist" aria-label="Profiles">
+        <button type="button" class="tab-btn active" role="tab" id="tab-overview" aria-selected="true" aria-controls="overview" data-tab="overview" onclick="showTab('overview')">All profiles</button>
+        {{range $i, $s := .Sections}}
+        <button type="button" class="tab-btn" role="tab" id="tab-profile-{{$i}}" aria-selected="false" aria-controls="profile-{{$i}}" tabindex="-1" data-tab="profile-{{$i}}" onclick="showTab('profile-{{$i}}')">
+            {{if $s.Name}}{{$s.Name}}{{else}}Default{{end}}
+            <span class="tab-count {{if $s.Summary.Failed}}failure{{end}}" aria-label="{{$s.Summary.Passed}} of {{$s.Summary.Total}} passed">{{$s.Summary.Passed}}/{{$s.Summary.Total}}</span>
+        </button>
+        {{end}}
+    </div>
+    {{end}}
+
+    <main class="tests" id="results" tabindex="-1">
+        {{with .Overview}}
+        <section class="section tab-panel" id="overview" role="tabpanel" aria-labelledby="tab-overview">
+            <table class="overview">
+                <caption class="visually-hidden">Screenshots of every test in each profile</caption>
+                <thead>
+                    <tr>
+                        <th scope="col">Test</th>
+                        {{range .Profiles}}<th scope="col">{{.}}</th>{{end}}
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Rows}}
+                    <tr>
+                        <th scope="row">{{.Name}}</th>
+                        {{range $i, $cell := .Cells}}
+                        {{if $cell}}
+                        <td class="overview-cell {{if $cell.Skipped}}skipped{{else if $cell.Success}}success{{else}}failure{{end}}" data-status="{{if $cell.Skipped}}skipped{{else if $cell.Success}}passed{{else}}failed{{end}}" data-tags="{{jsonify $cell.Test.Tags}}" data-search="{{$cell.Test.Name}} {{$cell.Test.Description}}">
+                            {{if $cell.Skipped}}
+                            <span class="overview-skip" title="{{$cell.SkipReason}}"><span aria-hidden="true">⏭️</span> SKIP<span class="visually-hidden">{{with $cell.SkipReason}}: {{.}}{{end}}</span></span>
+                            {{else if $cell.ExpectedFailure}}
+                            <span class="overview-xfail" title="{{$cell.Error}}"><span aria-hidden="true">🟡</span> XFAIL<span class="visually-hidden">: {{$cell.Error}}</span></span>
+                            {{else if $cell.Success}}
+                            <a href="{{relpath $cell.ScreenshotPath}}" target="_blank">
+                                <img src="{{imgsrc (thumb $cell.ScreenshotPath)}}" alt="{{$cell.Test.AltText}} ({{$cell.Profile}})" loading="lazy">
+                            </a>
+                            {{else}}
+                            <span class="overview-error" title="{{$cell.Error}}"><span aria-hidden="true">❌</span> FAIL<span class="visually-hidden">: {{$cell.Error}}</span></span>
+                            {{end}}
+                        </td>
+                        {{else}}
+                        <td class="overview-cell empty"><span aria-hidden="true">–</span><span class="visually-hidden">Not run</span></td>
+                        {{end}}
+                        {{end}}
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </section>
+        {{end}}
+        {{range $i, $s := .Sections}}
+        <section class="section{{if $.Overview}} tab-panel{{end}}" id="profile-{{$i}}"{{if $.Overview}} role="tabpanel" aria-labelledby="tab-profile-{{$i}}" hidden{{else}} aria-label="Results"{{end}}>
+            {{if .Name}}
+            <h2 class="section-title">{{.Name}} <span class="section-summary">{{.Summary.Passed}}/{{.Summary.Total}} passed{{with .Summary.Skipped}}, {{.}} skipped{{end}}</span></h2>
             {{end}}
-            
-            {{if and $.IncludeMetadata .Metadata}}
-            <details class="metadata">
-                <summary>Metadata</summary>
-                <pre>{{jsonify .Metadata}}</pre>
+            {{range .Groups}}
+            <details class="report-group" id="{{.ID}}"{{if .Summary.Failed}} open{{end}}>
+                <summary>
+                    <span class="report-group-name">{{if .Name}}{{.Name}}{{else}}Untagged{{end}}</span>
+                    <span class="section-summary">{{.Summary.Passed}}/{{.Summary.Total}} passed{{with .Summary.Failed}}, <strong class="report-group-failed">{{.}} failed</strong>{{end}}{{with .Summary.Skipped}}, {{.}} skipped{{end}}</span>
+                </summary>
+                {{template "pages" .Pages}}
             </details>
+            {{else}}
+            {{template "pages" .Pages}}
             {{end}}
-        </div>
+        </section>
         {{end}}
-    </div>
+    </main>
+    {{block "footer" .}}{{end}}
 
     <script>
-    function filterTests(filter) {
-        const tests = document.querySelectorAll('.test');
-        const buttons = document.querySelectorAll('.filter-btn');
+    function showTab(id) {
+        document.querySelectorAll('.tab-panel').forEach(panel => {
+            panel.hidden = panel.id !== id;
+        });
+        document.querySelectorAll('.tab-btn').forEach(btn => {
+            const selected = btn.dataset.tab === id;
+            btn.classList.toggle('active', selected);
+            btn.setAttribute('aria-selected', selected);
+            btn.tabIndex = selected ? 0 : -1;
+        });
+    }
+    
+    // Active filters: status, tags a test must all carry and search text
+    const filters = { status: 'all', tags: new Set(), query: '' };
+    
+    function matches(test) {
+        if (filters.status !== 'all' && test.dataset.status !== filters.status) {
+            return false;
+        }
+        if (filters.tags.size > 0) {
+            const tags = JSON.parse(test.dataset.tags || 'null') || [];
+            for (const tag of filters.tags) {
+                if (!tags.includes(tag)) return false;
+            }
+        }
+        return !filters.query || (test.dataset.search || '').toLowerCase().includes(filters.query);
+    }
+    
+    function filtering() {
+        return filters.status !== 'all' || filters.tags.size > 0 || filters.query !== '';
+    }
+    
+    // Renders the next hidden page of cards in section, keeping the active filters
+    function renderPage(section) {
+        const page = section.querySelector('template.card-page');
+        if (!page) return false;
         
-        buttons.forEach(btn => btn.classList.remove('active'));
-        event.target.classList.add('active');
+        page.content.querySelectorAll('.test').forEach(test => {
+            if (!matches(test)) {
+                test.style.display = 'none';
+            }
+        });
+        page.replaceWith(page.content);
+        return true;
+    }
+    
+    function renderAllPages() {
+        document.querySelectorAll('.section').forEach(section => {
+            while (renderPage(section)) {}
+        });
+    }
+    
+    function filterTests(button) {
+        filters.status = button.dataset.filter;
+        document.querySelectorAll('.filter-btn').forEach(btn => {
+            btn.classList.toggle('active', btn === button);
+            btn.setAttribute('aria-pressed', btn === button);
+        });
+        applyFilters();
+    }
+    
+    function toggleTag(tag) {
+        if (!filters.tags.delete(tag)) {
+            filters.tags.add(tag);
+        }
+        document.querySelectorAll('.tag-btn').forEach(btn => {
+            const active = filters.tags.has(btn.dataset.tag);
+            btn.classList.toggle('active', active);
+            btn.setAttribute('aria-pressed', active);
+        });
+        applyFilters();
+    }
+    
+    function applyFilters() {
+        filters.query = document.getElementById('search').value.trim().toLowerCase();
+        if (filtering()) {
+            renderAllPages();
+        }
+        const tests = document.querySelectorAll('.test, .overview-cell[data-status]');
         
+        let shown = 0;
         tests.forEach(test => {
-            if (filter === 'all') {
-                test.style.display = 'block';
-            } else if (filter === 'passed' && test.dataset.status === 'passed') {
-                test.style.display = 'block';
-            } else if (filter === 'failed' && test.dataset.status === 'failed') {
-                test.style.display = 'block';
+            if (matches(test)) {
+                test.style.visibility = 'visible';
+                test.style.display = '';
+                if (test.tagName !== 'TD') {
+                    shown++;
+                }
+            } else if (test.tagName === 'TD') {
+                test.style.visibility = 'hidden';
             } else {
                 test.style.display = 'none';
             }
         });
+        
+        // Hide groups left empty by the filters and open the others
+        document.querySelectorAll('.report-group').forEach(group => {
+            const tests = Array.from(group.querySelectorAll('.test'));
+            group.hidden = !tests.some(test => test.style.display !== 'none');
+            if (filtering() && !group.hidden) {
+                group.open = true;
+            }
+        });
+        
+        document.getElementById('filter-status').textContent = 'Showing ' + shown + ' tests';
     }
     
-    // Add click-to-zoom for images
+    // Render further pages of cards as the end of a section scrolls into view
     document.addEventListener('DOMContentLoaded', function() {
-        const images = document.querySelectorAll('.screenshot-container img');
-        images.forEach(img => {
-            img.addEventListener('click', function() {
-                window.open(this.src, '_blank');
+        const sentinels = document.querySelectorAll('.page-sentinel');
+        if (!('IntersectionObserver' in window)) {
+            renderAllPages();
+            return;
+        }
+        const observer = new IntersectionObserver(entries => {
+            entries.forEach(entry => {
+                if (!entry.isIntersecting) return;
+                // Observing again re-checks the sentinel after the page pushed it down
+                observer.unobserve(entry.target);
+                if (renderPage(entry.target.parentElement)) {
+                    observer.observe(entry.target);
+                }
+            });
+        }, { rootMargin: '1000px' });
+        sentinels.forEach(sentinel => observer.observe(sentinel));
+    });
+    
+    // Arrow key navigation between profile tabs
+    document.addEventListener('DOMContentLoaded', function() {
+        const tabs = Array.from(document.querySelectorAll('.tab-btn'));
+        tabs.forEach((tab, i) => {
+            tab.addEventListener('keydown', function(e) {
+                let next = -1;
+                if (e.key === 'ArrowRight') next = (i + 1) % tabs.length;
+                if (e.key === 'ArrowLeft') next = (i - 1 + tabs.length) % tabs.length;
+                if (e.key === 'Home') next = 0;
+                if (e.key === 'End') next = tabs.length - 1;
+                if (next < 0) return;
+                
+                e.preventDefault();
+                showTab(tabs[next].dataset.tab);
+                tabs[next].focus();
             });
         });
     });
     </script>
 </body>
-</html>`
+</html>
+{{define "pages"}}
+{{range $p, $page := .}}
+{{if $p}}<template class="card-page">{{end}}
+{{range $page}}{{template "test-card" .}}{{end}}
+{{if $p}}</template>{{end}}
+{{end}}
+{{if gt (len .) 1}}<div class="page-sentinel" aria-hidden="true"></div>{{end}}
+{{end}}
+{{define "test-card"}}
+<article class="test {{if .Skipped}}skipped{{else if .Success}}success{{else}}failure{{end}}" data-status="{{if .Skipped}}skipped{{else if .Success}}passed{{else}}failed{{end}}" data-tags="{{jsonify .Test.Tags}}" data-search="{{.Test.Name}} {{.Test.Description}}" aria-labelledby="{{.ID}}">
+    <div class="test-header">
+        <h3 id="{{.ID}}">{{.Test.Name}}</h3>
+        <div class="test-status-badge {{if .Skipped}}skipped{{else if .ExpectedFailure}}xfail{{else if .UnexpectedPass}}xpass{{else if .Success}}success{{else}}failure{{end}}">
+            {{if .Skipped}}<span aria-hidden="true">⏭️</span> SKIP{{else if .ExpectedFailure}}<span aria-hidden="true">🟡</span> XFAIL{{else if .UnexpectedPass}}<span aria-hidden="true">👀</span> XPASS{{else if .Success}}<span aria-hidden="true">✅</span> PASS{{else}}<span aria-hidden="true">❌</span> FAIL{{end}}
+        </div>
+    </div>
+
+    {{if .Test.Description}}
+    <p class="description">{{.Test.Description}}</p>
+    {{end}}
+
+    {{if .Test.Tags}}
+    <ul class="tags" aria-label="Tags">
+        {{range .Test.Tags}}
+        <li><button type="button" class="tag" data-tag="{{.}}" onclick="toggleTag(this.dataset.tag)" title="Show only tests tagged {{.}}">{{.}}</button></li>
+        {{end}}
+    </ul>
+    {{end}}
+
+    <div class="test-details">
+        <span class="detail"><span aria-hidden="true">⏱️</span><span class="visually-hidden">Duration:</span> {{formatDuration .Duration}}</span>
+        {{if .SetupDuration}}
+        <span class="detail"><span aria-hidden="true">🧰</span><span class="visually-hidden">Setup:</span> setup {{formatDuration .SetupDuration}}</span>
+        {{end}}
+        <span class="detail"><span aria-hidden="true">📅</span><span class="visually-hidden">Run at:</span> {{formatTime .Timestamp}}</span>
+        {{if and .Success (not .Skipped)}}
+        <span class="detail"><span aria-hidden="true">📐</span><span class="visually-hidden">Size:</span> {{.ImageSize.Width}}×{{.ImageSize.Height}}px</span>
+        {{end}}
+        {{if gt .Attempts 1}}
+        <span class="detail{{if .Success}} flaky{{end}}"><span aria-hidden="true">🔁</span> {{if .Success}}flaky: passed on attempt {{.Attempts}}{{else}}failed all {{.Attempts}} attempts{{end}}</span>
+        {{end}}
+        {{with .Diff}}{{if .DiffPixels}}
+        <span class="detail"><span aria-hidden="true">🔍</span><span class="visually-hidden">Changed:</span> {{printf "%.2f" .DiffPercent}}% of pixels (max Δ {{.MaxDelta}}{{if .Severity}}, severity {{printf "%.2f" .Severity}}{{end}})</span>
+        {{if .WithinBudget}}<span class="detail within-budget"><span aria-hidden="true">🪙</span> within change budget</span>{{end}}
+        {{end}}{{end}}
+    </div>
+
+    {{if .UnexpectedPass}}
+    <p class="unexpected-pass"><strong>Needs baseline review:</strong> expected to fail but passed; the regression may be fixed</p>
+    {{end}}
+    {{if .Skipped}}
+    <p class="skip-reason"><strong>Skipped</strong>{{with .SkipReason}}: {{.}}{{end}}</p>
+    {{else if and .Success (not .ExpectedFailure)}}
+    <figure class="screenshot-container">
+        <a href="{{relpath .ScreenshotPath}}" target="_blank">
+            <img src="{{imgsrc (thumb .ScreenshotPath)}}" alt="{{.Test.AltText}}" loading="lazy">
+        </a>
+        {{with .Test.Caption}}
+        <figcaption class="caption">{{.}}</figcaption>
+        {{else}}{{with .ScreenSummary}}
+        <figcaption class="caption draft-caption"><span class="summary-label">Draft caption:</span> {{.}}</figcaption>
+        {{end}}{{end}}
+    </figure>
+    {{if .LazyAssets}}
+    <p class="asset-links">
+        {{if .Diff}}
+        <a href="{{relpath (asset .ScreenshotPath "heatmap")}}" target="_blank">Heatmap</a>
+        <a href="{{relpath (asset .ScreenshotPath "composite")}}" target="_blank">Side by side</a>
+        {{end}}
+        <a href="/_timeline/?test={{.Test.Name}}&amp;profile={{.Profile}}" target="_blank">Timeline</a>
+    </p>
+    {{end}}
+    {{else if .Error}}
+    <div class="error-box">
+        <strong>{{if .ExpectedFailure}}Expected failure{{else}}Error{{end}}:</strong> {{.Error}}
+        {{with .CrashDump}}<p class="asset-links"><a href="{{relpath .}}" target="_blank">Crash dump</a></p>{{end}}
+    </div>
+    {{with .WidgetChanges}}
+    <details class="widget-changes" open>
+        <summary>Widget changes ({{len .}})</summary>
+        <ul>
+            {{range .}}
+            <li class="widget-change {{.Kind}}"><strong>{{.Kind}}</strong> <code>{{.Path}}</code>{{range .Details}} · {{.}}{{end}}</li>
+            {{end}}
+        </ul>
+    </details>
+    {{end}}
+    {{with .DiffAssets}}
+    <figure class="screenshot-container">
+        <a href="{{relpath .Composite}}" target="_blank">
+            <img src="{{imgsrc (thumb .Composite)}}" alt="Expected, actual and diff: {{$.Test.AltText}}" loading="lazy">
+        </a>
+        <figcaption class="asset-links">
+            {{with .Diff}}<a href="{{relpath .}}" target="_blank">Diff</a>{{end}}
+            {{with .Heatmap}}<a href="{{relpath .}}" target="_blank">Heatmap</a>{{end}}
+        </figcaption>
+    </figure>
+    {{end}}
+    {{end}}
+
+    {{with .Output}}
+    <details class="metadata output"{{if not $.Success}} open{{end}}>
+        <summary>Setup output</summary>
+        <pre>{{.}}</pre>
+    </details>
+    {{end}}
+
+    {{if and .WidgetTree (not .CompactMode)}}
+    <details class="widget-tree">
+        <summary>Widget tree ({{.WidgetTree.Count}} objects)</summary>
+        <ul>{{template "widget-node" .WidgetTree}}</ul>
+    </details>
+    {{end}}
+
+    {{if and .IncludeMetadata .Metadata}}
+    <details class="metadata">
+        <summary>Metadata</summary>
+        <pre>{{jsonify .Metadata}}</pre>
+    </details>
+    {{end}}
+</article>
+{{end}}
+{{define "widget-node"}}
+<li{{if .Hidden}} class="hidden-node"{{end}}>
+    <code>{{.Type}}</code>{{with .Name}} <strong>#{{.}}</strong>{{end}}{{with .Text}} “{{.}}”{{end}}
+    <span class="node-geometry">({{printf "%g" .Position.X}}, {{printf "%g" .Position.Y}}) {{printf "%g" .Size.Width}}×{{printf "%g" .Size.Height}}</span>
+    {{range $key, $value := .Props}}<span class="node-prop">{{$key}}={{$value}}</span> {{end}}{{if .Hidden}}<span class="node-prop">hidden</span>{{end}}
+    {{with .Children}}<ul>{{range .}}{{template "widget-node" .}}{{end}}</ul>{{end}}
+</li>
+{{end}}`
 
 const defaultCSS = `
         * {
             box-sizing: border-box;
         }
         
+        :focus-visible {
+            outline: 3px solid #f59e0b;
+            outline-offset: 2px;
+        }
+        
+        .visually-hidden {
+            position: absolute;
+            width: 1px;
+            height: 1px;
+            padding: 0;
+            margin: -1px;
+            overflow: hidden;
+            clip: rect(0, 0, 0, 0);
+            white-space: nowrap;
+            border: 0;
+        }
+        
+        .skip-link {
+            position: absolute;
+            left: 1rem;
+            top: -3rem;
+            background: white;
+            color: #2d3748;
+            padding: 0.5rem 1rem;
+            border-radius: 6px;
+            z-index: 10;
+        }
+        
+        .skip-link:focus {
+            top: 1rem;
+        }
+        
         body {
             font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, "Helvetica Neue", Arial, sans-serif;
             margin: 0;
@@ -410,6 +1325,17 @@ const defaultCSS = `
             border-color: rgba(220, 53, 69, 0.3);
         }
         
+        .summary-card.skipped {
+            background: rgba(108, 117, 125, 0.2);
+            border-color: rgba(108, 117, 125, 0.3);
+        }
+        
+        .summary-card.xfail,
+        .summary-card.xpass {
+            background: rgba(245, 158, 11, 0.2);
+            border-color: rgba(245, 158, 11, 0.3);
+        }
+        
         .summary-value {
             font-size: 2rem;
             font-weight: bold;
@@ -450,6 +1376,228 @@ const defaultCSS = `
             border-color: #667eea;
         }
         
+        .tabs {
+            background: white;
+            padding: 0 2rem;
+            display: flex;
+            gap: 0.25rem;
+            overflow-x: auto;
+            border-bottom: 1px solid #e1e4e8;
+        }
+        
+        .tab-btn {
+            background: transparent;
+            border: none;
+            border-bottom: 3px solid transparent;
+            padding: 0.75rem 1rem;
+            cursor: pointer;
+            font-size: 0.875rem;
+            white-space: nowrap;
+            color: #4a5568;
+        }
+        
+        .tab-btn.active {
+            border-bottom-color: #667eea;
+            color: #2d3748;
+            font-weight: 600;
+        }
+        
+        .tab-count {
+            margin-left: 0.25rem;
+            padding: 0 0.5rem;
+            border-radius: 9999px;
+            background: #d4edda;
+            color: #155724;
+            font-size: 0.75rem;
+        }
+        
+        .tab-count.failure {
+            background: #f8d7da;
+            color: #721c24;
+        }
+        
+        .overview {
+            width: 100%;
+            border-collapse: collapse;
+            background: white;
+            border-radius: 12px;
+            overflow: hidden;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.05);
+        }
+        
+        .overview th,
+        .overview td {
+            padding: 0.5rem;
+            border-bottom: 1px solid #e1e4e8;
+            text-align: center;
+            vertical-align: middle;
+        }
+        
+        .overview tbody th {
+            text-align: left;
+            color: #2d3748;
+        }
+        
+        .overview-cell img {
+            max-width: 160px;
+            max-height: 120px;
+            border: 1px solid #e1e4e8;
+            border-radius: 4px;
+            cursor: zoom-in;
+        }
+        
+        .overview-cell.failure {
+            background: #fee;
+        }
+        
+        .overview-cell.empty,
+        .overview-cell.skipped {
+            color: #9ca3af;
+        }
+        
+        .trends {
+            display: flex;
+            flex-wrap: wrap;
+            gap: 1rem;
+            margin-top: 1.5rem;
+        }
+        
+        .trends-title {
+            width: 100%;
+            margin: 0;
+            font-size: 1rem;
+            font-weight: 500;
+        }
+        
+        .trend {
+            margin: 0;
+            padding: 0.75rem 1rem;
+            background: rgba(255,255,255,0.15);
+            border-radius: 8px;
+        }
+        
+        .trend svg {
+            display: block;
+            width: 240px;
+            height: 60px;
+        }
+        
+        .trend polyline {
+            fill: none;
+            stroke: currentColor;
+            stroke-width: 2;
+        }
+        
+        .trend circle {
+            fill: currentColor;
+        }
+        
+        .failure-groups {
+            padding: 0 2rem;
+            max-width: 1200px;
+            margin: 0 auto;
+        }
+        
+        .flaky-tests {
+            padding: 0 2rem 1rem;
+            max-width: 1200px;
+            margin: 0 auto;
+        }
+        
+        .flaky-tests ul {
+            background: white;
+            border-left: 4px solid #f59e0b;
+            border-radius: 12px;
+            padding: 1rem 1rem 1rem 2.5rem;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.05);
+        }
+        
+        .summary-card.flaky .summary-value,
+        .detail.flaky {
+            color: #b45309;
+        }
+        
+        .in-progress {
+            background: #fffbeb;
+            border: 1px solid #f59e0b;
+            border-radius: 8px;
+            padding: 0.75rem 1rem;
+            margin-bottom: 1rem;
+        }
+        
+        .build-info {
+            margin: 0.5rem 0 1rem;
+            font-size: 0.875rem;
+            color: #4a5568;
+        }
+        
+        .build-info dl {
+            display: grid;
+            grid-template-columns: max-content 1fr;
+            gap: 0.25rem 1rem;
+            margin: 0.5rem 0 0;
+        }
+        
+        .build-info dd {
+            margin: 0;
+        }
+        
+        .detail.within-budget {
+            color: #0f766e;
+        }
+        
+        .failure-group {
+            background: white;
+            border-left: 4px solid #dc3545;
+            border-radius: 12px;
+            margin-bottom: 1rem;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.05);
+            overflow: hidden;
+        }
+        
+        .failure-group summary {
+            padding: 1rem 1.5rem;
+            cursor: pointer;
+            color: #2d3748;
+        }
+        
+        .failure-group-tests {
+            margin: 0 1.5rem 1.5rem;
+            columns: 3 16rem;
+            font-size: 0.875rem;
+        }
+        
+        .report-group {
+            margin-bottom: 1.5rem;
+        }
+        
+        .report-group summary {
+            padding: 0.75rem 0;
+            cursor: pointer;
+            color: #2d3748;
+        }
+        
+        .report-group-name {
+            font-size: 1.25rem;
+            font-weight: 600;
+        }
+        
+        .report-group-failed {
+            color: #dc3545;
+        }
+        
+        .section-title {
+            margin: 1rem 0;
+            color: #2d3748;
+            font-size: 1.75rem;
+        }
+        
+        .section-summary {
+            font-size: 1rem;
+            font-weight: normal;
+            color: #6b7280;
+        }
+        
         .tests {
             padding: 2rem;
             max-width: 1200px;
@@ -463,6 +1611,8 @@ const defaultCSS = `
             box-shadow: 0 2px 4px rgba(0,0,0,0.05);
             overflow: hidden;
             transition: transform 0.2s, box-shadow 0.2s;
+            content-visibility: auto;
+            contain-intrinsic-size: auto 600px;
         }
         
         .test:hover {
@@ -478,6 +1628,10 @@ const defaultCSS = `
             border-left: 4px solid #28a745;
         }
         
+        .test.skipped {
+            border-left: 4px solid #9ca3af;
+        }
+        
         .test-header {
             padding: 1.5rem;
             display: flex;
@@ -486,7 +1640,7 @@ const defaultCSS = `
             border-bottom: 1px solid #e1e4e8;
         }
         
-        .test h2 {
+        .test h3 {
             margin: 0;
             color: #2d3748;
             font-size: 1.5rem;
@@ -510,6 +1664,31 @@ const defaultCSS = `
             color: #721c24;
         }
         
+        .test-status-badge.skipped {
+            background: #e5e7eb;
+            color: #374151;
+        }
+        
+        .skip-reason {
+            margin: 1.5rem;
+            color: #4b5563;
+        }
+        
+        .test-status-badge.xfail,
+        .test-status-badge.xpass {
+            background: #fef3c7;
+            color: #92400e;
+        }
+        
+        .unexpected-pass {
+            margin: 1.5rem 1.5rem 0;
+            padding: 0.75rem 1rem;
+            background: #fffbeb;
+            border: 1px solid #fcd34d;
+            border-radius: 6px;
+            color: #92400e;
+        }
+        
         .description {
             padding: 0 1.5rem;
             color: #6b7280;
@@ -518,6 +1697,8 @@ const defaultCSS = `
         }
         
         .tags {
+            list-style: none;
+            margin: 0;
             padding: 0 1.5rem 1rem;
             display: flex;
             gap: 0.5rem;
@@ -528,9 +1709,52 @@ const defaultCSS = `
             background: #e0e7ff;
             color: #5850ec;
             padding: 0.25rem 0.75rem;
+            border: none;
             border-radius: 9999px;
             font-size: 0.75rem;
             font-weight: 500;
+            cursor: pointer;
+        }
+        
+        .search {
+            margin-left: auto;
+        }
+        
+        .search input {
+            border: 1px solid #d1d5db;
+            border-radius: 6px;
+            padding: 0.5rem 0.75rem;
+            font-size: 0.875rem;
+            min-width: 16rem;
+        }
+        
+        .tag-filters {
+            background: white;
+            padding: 0.75rem 2rem;
+            display: flex;
+            flex-wrap: wrap;
+            gap: 0.5rem;
+            border-bottom: 1px solid #e1e4e8;
+        }
+        
+        .tag-btn {
+            background: #e0e7ff;
+            color: #5850ec;
+            border: 1px solid transparent;
+            padding: 0.25rem 0.75rem;
+            border-radius: 9999px;
+            font-size: 0.75rem;
+            font-weight: 500;
+            cursor: pointer;
+        }
+        
+        .tag-btn.active {
+            background: #5850ec;
+            color: white;
+        }
+        
+        .tag-btn .tag-count {
+            opacity: 0.7;
         }
         
         .test-details {
@@ -552,6 +1776,21 @@ const defaultCSS = `
             background: #f9fafb;
         }
         
+        .screenshot-container {
+            margin: 0;
+        }
+        
+        .caption {
+            margin-top: 0.75rem;
+            text-align: center;
+            color: #4a5568;
+            font-size: 0.875rem;
+        }
+        
+        .draft-caption {
+            font-style: italic;
+        }
+        
         .screenshot-container img {
             max-width: 100%;
             height: auto;
@@ -563,6 +1802,14 @@ const defaultCSS = `
             box-shadow: 0 2px 8px rgba(0,0,0,0.1);
         }
         
+        .asset-links {
+            display: flex;
+            justify-content: center;
+            gap: 1rem;
+            margin: 0.75rem 1.5rem 0;
+            font-size: 0.875rem;
+        }
+        
         .error-box {
             margin: 1.5rem;
             background: #fee;
@@ -574,6 +1821,74 @@ const defaultCSS = `
             font-size: 0.875rem;
         }
         
+        .widget-changes {
+            margin: 0 1.5rem 1.5rem;
+            font-size: 0.875rem;
+        }
+        
+        .widget-changes summary {
+            cursor: pointer;
+            font-weight: 500;
+            color: #4a5568;
+        }
+        
+        .widget-changes ul {
+            margin: 0.5rem 0 0;
+            padding-left: 1.25rem;
+        }
+        
+        .widget-tree {
+            margin: 0 1.5rem 1.5rem;
+            font-size: 0.8125rem;
+        }
+        
+        .widget-tree summary {
+            cursor: pointer;
+            font-weight: 500;
+            color: #4a5568;
+        }
+        
+        .widget-tree ul {
+            margin: 0;
+            padding-left: 1.25rem;
+            list-style: none;
+            border-left: 1px dotted #cbd5e0;
+        }
+        
+        .widget-tree > ul {
+            margin-top: 0.5rem;
+            border-left: none;
+            padding-left: 0;
+        }
+        
+        .widget-tree .hidden-node {
+            opacity: 0.5;
+        }
+        
+        .node-geometry {
+            color: #718096;
+            font-variant-numeric: tabular-nums;
+        }
+        
+        .node-prop {
+            background: #edf2f7;
+            border-radius: 4px;
+            padding: 0 0.25rem;
+            margin-left: 0.25rem;
+        }
+        
+        .widget-change.added strong {
+            color: #155724;
+        }
+        
+        .widget-change.removed strong {
+            color: #721c24;
+        }
+        
+        .widget-change.changed strong {
+            color: #92400e;
+        }
+        
         .metadata {
             margin: 0 1.5rem 1.5rem;
             background: #f5f7fa;
@@ -601,6 +1916,12 @@ const defaultCSS = `
             line-height: 1.5;
         }
         
+        .output pre {
+            max-height: 20rem;
+            white-space: pre-wrap;
+            word-break: break-word;
+        }
+        
         @media (max-width: 768px) {
             .header {
                 padding: 1rem;
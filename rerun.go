@@ -0,0 +1,233 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// reportJSON is the name of the JSON report written next to index.html; it is
+// the source of truth when a run's report is updated in place.
+const reportJSON = "index.json"
+
+// LoadJSONReport reads a JSON report written by GenerateJSONReport.
+func LoadJSONReport(path string) (JSONReport, error) {
+	var report JSONReport
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, err
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("invalid report %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// Result converts a JSON result back into a Result for reporting. Paths are
+// resolved against baseDir, the directory of the JSON report. The Setup of
+// the returned test is nil, so it cannot be run again.
+func (jr JSONResult) Result(baseDir string) Result {
+	result := Result{
+		Test: Test{
+			Name:        jr.Name,
+			Description: jr.Description,
+			Tags:        jr.Tags,
+			Metadata:    jr.Metadata,
+		},
+		Success:         jr.Success,
+		Skipped:         jr.Skipped,
+		SkipReason:      jr.SkipReason,
+		ExpectedFailure: jr.ExpectedFailure,
+		UnexpectedPass:  jr.UnexpectedPass,
+		ScreenshotPath:  resolvePath(baseDir, jr.ScreenshotPath),
+		ImageSize:       jr.ImageSize,
+		Duration:        jr.Duration,
+		Overhead:        jr.Overhead,
+		SetupDuration:   jr.SetupDuration,
+		Output:          jr.Output,
+		CrashDump:       resolvePath(baseDir, jr.CrashDump),
+		Timestamp:       jr.Timestamp,
+		Metadata:        jr.Metadata,
+		Profile:         jr.Profile,
+		WidgetChanges:   jr.WidgetChanges,
+		WidgetTree:      jr.WidgetTree,
+		Attempts:        jr.Attempts,
+	}
+	if jr.Error != "" {
+		result.Error = errors.New(jr.Error)
+	}
+	if assets := jr.DiffAssets; assets != nil {
+		result.DiffAssets = &DiffAssets{
+			Diff:      resolvePath(baseDir, assets.Diff),
+			Heatmap:   resolvePath(baseDir, assets.Heatmap),
+			Composite: resolvePath(baseDir, assets.Composite),
+		}
+	}
+	if d := jr.Diff; d != nil {
+		result.Diff = &DiffResult{
+			Match:        d.Match,
+			SizeMismatch: d.SizeMismatch,
+			DiffPixels:   d.DiffPixels,
+			TotalPixels:  d.TotalPixels,
+			DiffPercent:  d.DiffPercent,
+			MaxDelta:     d.MaxDelta,
+			Similarity:   d.Similarity,
+			Partial:      d.Partial,
+			Severity:     d.Severity,
+			WithinBudget: d.WithinBudget,
+		}
+		if box := d.BoundingBox; box != nil {
+			result.Diff.Bounds = image.Rect(box.X, box.Y, box.X+box.Width, box.Y+box.Height)
+		}
+	}
+	return result
+}
+
+func resolvePath(baseDir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, filepath.FromSlash(path))
+}
+
+// LatestRun returns the most recent timestamped run directory in outputDir
+// that has a report.
+func LatestRun(outputDir string) (string, error) {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if _, err := time.Parse(runTimestamp, entry.Name()); err == nil && entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	for _, name := range names {
+		runDir := filepath.Join(outputDir, name)
+		if _, err := os.Stat(filepath.Join(runDir, reportJSON)); err == nil {
+			return runDir, nil
+		}
+	}
+	return "", fmt.Errorf("no previous run with a report in %s", outputDir)
+}
+
+// MergeResults replaces the results in previous that have a counterpart,
+// by test name and profile, in updated. Order is kept; results of tests
+// that were not in previous are appended.
+func MergeResults(previous, updated []Result) []Result {
+	type key struct{ name, profile string }
+	index := make(map[key]int, len(previous))
+	merged := append([]Result(nil), previous...)
+	for i, r := range merged {
+		index[key{r.Test.Name, r.Profile}] = i
+	}
+
+	for _, r := range updated {
+		if i, ok := index[key{r.Test.Name, r.Profile}]; ok {
+			merged[i] = r
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// RerunTests runs tests again inside the existing run directory runDir and
+// updates its report in place: the cards of these tests are replaced while
+// every other result is kept from the run's JSON report, so the full run
+// stays in context. Profile results are kept as they are.
+func (s *Suite) RerunTests(runDir string, tests []Test) (SuiteResult, error) {
+	previous, err := LoadRun(runDir)
+	if err != nil {
+		return SuiteResult{}, fmt.Errorf("failed to load previous run: %w", err)
+	}
+	return s.rerun(runDir, previous.Results, map[string][]Test{"": tests})
+}
+
+// RerunFailed runs the tests that failed in runDir again, with the profile
+// they failed under, and updates the run's report in place.
+func (s *Suite) RerunFailed(runDir string) (SuiteResult, error) {
+	previous, err := LoadRun(runDir)
+	if err != nil {
+		return SuiteResult{}, fmt.Errorf("failed to load previous run: %w", err)
+	}
+
+	selections := make(map[string][]Test)
+	for _, r := range previous.Results {
+		if r.Success {
+			continue
+		}
+		tests := s.filterByExactName(r.Test.Name)
+		if len(tests) == 0 {
+			continue
+		}
+		test := tests[0]
+		if r.Profile != "" {
+			profile, ok := s.Profile(r.Profile)
+			if !ok {
+				continue
+			}
+			test = profile.Apply(test)
+		}
+		selections[r.Profile] = append(selections[r.Profile], test)
+	}
+	return s.rerun(runDir, previous.Results, selections)
+}
+
+// rerun runs the selected tests of each profile into runDir and merges their
+// results with the previous ones into the run's report.
+func (s *Suite) rerun(runDir string, previous []Result, selections map[string][]Test) (SuiteResult, error) {
+	if err := s.beforeSuite(); err != nil {
+		return SuiteResult{}, err
+	}
+	startTime := time.Now()
+	originalOutputDir := s.runner.OutputDir
+	defer func() {
+		s.runner.OutputDir = originalOutputDir
+		s.runner.profile = ""
+	}()
+
+	profiles := make([]string, 0, len(selections))
+	total := 0
+	for name, tests := range selections {
+		profiles = append(profiles, name)
+		total += len(tests)
+	}
+	sort.Strings(profiles)
+	s.runner.emit(Event{Type: EventSuiteStart, Suite: s.config.Name, Total: total})
+
+	updated := make([]Result, 0, total)
+	for _, name := range profiles {
+		s.runner.OutputDir = runDir
+		if name != "" {
+			s.runner.OutputDir = filepath.Join(runDir, sanitizeFilename(name))
+		}
+		s.runner.profile = name
+		updated = append(updated, s.runner.RunTests(selections[name])...)
+	}
+
+	suiteResult := SuiteResult{
+		Name:      s.config.Name,
+		Results:   MergeResults(previous, updated),
+		StartTime: startTime,
+		EndTime:   time.Now(),
+		OutputDir: runDir,
+	}
+	defer func() { s.afterSuite(suiteResult) }()
+
+	if err := s.generateReport(&suiteResult); err != nil {
+		return suiteResult, err
+	}
+
+	s.runner.emit(newSuiteFinishEvent(suiteResult))
+	return suiteResult, nil
+}
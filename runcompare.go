@@ -0,0 +1,357 @@
+package fynetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SignificantDurationChange is the fraction of its baseline duration a test
+// must speed up or slow down by for RunChange.DurationChanged.
+const SignificantDurationChange = 0.25
+
+// minDurationChange keeps timer noise of very fast tests out of
+// RunChange.DurationChanged.
+const minDurationChange = 10 * time.Millisecond
+
+// RunComparison lists how every test differs between two runs.
+type RunComparison struct {
+	Baseline *RunData
+	Current  *RunData
+
+	// Changes has an entry for every test of either run, in the order of
+	// the current run followed by the tests only the baseline run has
+	Changes []RunChange
+}
+
+// RunChange is one test compared across two runs.
+type RunChange struct {
+	Name    string
+	Profile string
+
+	// Baseline and Current are the results of the test in each run, nil
+	// when the run doesn't have the test
+	Baseline *Result
+	Current  *Result
+
+	// Diff compares the captures of both runs, nil when either is missing
+	Diff *DiffResult
+
+	// Composite shows the baseline capture, the current capture and the
+	// differing pixels side by side, when the captures differ
+	Composite string
+}
+
+// Label names the test, with its profile if any.
+func (c RunChange) Label() string {
+	if c.Profile == "" {
+		return c.Name
+	}
+	return c.Name + " (" + c.Profile + ")"
+}
+
+// NewlyFailing reports whether the test passed in the baseline run and
+// fails in the current one.
+func (c RunChange) NewlyFailing() bool {
+	return c.Baseline != nil && c.Current != nil && c.Baseline.Success && !c.Current.Success
+}
+
+// NewlyPassing reports whether the test failed in the baseline run and
+// passes in the current one.
+func (c RunChange) NewlyPassing() bool {
+	return c.Baseline != nil && c.Current != nil && !c.Baseline.Success && c.Current.Success
+}
+
+// VisuallyChanged reports whether the captures of the two runs differ.
+func (c RunChange) VisuallyChanged() bool {
+	return c.Diff != nil && !c.Diff.Match
+}
+
+// DurationDelta is how much longer the test took in the current run.
+func (c RunChange) DurationDelta() time.Duration {
+	if c.Baseline == nil || c.Current == nil {
+		return 0
+	}
+	return c.Current.Duration - c.Baseline.Duration
+}
+
+// DurationChanged reports whether the test took significantly longer or
+// shorter in the current run than in the baseline run.
+func (c RunChange) DurationChanged() bool {
+	delta := c.DurationDelta()
+	if delta < 0 {
+		delta = -delta
+	}
+	return delta >= minDurationChange && float64(delta) >= SignificantDurationChange*float64(c.Baseline.Duration)
+}
+
+// formatDelta formats a duration difference with its sign, e.g. "+120ms".
+func formatDelta(d time.Duration) string {
+	if d < 0 {
+		return "-" + formatDuration(-d)
+	}
+	return "+" + formatDuration(d)
+}
+
+// filter returns the changes matching keep.
+func (rc *RunComparison) filter(keep func(RunChange) bool) []RunChange {
+	changes := make([]RunChange, 0)
+	for _, change := range rc.Changes {
+		if keep(change) {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// NewlyFailing returns the tests that passed in the baseline run and fail
+// in the current one.
+func (rc *RunComparison) NewlyFailing() []RunChange {
+	return rc.filter(RunChange.NewlyFailing)
+}
+
+// NewlyPassing returns the tests that failed in the baseline run and pass
+// in the current one.
+func (rc *RunComparison) NewlyPassing() []RunChange {
+	return rc.filter(RunChange.NewlyPassing)
+}
+
+// VisuallyChanged returns the tests whose capture differs between the runs.
+func (rc *RunComparison) VisuallyChanged() []RunChange {
+	return rc.filter(RunChange.VisuallyChanged)
+}
+
+// DurationChanged returns the tests whose duration changed significantly,
+// the most slowed down first.
+func (rc *RunComparison) DurationChanged() []RunChange {
+	changes := rc.filter(RunChange.DurationChanged)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].DurationDelta() > changes[j].DurationDelta()
+	})
+	return changes
+}
+
+// Added returns the tests only the current run has.
+func (rc *RunComparison) Added() []RunChange {
+	return rc.filter(func(c RunChange) bool { return c.Baseline == nil })
+}
+
+// Removed returns the tests only the baseline run has.
+func (rc *RunComparison) Removed() []RunChange {
+	return rc.filter(func(c RunChange) bool { return c.Current == nil })
+}
+
+// CompareRuns compares every test of two runs: its status, duration and
+// capture. Captures are compared with tol. When assetDir is set, a
+// composite review image is written there for each capture that changed.
+func CompareRuns(baseline, current *RunData, tol Tolerance, assetDir string) (*RunComparison, error) {
+	rc := &RunComparison{Baseline: baseline, Current: current}
+	if assetDir != "" {
+		if err := os.MkdirAll(assetDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := range current.Results {
+		result := &current.Results[i]
+		seen[result.Profile+"\x00"+result.Test.Name] = true
+		change := RunChange{Name: result.Test.Name, Profile: result.Profile, Current: result}
+		if previous, ok := baseline.Find(result.Test.Name, result.Profile); ok {
+			change.Baseline = &previous
+			if err := rc.compareCaptures(&change, tol, assetDir); err != nil {
+				return nil, err
+			}
+		}
+		rc.Changes = append(rc.Changes, change)
+	}
+	for i := range baseline.Results {
+		result := &baseline.Results[i]
+		if !seen[result.Profile+"\x00"+result.Test.Name] {
+			rc.Changes = append(rc.Changes, RunChange{Name: result.Test.Name, Profile: result.Profile, Baseline: result})
+		}
+	}
+	return rc, nil
+}
+
+// compareCaptures sets the diff of change, and its composite when the
+// captures differ. Captures missing from a run are not an error.
+func (rc *RunComparison) compareCaptures(change *RunChange, tol Tolerance, assetDir string) error {
+	if change.Baseline.ScreenshotPath == "" || change.Current.ScreenshotPath == "" {
+		return nil
+	}
+	expected, err := rc.Baseline.Image(change.Baseline.ScreenshotPath)
+	if err != nil {
+		return nil
+	}
+	actual, err := rc.Current.Image(change.Current.ScreenshotPath)
+	if err != nil {
+		return nil
+	}
+
+	diff := CompareImages(expected, actual, tol)
+	change.Diff = &diff
+	if diff.Match || assetDir == "" {
+		return nil
+	}
+	composite := renderAsset(AssetComposite, expected, actual, tol)
+	if composite == nil {
+		return nil
+	}
+	defer ReleaseImage(composite)
+
+	name := sanitizeFilename(change.Name)
+	if change.Profile != "" {
+		name += "_" + sanitizeFilename(change.Profile)
+	}
+	change.Composite = filepath.Join(assetDir, name+"_"+AssetComposite+".png")
+	if err := writeImage(composite, change.Composite); err != nil {
+		return fmt.Errorf("failed to save comparison of %s: %w", change.Label(), err)
+	}
+	return nil
+}
+
+// GenerateComparisonReport loads two runs, each given as a run directory,
+// archive or JSON report, and writes an HTML page highlighting the tests
+// that newly fail, newly pass, changed visually, or were added or removed
+// between them. Composites of the changed captures are written to a
+// directory next to the page, named after it.
+func (g *ReportGenerator) GenerateComparisonReport(baselineRun, currentRun, outputPath string) error {
+	baseline, err := LoadRun(baselineRun)
+	if err != nil {
+		return fmt.Errorf("failed to load baseline run: %w", err)
+	}
+	current, err := LoadRun(currentRun)
+	if err != nil {
+		return fmt.Errorf("failed to load current run: %w", err)
+	}
+	assetDir := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + "_images"
+	rc, err := CompareRuns(baseline, current, Tolerance{}, assetDir)
+	if err != nil {
+		return err
+	}
+	return g.WriteComparisonReport(rc, outputPath)
+}
+
+// WriteComparisonReport renders a comparison made with CompareRuns as an
+// HTML page at outputPath.
+func (g *ReportGenerator) WriteComparisonReport(rc *RunComparison, outputPath string) error {
+	dir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	tmpl, err := g.createTemplate(dir)
+	if err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+	if tmpl, err = tmpl.New("comparison").Parse(comparisonTemplate); err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create comparison report: %w", err)
+	}
+	defer file.Close()
+	err = tmpl.ExecuteTemplate(file, "comparison", struct {
+		Title      string
+		StyleSheet string
+		Timestamp  time.Time
+		*RunComparison
+	}{g.Title, g.StyleSheet, time.Now(), rc})
+	if err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return nil
+}
+
+const comparisonTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Run comparison · {{.Title}}</title>
+    <style>
+{{.StyleSheet}}
+        .comparison-runs { display: flex; gap: 24px; flex-wrap: wrap; }
+        .comparison-list { list-style: none; padding: 0; }
+        .comparison-list li { margin-bottom: 24px; }
+        .comparison-list img { display: block; max-width: 100%; border: 1px solid #ddd; margin-top: 8px; }
+        .delta-slower { color: #dc2626; }
+        .delta-faster { color: #16a34a; }
+    </style>
+</head>
+<body>
+    <header class="header">
+        <h1>Run comparison</h1>
+        <div class="comparison-runs">
+            {{template "comparison-run" .Baseline}}
+            <span aria-hidden="true">→</span>
+            {{template "comparison-run" .Current}}
+        </div>
+        <p class="timestamp">Generated: <time datetime="{{.Timestamp.Format "2006-01-02T15:04:05Z07:00"}}">{{formatTime .Timestamp}}</time></p>
+    </header>
+    <main class="tests">
+        {{with .NewlyFailing}}
+        <section class="section" aria-labelledby="newly-failing">
+            <h2 id="newly-failing">❌ {{len .}} newly failing</h2>
+            <ul class="comparison-list">{{range .}}{{template "comparison-change" .}}{{end}}</ul>
+        </section>
+        {{end}}
+        {{with .NewlyPassing}}
+        <section class="section" aria-labelledby="newly-passing">
+            <h2 id="newly-passing">✅ {{len .}} newly passing</h2>
+            <ul class="comparison-list">{{range .}}{{template "comparison-change" .}}{{end}}</ul>
+        </section>
+        {{end}}
+        {{with .VisuallyChanged}}
+        <section class="section" aria-labelledby="visually-changed">
+            <h2 id="visually-changed">🖼️ {{len .}} visually changed</h2>
+            <ul class="comparison-list">{{range .}}{{template "comparison-change" .}}{{end}}</ul>
+        </section>
+        {{end}}
+        {{with .DurationChanged}}
+        <section class="section" aria-labelledby="duration-changed">
+            <h2 id="duration-changed">⏱️ {{len .}} slower or faster</h2>
+            <ul>{{range .}}<li>{{.Label}}: {{formatDuration .Baseline.Duration}} → {{formatDuration .Current.Duration}} (<span class="{{if gt .DurationDelta 0}}delta-slower{{else}}delta-faster{{end}}">{{formatDelta .DurationDelta}}</span>)</li>{{end}}</ul>
+        </section>
+        {{end}}
+        {{with .Added}}
+        <section class="section" aria-labelledby="added">
+            <h2 id="added">{{len .}} added</h2>
+            <ul>{{range .}}<li>{{if .Current.Success}}✅{{else}}❌{{end}} {{.Label}}</li>{{end}}</ul>
+        </section>
+        {{end}}
+        {{with .Removed}}
+        <section class="section" aria-labelledby="removed">
+            <h2 id="removed">{{len .}} removed</h2>
+            <ul>{{range .}}<li>{{.Label}}</li>{{end}}</ul>
+        </section>
+        {{end}}
+        {{if not (or .NewlyFailing .NewlyPassing .VisuallyChanged .DurationChanged .Added .Removed)}}
+        <p>No test changed status, appearance or duration between the runs.</p>
+        {{end}}
+    </main>
+</body>
+</html>
+{{define "comparison-run"}}
+<div>
+    <strong>{{.Title}}</strong>
+    <br><time datetime="{{.Timestamp.Format "2006-01-02T15:04:05Z07:00"}}">{{formatTime .Timestamp}}</time>
+    <br>{{.Summary.Passed}} passed, {{.Summary.Failed}} failed ({{printf "%.1f%%" .Summary.PassRate}})
+</div>
+{{end}}
+{{define "comparison-change"}}
+<li>
+    <strong>{{.Label}}</strong>
+    {{with .Diff}}{{if .SizeMismatch}} · size changed{{else if not .Match}} · {{printf "%.2f%%" .DiffPercent}} of pixels changed{{end}}{{end}}
+    {{with .DurationDelta}} · <span class="{{if gt . 0}}delta-slower{{else}}delta-faster{{end}}">{{formatDelta .}}</span>{{end}}
+    {{with .Current.Error}}<div class="error-box"><strong>Error:</strong> {{.}}</div>{{end}}
+    {{with .Composite}}<img src="{{imgsrc .}}" alt="Baseline run, current run and differing pixels of {{$.Label}}" loading="lazy">{{end}}
+</li>
+{{end}}
+`
@@ -0,0 +1,162 @@
+package fynetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RunIndexName is the file name of the page listing every run, written to
+// the output root.
+const RunIndexName = "index.html"
+
+// runIndexEntry is one run on the run index page.
+type runIndexEntry struct {
+	Name     string
+	Started  time.Time
+	Archived bool
+	Link     string
+
+	// Run is nil when the run has no readable report, e.g. it was
+	// interrupted
+	Run *RunData
+}
+
+// GenerateRunIndex writes an index.html to outputDir listing the runs kept
+// there, newest first, with their date, pass rate and a link to their
+// report. Archived runs link to their archive. Regenerate it whenever runs
+// are added, archived or deleted, so the page matches what is retained.
+func (g *ReportGenerator) GenerateRunIndex(outputDir string) error {
+	paths, err := ListRuns(outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	entries := make([]runIndexEntry, 0, len(paths))
+	for i := len(paths) - 1; i >= 0; i-- {
+		name := filepath.Base(paths[i])
+		entry := runIndexEntry{Name: strings.TrimSuffix(name, ArchiveExt), Archived: strings.HasSuffix(name, ArchiveExt)}
+		entry.Started, _ = time.ParseInLocation(runTimestamp, entry.Name, time.Local)
+		entry.Link = name
+		if !entry.Archived {
+			entry.Link = name + "/" + RunIndexName
+		}
+		if run, err := LoadRun(paths[i]); err == nil {
+			entry.Run = run
+		}
+		entries = append(entries, entry)
+	}
+
+	tmpl, err := g.createTemplate(outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+	if tmpl, err = tmpl.New("runs").Parse(runIndexTemplate); err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+
+	path := filepath.Join(outputDir, RunIndexName)
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create run index: %w", err)
+	}
+	err = tmpl.ExecuteTemplate(file, "runs", struct {
+		Title      string
+		StyleSheet string
+		Timestamp  time.Time
+		Runs       []runIndexEntry
+	}{g.Title, g.StyleSheet, time.Now(), entries})
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write run index: %w", err)
+	}
+	// Replace the page in one step, so a browser never reads half of it
+	return os.Rename(tmpPath, path)
+}
+
+const runIndexTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}: all runs</title>
+    <style>
+{{.StyleSheet}}
+        .runs {
+            width: 100%;
+            border-collapse: collapse;
+            background: white;
+            border-radius: 12px;
+            overflow: hidden;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.05);
+        }
+
+        .runs th,
+        .runs td {
+            padding: 0.75rem 1rem;
+            border-bottom: 1px solid #e1e4e8;
+            text-align: left;
+        }
+
+        .runs td.number {
+            text-align: right;
+            font-variant-numeric: tabular-nums;
+        }
+
+        .runs tr.failure td:first-child {
+            border-left: 4px solid #dc3545;
+        }
+
+        .runs tr.success td:first-child {
+            border-left: 4px solid #28a745;
+        }
+    </style>
+</head>
+<body>
+    <header class="header">
+        <h1>{{.Title}}</h1>
+        <p class="timestamp">{{len .Runs}} runs kept · updated <time datetime="{{.Timestamp.Format "2006-01-02T15:04:05Z07:00"}}">{{formatTime .Timestamp}}</time></p>
+    </header>
+    <main class="tests">
+        <table class="runs">
+            <caption class="visually-hidden">Runs, newest first</caption>
+            <thead>
+                <tr>
+                    <th scope="col">Run</th>
+                    <th scope="col">Status</th>
+                    <th scope="col">Pass rate</th>
+                    <th scope="col">Passed</th>
+                    <th scope="col">Failed</th>
+                    <th scope="col">Duration</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range .Runs}}
+                {{if .Run}}
+                <tr class="{{if .Run.Summary.Failed}}failure{{else}}success{{end}}">
+                    <th scope="row"><a href="{{.Link}}">{{if .Started.IsZero}}{{.Name}}{{else}}{{formatTime .Started}}{{end}}</a>{{if .Archived}} <span title="Download, or serve with -serve :8080 -open {{.Link}}">(archived)</span>{{end}}</th>
+                    <td>{{if .Run.Summary.Failed}}<span aria-hidden="true">❌</span> failed{{else}}<span aria-hidden="true">✅</span> passed{{end}}</td>
+                    <td class="number">{{printf "%.1f%%" .Run.Summary.PassRate}}</td>
+                    <td class="number">{{.Run.Summary.Passed}}</td>
+                    <td class="number">{{.Run.Summary.Failed}}</td>
+                    <td class="number">{{formatDuration .Run.Summary.Duration}}</td>
+                </tr>
+                {{else}}
+                <tr>
+                    <th scope="row">{{if .Started.IsZero}}{{.Name}}{{else}}{{formatTime .Started}}{{end}}</th>
+                    <td colspan="5">No report; the run was interrupted or is still running</td>
+                </tr>
+                {{end}}
+                {{end}}
+            </tbody>
+        </table>
+    </main>
+</body>
+</html>
+`
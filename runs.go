@@ -0,0 +1,177 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RunData is a past run loaded from its output: the report header and the
+// results, with paths to the images the run wrote. Tools analyzing trends
+// across runs use it instead of parsing the output layout.
+type RunData struct {
+	// Path is the run directory, or the archive written by ArchiveRun
+	Path string
+
+	// Title, Timestamp, Summary and Stats are those of the run's report
+	Title     string
+	Timestamp time.Time
+	Summary   Summary
+	Stats     ImageStats
+
+	// Results are the results of the run in report order. Their paths are
+	// under Path; use Open or Image to read them, which also works for
+	// archived runs. The tests have no Setup and cannot be run again.
+	Results []Result
+}
+
+// LoadRun loads the run in path, a timestamped run directory or an archive
+// of one (see ArchiveRun), from the JSON report written with it. path may
+// also be the JSON report itself.
+func LoadRun(path string) (*RunData, error) {
+	run := &RunData{Path: filepath.Clean(path)}
+	reportPath := filepath.Join(run.Path, reportJSON)
+	if strings.HasSuffix(run.Path, ".json") {
+		run.Path, reportPath = filepath.Dir(run.Path), run.Path
+	}
+	file, err := run.Open(reportPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var report JSONReport
+	if err := json.NewDecoder(file).Decode(&report); err != nil {
+		return nil, fmt.Errorf("invalid report in %s: %w", path, err)
+	}
+	run.Title = report.Title
+	run.Timestamp = report.Timestamp
+	run.Summary = report.Summary
+	run.Stats = report.Stats
+	run.Results = make([]Result, len(report.Results))
+	for i, jr := range report.Results {
+		run.Results[i] = jr.Result(run.Path)
+	}
+	return run, nil
+}
+
+// ListRuns returns the timestamped runs in outputDir, directories and
+// archives alike, oldest first.
+func ListRuns(outputDir string) ([]string, error) {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() {
+			if !strings.HasSuffix(name, ArchiveExt) {
+				continue
+			}
+			name = strings.TrimSuffix(name, ArchiveExt)
+		}
+		if _, err := time.Parse(runTimestamp, name); err == nil {
+			runs = append(runs, entry.Name())
+		}
+	}
+	sort.Strings(runs)
+	for i, name := range runs {
+		runs[i] = filepath.Join(outputDir, name)
+	}
+	return runs, nil
+}
+
+// LoadRuns loads every run in outputDir with a report, oldest first. Runs
+// without a readable report, such as interrupted ones, are skipped.
+func LoadRuns(outputDir string) ([]*RunData, error) {
+	paths, err := ListRuns(outputDir)
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*RunData, 0, len(paths))
+	for _, path := range paths {
+		if run, err := LoadRun(path); err == nil {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+// Find returns the result of the named test under profile ("" for runs
+// without profiles).
+func (run *RunData) Find(name, profile string) (Result, bool) {
+	for _, result := range run.Results {
+		if result.Test.Name == name && result.Profile == profile {
+			return result, true
+		}
+	}
+	return Result{}, false
+}
+
+// Failed returns the failed results of the run.
+func (run *RunData) Failed() []Result {
+	failed := make([]Result, 0)
+	for _, result := range run.Results {
+		if !result.Success {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Open opens a file of the run, such as a result's ScreenshotPath, reading
+// it from the archive when the run is archived.
+func (run *RunData) Open(path string) (io.ReadCloser, error) {
+	if !strings.HasSuffix(run.Path, ArchiveExt) {
+		return os.Open(path)
+	}
+
+	rel, err := filepath.Rel(run.Path, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("%s is not in run %s", path, run.Path)
+	}
+	archive, err := openArchive(run.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	file, err := archive.Open(filepath.ToSlash(rel))
+	if err != nil {
+		archive.Close()
+		return nil, err
+	}
+	return archiveFile{file, archive}, nil
+}
+
+// Image decodes a PNG file of the run, such as a result's ScreenshotPath or
+// one of its DiffAssets.
+func (run *RunData) Image(path string) (image.Image, error) {
+	file, err := run.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return png.Decode(file)
+}
+
+// archiveFile is a file read from an archive, closing the archive with it.
+type archiveFile struct {
+	io.ReadCloser
+	archive io.Closer
+}
+
+func (f archiveFile) Close() error {
+	err := f.ReadCloser.Close()
+	if cerr := f.archive.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
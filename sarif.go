@@ -0,0 +1,175 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SARIF rule identifiers of failed tests.
+const (
+	sarifRuleRegression = "visual-regression"
+	sarifRuleBroken     = "visual-test-error"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSARIF writes the failed tests of results to path as a SARIF 2.1.0
+// log, each pointing at the line that registered the test (see
+// Test.SourceFile). Uploaded with GitHub's "upload-sarif" action, visual
+// regressions show up in code scanning and inline on pull requests.
+// Mismatches against the baseline use the visual-regression rule and other
+// errors the visual-test-error rule.
+func WriteSARIF(results []Result, path string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "vfyne",
+			InformationURI: "https://github.com/jairo/vfyne",
+			Rules: []sarifRule{
+				{
+					ID:               sarifRuleRegression,
+					ShortDescription: sarifMessage{Text: "Visual regression"},
+					FullDescription:  sarifMessage{Text: "The capture of a visual test differs from its baseline."},
+				},
+				{
+					ID:               sarifRuleBroken,
+					ShortDescription: sarifMessage{Text: "Visual test error"},
+					FullDescription:  sarifMessage{Text: "A visual test could not be rendered, captured or compared."},
+				},
+			},
+		}},
+		Results: make([]sarifResult, 0),
+	}
+	for _, result := range results {
+		if !result.Success {
+			run.Results = append(run.Results, newSARIFResult(result))
+		}
+	}
+
+	data, err := json.MarshalIndent(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create SARIF directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func newSARIFResult(result Result) sarifResult {
+	rule := sarifRuleBroken
+	if result.Diff != nil && !result.Diff.Match {
+		rule = sarifRuleRegression
+	}
+	name := result.Test.Name
+	if result.Profile != "" {
+		name += " [" + result.Profile + "]"
+	}
+	message := "Visual test " + name + " failed"
+	if result.Error != nil {
+		message += ": " + result.Error.Error()
+	}
+
+	sr := sarifResult{
+		RuleID:  rule,
+		Level:   "error",
+		Message: sarifMessage{Text: message},
+		// Keeps one alert per test across runs, however its message changes
+		PartialFingerprints: map[string]string{"vfyneTest/v1": name},
+	}
+	if file := result.Test.SourceFile; file != "" {
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactFor(file)}
+		if result.Test.SourceLine > 0 {
+			location.Region = &sarifRegion{StartLine: result.Test.SourceLine}
+		}
+		sr.Locations = []sarifLocation{{PhysicalLocation: location}}
+	}
+	return sr
+}
+
+// sarifArtifactFor locates file relative to the root of its git checkout,
+// as code scanning expects, or by absolute URI outside of one.
+func sarifArtifactFor(file string) sarifArtifact {
+	if !filepath.IsAbs(file) {
+		// Built with -trimpath
+		return sarifArtifact{URI: filepath.ToSlash(file), URIBaseID: "%SRCROOT%"}
+	}
+	for dir := filepath.Dir(file); ; {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			if rel, err := filepath.Rel(dir, file); err == nil {
+				return sarifArtifact{URI: filepath.ToSlash(rel), URIBaseID: "%SRCROOT%"}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	uri := filepath.ToSlash(file)
+	if !strings.HasPrefix(uri, "/") {
+		uri = "/" + uri
+	}
+	return sarifArtifact{URI: "file://" + uri}
+}
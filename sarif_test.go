@@ -0,0 +1,78 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestWriteSARIFPointsAtTestSource(t *testing.T) {
+	setup := func() fyne.CanvasObject { return widget.NewLabel("") }
+	regression := QuickTest("Login", setup)
+	broken := QuickTest("Chart", setup)
+	results := []Result{
+		{Test: QuickTest("Passing", setup), Success: true},
+		{Test: regression, Profile: "dark", Error: errors.New("capture differs"), Diff: &DiffResult{DiffPercent: 2}},
+		{Test: broken, Error: errors.New("capture panicked")},
+	}
+
+	path := filepath.Join(t.TempDir(), "vfyne.sarif")
+	if err := WriteSARIF(results, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("SARIF version %q with %d runs, want 2.1.0 with 1", log.Version, len(log.Runs))
+	}
+
+	got := log.Runs[0].Results
+	if len(got) != 2 {
+		t.Fatalf("%d results, want one per failed test", len(got))
+	}
+	want := []struct {
+		rule, message, fingerprint string
+		line                       int
+	}{
+		{sarifRuleRegression, "Visual test Login [dark] failed: capture differs", "Login [dark]", regression.SourceLine},
+		{sarifRuleBroken, "Visual test Chart failed: capture panicked", "Chart", broken.SourceLine},
+	}
+	for i, w := range want {
+		r := got[i]
+		if r.RuleID != w.rule || r.Message.Text != w.message || r.PartialFingerprints["vfyneTest/v1"] != w.fingerprint {
+			t.Errorf("result %d = %s %q %v, want %s %q %s", i, r.RuleID, r.Message.Text, r.PartialFingerprints, w.rule, w.message, w.fingerprint)
+		}
+		if len(r.Locations) != 1 {
+			t.Fatalf("result %d has %d locations, want 1", i, len(r.Locations))
+		}
+		location := r.Locations[0].PhysicalLocation
+		if !strings.HasSuffix(location.ArtifactLocation.URI, "sarif_test.go") {
+			t.Errorf("result %d points at %+v, want sarif_test.go", i, location.ArtifactLocation)
+		}
+		if location.Region == nil || location.Region.StartLine != w.line {
+			t.Errorf("result %d region = %+v, want line %d", i, location.Region, w.line)
+		}
+	}
+}
+
+func TestSARIFArtifactOutsideCheckout(t *testing.T) {
+	if got := sarifArtifactFor("ui/login_test.go"); got.URI != "ui/login_test.go" || got.URIBaseID != "%SRCROOT%" {
+		t.Errorf("trimmed path: %+v, want it relative to the checkout", got)
+	}
+	file := filepath.Join(t.TempDir(), "login_test.go")
+	if got := sarifArtifactFor(file); !strings.HasPrefix(got.URI, "file:///") || !strings.HasSuffix(got.URI, "/login_test.go") || got.URIBaseID != "" {
+		t.Errorf("path outside a checkout: %+v, want a file URI", got)
+	}
+}
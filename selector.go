@@ -0,0 +1,321 @@
+package fynetest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+)
+
+// objectNames holds the names given with Named, for selectors and trees.
+var objectNames sync.Map
+
+// Named gives obj a name that selectors can refer to, as in
+// `Label[name=clock]`, and returns obj:
+//
+//	clock := fynetest.Named("clock", widget.NewLabel(time.Now().Format("15:04")))
+func Named[T fyne.CanvasObject](name string, obj T) T {
+	objectNames.Store(fyne.CanvasObject(obj), name)
+	return obj
+}
+
+// objectName returns the name given to obj with Named, if any.
+func objectName(obj fyne.CanvasObject) string {
+	name, _ := objectNames.Load(obj)
+	s, _ := name.(string)
+	return s
+}
+
+// Selector picks widgets from a widget tree by type and attributes, so ignore
+// masks follow the widgets they cover when the layout changes. A selector
+// is a space-separated chain of steps, each matching a descendant of the
+// previous one. A step is a type, matched with or without its package
+// ("ProgressBar", "widget.Label", or "*" for any), followed by attribute
+// conditions on "name" (see Named), "text" or a widget property such as
+// "value" or "checked":
+//
+//	ProgressBar
+//	Label[name=clock]
+//	Card Label[text^="Updated "]
+//
+// Conditions use "=" (equals), "^=" (starts with), "$=" (ends with) or "*="
+// (contains); values may be quoted with single or double quotes.
+type Selector struct {
+	source string
+	steps  []selectorStep
+}
+
+type selectorStep struct {
+	typ   string
+	conds []selectorCond
+}
+
+type selectorCond struct {
+	key, op, value string
+}
+
+// ParseSelector parses a selector expression.
+func ParseSelector(expr string) (Selector, error) {
+	sel := Selector{source: expr}
+	p := selectorParser{input: expr}
+	for {
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+		step, err := p.step()
+		if err != nil {
+			return Selector{}, fmt.Errorf("invalid selector %q: %w", expr, err)
+		}
+		sel.steps = append(sel.steps, step)
+	}
+	if len(sel.steps) == 0 {
+		return Selector{}, fmt.Errorf("invalid selector %q: empty", expr)
+	}
+	return sel, nil
+}
+
+func (s Selector) String() string {
+	return s.source
+}
+
+// Regions returns the canvas regions of the visible widgets in tree that
+// the selector matches. Hidden widgets are skipped, since they aren't drawn.
+func (s Selector) Regions(tree *WidgetNode) []Region {
+	regions := make([]Region, 0)
+	seen := make(map[Region]bool)
+	s.match(tree, true, func(node *WidgetNode, pos fyne.Position) {
+		region := Region{X: pos.X, Y: pos.Y, Width: node.Size.Width, Height: node.Size.Height}
+		if !seen[region] {
+			seen[region] = true
+			regions = append(regions, region)
+		}
+	})
+	return regions
+}
+
+// match calls fn with each node of tree the selector matches, once, in tree
+// order, and its position on the canvas. With visible, hidden nodes and
+// their children are skipped.
+func (s Selector) match(tree *WidgetNode, visible bool, fn func(node *WidgetNode, pos fyne.Position)) {
+	seen := make(map[*WidgetNode]bool)
+
+	var walk func(node *WidgetNode, origin fyne.Position, step int)
+	walk = func(node *WidgetNode, origin fyne.Position, step int) {
+		if node == nil || visible && node.Hidden {
+			return
+		}
+		pos := fyne.NewPos(origin.X+node.Position.X, origin.Y+node.Position.Y)
+		if s.steps[step].matches(node) {
+			if step == len(s.steps)-1 {
+				if !seen[node] {
+					seen[node] = true
+					fn(node, pos)
+				}
+			} else {
+				for _, child := range node.Children {
+					walk(child, pos, step+1)
+				}
+			}
+		}
+		for _, child := range node.Children {
+			walk(child, pos, step)
+		}
+	}
+	walk(tree, fyne.Position{}, 0)
+}
+
+// Objects returns the objects under root that the selector matches, hidden
+// ones included. Like CaptureWidgetTree, it must be called while root is
+// shown.
+func (s Selector) Objects(root fyne.CanvasObject) []fyne.CanvasObject {
+	objects := make([]fyne.CanvasObject, 0)
+	seen := make(map[fyne.CanvasObject]bool)
+
+	var walk func(obj fyne.CanvasObject, step int)
+	walk = func(obj fyne.CanvasObject, step int) {
+		if obj == nil {
+			return
+		}
+		node, children := newWidgetNode(obj)
+		if s.steps[step].matches(node) {
+			if step == len(s.steps)-1 {
+				if !seen[obj] {
+					seen[obj] = true
+					objects = append(objects, obj)
+				}
+			} else {
+				for _, child := range children {
+					walk(child, step+1)
+				}
+			}
+		}
+		for _, child := range children {
+			walk(child, step)
+		}
+	}
+	walk(root, 0)
+	return objects
+}
+
+// SelectorRegions resolves ignore selectors against the widget tree of a
+// capture. A selector matching no visible widget is an error, so a mask
+// can't silently stop covering anything.
+func SelectorRegions(tree *WidgetNode, selectors []string) ([]Region, error) {
+	regions := make([]Region, 0)
+	for _, expr := range selectors {
+		sel, err := ParseSelector(expr)
+		if err != nil {
+			return nil, err
+		}
+		matched := sel.Regions(tree)
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("ignore selector %q matched no visible widget", expr)
+		}
+		regions = append(regions, matched...)
+	}
+	return regions, nil
+}
+
+func (step selectorStep) matches(node *WidgetNode) bool {
+	if step.typ != "*" && !strings.EqualFold(step.typ, node.Type) {
+		short := node.Type[strings.LastIndex(node.Type, ".")+1:]
+		if !strings.EqualFold(step.typ, short) {
+			return false
+		}
+	}
+	for _, cond := range step.conds {
+		var value string
+		switch cond.key {
+		case "name":
+			value = node.Name
+		case "text":
+			value = node.Text
+		default:
+			value = node.Props[cond.key]
+		}
+		if !cond.matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cond selectorCond) matches(value string) bool {
+	switch cond.op {
+	case "^=":
+		return strings.HasPrefix(value, cond.value)
+	case "$=":
+		return strings.HasSuffix(value, cond.value)
+	case "*=":
+		return strings.Contains(value, cond.value)
+	}
+	return value == cond.value
+}
+
+// selectorParser scans a selector expression.
+type selectorParser struct {
+	input string
+	pos   int
+}
+
+func (p *selectorParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *selectorParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *selectorParser) skipSpace() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// ident scans a type name, which may be "*", or an attribute key.
+func (p *selectorParser) ident(wildcard bool) string {
+	start := p.pos
+	if wildcard && p.peek() == '*' {
+		p.pos++
+		return "*"
+	}
+	for !p.done() {
+		c := p.peek()
+		if c == '_' || c == '.' || c == '-' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *selectorParser) step() (selectorStep, error) {
+	step := selectorStep{typ: p.ident(true)}
+	if step.typ == "" {
+		if p.peek() != '[' {
+			return step, fmt.Errorf("unexpected %q at offset %d", p.peek(), p.pos)
+		}
+		step.typ = "*"
+	}
+	for p.peek() == '[' {
+		p.pos++
+		cond, err := p.cond()
+		if err != nil {
+			return step, err
+		}
+		step.conds = append(step.conds, cond)
+	}
+	if !p.done() && p.peek() != ' ' && p.peek() != '\t' {
+		return step, fmt.Errorf("unexpected %q at offset %d", p.peek(), p.pos)
+	}
+	return step, nil
+}
+
+func (p *selectorParser) cond() (selectorCond, error) {
+	p.skipSpace()
+	cond := selectorCond{key: p.ident(false)}
+	if cond.key == "" {
+		return cond, fmt.Errorf("missing attribute at offset %d", p.pos)
+	}
+	p.skipSpace()
+	for _, op := range []string{"^=", "$=", "*=", "="} {
+		if strings.HasPrefix(p.input[p.pos:], op) {
+			cond.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if cond.op == "" {
+		return cond, fmt.Errorf("missing operator after %q", cond.key)
+	}
+	p.skipSpace()
+
+	if quote := p.peek(); quote == '"' || quote == '\'' {
+		end := strings.IndexByte(p.input[p.pos+1:], quote)
+		if end < 0 {
+			return cond, fmt.Errorf("unterminated value for %q", cond.key)
+		}
+		cond.value = p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+	} else {
+		end := strings.IndexByte(p.input[p.pos:], ']')
+		if end < 0 {
+			return cond, fmt.Errorf("missing ] after %q", cond.key)
+		}
+		cond.value = strings.TrimSpace(p.input[p.pos : p.pos+end])
+		p.pos += end
+	}
+
+	p.skipSpace()
+	if p.peek() != ']' {
+		return cond, fmt.Errorf("missing ] after %q", cond.key)
+	}
+	p.pos++
+	return cond, nil
+}
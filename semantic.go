@@ -0,0 +1,301 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// AISuffix replaces ".png" in a screenshot path to name its AI export.
+const AISuffix = ".ai.json"
+
+// MetadataAIExport is the result metadata key holding the path of the
+// capture's AI export.
+const MetadataAIExport = "ai_export"
+
+// SemanticRole is what a widget is for, independent of the widget type that
+// implements it, so an agent can tell a custom button from a label.
+type SemanticRole string
+
+const (
+	RoleButton     SemanticRole = "button"
+	RoleTextInput  SemanticRole = "text-input"
+	RoleCheckbox   SemanticRole = "checkbox"
+	RoleChoice     SemanticRole = "choice"
+	RoleSlider     SemanticRole = "slider"
+	RoleNavigation SemanticRole = "navigation"
+	RoleDataTable  SemanticRole = "data-table"
+	RoleStatus     SemanticRole = "status"
+	RoleText       SemanticRole = "text"
+	RoleImage      SemanticRole = "image"
+)
+
+// semanticRoles maps widget types, without their package, to their role.
+var semanticRoles = map[string]SemanticRole{
+	"Button":              RoleButton,
+	"Hyperlink":           RoleButton,
+	"Entry":               RoleTextInput,
+	"SelectEntry":         RoleTextInput,
+	"Check":               RoleCheckbox,
+	"CheckGroup":          RoleCheckbox,
+	"Select":              RoleChoice,
+	"RadioGroup":          RoleChoice,
+	"Slider":              RoleSlider,
+	"AppTabs":             RoleNavigation,
+	"DocTabs":             RoleNavigation,
+	"Toolbar":             RoleNavigation,
+	"Menu":                RoleNavigation,
+	"Tree":                RoleNavigation,
+	"Table":               RoleDataTable,
+	"List":                RoleDataTable,
+	"GridWrap":            RoleDataTable,
+	"ProgressBar":         RoleStatus,
+	"ProgressBarInfinite": RoleStatus,
+	"Label":               RoleText,
+	"RichText":            RoleText,
+	"Text":                RoleText,
+	"Image":               RoleImage,
+	"Icon":                RoleImage,
+}
+
+// statusNames are name fragments that make a text widget a status, as in
+// Named("status", label) or Named("save-error", label).
+var statusNames = []string{"status", "error", "warning", "message", "toast"}
+
+// InferRole returns the semantic role of a widget tree node, or "" for
+// layout containers and objects without one. Text widgets named like a
+// status line (see Named) are statuses.
+func InferRole(node *WidgetNode) SemanticRole {
+	if node == nil {
+		return ""
+	}
+	role := semanticRoles[node.Type[strings.LastIndex(node.Type, ".")+1:]]
+	if role == RoleText {
+		name := strings.ToLower(node.Name)
+		for _, fragment := range statusNames {
+			if strings.Contains(name, fragment) {
+				return RoleStatus
+			}
+		}
+	}
+	return role
+}
+
+// AIScreen describes one captured screen for LLM agents that analyze or
+// drive the UI: a plain-language summary and the widgets that matter, each
+// with its role, label, state and place on the canvas.
+type AIScreen struct {
+	Test        string  `json:"test"`
+	Description string  `json:"description,omitempty"`
+	Screenshot  string  `json:"screenshot,omitempty"`
+	Width       float32 `json:"width"`
+	Height      float32 `json:"height"`
+	Summary     string  `json:"summary"`
+
+	Elements []AIElement `json:"elements"`
+}
+
+// AIElement is a visible widget with a semantic role.
+type AIElement struct {
+	Role SemanticRole `json:"role"`
+	Type string       `json:"type"`
+	Name string       `json:"name,omitempty"`
+
+	// Label is the text the widget shows or holds
+	Label string `json:"label,omitempty"`
+
+	// State holds properties such as "disabled", "checked" or "value"
+	State map[string]string `json:"state,omitempty"`
+
+	// Bounds is the widget's area in canvas coordinates
+	Bounds AIBounds `json:"bounds"`
+
+	// Path locates the widget in the widget tree, as WidgetVisit.Path does
+	Path string `json:"path"`
+}
+
+// AIBounds is a rectangle in canvas coordinates.
+type AIBounds struct {
+	X      float32 `json:"x"`
+	Y      float32 `json:"y"`
+	Width  float32 `json:"width"`
+	Height float32 `json:"height"`
+}
+
+// NewAIScreen describes the screen of test captured with tree. Hidden
+// widgets, and everything inside them, are left out.
+func NewAIScreen(test Test, tree *WidgetTree) AIScreen {
+	screen := AIScreen{Test: test.Name, Description: test.Description, Elements: make([]AIElement, 0)}
+	if tree != nil && tree.Root != nil {
+		screen.Width, screen.Height = tree.Root.Size.Width, tree.Root.Size.Height
+	}
+	tree.Walk(func(v WidgetVisit) bool {
+		if v.Node.Hidden {
+			return false
+		}
+		role := v.Node.Role
+		if role == "" {
+			role = InferRole(v.Node)
+		}
+		if role != "" {
+			region := v.Region()
+			screen.Elements = append(screen.Elements, AIElement{
+				Role:   role,
+				Type:   v.Node.Type,
+				Name:   v.Node.Name,
+				Label:  v.Node.Text,
+				State:  v.Node.Props,
+				Bounds: AIBounds{X: region.X, Y: region.Y, Width: region.Width, Height: region.Height},
+				Path:   v.Path,
+			})
+		}
+		return true
+	})
+	screen.Summary = screen.summarize()
+	return screen
+}
+
+// summaryRoles lists roles in the order a summary mentions them, with the
+// singular and plural nouns used.
+var summaryRoles = []struct {
+	role             SemanticRole
+	singular, plural string
+}{
+	{RoleNavigation, "navigation element", "navigation elements"},
+	{RoleTextInput, "text input", "text inputs"},
+	{RoleChoice, "choice", "choices"},
+	{RoleCheckbox, "checkbox", "checkboxes"},
+	{RoleSlider, "slider", "sliders"},
+	{RoleButton, "button", "buttons"},
+	{RoleDataTable, "data table", "data tables"},
+	{RoleImage, "image", "images"},
+	{RoleText, "text", "texts"},
+}
+
+// summaryLabels is how many labels a summary quotes per role.
+const summaryLabels = 4
+
+// summarize describes the screen in a few sentences: what it is, its
+// interactive elements by role with their labels, and its status lines.
+func (s AIScreen) summarize() string {
+	var b strings.Builder
+	subject := s.Description
+	if subject == "" {
+		subject = s.Test
+	}
+	fmt.Fprintf(&b, "Screen %q (%gx%g)", subject, s.Width, s.Height)
+
+	byRole := make(map[SemanticRole][]AIElement)
+	for _, element := range s.Elements {
+		byRole[element.Role] = append(byRole[element.Role], element)
+	}
+	parts := make([]string, 0, len(summaryRoles))
+	for _, r := range summaryRoles {
+		elements := byRole[r.role]
+		if len(elements) == 0 {
+			continue
+		}
+		noun := r.plural
+		if len(elements) == 1 {
+			noun = r.singular
+		}
+		part := fmt.Sprintf("%d %s", len(elements), noun)
+		if r.role != RoleText {
+			part += summaryLabelList(elements)
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		b.WriteString(" shows no recognised widgets.")
+	} else {
+		b.WriteString(" shows " + joinList(parts) + ".")
+	}
+
+	for _, status := range byRole[RoleStatus] {
+		switch {
+		case status.Label != "":
+			fmt.Fprintf(&b, " Status: %q.", status.Label)
+		case status.State["value"] != "":
+			if value, err := strconv.ParseFloat(status.State["value"], 64); err == nil {
+				fmt.Fprintf(&b, " Progress: %.0f%%.", value*100)
+			}
+		}
+	}
+
+	disabled := make([]string, 0)
+	for _, element := range s.Elements {
+		if element.State["disabled"] == "true" {
+			disabled = append(disabled, elementLabel(element))
+		}
+	}
+	if len(disabled) > 0 {
+		fmt.Fprintf(&b, " Disabled: %s.", joinList(disabled))
+	}
+	return b.String()
+}
+
+// summaryLabelList returns the quoted labels of up to summaryLabels
+// elements in parentheses, or "" if none has one.
+func summaryLabelList(elements []AIElement) string {
+	labels := make([]string, 0, summaryLabels)
+	for _, element := range elements {
+		if element.Label == "" && element.Name == "" && element.State["placeholder"] == "" {
+			continue
+		}
+		if len(labels) == summaryLabels {
+			labels = append(labels, "…")
+			break
+		}
+		labels = append(labels, elementLabel(element))
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(labels, ", ") + ")"
+}
+
+// elementLabel names an element by its label, else its name, else its type.
+// Text inputs go by their placeholder first, which says what they are for.
+func elementLabel(element AIElement) string {
+	placeholder := element.State["placeholder"]
+	switch {
+	case placeholder != "" && (element.Role == RoleTextInput || element.Label == ""):
+		return fmt.Sprintf("%q", placeholder)
+	case element.Label != "":
+		return fmt.Sprintf("%q", element.Label)
+	case element.Name != "":
+		return element.Name
+	}
+	return element.Type
+}
+
+// joinList joins items as "a, b and c".
+func joinList(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+// WriteAIExport writes the AI description of a captured result next to its
+// screenshot, as <screenshot>.ai.json, and returns its path.
+func WriteAIExport(result Result) (string, error) {
+	if result.ScreenshotPath == "" || result.WidgetTree == nil {
+		return "", fmt.Errorf("no capture to describe")
+	}
+	screen := NewAIScreen(result.Test, result.Tree())
+	screen.Screenshot = filepath.Base(result.ScreenshotPath)
+
+	data, err := json.MarshalIndent(screen, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSuffix(result.ScreenshotPath, ".png") + AISuffix
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,148 @@
+package fynetest
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+// Importance weights the changes inside a region when scoring the severity
+// of a diff: a weight of 4 makes a change to the primary button count four
+// times as much as the same change elsewhere, 0.25 makes changes to a
+// decorative background count a quarter. Pixels outside every region weigh 1.
+type Importance struct {
+	Region Region
+	Weight float64
+}
+
+// WeightedRect is an Importance converted to the pixels of a capture.
+type WeightedRect struct {
+	Rect   image.Rectangle
+	Weight float64
+}
+
+// WeightedRects converts importance regions into pixel rectangles of img, a
+// capture of canvas c, like IgnoreRects does for ignored regions.
+func WeightedRects(c fyne.Canvas, img image.Image, importance []Importance) []WeightedRect {
+	rects := make([]WeightedRect, 0, len(importance))
+	for _, imp := range importance {
+		if rect := PixelRect(c, img, imp.Region); !rect.Empty() {
+			rects = append(rects, WeightedRect{Rect: rect, Weight: imp.Weight})
+		}
+	}
+	return rects
+}
+
+// cropWeights moves weighted rectangles into a capture cropped to crop.
+func cropWeights(weights []WeightedRect, crop image.Rectangle) []WeightedRect {
+	rects := make([]WeightedRect, 0, len(weights))
+	for _, w := range weights {
+		if rect := w.Rect.Intersect(crop); !rect.Empty() {
+			rects = append(rects, WeightedRect{Rect: rect.Sub(crop.Min), Weight: w.Weight})
+		}
+	}
+	return rects
+}
+
+// Severity scores how much actual visibly changed from expected: the sum,
+// over every pixel differing beyond tol, of its largest channel delta
+// (0-1) times the weight of the region it lies in, as a percentage of all
+// pixels. It grows with both the area and the contrast of a change, so a
+// one pixel anti-aliasing shift scores close to 0 while inverting the whole
+// capture scores 100 (more with weights above 1). Images of different
+// sizes score 100.
+func Severity(expected, actual image.Image, tol Tolerance, weights []WeightedRect) float64 {
+	if expected.Bounds().Size() != actual.Bounds().Size() {
+		return 100
+	}
+
+	e, a := newPixelBuffer(expected), newPixelBuffer(actual)
+	defer e.release()
+	defer a.release()
+	if e.w == 0 || e.h == 0 {
+		return 0
+	}
+
+	var sum float64
+	for y := 0; y < e.h; y++ {
+		for x := 0; x < e.w; x++ {
+			ep, ap := e.at(x, y), a.at(x, y)
+			delta := maxChannelDelta(ep, ap)
+			if !tol.differs(ep, ap, delta) {
+				continue
+			}
+			sum += float64(delta) / 255 * pixelWeight(weights, x, y)
+		}
+	}
+	return sum / float64(e.w*e.h) * 100
+}
+
+// pixelWeight returns the weight of the pixel at x, y: the largest weight
+// of the rectangles containing it, or 1.
+func pixelWeight(weights []WeightedRect, x, y int) float64 {
+	weight, found := 1.0, false
+	p := image.Pt(x, y)
+	for _, w := range weights {
+		if p.In(w.Rect) && (!found || w.Weight > weight) {
+			weight, found = w.Weight, true
+		}
+	}
+	return weight
+}
+
+// severity scores the diff of img against the decoded baseline expected of
+// test, with the ignored rectangles masked out.
+func (r *Runner) severity(test Test, expected, img image.Image, ignored []image.Rectangle, weights []WeightedRect) float64 {
+	if expected.Bounds().Size() == img.Bounds().Size() && len(ignored) > 0 {
+		expected, img = maskedPair(expected, img, ignored)
+		defer ReleaseImage(expected)
+		defer ReleaseImage(img)
+	}
+	return Severity(expected, img, r.ToleranceFor(test), weights)
+}
+
+// ChangeBudget returns the largest severity a capture of test may differ
+// from its baseline by and still pass: the test's own budget, else the
+// smallest ChangeBudgets entry of its tags, else the "" entry. 0 means no
+// budget.
+func (r *Runner) ChangeBudget(test Test) float64 {
+	if test.ChangeBudget > 0 {
+		return test.ChangeBudget
+	}
+	budget, found := 0.0, false
+	for _, tag := range test.Tags {
+		if b, ok := r.ChangeBudgets[tag]; ok && (!found || b < budget) {
+			budget, found = b, true
+		}
+	}
+	if found {
+		return budget
+	}
+	return r.ChangeBudgets[""]
+}
+
+// ParseChangeBudgets parses change budgets given as comma separated
+// "tag=budget" pairs; a bare budget applies to tests without a tagged one,
+// e.g. "0.5,charts=2,branding=0".
+func ParseChangeBudgets(spec string) (map[string]float64, error) {
+	budgets := make(map[string]float64)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		tag, value, ok := strings.Cut(part, "=")
+		if !ok {
+			tag, value = "", part
+		}
+		budget, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || budget < 0 {
+			return nil, fmt.Errorf("invalid change budget %q", part)
+		}
+		budgets[strings.TrimSpace(tag)] = budget
+	}
+	return budgets, nil
+}
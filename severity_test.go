@@ -0,0 +1,92 @@
+package fynetest
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestSeverityScalesWithAreaContrastAndWeight(t *testing.T) {
+	expected := image.NewRGBA(image.Rect(0, 0, 100, 100))
+	draw.Draw(expected, expected.Rect, image.NewUniform(color.Black), image.Point{}, draw.Src)
+	changed := func(c color.Color, rect image.Rectangle) *image.RGBA {
+		actual := image.NewRGBA(expected.Rect)
+		copy(actual.Pix, expected.Pix)
+		draw.Draw(actual, rect, image.NewUniform(c), image.Point{}, draw.Src)
+		return actual
+	}
+	square := image.Rect(0, 0, 10, 10)
+
+	tests := []struct {
+		name    string
+		actual  image.Image
+		weights []WeightedRect
+		want    float64
+	}{
+		{"identical", expected, nil, 0},
+		{"full contrast", changed(color.White, square), nil, 1},
+		{"twice the area", changed(color.White, image.Rect(0, 0, 20, 10)), nil, 2},
+		{"half contrast", changed(color.Gray{0x80}, square), nil, 0x80 / 255.0},
+		{"weighted", changed(color.White, square), []WeightedRect{{Rect: image.Rect(0, 0, 5, 10), Weight: 4}}, 2.5},
+		{"size mismatch", image.NewRGBA(image.Rect(0, 0, 10, 10)), nil, 100},
+	}
+	for _, tt := range tests {
+		if got := Severity(expected, tt.actual, ExactTolerance, tt.weights); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: Severity() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChangeBudgetResolution(t *testing.T) {
+	r := NewRunner()
+	r.ChangeBudgets = map[string]float64{"": 0.5, "charts": 3, "forms": 1}
+
+	tests := []struct {
+		test Test
+		want float64
+	}{
+		{Test{Name: "Own", ChangeBudget: 7, Tags: []string{"charts"}}, 7},
+		{Test{Name: "Tagged", Tags: []string{"charts", "forms"}}, 1},
+		{Test{Name: "Untagged"}, 0.5},
+	}
+	for _, tt := range tests {
+		if got := r.ChangeBudget(tt.test); got != tt.want {
+			t.Errorf("%s: ChangeBudget() = %v, want %v", tt.test.Name, got, tt.want)
+		}
+	}
+}
+
+func TestChangesWithinBudgetPass(t *testing.T) {
+	r := NewRunner()
+	r.BaselineDir = t.TempDir()
+	expected, actual := focusImages()
+	if err := SaveBaseline(filepath.Join(r.BaselineDir, "Budget.png"), expected); err != nil {
+		t.Fatal(err)
+	}
+
+	// 925 pixels changed by half the contrast: a severity of about 4.64
+	tests := []struct {
+		name    string
+		budget  float64
+		focus   []FocusRect
+		wantErr bool
+	}{
+		{"no budget", 0, nil, true},
+		{"within budget", 5, nil, false},
+		{"over budget", 4, nil, true},
+		{"focus region changed", 5, []FocusRect{{Rect: image.Rect(60, 60, 100, 100), Tolerance: ExactTolerance}}, true},
+	}
+	for _, tt := range tests {
+		test := Test{Name: "Budget", ChangeBudget: tt.budget}
+		diff, err := r.compareBaseline(test, actual, nil, nil, tt.focus)
+		if (err != nil) != tt.wantErr || diff.WithinBudget == tt.wantErr {
+			t.Errorf("%s: error %v, within budget %v; want failure %v", tt.name, err, diff.WithinBudget, tt.wantErr)
+		}
+		if math.Abs(diff.Severity-4.64) > 0.01 {
+			t.Errorf("%s: severity %.3f, want 4.64", tt.name, diff.Severity)
+		}
+	}
+}
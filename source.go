@@ -0,0 +1,150 @@
+package fynetest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BaselineSource provides baselines kept outside the repository. The
+// baseline directory then acts as a local cache: before a comparison the
+// source refreshes the cached copy of the baseline.
+type BaselineSource interface {
+	// Fetch writes the baseline with the given file name, e.g.
+	// "login_form.png", to path unless the copy at path is up to date, and
+	// reports whether it wrote it. It returns an error wrapping
+	// fs.ErrNotExist when the source has no such baseline.
+	Fetch(name, path string) (bool, error)
+}
+
+// HTTPBaselineSource fetches baselines from "<BaseURL>/<name>". Cached copies
+// are revalidated with If-Modified-Since, so unchanged baselines cost a 304
+// response rather than a download.
+type HTTPBaselineSource struct {
+	// BaseURL is the URL of the directory holding the baselines
+	BaseURL string
+
+	// Client performs the requests (default: a client with a 30s timeout)
+	Client *http.Client
+
+	// Header is added to every request, e.g. for authorization
+	Header http.Header
+}
+
+// NewHTTPBaselineSource returns a source fetching baselines below baseURL.
+func NewHTTPBaselineSource(baseURL string) *HTTPBaselineSource {
+	return &HTTPBaselineSource{
+		BaseURL: baseURL,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+		Header:  make(http.Header),
+	}
+}
+
+// Fetch implements BaselineSource.
+func (s *HTTPBaselineSource) Fetch(name, path string) (bool, error) {
+	target := strings.TrimSuffix(s.BaseURL, "/") + "/" + url.PathEscape(name)
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return false, err
+	}
+	for key, values := range s.Header {
+		req.Header[key] = values
+	}
+	if info, err := os.Stat(path); err == nil {
+		req.Header.Set("If-Modified-Since", info.ModTime().UTC().Format(http.TimeFormat))
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch baseline %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusNotFound:
+		return false, fmt.Errorf("baseline %s not found at %s: %w", name, target, fs.ErrNotExist)
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("failed to fetch baseline %s: %s", name, resp.Status)
+	}
+
+	if err := writeFileAtomic(path, resp.Body); err != nil {
+		return false, fmt.Errorf("failed to cache baseline %s: %w", name, err)
+	}
+	// Keep the server's clock for the next If-Modified-Since
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		os.Chtimes(path, modified, modified)
+	}
+	return true, nil
+}
+
+// writeFileAtomic writes r to path through a temporary file, so an
+// interrupted download never leaves a truncated baseline behind.
+func writeFileAtomic(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// FetchBaseline refreshes the cached baseline at path from source. A new
+// copy replaces the digest, widget tree and metadata files stored with the
+// previous one and is recorded in the manifest. A baseline the source
+// doesn't have is not an error; the cached copy, if any, is kept.
+func FetchBaseline(source BaselineSource, path, testName string) (bool, error) {
+	fetched, err := source.Fetch(filepath.Base(path), path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil || !fetched {
+		return false, err
+	}
+
+	for _, suffix := range []string{DigestSuffix, TreeSuffix, MetaSuffix} {
+		if err := os.Remove(path + suffix); err != nil && !os.IsNotExist(err) {
+			return true, err
+		}
+	}
+	return true, RecordBaseline(path, testName)
+}
+
+// fetchBaseline refreshes the cached baseline of test from BaselineSource.
+// Baselines written by this run are never replaced by their remote copy.
+func (r *Runner) fetchBaseline(test Test) error {
+	if r.BaselineSource == nil || r.Update.Matches(test.Name, test.Tags) {
+		return nil
+	}
+
+	path := r.BaselinePath(test)
+	fetched, err := FetchBaseline(r.BaselineSource, path, test.Name)
+	if fetched {
+		r.forgetProblem(path)
+	}
+	return err
+}
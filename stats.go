@@ -0,0 +1,129 @@
+package fynetest
+
+import (
+	"math"
+	"sort"
+)
+
+// ImageStats aggregates capture sizes and baseline diff scores over a set of
+// results, suitable for feeding quality dashboards.
+type ImageStats struct {
+	// Captures is the number of results with a captured image
+	Captures int `json:"captures"`
+	
+	// TotalPixels is the number of pixels rendered across all captures
+	TotalPixels int64 `json:"total_pixels"`
+	
+	// AverageWidth is the mean capture width in pixels
+	AverageWidth float64 `json:"average_width"`
+	
+	// AverageHeight is the mean capture height in pixels
+	AverageHeight float64 `json:"average_height"`
+	
+	// AveragePixels is the mean number of pixels per capture
+	AveragePixels float64 `json:"average_pixels"`
+	
+	// Compared is the number of captures compared against a baseline
+	Compared int `json:"compared"`
+	
+	// DiffScores describes the distribution of diff percentages of compared captures
+	DiffScores DiffDistribution `json:"diff_scores"`
+}
+
+// DiffDistribution summarises a set of diff percentages (0-100).
+type DiffDistribution struct {
+	Min     float64      `json:"min"`
+	Max     float64      `json:"max"`
+	Mean    float64      `json:"mean"`
+	Median  float64      `json:"median"`
+	P95     float64      `json:"p95"`
+	Buckets []DiffBucket `json:"buckets"`
+}
+
+// DiffBucket counts comparisons whose diff percentage is at most UpTo and
+// above the previous bucket's bound.
+type DiffBucket struct {
+	Label string  `json:"label"`
+	UpTo  float64 `json:"up_to"`
+	Count int     `json:"count"`
+}
+
+// diffBucketBounds are the upper bounds of the diff score histogram.
+var diffBucketBounds = []struct {
+	label string
+	upTo  float64
+}{
+	{"identical", 0},
+	{"<=0.1%", 0.1},
+	{"<=1%", 1},
+	{"<=5%", 5},
+	{">5%", 100},
+}
+
+// ComputeImageStats aggregates capture and diff statistics over results.
+func ComputeImageStats(results []Result) ImageStats {
+	var stats ImageStats
+	var width, height float64
+	scores := make([]float64, 0)
+	
+	for _, result := range results {
+		if result.ImageSize.Width > 0 && result.ImageSize.Height > 0 {
+			stats.Captures++
+			width += float64(result.ImageSize.Width)
+			height += float64(result.ImageSize.Height)
+			stats.TotalPixels += int64(result.ImageSize.Width) * int64(result.ImageSize.Height)
+		}
+		if result.Diff != nil && !result.Diff.SizeMismatch {
+			scores = append(scores, result.Diff.DiffPercent)
+		}
+	}
+	
+	if stats.Captures > 0 {
+		n := float64(stats.Captures)
+		stats.AverageWidth = width / n
+		stats.AverageHeight = height / n
+		stats.AveragePixels = float64(stats.TotalPixels) / n
+	}
+	
+	stats.Compared = len(scores)
+	stats.DiffScores = newDiffDistribution(scores)
+	return stats
+}
+
+func newDiffDistribution(scores []float64) DiffDistribution {
+	dist := DiffDistribution{Buckets: make([]DiffBucket, len(diffBucketBounds))}
+	for i, b := range diffBucketBounds {
+		dist.Buckets[i] = DiffBucket{Label: b.label, UpTo: b.upTo}
+	}
+	if len(scores) == 0 {
+		return dist
+	}
+	
+	sort.Float64s(scores)
+	sum := 0.0
+	for _, score := range scores {
+		sum += score
+		for i := range dist.Buckets {
+			if score <= dist.Buckets[i].UpTo {
+				dist.Buckets[i].Count++
+				break
+			}
+		}
+	}
+	
+	dist.Min = scores[0]
+	dist.Max = scores[len(scores)-1]
+	dist.Mean = sum / float64(len(scores))
+	dist.Median = percentile(scores, 50)
+	dist.P95 = percentile(scores, 95)
+	return dist
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
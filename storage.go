@@ -0,0 +1,231 @@
+package fynetest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Storage stores files by slash-separated key, such as baselines and run
+// directories kept in a bucket. Missing keys yield errors wrapping
+// fs.ErrNotExist.
+type Storage interface {
+	// Get returns the content stored under key
+	Get(key string) ([]byte, error)
+
+	// Put stores data under key, replacing any previous content
+	Put(key string, data []byte) error
+
+	// Stat describes the content stored under key without fetching it
+	Stat(key string) (StorageInfo, error)
+}
+
+// StorageInfo describes stored content.
+type StorageInfo struct {
+	Size    int64
+	ModTime time.Time
+
+	// MD5 is the hex MD5 of the content, when the storage reports it
+	MD5 string
+}
+
+// Key prefixes used by a suite's Storage.
+const (
+	storageBaselines = "baselines"
+	storageRuns      = "runs"
+)
+
+// OpenStorage returns the storage for a location: "s3://bucket/prefix",
+// "gs://bucket/prefix", "file:///path" or a plain directory path. Bucket
+// credentials are read from the environment; see NewS3StorageFromEnv and
+// NewGCSStorageFromEnv.
+func OpenStorage(location string) (Storage, error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		// Plain paths, including Windows drive letters
+		return DirStorage(location), nil
+	}
+
+	var storage Storage
+	switch u.Scheme {
+	case "file":
+		return DirStorage(filepath.FromSlash(u.Path)), nil
+	case "s3":
+		storage, err = NewS3StorageFromEnv(u.Host)
+	case "gs":
+		storage, err = NewGCSStorageFromEnv(u.Host)
+	default:
+		return nil, fmt.Errorf("unsupported storage %q (use s3://, gs:// or a directory)", location)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return PrefixStorage(storage, u.Path), nil
+}
+
+// DirStorage stores files below a local directory.
+type DirStorage string
+
+func (d DirStorage) path(key string) string {
+	return filepath.Join(string(d), filepath.FromSlash(key))
+}
+
+// Get implements Storage.
+func (d DirStorage) Get(key string) ([]byte, error) {
+	return os.ReadFile(d.path(key))
+}
+
+// Put implements Storage.
+func (d DirStorage) Put(key string, data []byte) error {
+	p := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0644)
+}
+
+// Stat implements Storage.
+func (d DirStorage) Stat(key string) (StorageInfo, error) {
+	info, err := os.Stat(d.path(key))
+	if err != nil {
+		return StorageInfo{}, err
+	}
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return StorageInfo{}, err
+	}
+	sum := md5.Sum(data)
+	return StorageInfo{Size: info.Size(), ModTime: info.ModTime(), MD5: hex.EncodeToString(sum[:])}, nil
+}
+
+// ChunkedStorage is implemented by storages that accept large objects in
+// parts, such as S3 and GCS multipart uploads. An upload can be continued
+// after an interruption by uploading the missing parts under the same ID.
+type ChunkedStorage interface {
+	Storage
+
+	// BeginChunked starts an upload of key and returns its ID
+	BeginChunked(key string) (string, error)
+
+	// PutChunk uploads part n, counting from 1, and returns its tag
+	PutChunk(key, uploadID string, n int, data []byte) (string, error)
+
+	// CompleteChunked assembles the parts, given by their tags in order
+	CompleteChunked(key, uploadID string, tags []string) error
+}
+
+type prefixStorage struct {
+	Storage
+	prefix string
+}
+
+// PrefixStorage returns a storage placing every key below prefix. It is a
+// ChunkedStorage when storage is one.
+func PrefixStorage(storage Storage, prefix string) Storage {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return storage
+	}
+	p := prefixStorage{Storage: storage, prefix: prefix}
+	if chunked, ok := storage.(ChunkedStorage); ok {
+		return chunkedPrefixStorage{prefixStorage: p, chunked: chunked}
+	}
+	return p
+}
+
+func (p prefixStorage) Get(key string) ([]byte, error) {
+	return p.Storage.Get(path.Join(p.prefix, key))
+}
+
+func (p prefixStorage) Put(key string, data []byte) error {
+	return p.Storage.Put(path.Join(p.prefix, key), data)
+}
+
+func (p prefixStorage) Stat(key string) (StorageInfo, error) {
+	return p.Storage.Stat(path.Join(p.prefix, key))
+}
+
+type chunkedPrefixStorage struct {
+	prefixStorage
+	chunked ChunkedStorage
+}
+
+func (p chunkedPrefixStorage) BeginChunked(key string) (string, error) {
+	return p.chunked.BeginChunked(path.Join(p.prefix, key))
+}
+
+func (p chunkedPrefixStorage) PutChunk(key, uploadID string, n int, data []byte) (string, error) {
+	return p.chunked.PutChunk(path.Join(p.prefix, key), uploadID, n, data)
+}
+
+func (p chunkedPrefixStorage) CompleteChunked(key, uploadID string, tags []string) error {
+	return p.chunked.CompleteChunked(path.Join(p.prefix, key), uploadID, tags)
+}
+
+// BaselineSink is implemented by baseline sources that also accept updated
+// baselines, so -update-snapshots publishes them.
+type BaselineSink interface {
+	// Store uploads the baseline at path under the given file name
+	Store(name, path string) error
+}
+
+// StorageSource serves baselines from a Storage and stores updated ones in
+// it. It implements BaselineSource and BaselineSink.
+type StorageSource struct {
+	Storage Storage
+}
+
+// NewStorageSource returns a baseline source keeping baselines below the
+// "baselines/" key prefix of storage.
+func NewStorageSource(storage Storage) *StorageSource {
+	return &StorageSource{Storage: PrefixStorage(storage, storageBaselines)}
+}
+
+// Fetch implements BaselineSource. A cached copy with the same MD5 as the
+// stored baseline is kept without downloading it.
+func (s *StorageSource) Fetch(name, path string) (bool, error) {
+	info, err := s.Storage.Stat(name)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch baseline %s: %w", name, err)
+	}
+	if info.MD5 != "" {
+		if local, err := os.ReadFile(path); err == nil {
+			if sum := md5.Sum(local); hex.EncodeToString(sum[:]) == info.MD5 {
+				return false, nil
+			}
+		}
+	}
+
+	data, err := s.Storage.Get(name)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch baseline %s: %w", name, err)
+	}
+	if err := writeFileAtomic(path, bytes.NewReader(data)); err != nil {
+		return false, fmt.Errorf("failed to cache baseline %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// Store implements BaselineSink.
+func (s *StorageSource) Store(name, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return s.Storage.Put(name, data)
+}
+
+// UploadRun copies a run directory, with its captures, review images and
+// reports, to storage below "runs/<run>/" and returns the number of files
+// uploaded. Relative links in the report keep working in the bucket. It
+// uses the default UploadOptions; see UploadRunWithOptions.
+func UploadRun(storage Storage, runDir string) (int, error) {
+	return UploadRunWithOptions(storage, runDir, UploadOptions{})
+}
@@ -0,0 +1,326 @@
+package fynetest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BucketStorage stores objects in an S3 or GCS bucket through their REST
+// APIs. Use NewS3Storage or NewGCSStorage to create one.
+type BucketStorage struct {
+	// Endpoint is the service URL; objects live at "<Endpoint>/<Bucket>/<key>"
+	Endpoint string
+
+	// Bucket is the bucket name
+	Bucket string
+
+	// Client performs the requests (default: a client with a 60s timeout)
+	Client *http.Client
+
+	// sign authorizes a request carrying a body with the given SHA-256
+	sign func(req *http.Request, payloadHash string)
+}
+
+// S3Credentials authenticate requests to S3 and S3-compatible services.
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+}
+
+// NewS3Storage returns storage for an S3 bucket, signing requests with AWS
+// Signature Version 4. An empty endpoint selects AWS for the region; set it
+// for S3-compatible services such as MinIO.
+func NewS3Storage(endpoint, bucket string, creds S3Credentials) *BucketStorage {
+	if creds.Region == "" {
+		creds.Region = "us-east-1"
+	}
+	if endpoint == "" {
+		endpoint = "https://s3." + creds.Region + ".amazonaws.com"
+	}
+	return &BucketStorage{
+		Endpoint: strings.TrimSuffix(endpoint, "/"),
+		Bucket:   bucket,
+		Client:   &http.Client{Timeout: 60 * time.Second},
+		sign: func(req *http.Request, payloadHash string) {
+			signV4(req, payloadHash, creds, time.Now().UTC())
+		},
+	}
+}
+
+// NewS3StorageFromEnv returns storage for an S3 bucket using the standard
+// AWS variables: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN,
+// AWS_REGION (or AWS_DEFAULT_REGION) and, for S3-compatible services,
+// AWS_ENDPOINT_URL.
+func NewS3StorageFromEnv(bucket string) (*BucketStorage, error) {
+	creds := S3Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Region:          os.Getenv("AWS_REGION"),
+	}
+	if creds.Region == "" {
+		creds.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 storage needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	return NewS3Storage(os.Getenv("AWS_ENDPOINT_URL"), bucket, creds), nil
+}
+
+// NewGCSStorage returns storage for a Google Cloud Storage bucket,
+// authorizing requests with an OAuth 2.0 access token.
+func NewGCSStorage(bucket, accessToken string) *BucketStorage {
+	return &BucketStorage{
+		Endpoint: "https://storage.googleapis.com",
+		Bucket:   bucket,
+		Client:   &http.Client{Timeout: 60 * time.Second},
+		sign: func(req *http.Request, _ string) {
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+		},
+	}
+}
+
+// NewGCSStorageFromEnv returns storage for a GCS bucket using the access
+// token in GOOGLE_OAUTH_ACCESS_TOKEN, e.g. from
+// "gcloud auth print-access-token".
+func NewGCSStorageFromEnv(bucket string) (*BucketStorage, error) {
+	token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("gcs storage needs GOOGLE_OAUTH_ACCESS_TOKEN")
+	}
+	return NewGCSStorage(bucket, token), nil
+}
+
+// Get implements Storage.
+func (b *BucketStorage) Get(key string) ([]byte, error) {
+	resp, err := b.do(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// Put implements Storage.
+func (b *BucketStorage) Put(key string, data []byte) error {
+	resp, err := b.do(http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Stat implements Storage. The MD5 is taken from the ETag, which both
+// services set to the content MD5 for objects uploaded in one piece.
+func (b *BucketStorage) Stat(key string) (StorageInfo, error) {
+	resp, err := b.do(http.MethodHead, key, nil)
+	if err != nil {
+		return StorageInfo{}, err
+	}
+	resp.Body.Close()
+
+	info := StorageInfo{Size: resp.ContentLength}
+	info.ModTime, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	if etag := strings.Trim(resp.Header.Get("ETag"), `"`); len(etag) == 32 {
+		info.MD5 = etag
+	}
+	return info, nil
+}
+
+// BeginChunked implements ChunkedStorage with a multipart upload, which
+// both services offer through their XML APIs.
+func (b *BucketStorage) BeginChunked(key string) (string, error) {
+	resp, err := b.doQuery(http.MethodPost, key, "uploads=", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil || result.UploadID == "" {
+		return "", fmt.Errorf("POST %s: invalid multipart upload response", key)
+	}
+	return result.UploadID, nil
+}
+
+// PutChunk implements ChunkedStorage. Parts other than the last must be at
+// least 5 MiB.
+func (b *BucketStorage) PutChunk(key, uploadID string, n int, data []byte) (string, error) {
+	query := fmt.Sprintf("partNumber=%d&uploadId=%s", n, escapeQueryValue(uploadID))
+	resp, err := b.doQuery(http.MethodPut, key, query, data)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+// CompleteChunked implements ChunkedStorage.
+func (b *BucketStorage) CompleteChunked(key, uploadID string, tags []string) error {
+	type part struct {
+		PartNumber int
+		ETag       string
+	}
+	request := struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}{}
+	for i, tag := range tags {
+		request.Parts = append(request.Parts, part{PartNumber: i + 1, ETag: tag})
+	}
+	body, err := xml.Marshal(request)
+	if err != nil {
+		return err
+	}
+	resp, err := b.doQuery(http.MethodPost, key, "uploadId="+escapeQueryValue(uploadID), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// S3 reports some failures with a 200 status and an error document
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if bytes.Contains(message, []byte("<Error>")) {
+		return fmt.Errorf("POST %s: %s", key, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// do sends a signed request for the object under key and returns the
+// response of a successful request.
+func (b *BucketStorage) do(method, key string, body []byte) (*http.Response, error) {
+	return b.doQuery(method, key, "", body)
+}
+
+// doQuery is do with a query string, which must already be in canonical
+// form: sorted by parameter and encoded with escapeQueryValue.
+func (b *BucketStorage) doQuery(method, key, query string, body []byte) (*http.Response, error) {
+	target := b.Endpoint + "/" + b.Bucket + "/" + escapeKey(key)
+	if query != "" {
+		target += "?" + query
+	}
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	b.sign(req, hex.EncodeToString(sum[:]))
+
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, &fs.PathError{Op: strings.ToLower(method), Path: key, Err: fs.ErrNotExist}
+	case resp.StatusCode >= 300:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: %s %s", method, key, resp.Status, bytes.TrimSpace(message))
+	}
+	return resp, nil
+}
+
+// escapeKey percent-encodes an object key as required by Signature Version
+// 4: everything but unreserved characters and the slashes between segments.
+func escapeKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// escapeQueryValue percent-encodes a query parameter value as required by
+// Signature Version 4.
+func escapeQueryValue(value string) string {
+	return strings.ReplaceAll(escapeKey(value), "/", "%2F")
+}
+
+// signV4 adds an AWS Signature Version 4 Authorization header to req.
+func signV4(req *http.Request, payloadHash string, creds S3Credentials, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := date + "/" + creds.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	for _, part := range []string{creds.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,353 @@
+package fynetest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+)
+
+// CompareFile compares the PNG baseline at path with actual, skipping the
+// ignored rectangles of actual. Baselines stored as 8-bit RGB or RGBA are
+// decoded one scanline at a time and the walk stops at the first row that
+// takes the differing pixels over the tolerance, so the common passing case
+// never holds the whole baseline in memory.
+//
+// Other PNG formats, CompareStructural and IgnoreAntialiasing, which needs
+// the neighbouring rows of every pixel, fall back to decoding the full image.
+// When the walk stops early the result has Partial set and its counts cover
+// only the rows read; use CompareImages for exact counts or a diff image.
+func CompareFile(path string, actual image.Image, tol Tolerance, ignored []image.Rectangle) (DiffResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	if tol.Mode != CompareStructural && !tol.IgnoreAntialiasing {
+		stream, err := newPNGStream(r)
+		if err == nil {
+			defer stream.Close()
+			return compareStream(stream, actual, tol, ignored)
+		}
+		if !errors.Is(err, errNotStreamable) {
+			return DiffResult{}, err
+		}
+
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return DiffResult{}, err
+		}
+		r.Reset(file)
+	}
+
+	return compareDecoded(r, actual, ignored, tol)
+}
+
+// compareDecoded decodes the whole baseline from r and compares it with
+// actual using comparer, masking the ignored rectangles in both.
+func compareDecoded(r io.Reader, actual image.Image, ignored []image.Rectangle, comparer ImageComparer) (DiffResult, error) {
+	expected, err := png.Decode(r)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	return compareImage(expected, actual, ignored, comparer)
+}
+
+// compareImage compares the decoded baseline expected with actual using
+// comparer, masking the ignored rectangles in both.
+func compareImage(expected, actual image.Image, ignored []image.Rectangle, comparer ImageComparer) (DiffResult, error) {
+	if expected.Bounds().Size() == actual.Bounds().Size() && len(ignored) > 0 {
+		expected, actual = maskedPair(expected, actual, ignored)
+		defer ReleaseImage(expected)
+		defer ReleaseImage(actual)
+	}
+	return comparer.Compare(expected, actual)
+}
+
+// compareStream walks the baseline rows of stream against actual, stopping
+// once more pixels differ than the tolerance allows.
+func compareStream(stream *pngStream, actual image.Image, tol Tolerance, ignored []image.Rectangle) (DiffResult, error) {
+	b := actual.Bounds()
+	if stream.width != b.Dx() || stream.height != b.Dy() {
+		return DiffResult{SizeMismatch: true}, nil
+	}
+
+	a := newPixelBuffer(actual)
+	defer a.release()
+	result := DiffResult{TotalPixels: a.w * a.h}
+	budget := int(tol.MaxDiffPercent / 100 * float64(result.TotalPixels))
+
+	rects := make([]image.Rectangle, len(ignored))
+	for i, rect := range ignored {
+		rects[i] = rect.Sub(b.Min)
+	}
+
+	for y := 0; y < a.h; y++ {
+		row, err := stream.next()
+		if err != nil {
+			return DiffResult{}, err
+		}
+		actualRow := a.row(y)
+		if bytes.Equal(row, actualRow) {
+			continue
+		}
+
+		for x := 0; x < a.w; x++ {
+			if insideAny(rects, x, y) {
+				continue
+			}
+			ep, ap := row[x*4:x*4+4], actualRow[x*4:x*4+4]
+			delta := maxChannelDelta(ep, ap)
+			if delta > result.MaxDelta {
+				result.MaxDelta = delta
+			}
+			if tol.differs(ep, ap, delta) {
+				result.DiffPixels++
+				result.Bounds = result.Bounds.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+
+		if result.DiffPixels > budget {
+			result = finishDiff(result, tol)
+			result.Match = false
+			result.Partial = y < a.h-1
+			return result, nil
+		}
+	}
+
+	return finishDiff(result, tol), nil
+}
+
+func insideAny(rects []image.Rectangle, x, y int) bool {
+	p := image.Pt(x, y)
+	for _, rect := range rects {
+		if p.In(rect) {
+			return true
+		}
+	}
+	return false
+}
+
+// errNotStreamable reports a valid PNG that pngStream cannot decode row by
+// row; the caller decodes it with image/png instead.
+var errNotStreamable = errors.New("png format not streamable")
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngStream decodes a non-interlaced 8-bit RGB or RGBA PNG one scanline at
+// a time into premultiplied RGBA, matching what newPixelBuffer produces for
+// the image returned by png.Decode. Its row buffers come from the pixel pool.
+type pngStream struct {
+	width, height int
+
+	bpp     int
+	inflate io.ReadCloser
+	cur     []byte
+	prev    []byte
+	rgba    []byte
+}
+
+func newPNGStream(r io.Reader) (*pngStream, error) {
+	signature := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, signature); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(signature, pngSignature) {
+		return nil, fmt.Errorf("not a PNG file")
+	}
+
+	length, kind, err := readChunkHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	if kind != "IHDR" || length != 13 {
+		return nil, fmt.Errorf("invalid PNG header")
+	}
+	header := make([]byte, 13+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	s := &pngStream{
+		width:  int(binary.BigEndian.Uint32(header[0:4])),
+		height: int(binary.BigEndian.Uint32(header[4:8])),
+	}
+	depth, colorType, interlace := header[8], header[9], header[12]
+	switch {
+	case depth != 8 || interlace != 0:
+		return nil, errNotStreamable
+	case colorType == 2:
+		s.bpp = 3
+	case colorType == 6:
+		s.bpp = 4
+	default:
+		return nil, errNotStreamable
+	}
+
+	// Skip ancillary chunks up to the image data
+	for {
+		length, kind, err = readChunkHeader(r)
+		if err != nil {
+			return nil, err
+		}
+		if kind == "IDAT" {
+			break
+		}
+		if kind == "tRNS" || kind == "IEND" {
+			return nil, errNotStreamable
+		}
+		if _, err := io.CopyN(io.Discard, r, int64(length)+4); err != nil {
+			return nil, err
+		}
+	}
+
+	s.inflate, err = zlib.NewReader(&idatReader{r: r, remaining: length})
+	if err != nil {
+		return nil, err
+	}
+	stride := 1 + s.width*s.bpp
+	s.cur = getPix(stride)
+	s.prev = getPix(stride)
+	s.rgba = getPix(4 * s.width)
+
+	// The first row is unfiltered against an all-zero previous row
+	for i := range s.prev {
+		s.prev[i] = 0
+	}
+	return s, nil
+}
+
+// next decodes the following scanline and returns its pixels. The slice is
+// reused by the next call.
+func (s *pngStream) next() ([]byte, error) {
+	if _, err := io.ReadFull(s.inflate, s.cur); err != nil {
+		return nil, fmt.Errorf("failed to decode baseline row: %w", err)
+	}
+	if err := unfilter(s.cur[0], s.cur[1:], s.prev[1:], s.bpp); err != nil {
+		return nil, err
+	}
+
+	px := s.cur[1:]
+	for x := 0; x < s.width; x++ {
+		src, dst := px[x*s.bpp:], s.rgba[x*4:x*4+4]
+		if s.bpp == 3 {
+			dst[0], dst[1], dst[2], dst[3] = src[0], src[1], src[2], 0xff
+			continue
+		}
+		// Same premultiplication as draw.Draw from *image.NRGBA
+		a := uint32(src[3]) * 0x101
+		dst[0] = uint8(uint32(src[0]) * a / 0xff >> 8)
+		dst[1] = uint8(uint32(src[1]) * a / 0xff >> 8)
+		dst[2] = uint8(uint32(src[2]) * a / 0xff >> 8)
+		dst[3] = src[3]
+	}
+
+	s.cur, s.prev = s.prev, s.cur
+	return s.rgba, nil
+}
+
+func (s *pngStream) Close() error {
+	putPix(s.cur)
+	putPix(s.prev)
+	putPix(s.rgba)
+	return s.inflate.Close()
+}
+
+func readChunkHeader(r io.Reader) (uint32, string, error) {
+	var header [8]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, "", err
+	}
+	return binary.BigEndian.Uint32(header[:4]), string(header[4:]), nil
+}
+
+// idatReader concatenates the data of consecutive IDAT chunks.
+type idatReader struct {
+	r         io.Reader
+	remaining uint32
+}
+
+func (d *idatReader) Read(p []byte) (int, error) {
+	for d.remaining == 0 {
+		// Skip the CRC of the finished chunk
+		if _, err := io.CopyN(io.Discard, d.r, 4); err != nil {
+			return 0, err
+		}
+		length, kind, err := readChunkHeader(d.r)
+		if err != nil {
+			return 0, err
+		}
+		if kind != "IDAT" {
+			return 0, io.ErrUnexpectedEOF
+		}
+		d.remaining = length
+	}
+
+	if uint32(len(p)) > d.remaining {
+		p = p[:d.remaining]
+	}
+	n, err := d.r.Read(p)
+	d.remaining -= uint32(n)
+	return n, err
+}
+
+// unfilter reverses the PNG filter of one scanline in place, given the
+// already unfiltered previous scanline.
+func unfilter(filter byte, cur, prev []byte, bpp int) error {
+	switch filter {
+	case 0:
+	case 1:
+		for i := bpp; i < len(cur); i++ {
+			cur[i] += cur[i-bpp]
+		}
+	case 2:
+		for i := range cur {
+			cur[i] += prev[i]
+		}
+	case 3:
+		for i := range cur {
+			var left byte
+			if i >= bpp {
+				left = cur[i-bpp]
+			}
+			cur[i] += byte((int(left) + int(prev[i])) / 2)
+		}
+	case 4:
+		for i := range cur {
+			var left, upLeft byte
+			if i >= bpp {
+				left, upLeft = cur[i-bpp], prev[i-bpp]
+			}
+			cur[i] += paeth(left, prev[i], upLeft)
+		}
+	default:
+		return fmt.Errorf("invalid PNG filter type %d", filter)
+	}
+	return nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := absInt(p-int(a)), absInt(p-int(b)), absInt(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,107 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// NewTAPWriter returns an event handler that writes TAP version 13 to w: a
+// plan from the suite start, one test line per finished test and a YAML
+// diagnostics block with the error, screenshot and review asset paths. It
+// is safe for concurrent use; tests are numbered in the order they finish.
+func NewTAPWriter(w io.Writer) func(Event) {
+	var mu sync.Mutex
+	count := 0
+	planned := false
+
+	return func(event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch event.Type {
+		case EventSuiteStart:
+			fmt.Fprintln(w, "TAP version 13")
+			if event.Total > 0 {
+				fmt.Fprintf(w, "1..%d\n", event.Total)
+				planned = true
+			}
+		case EventTestFinish:
+			count++
+			writeTAPResult(w, count, event.Result)
+		case EventSuiteFinish:
+			if !planned {
+				fmt.Fprintf(w, "1..%d\n", count)
+			}
+		}
+	}
+}
+
+// writeTAPResult writes the test line and diagnostics of one result.
+func writeTAPResult(w io.Writer, n int, result *JSONResult) {
+	if result == nil {
+		return
+	}
+	status := "ok"
+	if !result.Success {
+		status = "not ok"
+	}
+	name := result.Name
+	if result.Profile != "" {
+		name += " [" + result.Profile + "]"
+	}
+	// "#" would start a directive in the description
+	name = strings.ReplaceAll(name, "#", `\#`)
+	if result.Skipped {
+		fmt.Fprintf(w, "%s %d - %s # SKIP %s\n", status, n, name, strings.ReplaceAll(result.SkipReason, "\n", " "))
+		return
+	}
+	// Expected failures are TODO tests: failing doesn't fail the run
+	directive := ""
+	switch {
+	case result.ExpectedFailure:
+		status, directive = "not ok", " # TODO expected failure"
+	case result.UnexpectedPass:
+		directive = " # TODO expected to fail but passed; needs baseline review"
+	}
+	fmt.Fprintf(w, "%s %d - %s%s\n", status, n, name, directive)
+
+	diagnostics := make([][2]string, 0)
+	add := func(key, value string) {
+		if value != "" {
+			diagnostics = append(diagnostics, [2]string{key, yamlString(value)})
+		}
+	}
+	if !result.Success {
+		add("message", result.Error)
+		add("severity", "fail")
+	} else if result.ExpectedFailure {
+		add("message", result.Error)
+	}
+	add("screenshot", result.ScreenshotPath)
+	if assets := result.DiffAssets; assets != nil {
+		add("diff", assets.Diff)
+		add("heatmap", assets.Heatmap)
+		add("composite", assets.Composite)
+	}
+	if diff := result.Diff; diff != nil && !diff.Match {
+		diagnostics = append(diagnostics, [2]string{"diff_percent", fmt.Sprintf("%.2f", diff.DiffPercent)})
+	}
+	add("crash_dump", result.CrashDump)
+	diagnostics = append(diagnostics, [2]string{"duration_ms", fmt.Sprint(result.Duration.Milliseconds())})
+
+	fmt.Fprintln(w, "  ---")
+	for _, d := range diagnostics {
+		fmt.Fprintf(w, "  %s: %s\n", d[0], d[1])
+	}
+	fmt.Fprintln(w, "  ...")
+}
+
+// yamlString quotes s as a YAML double-quoted scalar, which accepts JSON
+// string escapes.
+func yamlString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
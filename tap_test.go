@@ -0,0 +1,47 @@
+package fynetest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTAPWriterGolden(t *testing.T) {
+	got := writeEvents(func(w *bytes.Buffer) func(Event) { return NewTAPWriter(w) }, writerEvents())
+	checkGolden(t, got, `TAP version 13
+1..5
+ok 1 - Login \#1 [dark]
+  ---
+  screenshot: "out/login.png"
+  duration_ms: 12
+  ...
+not ok 2 - Form's [draft]
+  ---
+  message: "capture differs:\n2.00% changed"
+  severity: "fail"
+  screenshot: "out/form.png"
+  diff: "out/form_diff.png"
+  diff_percent: 2.00
+  duration_ms: 30
+  ...
+ok 3 - Mobile # SKIP not on linux
+not ok 4 - Known bug # TODO expected failure
+  ---
+  message: "capture differs"
+  duration_ms: 5
+  ...
+ok 5 - Fixed # TODO expected to fail but passed; needs baseline review
+  ---
+  duration_ms: 7
+  ...
+`)
+}
+
+func TestTAPWriterPlansAtEndWithoutTotal(t *testing.T) {
+	events := writerEvents()
+	events[0].Total = 0
+	got := writeEvents(func(w *bytes.Buffer) func(Event) { return NewTAPWriter(w) }, events)
+	if !strings.HasPrefix(got, "TAP version 13\nok 1 - ") || !strings.HasSuffix(got, "  ...\n1..5\n") {
+		t.Errorf("plan isn't written last:\n%s", got)
+	}
+}
@@ -0,0 +1,138 @@
+package fynetest
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// TeamCityArtifactDir is the directory of the build artifacts that
+// NewTeamCityWriter publishes screenshots and review images to.
+const TeamCityArtifactDir = "vfyne"
+
+// NewTeamCityWriter returns an event handler that writes TeamCity service
+// messages to w, so TeamCity and other JetBrains CI servers report each
+// visual test as a test of the build. Screenshots, review images and crash
+// dumps are published as build artifacts under TeamCityArtifactDir and
+// attached to their test, which shows the images inline. What Setup printed
+// becomes the test's output. It is safe for concurrent use.
+func NewTeamCityWriter(w io.Writer) func(Event) {
+	var mu sync.Mutex
+	suite := ""
+
+	return func(event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch event.Type {
+		case EventSuiteStart:
+			suite = event.Suite
+			if suite == "" {
+				suite = "vfyne"
+			}
+			writeTeamCity(w, "testSuiteStarted", "name", suite)
+		case EventTestStart:
+			name := teamCityTestName(event.Test, event.Profile)
+			writeTeamCity(w, "testStarted", "name", name, "flowId", name)
+		case EventTestFinish:
+			writeTeamCityResult(w, event.Result)
+		case EventSuiteFinish:
+			if suite != "" {
+				writeTeamCity(w, "testSuiteFinished", "name", suite)
+			}
+		}
+	}
+}
+
+// writeTeamCityResult writes the outcome, output and artifacts of one
+// result, then finishes its test.
+func writeTeamCityResult(w io.Writer, result *JSONResult) {
+	if result == nil {
+		return
+	}
+	name := teamCityTestName(result.Name, result.Profile)
+
+	if result.Output != "" {
+		writeTeamCity(w, "testStdOut", "name", name, "out", result.Output, "flowId", name)
+	}
+	if result.Skipped {
+		writeTeamCity(w, "testIgnored", "name", name, "message", result.SkipReason, "flowId", name)
+	}
+	if !result.Success {
+		details := ""
+		if result.CrashDump != "" {
+			details = "Crash dump: " + result.CrashDump
+		}
+		writeTeamCity(w, "testFailed", "name", name, "message", result.Error, "details", details, "flowId", name)
+	}
+
+	attach := func(label, kind, path string) {
+		if path == "" {
+			return
+		}
+		writeTeamCityArtifact(w, path)
+		writeTeamCity(w, "testMetadata", "testName", name, "type", kind, "name", label,
+			"value", TeamCityArtifactDir+"/"+filepath.Base(path), "flowId", name)
+	}
+	attach("screenshot", "image", result.ScreenshotPath)
+	if assets := result.DiffAssets; assets != nil {
+		attach("diff", "image", assets.Diff)
+		attach("heatmap", "image", assets.Heatmap)
+		attach("composite", "image", assets.Composite)
+	}
+	attach("crash dump", "artifact", result.CrashDump)
+	if diff := result.Diff; diff != nil && !diff.Match {
+		writeTeamCity(w, "testMetadata", "testName", name, "type", "number", "name", "diff percent",
+			"value", fmt.Sprintf("%.2f", diff.DiffPercent), "flowId", name)
+	}
+
+	writeTeamCity(w, "testFinished", "name", name, "duration", fmt.Sprint(result.Duration.Milliseconds()), "flowId", name)
+}
+
+// writeTeamCityArtifact publishes the file at path to TeamCityArtifactDir.
+func writeTeamCityArtifact(w io.Writer, path string) {
+	fmt.Fprintf(w, "##teamcity[publishArtifacts '%s']\n", teamCityEscape(path+" => "+TeamCityArtifactDir))
+}
+
+// writeTeamCity writes a service message with attributes given as name and
+// value pairs; those with an empty value are left out.
+func writeTeamCity(w io.Writer, message string, attrs ...string) {
+	var b strings.Builder
+	b.WriteString("##teamcity[")
+	b.WriteString(message)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		if attrs[i+1] == "" {
+			continue
+		}
+		fmt.Fprintf(&b, " %s='%s'", attrs[i], teamCityEscape(attrs[i+1]))
+	}
+	b.WriteString("]\n")
+	io.WriteString(w, b.String())
+}
+
+// teamCityTestName names a test after its profile too, as the report does.
+func teamCityTestName(name, profile string) string {
+	if profile != "" {
+		return name + " [" + profile + "]"
+	}
+	return name
+}
+
+// teamCityEscaper escapes the characters service message values can't hold.
+var teamCityEscaper = strings.NewReplacer(
+	"|", "||",
+	"'", "|'",
+	"\n", "|n",
+	"\r", "|r",
+	"[", "|[",
+	"]", "|]",
+	"\u0085", "|x",
+	"\u2028", "|l",
+	"\u2029", "|p",
+)
+
+func teamCityEscape(s string) string {
+	return teamCityEscaper.Replace(s)
+}
@@ -0,0 +1,33 @@
+package fynetest
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTeamCityWriterGolden(t *testing.T) {
+	got := writeEvents(func(w *bytes.Buffer) func(Event) { return NewTeamCityWriter(w) }, writerEvents())
+	checkGolden(t, got, `##teamcity[testSuiteStarted name='Visual']
+##teamcity[testStarted name='Login #1 |[dark|]' flowId='Login #1 |[dark|]']
+##teamcity[publishArtifacts 'out/login.png => vfyne']
+##teamcity[testMetadata testName='Login #1 |[dark|]' type='image' name='screenshot' value='vfyne/login.png' flowId='Login #1 |[dark|]']
+##teamcity[testFinished name='Login #1 |[dark|]' duration='12' flowId='Login #1 |[dark|]']
+##teamcity[testStarted name='Form|'s |[draft|]' flowId='Form|'s |[draft|]']
+##teamcity[testStdOut name='Form|'s |[draft|]' out='loading||done|n' flowId='Form|'s |[draft|]']
+##teamcity[testFailed name='Form|'s |[draft|]' message='capture differs:|n2.00% changed' flowId='Form|'s |[draft|]']
+##teamcity[publishArtifacts 'out/form.png => vfyne']
+##teamcity[testMetadata testName='Form|'s |[draft|]' type='image' name='screenshot' value='vfyne/form.png' flowId='Form|'s |[draft|]']
+##teamcity[publishArtifacts 'out/form_diff.png => vfyne']
+##teamcity[testMetadata testName='Form|'s |[draft|]' type='image' name='diff' value='vfyne/form_diff.png' flowId='Form|'s |[draft|]']
+##teamcity[testMetadata testName='Form|'s |[draft|]' type='number' name='diff percent' value='2.00' flowId='Form|'s |[draft|]']
+##teamcity[testFinished name='Form|'s |[draft|]' duration='30' flowId='Form|'s |[draft|]']
+##teamcity[testStarted name='Mobile' flowId='Mobile']
+##teamcity[testIgnored name='Mobile' message='not on|nlinux' flowId='Mobile']
+##teamcity[testFinished name='Mobile' duration='0' flowId='Mobile']
+##teamcity[testStarted name='Known bug' flowId='Known bug']
+##teamcity[testFinished name='Known bug' duration='5' flowId='Known bug']
+##teamcity[testStarted name='Fixed' flowId='Fixed']
+##teamcity[testFinished name='Fixed' duration='7' flowId='Fixed']
+##teamcity[testSuiteFinished name='Visual']
+`)
+}
@@ -1,9 +1,12 @@
 package fynetest
 
 import (
+	"fmt"
+	"runtime"
 	"time"
 
 	"fyne.io/fyne/v2"
+	"github.com/jairo/vfyne/faker"
 )
 
 // TestBuilder provides a fluent interface for creating tests.
@@ -14,11 +17,20 @@ type TestBuilder struct {
 // NewTest creates a new test builder with the given name.
 // The name must be unique and will be used as the filename for screenshots.
 func NewTest(name string) *TestBuilder {
+	return newTestAt(name, 1)
+}
+
+// newTestAt creates a test builder whose source location is skip frames
+// above its caller, so helpers can record where they were called from.
+func newTestAt(name string, skip int) *TestBuilder {
+	_, file, line, _ := runtime.Caller(skip + 1)
 	return &TestBuilder{
 		test: &Test{
-			Name:     name,
-			Tags:     make([]string, 0),
-			Metadata: make(map[string]interface{}),
+			Name:       name,
+			Tags:       make([]string, 0),
+			Metadata:   make(map[string]interface{}),
+			SourceFile: file,
+			SourceLine: line,
 		},
 	}
 }
@@ -44,6 +56,34 @@ func (b *TestBuilder) WithSize(width, height float32) *TestBuilder {
 	return b
 }
 
+// WithCanvasSize captures canvas-level content (lines, circles, rasters,
+// custom painters) at exactly the given size without window padding.
+func (b *TestBuilder) WithCanvasSize(width, height float32) *TestBuilder {
+	b.test.Unpadded = true
+	return b.WithSize(width, height)
+}
+
+// WithScale renders the test at the given canvas scale to simulate a
+// different display DPI.
+func (b *TestBuilder) WithScale(scale float32) *TestBuilder {
+	b.test.Scale = scale
+	return b
+}
+
+// WithRenderer captures the test with the given render path, e.g. RenderGL
+// for custom widgets that only misbehave on the real driver.
+func (b *TestBuilder) WithRenderer(renderer RenderPath) *TestBuilder {
+	b.test.Renderer = renderer
+	return b
+}
+
+// WithCropToContent saves and compares only the area the content is rendered
+// at, leaving out window padding and any space the window adds around it.
+func (b *TestBuilder) WithCropToContent() *TestBuilder {
+	b.test.CropToContent = true
+	return b
+}
+
 // WithTheme sets a custom theme for this test.
 // If not set, the runner's default theme will be used.
 func (b *TestBuilder) WithTheme(theme fyne.Theme) *TestBuilder {
@@ -58,6 +98,55 @@ func (b *TestBuilder) WithWaitDuration(duration time.Duration) *TestBuilder {
 	return b
 }
 
+// WithTimeout fails the test with ErrTimeout when setting it up, rendering
+// and capturing it take longer than timeout, overriding the runner's
+// DefaultTimeout. It includes the wait duration.
+func (b *TestBuilder) WithTimeout(timeout time.Duration) *TestBuilder {
+	b.test.Timeout = timeout
+	return b
+}
+
+// WithSkip skips the test, reporting it as skipped with reason.
+func (b *TestBuilder) WithSkip(reason string) *TestBuilder {
+	b.test.Skip = true
+	b.test.SkipReason = reason
+	return b
+}
+
+// WithSkipFunc skips the test when skip, called when the test is run,
+// returns true, e.g. on an unsupported OS or when a dependency is missing.
+func (b *TestBuilder) WithSkipFunc(skip func() (bool, string)) *TestBuilder {
+	b.test.SkipFunc = skip
+	return b
+}
+
+// WithSkipOnPlatform skips the test on platforms it is known to be unstable
+// on, given as GOOS ("windows") or GOOS/GOARCH ("darwin/arm64").
+func (b *TestBuilder) WithSkipOnPlatform(platforms ...string) *TestBuilder {
+	b.test.SkipPlatforms = append(b.test.SkipPlatforms, platforms...)
+	return b
+}
+
+// WithLooseToleranceOn lets fraction (0-1) of the pixels of captures made on
+// platform, given as for WithSkipOnPlatform, differ from the baseline, for
+// renderers with known quirks there. It never tightens the runner's
+// tolerance.
+func (b *TestBuilder) WithLooseToleranceOn(platform string, fraction float64) *TestBuilder {
+	if b.test.PlatformTolerances == nil {
+		b.test.PlatformTolerances = make(map[string]float64)
+	}
+	b.test.PlatformTolerances[platform] = fraction
+	return b
+}
+
+// WithExpectedToFail marks the test as a known visual regression: it passes
+// when its capture doesn't match the baseline, and matching flags it for a
+// baseline review.
+func (b *TestBuilder) WithExpectedToFail() *TestBuilder {
+	b.test.ExpectedToFail = true
+	return b
+}
+
 // WithTags adds tags for categorizing and filtering tests.
 func (b *TestBuilder) WithTags(tags ...string) *TestBuilder {
 	b.test.Tags = append(b.test.Tags, tags...)
@@ -70,6 +159,94 @@ func (b *TestBuilder) WithMetadata(key string, value interface{}) *TestBuilder {
 	return b
 }
 
+// WithCaption sets a caption shown under the screenshot in reports and exports.
+func (b *TestBuilder) WithCaption(caption string) *TestBuilder {
+	return b.WithMetadata(MetadataCaption, caption)
+}
+
+// WithAltText sets the alternative text describing the screenshot for
+// screen readers. If not set, the description is used.
+func (b *TestBuilder) WithAltText(text string) *TestBuilder {
+	return b.WithMetadata(MetadataAltText, text)
+}
+
+// WithIgnoreRegion excludes a rectangle, in canvas coordinates, from
+// baseline comparisons. Use it for clocks, progress bars or random data.
+func (b *TestBuilder) WithIgnoreRegion(x, y, width, height float32) *TestBuilder {
+	b.test.IgnoreRegions = append(b.test.IgnoreRegions, NewRegion(x, y, width, height))
+	return b
+}
+
+// WithIgnoreObject excludes the area an object is rendered at from baseline
+// comparisons. The object must be part of the content returned by Setup.
+func (b *TestBuilder) WithIgnoreObject(obj fyne.CanvasObject) *TestBuilder {
+	b.test.IgnoreObjects = append(b.test.IgnoreObjects, obj)
+	return b
+}
+
+// WithIgnoreSelector excludes the widgets matching a selector, such as
+// "ProgressBar" or `Label[name=clock]`, from baseline comparisons. Unlike
+// regions, selector masks follow their widgets when the layout changes.
+func (b *TestBuilder) WithIgnoreSelector(selector string) *TestBuilder {
+	b.test.IgnoreSelectors = append(b.test.IgnoreSelectors, selector)
+	return b
+}
+
+// WithFocusRegion compares a rectangle, in canvas coordinates, pixel for
+// pixel while the rest of the capture uses the runner's tolerance.
+func (b *TestBuilder) WithFocusRegion(x, y, width, height float32) *TestBuilder {
+	b.test.FocusRegions = append(b.test.FocusRegions, FocusRegion{Region: NewRegion(x, y, width, height)})
+	return b
+}
+
+// WithFocusSelector compares the widgets matching a selector, such as
+// `Button[text="Pay"]`, with tol while the rest of the capture uses the
+// runner's tolerance.
+func (b *TestBuilder) WithFocusSelector(selector string, tol Tolerance) *TestBuilder {
+	b.test.FocusRegions = append(b.test.FocusRegions, FocusRegion{Selector: selector, Tolerance: tol})
+	return b
+}
+
+// WithImportance weights changes inside a rectangle, in canvas coordinates,
+// when scoring the severity of a diff: above 1 for the primary button,
+// below 1 for decoration.
+func (b *TestBuilder) WithImportance(x, y, width, height float32, weight float64) *TestBuilder {
+	b.test.Importance = append(b.test.Importance, Importance{Region: NewRegion(x, y, width, height), Weight: weight})
+	return b
+}
+
+// WithChangeBudget lets captures differing from the baseline by up to
+// severity still pass, overriding the runner's ChangeBudgets.
+func (b *TestBuilder) WithChangeBudget(severity float64) *TestBuilder {
+	b.test.ChangeBudget = severity
+	return b
+}
+
+// WithFreeze shows value in the widgets matching selector, such as
+// `Label[name=clock]`, instead of what they display at capture time. Unlike
+// an ignore mask, the widget is still compared and keeps its layout.
+func (b *TestBuilder) WithFreeze(selector, value string) *TestBuilder {
+	b.test.Freezes = append(b.test.Freezes, Freeze{Selector: selector, Value: value})
+	return b
+}
+
+// WithSeed sets the seed used for deterministic fake data.
+// If not set, a seed is derived from the test name.
+func (b *TestBuilder) WithSeed(seed int64) *TestBuilder {
+	b.test.Seed = seed
+	return b
+}
+
+// WithFakerSetup sets a setup function that receives a faker seeded from the
+// test seed, so generated sample data is identical on every run.
+func (b *TestBuilder) WithFakerSetup(setup func(f *faker.Faker) fyne.CanvasObject) *TestBuilder {
+	test := b.test
+	b.test.Setup = func() fyne.CanvasObject {
+		return setup(faker.New(test.EffectiveSeed()))
+	}
+	return b
+}
+
 // Build creates the final Test instance.
 // This will validate the test configuration and return an error if invalid.
 func (b *TestBuilder) Build() (Test, error) {
@@ -93,12 +270,12 @@ func (b *TestBuilder) MustBuild() Test {
 
 // QuickTest creates a simple test with just a name and setup function.
 func QuickTest(name string, setup func() fyne.CanvasObject) Test {
-	return NewTest(name).WithSetup(setup).MustBuild()
+	return newTestAt(name, 1).WithSetup(setup).MustBuild()
 }
 
 // QuickTestWithDescription creates a test with a name, description, and setup.
 func QuickTestWithDescription(name, description string, setup func() fyne.CanvasObject) Test {
-	return NewTest(name).
+	return newTestAt(name, 1).
 		WithDescription(description).
 		WithSetup(setup).
 		MustBuild()
@@ -106,15 +283,36 @@ func QuickTestWithDescription(name, description string, setup func() fyne.Canvas
 
 // ThemeTest creates a test that runs with a specific theme.
 func ThemeTest(name string, theme fyne.Theme, setup func() fyne.CanvasObject) Test {
-	return NewTest(name).
+	return newTestAt(name, 1).
 		WithTheme(theme).
 		WithSetup(setup).
 		MustBuild()
 }
 
+// CanvasTest creates a fixed-size, unpadded test for canvas primitives.
+func CanvasTest(name string, width, height float32, setup func() fyne.CanvasObject) Test {
+	return newTestAt(name, 1).
+		WithCanvasSize(width, height).
+		WithSetup(setup).
+		MustBuild()
+}
+
+// ScaleVariants returns one copy of test per scale, each named with an
+// "@<scale>x" suffix, so the same content can be captured at several DPIs.
+func ScaleVariants(test Test, scales ...float32) []Test {
+	variants := make([]Test, len(scales))
+	for i, scale := range scales {
+		variant := test
+		variant.Name = fmt.Sprintf("%s@%gx", test.Name, scale)
+		variant.Scale = scale
+		variants[i] = variant
+	}
+	return variants
+}
+
 // SizedTest creates a test with a specific window size.
 func SizedTest(name string, width, height float32, setup func() fyne.CanvasObject) Test {
-	return NewTest(name).
+	return newTestAt(name, 1).
 		WithSize(width, height).
 		WithSetup(setup).
 		MustBuild()
@@ -0,0 +1,28 @@
+package fynetest
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestHelpersRecordCallerSource(t *testing.T) {
+	setup := func() fyne.CanvasObject { return widget.NewLabel("Hello") }
+	_, file, line, _ := runtime.Caller(0)
+	tests := map[string]Test{
+		"NewTest":                  NewTest("a").WithSetup(setup).MustBuild(),
+		"QuickTest":                QuickTest("b", setup),
+		"QuickTestWithDescription": QuickTestWithDescription("c", "description", setup),
+		"ThemeTest":                ThemeTest("d", nil, setup),
+		"CanvasTest":               CanvasTest("e", 10, 10, setup),
+		"SizedTest":                SizedTest("f", 10, 10, setup),
+	}
+	for helper, test := range tests {
+		if filepath.Base(test.SourceFile) != filepath.Base(file) || test.SourceLine <= line {
+			t.Errorf("%s recorded %s:%d, want a line of %s after %d", helper, test.SourceFile, test.SourceLine, filepath.Base(file), line)
+		}
+	}
+}
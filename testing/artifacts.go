@@ -0,0 +1,91 @@
+package testing
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ArtifactMarker starts the log line recording each file a snapshot test
+// writes. Under `go test -json` the line lands in the output event of the
+// test that wrote it, so tools like gotestsum or a CI parser can attach the
+// screenshot, diff or heatmap to the right test. The rest of the line is the
+// Artifact as JSON; ParseArtifact reads it back.
+const ArtifactMarker = "vfyne-artifact "
+
+// Artifact kinds.
+const (
+	ArtifactScreenshot = "screenshot"
+	ArtifactBaseline   = "baseline"
+	ArtifactActual     = "actual"
+	ArtifactDiff       = "diff"
+	ArtifactHeatmap    = "heatmap"
+	ArtifactComposite  = "composite"
+)
+
+// Artifact is a file written by a snapshot test.
+type Artifact struct {
+	// Test is the full name of the go test, as in `go test -json` events
+	Test string `json:"test"`
+
+	// Snapshot is the name of the screenshot or snapshot within the test
+	Snapshot string `json:"snapshot"`
+
+	Kind string `json:"kind"`
+
+	// Path is absolute, since `go test` runs each package in its own
+	// directory
+	Path string `json:"path"`
+}
+
+var artifactsPath = flag.String("vfyne-artifacts", "", "Append every screenshot, diff and updated snapshot to this file as JSON lines")
+
+// artifactsMu serialises appends to the -vfyne-artifacts file by parallel tests.
+var artifactsMu sync.Mutex
+
+// ParseArtifact returns the artifact recorded on a log line, such as the
+// Output of a `go test -json` event, if it carries one.
+func ParseArtifact(line string) (Artifact, bool) {
+	i := strings.Index(line, ArtifactMarker)
+	if i < 0 {
+		return Artifact{}, false
+	}
+	var artifact Artifact
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line[i+len(ArtifactMarker):])), &artifact); err != nil {
+		return Artifact{}, false
+	}
+	return artifact, true
+}
+
+// artifact logs the file at path with ArtifactMarker and appends it to the
+// -vfyne-artifacts file, if set.
+func (v *VFyneTest) artifact(snapshot, kind, path string) {
+	v.t.Helper()
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	data, err := json.Marshal(Artifact{Test: v.t.Name(), Snapshot: snapshot, Kind: kind, Path: path})
+	if err != nil {
+		return
+	}
+	v.t.Log(ArtifactMarker + string(data))
+
+	if *artifactsPath == "" {
+		return
+	}
+	artifactsMu.Lock()
+	defer artifactsMu.Unlock()
+	file, err := os.OpenFile(*artifactsPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		v.t.Logf("Failed to record artifact: %v", err)
+		return
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		v.t.Logf("Failed to record artifact: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package testing
 
 import (
 	"flag"
+	"fmt"
 	"image"
 	"image/png"
 	"os"
@@ -14,9 +15,44 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/test"
 	"fyne.io/fyne/v2/theme"
+	fynetest "github.com/jairo/vfyne"
 )
 
-var updateSnapshots = flag.Bool("update-snapshots", false, "Update snapshot images")
+// update selects the snapshots rewritten instead of compared, from
+// -update-snapshots[=patterns] and -update-tag=tags.
+var update fynetest.SnapshotUpdate
+
+func init() {
+	flag.Var(update.PatternFlag(), "update-snapshots", "Update snapshot images; optionally only those matching comma-separated globs (-update-snapshots=button*)")
+	flag.Var(update.TagFlag(), "update-tag", "Update the snapshots tagged with any of these comma-separated tags (see WithTags)")
+}
+
+var historyDepth = flag.Int("snapshot-history", 0, "Keep this many previous versions of each updated snapshot under snapshots/.history")
+
+var strictness = flag.String("strictness", "", "Default snapshot strictness: exact, strict, normal or loose")
+
+// defaultTolerance applies to snapshots that don't set their own tolerance.
+// Without one, Snapshot compares exactly and CanvasSnapshot with
+// VectorTolerance.
+var defaultTolerance *fynetest.Tolerance
+
+// SetDefaultTolerance sets the tolerance used by every snapshot comparison in
+// the test binary, typically from TestMain. Individual tests can still
+// override it with SetTolerance or WithTolerance. The -strictness flag takes
+// precedence over this default.
+func SetDefaultTolerance(tol fynetest.Tolerance) {
+	defaultTolerance = &tol
+}
+
+// baselineSource, when set, provides snapshots kept outside the repository.
+var baselineSource fynetest.BaselineSource
+
+// SetBaselineSource makes every snapshot comparison in the test binary fetch
+// its baseline from source first, typically from TestMain. The snapshot
+// directory then caches the fetched baselines.
+func SetBaselineSource(source fynetest.BaselineSource) {
+	baselineSource = source
+}
 
 type VFyneTest struct {
 	t              *testing.T
@@ -25,6 +61,8 @@ type VFyneTest struct {
 	snapshotDir    string
 	screenshotDir  string
 	renderWait     time.Duration
+	tolerance      *fynetest.Tolerance
+	comparer       fynetest.ImageComparer
 }
 
 func New(t *testing.T) *VFyneTest {
@@ -41,7 +79,23 @@ func New(t *testing.T) *VFyneTest {
 		snapshotDir:   filepath.Join(testDir, "snapshots"),
 		screenshotDir: filepath.Join(testDir, "screenshots"),
 		renderWait:    100 * time.Millisecond,
+		tolerance:     suiteTolerance(t),
+	}
+}
+
+// suiteTolerance returns the default tolerance, honouring -strictness, or
+// nil when neither sets one.
+func suiteTolerance(t *testing.T) *fynetest.Tolerance {
+	t.Helper()
+	
+	if *strictness == "" {
+		return defaultTolerance
+	}
+	tol, err := fynetest.StrictnessTolerance(*strictness)
+	if err != nil {
+		t.Fatalf("Invalid -strictness: %v", err)
 	}
+	return &tol
 }
 
 func (v *VFyneTest) SetTheme(theme fyne.Theme) {
@@ -52,26 +106,22 @@ func (v *VFyneTest) SetRenderWait(duration time.Duration) {
 	v.renderWait = duration
 }
 
+// SetTolerance sets the tolerance for snapshots taken by this test.
+func (v *VFyneTest) SetTolerance(tol fynetest.Tolerance) {
+	v.tolerance = &tol
+}
+
+// SetComparer sets custom comparison logic for snapshots taken by this test,
+// replacing the tolerance. WithComparer overrides it per snapshot.
+func (v *VFyneTest) SetComparer(comparer fynetest.ImageComparer) {
+	v.comparer = comparer
+}
+
 func (v *VFyneTest) Screenshot(name string, content fyne.CanvasObject, opts ...ScreenshotOption) {
 	v.t.Helper()
 	
-	options := &screenshotOptions{
-		size: fyne.NewSize(800, 600),
-	}
-	
-	for _, opt := range opts {
-		opt(options)
-	}
-	
-	v.window = test.NewWindow(content)
-	v.window.Resize(options.size)
-	
-	// Wait for rendering
-	time.Sleep(v.renderWait)
-	
-	// Capture the canvas
-	canvas := v.window.Canvas()
-	img := canvas.Capture()
+	options := newScreenshotOptions(opts)
+	img, _, _ := v.capture(content, options)
 	
 	filename := sanitizeFilename(name) + ".png"
 	path := filepath.Join(v.screenshotDir, filename)
@@ -85,45 +135,103 @@ func (v *VFyneTest) Screenshot(name string, content fyne.CanvasObject, opts ...S
 	}
 	
 	v.t.Logf("Screenshot saved: %s", path)
-	
-	v.window.Close()
+	v.artifact(name, ArtifactScreenshot, path)
 }
 
 func (v *VFyneTest) Snapshot(name string, content fyne.CanvasObject, opts ...ScreenshotOption) {
 	v.t.Helper()
 	
-	options := &screenshotOptions{
-		size: fyne.NewSize(800, 600),
+	options := newScreenshotOptions(opts)
+	v.resolveComparison(options, fynetest.ExactTolerance)
+	v.snapshot(name, content, options)
+}
+
+// resolveComparison fills in the tolerance and comparer options leaves
+// unset from the test's, then the suite default, then fallback.
+func (v *VFyneTest) resolveComparison(options *screenshotOptions, fallback fynetest.Tolerance) {
+	if options.tolerance == nil {
+		options.tolerance = v.tolerance
+	}
+	if options.tolerance == nil {
+		options.tolerance = &fallback
+	}
+	if options.comparer == nil {
+		options.comparer = v.comparer
 	}
+}
+
+// CanvasSnapshot compares canvas-level content such as canvas.Line,
+// canvas.Circle, canvas.Raster or custom painters against a baseline.
+// The object is rendered without window padding at exactly the requested size
+// (default 200x200) and compared like Snapshot, except that without a
+// tolerance set by the test, SetDefaultTolerance or -strictness it uses
+// VectorTolerance, which absorbs anti-aliasing noise along edges. Use
+// WithScales to compare the content at several DPI scales; each scale gets
+// its own baseline suffixed "@<scale>x", as with ScaleVariants.
+func (v *VFyneTest) CanvasSnapshot(name string, obj fyne.CanvasObject, opts ...ScreenshotOption) {
+	v.t.Helper()
 	
+	options := &screenshotOptions{
+		size:     fyne.NewSize(200, 200),
+		unpadded: true,
+	}
 	for _, opt := range opts {
 		opt(options)
 	}
+	v.resolveComparison(options, fynetest.VectorTolerance)
 	
-	v.window = test.NewWindow(content)
-	v.window.Resize(options.size)
+	if len(options.scales) == 0 {
+		v.snapshot(name, obj, options)
+		return
+	}
 	
-	// Wait for rendering
-	time.Sleep(v.renderWait)
+	for _, scale := range options.scales {
+		scaled := *options
+		scaled.scale = scale
+		v.snapshot(fmt.Sprintf("%s@%gx", name, scale), obj, &scaled)
+	}
+}
+
+func (v *VFyneTest) snapshot(name string, content fyne.CanvasObject, options *screenshotOptions) {
+	v.t.Helper()
 	
-	// Capture the canvas
-	canvas := v.window.Canvas()
-	img := canvas.Capture()
+	img, ignored, tree := v.capture(content, options)
 	
 	filename := sanitizeFilename(name) + ".png"
 	snapshotPath := filepath.Join(v.snapshotDir, filename)
 	
-	if *updateSnapshots {
+	if update.Matches(name, options.tags) || update.Matches(v.t.Name(), options.tags) {
 		if err := os.MkdirAll(v.snapshotDir, 0755); err != nil {
 			v.t.Fatalf("Failed to create snapshot directory: %v", err)
 		}
+		if err := fynetest.CheckBaselineWrite(v.snapshotDir, name, options.tags); err != nil {
+			v.t.Fatalf("Cannot update snapshot: %v", err)
+		}
 		
-		if err := saveImage(snapshotPath, img); err != nil {
+		if digest, ok := fynetest.ReadBaselineDigest(snapshotPath); *historyDepth > 0 && (!ok || digest != fynetest.ImageDigest(img)) {
+			if _, err := fynetest.ArchiveBaseline(snapshotPath, *historyDepth); err != nil {
+				v.t.Fatalf("Failed to archive previous snapshot: %v", err)
+			}
+		}
+		if err := fynetest.SaveBaseline(snapshotPath, img); err != nil {
 			v.t.Fatalf("Failed to save snapshot: %v", err)
 		}
+		if err := fynetest.SaveWidgetTree(snapshotPath+fynetest.TreeSuffix, tree); err != nil {
+			v.t.Fatalf("Failed to save widget tree: %v", err)
+		}
+		if err := fynetest.RecordBaseline(snapshotPath, name, options.tags...); err != nil {
+			v.t.Fatalf("Failed to update snapshot manifest: %v", err)
+		}
+		if sink, ok := baselineSource.(fynetest.BaselineSink); ok {
+			if err := sink.Store(filename, snapshotPath); err != nil {
+				v.t.Fatalf("Failed to store snapshot: %v", err)
+			}
+		}
 		
 		v.t.Logf("Snapshot updated: %s", snapshotPath)
+		v.artifact(name, ArtifactBaseline, snapshotPath)
 	} else {
+		v.fetchSnapshot(name, snapshotPath)
 		if _, err := os.Stat(snapshotPath); os.IsNotExist(err) {
 			v.t.Errorf("Snapshot does not exist: %s (run with -update-snapshots to create)", snapshotPath)
 			
@@ -131,38 +239,190 @@ func (v *VFyneTest) Snapshot(name string, content fyne.CanvasObject, opts ...Scr
 			if err := os.MkdirAll(v.screenshotDir, 0755); err == nil {
 				saveImage(tempPath, img)
 				v.t.Logf("Actual output saved to: %s", tempPath)
+				v.artifact(name, ArtifactActual, tempPath)
 			}
+		} else if err := fynetest.CheckBaseline(snapshotPath); err != nil {
+			v.t.Errorf("Snapshot %s failed verification: %v", name, err)
+		} else if digest, ok := fynetest.ReadBaselineDigest(snapshotPath); ok && digest == fynetest.ImageDigest(img) {
+			v.t.Logf("Snapshot matched: %s", name)
+		} else if streamMatch(snapshotPath, img, ignored, options) {
+			v.t.Logf("Snapshot matched: %s", name)
 		} else {
 			expected, err := loadImage(snapshotPath)
 			if err != nil {
 				v.t.Fatalf("Failed to load snapshot: %v", err)
 			}
 			
-			if !imagesEqual(expected, img) {
-				v.t.Errorf("Snapshot mismatch for %s", name)
+			// Black out ignored regions in both images before diffing
+			actual := img
+			if expected.Bounds().Size() == img.Bounds().Size() && len(ignored) > 0 {
+				expected = fynetest.MaskRegions(expected, ignored)
+				actual = fynetest.MaskRegions(img, ignored)
+				defer fynetest.ReleaseImage(expected)
+				defer fynetest.ReleaseImage(actual)
+			}
+			
+			result, err := options.imageComparer().Compare(expected, actual)
+			if err != nil {
+				v.t.Fatalf("Failed to compare snapshot: %v", err)
+			}
+			if result.Band == fynetest.BandWarn {
+				v.t.Logf("Snapshot %s drifted (similarity %.3f) but is within the warning band", name, result.Similarity)
+			}
+			
+			if !result.Match {
+				switch {
+				case result.SizeMismatch:
+					v.t.Errorf("Snapshot mismatch for %s: size %v differs from baseline %v", name, img.Bounds().Size(), expected.Bounds().Size())
+				case options.comparer != nil:
+					v.t.Errorf("Snapshot mismatch for %s: %.2f%% of pixels differ", name, result.DiffPercent)
+				case options.chart != nil || options.effectiveTolerance().Mode == Structural:
+					v.t.Errorf("Snapshot mismatch for %s: similarity %.3f", name, result.Similarity)
+				default:
+					v.t.Errorf("Snapshot mismatch for %s: %.2f%% of pixels differ (allowed %.2f%%, max delta %d)",
+						name, result.DiffPercent, options.tolerance.MaxDiffPercent, result.MaxDelta)
+				}
+				if meta, ok := fynetest.ReadBaselineMeta(snapshotPath); ok && meta.CrossesFyneMinor(fynetest.FyneVersion()) {
+					v.t.Logf("Snapshot %s was created with fyne %s, running %s; the mismatch may come from the upgrade",
+						name, meta.FyneVersion, fynetest.FyneVersion())
+				}
+				if !result.SizeMismatch {
+					if swap := fynetest.DetectColorSwap(expected, actual, v.app.Settings().Theme()); swap != nil {
+						v.t.Logf("Snapshot %s: %s", name, swap)
+					}
+				}
+				if expectedTree, err := fynetest.LoadWidgetTree(snapshotPath + fynetest.TreeSuffix); err == nil {
+					for _, change := range fynetest.DiffWidgetTrees(expectedTree, tree) {
+						v.t.Logf("Widget %s", change)
+					}
+				}
 				
 				diffPath := filepath.Join(v.screenshotDir, "diff_"+filename)
 				actualPath := filepath.Join(v.screenshotDir, "actual_"+filename)
+				heatmapPath := filepath.Join(v.screenshotDir, "heatmap_"+filename)
+				compositePath := filepath.Join(v.screenshotDir, "composite_"+filename)
 				
 				if err := os.MkdirAll(v.screenshotDir, 0755); err == nil {
 					saveImage(actualPath, img)
-					if diff := createDiffImage(expected, img); diff != nil {
+					diff := createDiffImage(expected, actual, options.effectiveTolerance())
+					if diff != nil {
 						saveImage(diffPath, diff)
 						v.t.Logf("Diff saved to: %s", diffPath)
+						v.artifact(name, ArtifactDiff, diffPath)
+					}
+					saveImage(compositePath, fynetest.CompositeImage(expected, actual, diff))
+					v.t.Logf("Side-by-side comparison saved to: %s", compositePath)
+					v.artifact(name, ArtifactComposite, compositePath)
+					fynetest.ReleaseImage(diff)
+					if heatmap := fynetest.HeatmapImage(expected, actual); heatmap != nil {
+						saveImage(heatmapPath, heatmap)
+						fynetest.ReleaseImage(heatmap)
+						v.t.Logf("Heatmap saved to: %s", heatmapPath)
+						v.artifact(name, ArtifactHeatmap, heatmapPath)
 					}
 					v.t.Logf("Actual output saved to: %s", actualPath)
+					v.artifact(name, ArtifactActual, actualPath)
 				}
 			} else {
 				v.t.Logf("Snapshot matched: %s", name)
 			}
 		}
 	}
+}
+
+// fetchSnapshot refreshes the cached snapshot from the baseline source set
+// with SetBaselineSource, if any.
+func (v *VFyneTest) fetchSnapshot(name, snapshotPath string) {
+	v.t.Helper()
+	
+	if baselineSource == nil {
+		return
+	}
+	if _, err := fynetest.FetchBaseline(baselineSource, snapshotPath, name); err != nil {
+		v.t.Fatalf("Failed to fetch snapshot %s: %v", name, err)
+	}
+}
+
+// capture renders content in a fresh test window and returns the canvas image
+// together with the pixel rectangles to ignore when comparing.
+func (v *VFyneTest) capture(content fyne.CanvasObject, options *screenshotOptions) (image.Image, []image.Rectangle, *fynetest.WidgetNode) {
+	v.t.Helper()
+	
+	v.window = test.NewWindow(content)
+	defer v.window.Close()
+	
+	if options.unpadded {
+		v.window.SetPadded(false)
+	}
+	if options.scale > 0 {
+		scaler, ok := v.window.Canvas().(interface{ SetScale(float32) })
+		if !ok {
+			v.t.Fatalf("Canvas does not support scaling")
+		}
+		scaler.SetScale(options.scale)
+	}
+	if err := fynetest.CheckCanvasSize(options.size, v.window.Canvas().Scale(), image.Point{}); err != nil {
+		v.t.Fatalf("Cannot capture: %v", err)
+	}
+	v.window.Resize(options.size)
+	
+	// Wait for rendering
+	time.Sleep(v.renderWait)
+	
+	if err := fynetest.FreezeWidgets(content, options.freezes); err != nil {
+		v.t.Fatalf("Cannot freeze: %v", err)
+	}
+	
+	// Capture the canvas
+	img := v.window.Canvas().Capture()
+	if err := fynetest.CheckCaptured(options.size, v.window.Canvas().Scale(), img); err != nil {
+		v.t.Fatalf("Cannot capture: %v", err)
+	}
+	tree := fynetest.CaptureWidgetTree(content)
 	
-	v.window.Close()
+	regions := options.ignoreRegions
+	if len(options.ignoreSelectors) > 0 {
+		selected, err := fynetest.SelectorRegions(tree, options.ignoreSelectors)
+		if err != nil {
+			v.t.Fatalf("Cannot mask: %v", err)
+		}
+		regions = append(append([]fynetest.Region(nil), regions...), selected...)
+	}
+	ignored := fynetest.IgnoreRects(v.window.Canvas(), img, regions, options.ignoreObjects)
+	if options.crop {
+		img, ignored = fynetest.CropToObject(v.window.Canvas(), img, content, ignored)
+	}
+	return img, ignored, tree
 }
 
 type screenshotOptions struct {
-	size fyne.Size
+	size            fyne.Size
+	scale           float32
+	scales          []float32
+	unpadded        bool
+	crop            bool
+	tags            []string
+	tolerance       *fynetest.Tolerance
+	mode            *fynetest.ComparisonMode
+	ignoreAA        bool
+	minSimilarity   float64
+	chart           *fynetest.ChartTolerance
+	comparer        fynetest.ImageComparer
+	ignoreRegions   []fynetest.Region
+	ignoreObjects   []fyne.CanvasObject
+	ignoreSelectors []string
+	freezes         []fynetest.Freeze
+}
+
+func newScreenshotOptions(opts []ScreenshotOption) *screenshotOptions {
+	options := &screenshotOptions{
+		size: fyne.NewSize(800, 600),
+	}
+	
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
 }
 
 type ScreenshotOption func(*screenshotOptions)
@@ -185,11 +445,166 @@ func WithTabletSize() ScreenshotOption {
 	}
 }
 
+// WithScale renders the content at the given canvas scale, simulating a
+// display with a different DPI.
+func WithScale(scale float32) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.scale = scale
+	}
+}
+
+// WithScales captures one variant per scale when used with CanvasSnapshot.
+func WithScales(scales ...float32) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.scales = scales
+	}
+}
+
+// WithoutPadding removes the window padding so the capture contains only
+// the content itself.
+func WithoutPadding() ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.unpadded = true
+	}
+}
+
+// WithCropToContent saves and compares only the area the content is
+// rendered at instead of the whole window.
+func WithCropToContent() ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.crop = true
+	}
+}
+
+// WithTags tags a snapshot so it can be refreshed with -update-tag.
+func WithTags(tags ...string) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// WithTolerance lets a snapshot differ in up to maxDiffPercent (0-100) of
+// its pixels, ignoring per-channel differences up to colorDelta (0-255).
+// It overrides the suite default set with SetDefaultTolerance.
+func WithTolerance(maxDiffPercent float64, colorDelta uint8) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.tolerance = &fynetest.Tolerance{
+			ColorDelta:     colorDelta,
+			MaxDiffPercent: maxDiffPercent,
+		}
+	}
+}
+
+// ComparisonMode selects how snapshot pixels are compared.
+type ComparisonMode = fynetest.ComparisonMode
+
+const (
+	// Pixel compares RGBA channels against the tolerance color delta
+	Pixel = fynetest.ComparePixel
+	
+	// Perceptual compares colors in CIE Lab space, ignoring imperceptible
+	// shifts caused by different rendering backends
+	Perceptual = fynetest.ComparePerceptual
+	
+	// Structural scores the whole snapshot with SSIM, ignoring sub-pixel
+	// font hinting differences but catching layout regressions
+	Structural = fynetest.CompareStructural
+)
+
+// WithComparisonMode selects how pixels are compared. The allowed share of
+// differing pixels still comes from the tolerance.
+func WithComparisonMode(mode ComparisonMode) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.mode = &mode
+	}
+}
+
+// WithSSIM compares using structural similarity, passing when the score
+// (0-1) is at least threshold.
+func WithSSIM(threshold float64) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		mode := Structural
+		o.mode = &mode
+		o.minSimilarity = threshold
+	}
+}
+
+// WithIgnoreAntialiasing ignores differing pixels that look like
+// anti-aliased text or curve edges, which vary between platforms.
+func WithIgnoreAntialiasing() ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.ignoreAA = true
+	}
+}
+
+// WithIgnoreRegion excludes a rectangle, in canvas coordinates, from the
+// comparison. Use it for clocks, progress bars or randomized data.
+func WithIgnoreRegion(x, y, width, height float32) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.ignoreRegions = append(o.ignoreRegions, fynetest.NewRegion(x, y, width, height))
+	}
+}
+
+// WithIgnoreObject excludes the area obj is rendered at from the comparison.
+// The object must be part of the captured content.
+func WithIgnoreObject(obj fyne.CanvasObject) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.ignoreObjects = append(o.ignoreObjects, obj)
+	}
+}
+
+// WithIgnoreSelector excludes the widgets matching a selector, such as
+// "ProgressBar" or `Label[name=clock]`, from the comparison. The selector is
+// resolved from the widget tree at capture time, so the mask follows the
+// widget when the layout changes.
+func WithIgnoreSelector(selector string) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.ignoreSelectors = append(o.ignoreSelectors, selector)
+	}
+}
+
+// WithFreeze shows value in the widgets matching selector, such as
+// `Label[name=clock]`, instead of what they display at capture time. The
+// widget is still compared and keeps its place in the layout.
+func WithFreeze(selector, value string) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.freezes = append(o.freezes, fynetest.Freeze{Selector: selector, Value: value})
+	}
+}
+
+// WithChartMode compares using downsampled structural similarity with the
+// default tolerance bands, for charts and other anti-aliased custom drawing.
+func WithChartMode() ScreenshotOption {
+	return WithChartTolerance(fynetest.DefaultChartTolerance)
+}
+
+// WithChartTolerance compares using downsampled structural similarity with
+// custom tolerance bands. Scores in the warning band pass but are logged.
+func WithChartTolerance(tol fynetest.ChartTolerance) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.chart = &tol
+	}
+}
+
+// WithComparer compares the snapshot with custom logic instead of the
+// tolerance, e.g. company-specific rules built on fynetest.CompareImages.
+func WithComparer(comparer fynetest.ImageComparer) ScreenshotOption {
+	return func(o *screenshotOptions) {
+		o.comparer = comparer
+	}
+}
+
+// scaleSuffix matches the "@<scale>x" suffix of a scale variant, which file
+// names keep, as fynetest.ScaleVariants does.
+var scaleSuffix = regexp.MustCompile(`@[0-9]+(\.[0-9]+)?x$`)
+
 func sanitizeFilename(name string) string {
+	suffix := scaleSuffix.FindString(name)
+	name = strings.TrimSuffix(name, suffix)
 	reg := regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
 	sanitized := reg.ReplaceAllString(name, "_")
 	sanitized = strings.Trim(sanitized, "_")
-	return strings.ToLower(sanitized)
+	return strings.ToLower(sanitized) + suffix
 }
 
 func saveImage(path string, img image.Image) error {
@@ -199,7 +614,7 @@ func saveImage(path string, img image.Image) error {
 	}
 	defer file.Close()
 	
-	return png.Encode(file, img)
+	return fynetest.EncodePNG(file, img)
 }
 
 func loadImage(path string) (image.Image, error) {
@@ -212,45 +627,46 @@ func loadImage(path string) (image.Image, error) {
 	return png.Decode(file)
 }
 
-func imagesEqual(a, b image.Image) bool {
-	if a.Bounds() != b.Bounds() {
-		return false
+// imageComparer returns the comparer selected by the options: a custom
+// comparer, chart mode or the effective tolerance, in that order.
+func (o *screenshotOptions) imageComparer() fynetest.ImageComparer {
+	switch {
+	case o.comparer != nil:
+		return o.comparer
+	case o.chart != nil:
+		return *o.chart
 	}
-	
-	bounds := a.Bounds()
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			if a.At(x, y) != b.At(x, y) {
-				return false
-			}
-		}
+	return o.effectiveTolerance()
+}
+
+// streamMatch reports whether the snapshot at path matches img, reading the
+// baseline scanline by scanline. Mismatches are compared again in full by the
+// caller to report exact counts and build the diff image.
+func streamMatch(path string, img image.Image, ignored []image.Rectangle, options *screenshotOptions) bool {
+	if options.chart != nil || options.comparer != nil {
+		return false
 	}
-	
-	return true
+	result, err := fynetest.CompareFile(path, img, options.effectiveTolerance(), ignored)
+	return err == nil && result.Match && result.Band != fynetest.BandWarn
 }
 
-func createDiffImage(expected, actual image.Image) image.Image {
-	bounds := expected.Bounds()
-	if bounds != actual.Bounds() {
-		return nil
+// effectiveTolerance combines the tolerance with the comparison mode option.
+func (o *screenshotOptions) effectiveTolerance() fynetest.Tolerance {
+	tol := *o.tolerance
+	if o.mode != nil {
+		tol.Mode = *o.mode
 	}
-	
-	diff := image.NewRGBA(bounds)
-	
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			e := expected.At(x, y)
-			a := actual.At(x, y)
-			
-			if e != a {
-				diff.Set(x, y, theme.ErrorColor())
-			} else {
-				diff.Set(x, y, e)
-			}
-		}
+	if o.ignoreAA {
+		tol.IgnoreAntialiasing = true
 	}
-	
-	return diff
+	if o.minSimilarity > 0 {
+		tol.MinSimilarity = o.minSimilarity
+	}
+	return tol
+}
+
+func createDiffImage(expected, actual image.Image, tol fynetest.Tolerance) image.Image {
+	return fynetest.DiffImage(expected, actual, tol, theme.ErrorColor())
 }
 
 func AssertScreenshot(t *testing.T, name string, content fyne.CanvasObject, opts ...ScreenshotOption) {
@@ -263,4 +679,10 @@ func AssertSnapshot(t *testing.T, name string, content fyne.CanvasObject, opts .
 	t.Helper()
 	vt := New(t)
 	vt.Snapshot(name, content, opts...)
+}
+
+func AssertCanvasSnapshot(t *testing.T, name string, obj fyne.CanvasObject, opts ...ScreenshotOption) {
+	t.Helper()
+	vt := New(t)
+	vt.CanvasSnapshot(name, obj, opts...)
 }
\ No newline at end of file
@@ -0,0 +1,168 @@
+package fynetest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// MetadataThemeHint is the result metadata key of the theme change a
+// mismatch was attributed to, if any.
+const MetadataThemeHint = "theme_hint"
+
+// Thresholds of DetectColorSwap.
+const (
+	// minSwapShare is the share of the differing pixels one color swap must
+	// explain; anti-aliased edges blend both colors and make up the rest
+	minSwapShare = 0.5
+
+	// minSwapPixels keeps a few stray pixels from being called a swap
+	minSwapPixels = 16
+
+	// swapColorDelta is how far colors may differ and still count as one
+	swapColorDelta = 2
+)
+
+// themeColorNames are the theme colors a swap is matched against.
+var themeColorNames = []fyne.ThemeColorName{
+	theme.ColorNamePrimary,
+	theme.ColorNameForeground,
+	theme.ColorNameBackground,
+	theme.ColorNameButton,
+	theme.ColorNameDisabled,
+	theme.ColorNameDisabledButton,
+	theme.ColorNameError,
+	theme.ColorNameFocus,
+	theme.ColorNameHover,
+	theme.ColorNameHyperlink,
+	theme.ColorNameInputBackground,
+	theme.ColorNameInputBorder,
+	theme.ColorNameMenuBackground,
+	theme.ColorNameOverlayBackground,
+	theme.ColorNamePlaceHolder,
+	theme.ColorNamePressed,
+	theme.ColorNameScrollBar,
+	theme.ColorNameSelection,
+	theme.ColorNameSeparator,
+	theme.ColorNameShadow,
+	theme.ColorNameSuccess,
+	theme.ColorNameWarning,
+}
+
+// ColorSwap is a baseline mismatch dominated by one color being replaced
+// with another, typically a theme color change.
+type ColorSwap struct {
+	Expected color.NRGBA
+	Actual   color.NRGBA
+
+	// Share is the fraction of the differing pixels the swap explains
+	Share float64
+
+	// ColorName is the theme color that has the actual (or, failing that,
+	// the expected) value, empty when none does
+	ColorName fyne.ThemeColorName
+}
+
+// String describes the swap, e.g.
+// "likely theme change: primary color #1976d2 → #0d47a1".
+func (s ColorSwap) String() string {
+	if s.ColorName == "" {
+		return fmt.Sprintf("likely color change: %s → %s in %.0f%% of changed pixels",
+			hexColor(s.Expected), hexColor(s.Actual), s.Share*100)
+	}
+	return fmt.Sprintf("likely theme change: %s color %s → %s", s.ColorName, hexColor(s.Expected), hexColor(s.Actual))
+}
+
+// DetectColorSwap builds a histogram of the (expected, actual) color pairs
+// of the pixels that differ between two images of the same size, and
+// returns the dominant pair when it explains most of the difference. The
+// colors are looked up in th, in both variants, to name the theme color
+// that changed; a nil th uses the default theme.
+func DetectColorSwap(expected, actual image.Image, th fyne.Theme) *ColorSwap {
+	eb, ab := expected.Bounds(), actual.Bounds()
+	if eb.Size() != ab.Size() {
+		return nil
+	}
+
+	pairs := make(map[[2]color.NRGBA]int)
+	differing := 0
+	for y := 0; y < eb.Dy(); y++ {
+		for x := 0; x < eb.Dx(); x++ {
+			e := color.NRGBAModel.Convert(expected.At(eb.Min.X+x, eb.Min.Y+y)).(color.NRGBA)
+			a := color.NRGBAModel.Convert(actual.At(ab.Min.X+x, ab.Min.Y+y)).(color.NRGBA)
+			if channelDelta(e, a) <= swapColorDelta {
+				continue
+			}
+			differing++
+			pairs[[2]color.NRGBA{e, a}]++
+		}
+	}
+	if differing < minSwapPixels {
+		return nil
+	}
+
+	var top [2]color.NRGBA
+	count := 0
+	for pair, n := range pairs {
+		if n > count || n == count && hexColor(pair[0])+hexColor(pair[1]) < hexColor(top[0])+hexColor(top[1]) {
+			top, count = pair, n
+		}
+	}
+	share := float64(count) / float64(differing)
+	if share < minSwapShare {
+		return nil
+	}
+
+	swap := &ColorSwap{Expected: top[0], Actual: top[1], Share: share}
+	if swap.ColorName = themeColorName(th, top[1]); swap.ColorName == "" {
+		swap.ColorName = themeColorName(th, top[0])
+	}
+	return swap
+}
+
+// themeColorName returns the name of the theme color with value c, if any.
+func themeColorName(th fyne.Theme, c color.NRGBA) fyne.ThemeColorName {
+	if th == nil {
+		th = theme.DefaultTheme()
+	}
+	if th == nil {
+		return ""
+	}
+	for _, variant := range []fyne.ThemeVariant{theme.VariantLight, theme.VariantDark} {
+		for _, name := range themeColorNames {
+			value := th.Color(name, variant)
+			if value == nil {
+				continue
+			}
+			if channelDelta(color.NRGBAModel.Convert(value).(color.NRGBA), c) <= swapColorDelta {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// themeHint looks for a color swap between the baseline of test and img,
+// ignoring the masked rectangles.
+func (r *Runner) themeHint(test Test, img image.Image, ignored []image.Rectangle, th fyne.Theme) *ColorSwap {
+	expected, err := decodePNGFile(r.BaselinePath(test))
+	if err != nil || expected.Bounds().Size() != img.Bounds().Size() {
+		return nil
+	}
+	if len(ignored) > 0 {
+		expected, img = maskedPair(expected, img, ignored)
+		defer ReleaseImage(expected)
+		defer ReleaseImage(img)
+	}
+	return DetectColorSwap(expected, img, th)
+}
+
+func hexColor(c color.NRGBA) string {
+	if c.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
@@ -0,0 +1,113 @@
+package fynetest
+
+import (
+	"image"
+	"image/png"
+	"os"
+)
+
+// AssetThumbnail is the kind of the downscaled copy of a capture shown in
+// the HTML report (see AssetPath).
+const AssetThumbnail = "thumb"
+
+// DefaultThumbnailMaxWidth is the default ReportGenerator.ThumbnailMaxWidth.
+const DefaultThumbnailMaxWidth = 640
+
+// thumbnailSource returns a function mapping an image to the thumbnail the
+// report shows in its place. Thumbnails are written next to the image on
+// first use and reused while they are newer than it. Images no wider than
+// ThumbnailMaxWidth, or that can't be scaled, are shown as they are.
+func (g *ReportGenerator) thumbnailSource(cache map[string]string) func(string) string {
+	return func(path string) string {
+		if g.ThumbnailMaxWidth <= 0 || path == "" {
+			return path
+		}
+		if thumb, ok := cache[path]; ok {
+			return thumb
+		}
+		thumb, err := writeThumbnail(path, g.ThumbnailMaxWidth)
+		if err != nil {
+			thumb = path
+		}
+		cache[path] = thumb
+		return thumb
+	}
+}
+
+// writeThumbnail saves a copy of the PNG at path scaled down to maxWidth and
+// returns its path, or path itself when the image is narrow enough.
+func writeThumbnail(path string, maxWidth int) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	config, err := png.DecodeConfig(file)
+	file.Close()
+	if err != nil {
+		return "", err
+	}
+	if config.Width <= maxWidth {
+		return path, nil
+	}
+
+	thumb := AssetPath(path, AssetThumbnail)
+	if source, err := os.Stat(path); err == nil {
+		if existing, err := os.Stat(thumb); err == nil && !existing.ModTime().Before(source.ModTime()) {
+			return thumb, nil
+		}
+	}
+	img, err := decodePNGFile(path)
+	if err != nil {
+		return "", err
+	}
+	height := config.Height * maxWidth / config.Width
+	if height < 1 {
+		height = 1
+	}
+	scaled := downscale(img, maxWidth, height)
+	defer ReleaseImage(scaled)
+	if err := writeImage(scaled, thumb); err != nil {
+		return "", err
+	}
+	return thumb, nil
+}
+
+// downscale resizes img to w×h by averaging the source pixels covering each
+// target pixel, which keeps thin lines and text legible when shrinking.
+func downscale(img image.Image, w, h int) *image.RGBA {
+	src := newPixelBuffer(img)
+	defer src.release()
+
+	out := newPooledRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := span(y, src.h, h)
+		for x := 0; x < w; x++ {
+			x0, x1 := span(x, src.w, w)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.row(sy)
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(row[sx*4+c])
+					}
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := out.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				out.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return out
+}
+
+// span returns the range of the n source pixels covered by target pixel i of
+// m, at least one pixel wide.
+func span(i, n, m int) (int, int) {
+	start, end := i*n/m, (i+1)*n/m
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
@@ -0,0 +1,214 @@
+package fynetest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TimelinePrefix is where the CLI mounts TimelineHandler while serving a
+// report with -serve.
+const TimelinePrefix = "/_timeline/"
+
+// timelineEntry is the capture of a test in one retained run.
+type timelineEntry struct {
+	Run       string
+	Timestamp time.Time
+	Success   bool
+	Image     string
+
+	// Diff compares the capture with the one of the previous entry, nil
+	// for the first entry or when either can't be read
+	Diff *DiffResult
+}
+
+// TimelineHandler serves a page per test showing its capture across the runs
+// retained in outputDir, oldest to newest, with a scrubber to step through
+// them and the share of pixels that changed from run to run. It reveals
+// when a slow drift started, even if no run failed. The page is
+// "?test=<name>&profile=<profile>" below the handler; captures are served
+// from "runs/<run>/<file>", from run archives too.
+func (g *ReportGenerator) TimelineHandler(outputDir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if rest, ok := strings.CutPrefix(path.Clean("/"+req.URL.Path), "/runs/"); ok {
+			g.serveRunFile(w, outputDir, rest)
+			return
+		}
+		test := req.URL.Query().Get("test")
+		if test == "" {
+			http.Error(w, "missing test parameter", http.StatusBadRequest)
+			return
+		}
+		entries, err := timeline(outputDir, test, req.URL.Query().Get("profile"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := g.writeTimeline(w, outputDir, test, entries); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// serveRunFile serves "<run>/<file>" of a run in outputDir.
+func (g *ReportGenerator) serveRunFile(w http.ResponseWriter, outputDir, name string) {
+	runName, rel, ok := strings.Cut(name, "/")
+	if !ok || runName == ".." {
+		http.NotFound(w, nil)
+		return
+	}
+	run, err := LoadRun(filepath.Join(outputDir, runName))
+	if err != nil {
+		http.NotFound(w, nil)
+		return
+	}
+	file, err := run.Open(filepath.Join(run.Path, filepath.FromSlash(rel)))
+	if err != nil {
+		http.NotFound(w, nil)
+		return
+	}
+	defer file.Close()
+	if strings.HasSuffix(rel, ".png") {
+		w.Header().Set("Content-Type", "image/png")
+	}
+	io.Copy(w, file)
+}
+
+// timeline lists the captures of a test across the runs in outputDir,
+// oldest first. Runs without the test are left out.
+func timeline(outputDir, test, profile string) ([]timelineEntry, error) {
+	runs, err := LoadRuns(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load runs: %w", err)
+	}
+
+	entries := make([]timelineEntry, 0, len(runs))
+	var previous *RunData
+	var previousPath string
+	for _, run := range runs {
+		result, ok := run.Find(test, profile)
+		if !ok || result.Skipped {
+			continue
+		}
+		name := filepath.Base(run.Path)
+		entry := timelineEntry{Run: strings.TrimSuffix(name, ArchiveExt), Timestamp: run.Timestamp, Success: result.Success}
+		if result.ScreenshotPath != "" {
+			if rel, err := filepath.Rel(run.Path, result.ScreenshotPath); err == nil {
+				entry.Image = "runs/" + name + "/" + filepath.ToSlash(rel)
+			}
+			if previous != nil {
+				entry.Diff = compareRunCaptures(previous, previousPath, run, result.ScreenshotPath)
+			}
+			previous, previousPath = run, result.ScreenshotPath
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// compareRunCaptures compares the captures of two runs exactly, or returns
+// nil when either can't be read.
+func compareRunCaptures(a *RunData, aPath string, b *RunData, bPath string) *DiffResult {
+	expected, err := a.Image(aPath)
+	if err != nil {
+		return nil
+	}
+	actual, err := b.Image(bPath)
+	if err != nil {
+		return nil
+	}
+	diff := CompareImages(expected, actual, Tolerance{})
+	return &diff
+}
+
+// writeTimeline renders the timeline page of test.
+func (g *ReportGenerator) writeTimeline(w io.Writer, outputDir, test string, entries []timelineEntry) error {
+	tmpl, err := g.createTemplate(outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+	if tmpl, err = tmpl.New("timeline").Parse(timelineTemplate); err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+	return tmpl.ExecuteTemplate(w, "timeline", struct {
+		Title      string
+		StyleSheet string
+		Test       string
+		Entries    []timelineEntry
+	}{g.Title, g.StyleSheet, test, entries})
+}
+
+const timelineTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Test}} over time · {{.Title}}</title>
+    <style>
+{{.StyleSheet}}
+        .timeline-scrubber { display: flex; gap: 1rem; align-items: center; margin-bottom: 1rem; }
+        .timeline-scrubber input { flex: 1; }
+        .timeline-frame img { display: block; max-width: 100%; border: 1px solid #ddd; }
+        .timeline-runs { list-style: none; padding: 0; display: flex; flex-wrap: wrap; gap: 0.5rem; }
+        .timeline-runs button { border: 1px solid #cbd5e0; background: white; border-radius: 6px; padding: 0.25rem 0.5rem; cursor: pointer; }
+        .timeline-runs button.changed { border-color: #f59e0b; background: #fffbeb; }
+        .timeline-runs button[aria-current="true"] { outline: 2px solid #4a5568; }
+    </style>
+</head>
+<body>
+    <header class="header">
+        <h1>{{.Test}} over time</h1>
+        <p class="timestamp"><a href="../">Back to the report</a> · {{len .Entries}} runs</p>
+    </header>
+    <main class="tests">
+        {{if .Entries}}
+        <div class="timeline-scrubber">
+            <label for="scrubber">Run</label>
+            <input type="range" id="scrubber" min="0" max="{{len .Entries}}" value="0" step="1">
+            <output id="scrubber-label" for="scrubber"></output>
+        </div>
+        {{range $i, $e := .Entries}}
+        <figure class="timeline-frame" data-index="{{$i}}" hidden>
+            {{with .Image}}<img src="{{.}}" alt="{{$.Test}} in run {{$e.Run}}" loading="lazy">{{else}}<p>No capture in this run.</p>{{end}}
+            <figcaption class="caption">
+                {{if .Timestamp.IsZero}}{{.Run}}{{else}}<time datetime="{{.Timestamp.Format "2006-01-02T15:04:05Z07:00"}}">{{formatTime .Timestamp}}</time>{{end}}
+                · {{if .Success}}<span aria-hidden="true">✅</span> passed{{else}}<span aria-hidden="true">❌</span> failed{{end}}
+                {{with .Diff}} · {{if .SizeMismatch}}size changed{{else if .Match}}unchanged from the previous run{{else}}{{printf "%.2f%%" .DiffPercent}} of pixels changed from the previous run{{end}}{{end}}
+            </figcaption>
+        </figure>
+        {{end}}
+        <ol class="timeline-runs" aria-label="Runs">
+            {{range $i, $e := .Entries}}
+            <li><button type="button" data-index="{{$i}}"{{with .Diff}}{{if not .Match}} class="changed" title="Changed from the previous run"{{end}}{{end}}>{{if .Success}}✅{{else}}❌{{end}} {{if .Timestamp.IsZero}}{{.Run}}{{else}}{{.Timestamp.Format "Jan 2 15:04"}}{{end}}</button></li>
+            {{end}}
+        </ol>
+        {{else}}
+        <p>No retained run has this test.</p>
+        {{end}}
+    </main>
+    <script>
+    (function() {
+        const scrubber = document.getElementById('scrubber');
+        if (!scrubber) return;
+        const frames = document.querySelectorAll('.timeline-frame');
+        const buttons = document.querySelectorAll('.timeline-runs button');
+        scrubber.max = frames.length - 1;
+        function show(index) {
+            scrubber.value = index;
+            frames.forEach(frame => { frame.hidden = frame.dataset.index !== String(index); });
+            buttons.forEach(button => button.setAttribute('aria-current', button.dataset.index === String(index)));
+            document.getElementById('scrubber-label').textContent = (Number(index) + 1) + ' / ' + frames.length;
+        }
+        scrubber.addEventListener('input', () => show(scrubber.value));
+        buttons.forEach(button => button.addEventListener('click', () => show(button.dataset.index)));
+        // Start at the newest run
+        show(frames.length - 1);
+    })();
+    </script>
+</body>
+</html>
+`
@@ -0,0 +1,189 @@
+package fynetest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// ErrTimeout is wrapped by the error of a test that took longer than its
+// timeout to set up, render and capture.
+var ErrTimeout = errors.New("test timed out")
+
+// timeout returns how long test may take to capture, or 0 for no limit.
+func (r *Runner) timeout(test Test) time.Duration {
+	if test.Timeout == 0 {
+		return r.DefaultTimeout
+	}
+	return test.Timeout
+}
+
+// timedCapture is safeCapture giving up after the test's timeout. A capture
+// that times out is left running on its own goroutine, since Go can't stop
+// it: its window is closed and never captured, its Setup, if still running,
+// is abandoned by guard so the next test can capture its own output, and
+// the shared app is retired so the next test doesn't render beside it. A
+// Setup that never returns keeps its goroutine for the rest of the process.
+func (r *Runner) timedCapture(app fyne.App, test Test, guard *setupGuard) (windowCapture, []byte, error) {
+	timeout := r.timeout(test)
+	if timeout <= 0 {
+		return r.safeCapture(app, test)
+	}
+
+	type outcome struct {
+		shot  windowCapture
+		stack []byte
+		err   error
+	}
+	open := make(map[fyne.Window]bool)
+	for _, w := range app.Driver().AllWindows() {
+		open[w] = true
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		shot, stack, err := r.safeCapture(app, test)
+		done <- outcome{shot, stack, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case o := <-done:
+		return o.shot, o.stack, o.err
+	case <-timer.C:
+		guard.abandon()
+		// The abandoned capture's window would otherwise stay open
+		for _, w := range app.Driver().AllWindows() {
+			if window, ok := captureWindows.Load(w); ok && !open[w] {
+				window.(*captureWindow).Close()
+			}
+		}
+		r.retireApp(app)
+		return windowCapture{}, nil, fmt.Errorf("%w after %v", ErrTimeout, timeout)
+	}
+}
+
+// retireApp stops sharing app with the tests to come, which get a fresh one
+// from ensureApp. It isn't quit, since an abandoned capture may still use
+// it. Isolated and GL apps aren't the shared app and are left alone.
+func (r *Runner) retireApp(app fyne.App) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.app == app {
+		r.app = nil
+	}
+}
+
+// captureWindows maps the windows opened by showWindow and not closed yet
+// to their captureWindow.
+var captureWindows sync.Map
+
+// captureWindow is a window opened by showWindow. Closing it more than once
+// has no effect, so a timed out capture's window can be closed while the
+// capture may still close it itself; fyne's test driver deadlocks on a
+// second Close.
+type captureWindow struct {
+	fyne.Window
+	once sync.Once
+}
+
+func newCaptureWindow(app fyne.App, title string) *captureWindow {
+	w := &captureWindow{Window: app.NewWindow(title)}
+	captureWindows.Store(w.Window, w)
+	return w
+}
+
+func (w *captureWindow) Close() {
+	w.once.Do(func() {
+		w.Window.Close()
+		captureWindows.Delete(w.Window)
+	})
+}
+
+// setupRun is how a test's Setup went.
+type setupRun struct {
+	output   string
+	duration time.Duration
+
+	// content is nil unless Setup returned
+	content fyne.CanvasObject
+}
+
+// setupGuard runs a test's Setup with its output captured, and lets a timed
+// out capture stop waiting for it.
+type setupGuard struct {
+	// stop is closed when the capture is abandoned; nil without a timeout
+	stop chan struct{}
+	done chan setupRun
+	
+	// capture is unset when Setup's output isn't captured
+	capture bool
+
+	mu        sync.Mutex
+	started   bool
+	abandoned bool
+}
+
+func newSetupGuard(timeout, capture bool) *setupGuard {
+	g := &setupGuard{done: make(chan setupRun, 1), capture: capture}
+	if timeout {
+		g.stop = make(chan struct{})
+	}
+	return g
+}
+
+// run calls setup, with its output captured unless disabled. Once the
+// capture is abandoned it returns nil, without calling setup if it hadn't
+// started.
+func (g *setupGuard) run(setup func() fyne.CanvasObject) fyne.CanvasObject {
+	g.mu.Lock()
+	if g.abandoned {
+		g.mu.Unlock()
+		return nil
+	}
+	g.started = true
+	g.mu.Unlock()
+
+	var run setupRun
+	start := time.Now()
+	defer func() {
+		run.duration = time.Since(start)
+		g.done <- run
+	}()
+	var content fyne.CanvasObject
+	var finished bool
+	if g.capture {
+		run.output, finished = captureOutputUntil(func() { content = setup() }, g.stop)
+	} else {
+		finished = runUntil(func() { content = setup() }, g.stop)
+	}
+	if finished {
+		run.content = content
+	}
+	return run.content
+}
+
+// abandon stops waiting for Setup.
+func (g *setupGuard) abandon() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.abandoned && g.stop != nil {
+		g.abandoned = true
+		close(g.stop)
+	}
+}
+
+// result returns how Setup went once the capture finished or was abandoned.
+// It is zero when Setup was never called.
+func (g *setupGuard) result() setupRun {
+	g.mu.Lock()
+	started := g.started
+	g.mu.Unlock()
+	if !started {
+		return setupRun{}
+	}
+	return <-g.done
+}
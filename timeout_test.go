@@ -0,0 +1,52 @@
+package fynetest
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestTimedOutTestMovesOn(t *testing.T) {
+	r := NewRunner()
+	r.OutputDir = t.TempDir()
+	r.DefaultWaitDuration = 0
+	r.DefaultTimeout = 200 * time.Millisecond
+	r.Retries = 2
+	t.Cleanup(r.Cleanup)
+
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	var hangs atomic.Int32
+	hanging := NewTest("Hangs").
+		WithSize(100, 50).
+		WithSetup(func() fyne.CanvasObject {
+			hangs.Add(1)
+			<-release
+			return widget.NewLabel("Hangs")
+		}).
+		MustBuild()
+	next := NewTest("Next").
+		WithSize(100, 50).
+		WithSetup(func() fyne.CanvasObject { return widget.NewLabel("Next") }).
+		MustBuild()
+
+	shared := r.ensureApp()
+	results := r.RunTests([]Test{hanging, next})
+
+	if !errors.Is(results[0].Error, ErrTimeout) {
+		t.Fatalf("Hangs: error = %v, want ErrTimeout", results[0].Error)
+	}
+	if hangs.Load() != 1 || results[0].Attempts != 1 {
+		t.Errorf("Hangs ran %d times over %d attempts; a timed out test shouldn't be retried", hangs.Load(), results[0].Attempts)
+	}
+	if !results[1].Success {
+		t.Errorf("Next failed after the timeout: %v", results[1].Error)
+	}
+	if r.app == shared {
+		t.Error("the test after a timeout rendered on the app the abandoned capture still uses")
+	}
+}
@@ -0,0 +1,287 @@
+package fynetest
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	fynetest "fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+// TreeSuffix is appended to a baseline path to name the widget tree stored
+// with it.
+const TreeSuffix = ".tree.json"
+
+// WidgetNode describes one object of the rendered widget tree: its type,
+// geometry relative to its parent and the state that affects its rendering.
+type WidgetNode struct {
+	Type     string            `json:"type"`
+	Name     string            `json:"name,omitempty"`
+	Role     SemanticRole      `json:"role,omitempty"`
+	Position fyne.Position     `json:"position"`
+	Size     fyne.Size         `json:"size"`
+	Text     string            `json:"text,omitempty"`
+	Props    map[string]string `json:"props,omitempty"`
+	Hidden   bool              `json:"hidden,omitempty"`
+	Children []*WidgetNode     `json:"children,omitempty"`
+}
+
+// CaptureWidgetTree records the tree rooted at obj. It must be called while
+// obj is shown, since widget internals are read from their renderers.
+// Widgets whose state is fully described by their properties, like labels
+// and buttons, are recorded without their internal objects.
+func CaptureWidgetTree(obj fyne.CanvasObject) *WidgetNode {
+	if obj == nil {
+		return nil
+	}
+
+	node, children := newWidgetNode(obj)
+	for _, child := range children {
+		node.Children = append(node.Children, CaptureWidgetTree(child))
+	}
+	return node
+}
+
+// newWidgetNode describes obj without its children, and returns the
+// children the tree records under it.
+func newWidgetNode(obj fyne.CanvasObject) (*WidgetNode, []fyne.CanvasObject) {
+	node := &WidgetNode{
+		Type:     typeName(obj),
+		Name:     objectName(obj),
+		Position: obj.Position(),
+		Size:     obj.Size(),
+		Hidden:   !obj.Visible(),
+	}
+	leaf := describe(node, obj)
+	node.Role = InferRole(node)
+
+	var children []fyne.CanvasObject
+	switch o := obj.(type) {
+	case *fyne.Container:
+		children = o.Objects
+	case fyne.Widget:
+		if !leaf {
+			if renderer := fynetest.WidgetRenderer(o); renderer != nil {
+				children = renderer.Objects()
+			}
+		}
+	}
+	return node, children
+}
+
+// describe records the rendering state of well-known objects on node and
+// reports whether their internals can be left out of the tree.
+func describe(node *WidgetNode, obj fyne.CanvasObject) bool {
+	props := make(map[string]string)
+	leaf := true
+	switch o := obj.(type) {
+	case *widget.Label:
+		node.Text = o.Text
+	case *widget.Button:
+		node.Text = o.Text
+		props["importance"] = strconv.Itoa(int(o.Importance))
+	case *widget.Entry:
+		node.Text = o.Text
+		props["placeholder"] = o.PlaceHolder
+	case *widget.Check:
+		node.Text = o.Text
+		props["checked"] = strconv.FormatBool(o.Checked)
+	case *widget.Select:
+		node.Text = o.Selected
+		props["placeholder"] = o.PlaceHolder
+	case *widget.RadioGroup:
+		node.Text = o.Selected
+		props["options"] = strings.Join(o.Options, ",")
+	case *widget.Slider:
+		props["value"] = strconv.FormatFloat(o.Value, 'g', -1, 64)
+	case *widget.ProgressBar:
+		props["value"] = strconv.FormatFloat(o.Value, 'g', -1, 64)
+	case *canvas.Text:
+		node.Text = o.Text
+	default:
+		leaf = false
+	}
+
+	if d, ok := obj.(fyne.Disableable); ok && d.Disabled() {
+		props["disabled"] = "true"
+	}
+	for key, value := range props {
+		if value == "" {
+			delete(props, key)
+		}
+	}
+	if len(props) > 0 {
+		node.Props = props
+	}
+	return leaf
+}
+
+func typeName(obj fyne.CanvasObject) string {
+	return strings.TrimPrefix(reflect.TypeOf(obj).String(), "*")
+}
+
+// Count returns the number of objects in the tree rooted at n.
+func (n *WidgetNode) Count() int {
+	if n == nil {
+		return 0
+	}
+	count := 1
+	for _, child := range n.Children {
+		count += child.Count()
+	}
+	return count
+}
+
+// SaveWidgetTree writes tree as indented JSON to path.
+func SaveWidgetTree(path string, tree *WidgetNode) error {
+	data, err := NewWidgetTree(tree).Marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadWidgetTree reads a widget tree written by SaveWidgetTree.
+func LoadWidgetTree(path string) (*WidgetNode, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := UnmarshalWidgetTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid widget tree %s: %w", path, err)
+	}
+	return tree.Root, nil
+}
+
+// Kinds of WidgetChange.
+const (
+	WidgetAdded   = "added"
+	WidgetRemoved = "removed"
+	WidgetChanged = "changed"
+)
+
+// WidgetChange is one structural difference between two widget trees.
+type WidgetChange struct {
+	Kind string `json:"kind"`
+
+	// Path locates the widget, e.g. "fyne.Container/widget.Button#1": each
+	// step is a type, numbered among the siblings of the same type
+	Path string `json:"path"`
+
+	// Details lists what changed, e.g. `text "Save" -> "Submit"`
+	Details []string `json:"details,omitempty"`
+}
+
+func (c WidgetChange) String() string {
+	if len(c.Details) == 0 {
+		return c.Kind + " " + c.Path
+	}
+	return c.Kind + " " + c.Path + ": " + strings.Join(c.Details, ", ")
+}
+
+// DiffWidgetTrees returns the widgets added, removed or changed from
+// expected to actual. Children are matched by type and order among siblings
+// of the same type, so inserting a widget doesn't shift unrelated ones.
+func DiffWidgetTrees(expected, actual *WidgetNode) []WidgetChange {
+	changes := make([]WidgetChange, 0)
+	diffChildren(&changes, "", []*WidgetNode{expected}, []*WidgetNode{actual})
+	return changes
+}
+
+func diffChildren(changes *[]WidgetChange, parent string, expected, actual []*WidgetNode) {
+	expectedKeys, expectedByKey := keyNodes(expected)
+	_, actualByKey := keyNodes(actual)
+
+	for _, key := range expectedKeys {
+		path := joinPath(parent, key)
+		e, a := expectedByKey[key], actualByKey[key]
+		if a == nil {
+			*changes = append(*changes, WidgetChange{Kind: WidgetRemoved, Path: path})
+			continue
+		}
+		if details := nodeChanges(e, a); len(details) > 0 {
+			*changes = append(*changes, WidgetChange{Kind: WidgetChanged, Path: path, Details: details})
+		}
+		diffChildren(changes, path, e.Children, a.Children)
+	}
+
+	actualKeys, _ := keyNodes(actual)
+	for _, key := range actualKeys {
+		if expectedByKey[key] == nil {
+			*changes = append(*changes, WidgetChange{Kind: WidgetAdded, Path: joinPath(parent, key)})
+		}
+	}
+}
+
+// keyNodes names each node by its type and, after the first, its index
+// among siblings of the same type.
+func keyNodes(nodes []*WidgetNode) ([]string, map[string]*WidgetNode) {
+	keys := make([]string, 0, len(nodes))
+	byKey := make(map[string]*WidgetNode, len(nodes))
+	seen := make(map[string]int)
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		key := node.Type
+		if n := seen[node.Type]; n > 0 {
+			key += "#" + strconv.Itoa(n)
+		}
+		seen[node.Type]++
+		keys = append(keys, key)
+		byKey[key] = node
+	}
+	return keys, byKey
+}
+
+func nodeChanges(expected, actual *WidgetNode) []string {
+	details := make([]string, 0)
+	if expected.Text != actual.Text {
+		details = append(details, fmt.Sprintf("text %q -> %q", expected.Text, actual.Text))
+	}
+	if expected.Hidden != actual.Hidden {
+		details = append(details, fmt.Sprintf("hidden %t -> %t", expected.Hidden, actual.Hidden))
+	}
+	if expected.Position != actual.Position {
+		details = append(details, fmt.Sprintf("position (%g,%g) -> (%g,%g)",
+			expected.Position.X, expected.Position.Y, actual.Position.X, actual.Position.Y))
+	}
+	if expected.Size != actual.Size {
+		details = append(details, fmt.Sprintf("size %gx%g -> %gx%g",
+			expected.Size.Width, expected.Size.Height, actual.Size.Width, actual.Size.Height))
+	}
+	for _, key := range propKeys(expected.Props, actual.Props) {
+		if e, a := expected.Props[key], actual.Props[key]; e != a {
+			details = append(details, fmt.Sprintf("%s %q -> %q", key, e, a))
+		}
+	}
+	return details
+}
+
+func propKeys(a, b map[string]string) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "/" + key
+}
@@ -0,0 +1,147 @@
+package fynetest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultHistoryRuns is how many previous runs the report's trend section
+// covers.
+const DefaultHistoryRuns = 20
+
+// Chart dimensions of the trend section, in SVG user units.
+const (
+	trendWidth  = 240
+	trendHeight = 60
+)
+
+// TrendPoint is one run in the report's trend section.
+type TrendPoint struct {
+	Timestamp time.Time
+	Summary   Summary
+
+	// Diffs is the number of results that did not match their baseline
+	Diffs int
+}
+
+// trendChart is one metric of the trend section, drawn as a sparkline.
+type trendChart struct {
+	Title  string
+	Latest string
+	Points string
+	Dots   []trendDot
+}
+
+type trendDot struct {
+	X, Y  float64
+	Label string
+}
+
+// loadHistory returns the last HistoryRuns runs in HistoryDir, oldest first.
+// The run in currentDir, whose report is being written, is left out.
+func (g *ReportGenerator) loadHistory(currentDir string) []*RunData {
+	if g.HistoryDir == "" {
+		return nil
+	}
+	paths, err := ListRuns(g.HistoryDir)
+	if err != nil {
+		return nil
+	}
+
+	runs := make([]*RunData, 0, g.HistoryRuns)
+	current, _ := filepath.Abs(currentDir)
+	for i := len(paths) - 1; i >= 0 && len(runs) < g.HistoryRuns; i-- {
+		if abs, _ := filepath.Abs(paths[i]); abs == current {
+			continue
+		}
+		run, err := LoadRun(paths[i])
+		if err != nil {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	for i, j := 0, len(runs)-1; i < j; i, j = i+1, j-1 {
+		runs[i], runs[j] = runs[j], runs[i]
+	}
+	return runs
+}
+
+// trendPoints returns a point for each run of history followed by the
+// current results, or nothing without history.
+func trendPoints(results []Result, history []*RunData) []TrendPoint {
+	if len(history) == 0 {
+		return nil
+	}
+	points := make([]TrendPoint, 0, len(history)+1)
+	for _, run := range history {
+		points = append(points, TrendPoint{Timestamp: run.Timestamp, Summary: run.Summary, Diffs: countDiffs(run.Results)})
+	}
+	return append(points, TrendPoint{Timestamp: time.Now(), Summary: newSummary(results), Diffs: countDiffs(results)})
+}
+
+// flakeScores scores the tests of results over history, or returns nothing
+// without history.
+func flakeScores(results []Result, history []*RunData) []FlakeScore {
+	if len(history) == 0 {
+		return nil
+	}
+	return FlakeScores(append(history, &RunData{Results: results}))
+}
+
+func countDiffs(results []Result) int {
+	diffs := 0
+	for _, result := range results {
+		if result.Diff != nil && !result.Diff.Match {
+			diffs++
+		}
+	}
+	return diffs
+}
+
+// trendCharts draws the pass rate, duration and diff count of points, or
+// nothing when there is no previous run to compare with.
+func trendCharts(points []TrendPoint) []trendChart {
+	if len(points) < 2 {
+		return nil
+	}
+	// A max of 0 scales the chart to the largest value
+	metric := func(title string, max float64, value func(TrendPoint) float64, format func(float64) string) trendChart {
+		values := make([]float64, len(points))
+		for i, point := range points {
+			values[i] = value(point)
+		}
+		if max == 0 {
+			for _, v := range values {
+				if v > max {
+					max = v
+				}
+			}
+		}
+		if max == 0 {
+			max = 1
+		}
+
+		chart := trendChart{Title: title, Latest: format(values[len(values)-1])}
+		coords := make([]string, len(values))
+		for i, v := range values {
+			x := float64(i)/float64(len(values)-1)*(trendWidth-8) + 4
+			y := trendHeight - 4 - v/max*(trendHeight-8)
+			coords[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+			chart.Dots = append(chart.Dots, trendDot{X: x, Y: y,
+				Label: points[i].Timestamp.Format("2006-01-02 15:04") + ": " + format(v)})
+		}
+		chart.Points = strings.Join(coords, " ")
+		return chart
+	}
+
+	return []trendChart{
+		metric("Pass rate", 100, func(p TrendPoint) float64 { return p.Summary.PassRate },
+			func(v float64) string { return fmt.Sprintf("%.1f%%", v) }),
+		metric("Duration", 0, func(p TrendPoint) float64 { return p.Summary.Duration.Seconds() },
+			func(v float64) string { return formatDuration(time.Duration(v * float64(time.Second))) }),
+		metric("Baseline diffs", 0, func(p TrendPoint) float64 { return float64(p.Diffs) },
+			func(v float64) string { return fmt.Sprintf("%.0f", v) }),
+	}
+}
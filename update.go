@@ -0,0 +1,109 @@
+package fynetest
+
+import (
+	"flag"
+	"path"
+	"strings"
+)
+
+// SnapshotUpdate selects the tests whose baselines are rewritten from their
+// fresh capture instead of being compared against it.
+type SnapshotUpdate struct {
+	// All updates every baseline
+	All bool
+
+	// Patterns are path.Match globs matched against test names; a plain name
+	// selects exactly that test
+	Patterns []string
+
+	// Tags select tests having any of these tags
+	Tags []string
+}
+
+// Enabled reports whether any baseline is selected for update.
+func (u SnapshotUpdate) Enabled() bool {
+	return u.All || len(u.Patterns) > 0 || len(u.Tags) > 0
+}
+
+// Matches reports whether the baseline of a test with the given name and
+// tags is selected. Patterns also match the sanitized name used for the
+// baseline file, so "login_form" selects the test "Login Form".
+func (u SnapshotUpdate) Matches(name string, tags []string) bool {
+	if u.All {
+		return true
+	}
+	for _, pattern := range u.Patterns {
+		for _, candidate := range []string{name, sanitizeFilename(name)} {
+			if ok, _ := path.Match(pattern, candidate); ok {
+				return true
+			}
+		}
+	}
+	for _, tag := range u.Tags {
+		if contains(tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// PatternFlag returns a flag value for "-update-snapshots[=patterns]". The
+// bare flag selects every baseline; a comma-separated list of globs selects
+// the matching test names only.
+func (u *SnapshotUpdate) PatternFlag() flag.Value {
+	return (*updatePatternFlag)(u)
+}
+
+// TagFlag returns a flag value for "-update-tag=tags", selecting the tests
+// having any of the comma-separated tags.
+func (u *SnapshotUpdate) TagFlag() flag.Value {
+	return (*updateTagFlag)(u)
+}
+
+type updatePatternFlag SnapshotUpdate
+
+func (f *updatePatternFlag) IsBoolFlag() bool { return true }
+
+func (f *updatePatternFlag) String() string {
+	if f == nil || f.All {
+		return ""
+	}
+	return strings.Join(f.Patterns, ",")
+}
+
+func (f *updatePatternFlag) Set(value string) error {
+	switch value {
+	case "true":
+		f.All = true
+	case "false":
+		f.All, f.Patterns = false, nil
+	default:
+		f.Patterns = append(f.Patterns, splitList(value)...)
+	}
+	return nil
+}
+
+type updateTagFlag SnapshotUpdate
+
+func (f *updateTagFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(f.Tags, ",")
+}
+
+func (f *updateTagFlag) Set(value string) error {
+	f.Tags = append(f.Tags, splitList(value)...)
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,318 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Defaults of UploadOptions.
+const (
+	DefaultUploadWorkers   = 4
+	DefaultUploadRetries   = 3
+	DefaultUploadChunkSize = 8 << 20
+)
+
+// UploadJournal is the file in a run directory that records the progress of
+// an interrupted upload, so the next UploadRun continues where it stopped.
+const UploadJournal = ".upload.json"
+
+// uploadRetryDelay is the wait before the first retry; it doubles with each
+// further attempt.
+var uploadRetryDelay = time.Second
+
+// UploadOptions tunes how UploadRunWithOptions pushes files.
+type UploadOptions struct {
+	// Workers is how many files are uploaded at once (default:
+	// DefaultUploadWorkers)
+	Workers int
+
+	// Retries is how often a failed file or chunk is retried, with
+	// exponential backoff (default: DefaultUploadRetries; negative disables)
+	Retries int
+
+	// ChunkSize splits larger files into parts uploaded one at a time, when
+	// the storage is a ChunkedStorage (default: DefaultUploadChunkSize; S3
+	// needs at least 5 MiB)
+	ChunkSize int
+
+	// BytesPerSecond limits the upload bandwidth of all workers together
+	// (0: unlimited)
+	BytesPerSecond int64
+}
+
+func (o UploadOptions) withDefaults() UploadOptions {
+	if o.Workers <= 0 {
+		o.Workers = DefaultUploadWorkers
+	}
+	if o.Retries == 0 {
+		o.Retries = DefaultUploadRetries
+	}
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = DefaultUploadChunkSize
+	}
+	return o
+}
+
+// uploadJournal records the files uploaded so far and the parts of
+// unfinished chunked uploads, keyed by storage key.
+type uploadJournal struct {
+	Files   map[string]journalFile    `json:"files"`
+	Chunked map[string]*journalChunks `json:"chunked,omitempty"`
+
+	mu   sync.Mutex
+	path string
+}
+
+// journalFile identifies the version of a file that was uploaded.
+type journalFile struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+func (f journalFile) same(other journalFile) bool {
+	return f.Size == other.Size && f.ModTime.Equal(other.ModTime)
+}
+
+// journalChunks is the state of a chunked upload.
+type journalChunks struct {
+	journalFile
+	UploadID  string   `json:"upload_id"`
+	ChunkSize int      `json:"chunk_size"`
+	Tags      []string `json:"tags"`
+}
+
+func loadUploadJournal(runDir string) *uploadJournal {
+	journal := &uploadJournal{path: filepath.Join(runDir, UploadJournal)}
+	if data, err := os.ReadFile(journal.path); err == nil {
+		json.Unmarshal(data, journal)
+	}
+	if journal.Files == nil {
+		journal.Files = make(map[string]journalFile)
+	}
+	if journal.Chunked == nil {
+		journal.Chunked = make(map[string]*journalChunks)
+	}
+	return journal
+}
+
+// update changes the journal and saves it. A journal that cannot be saved
+// only costs the ability to resume.
+func (j *uploadJournal) update(change func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	change()
+	if data, err := json.Marshal(j); err == nil {
+		os.WriteFile(j.path, data, 0644)
+	}
+}
+
+// uploaded reports whether the journal has this version of the file.
+func (j *uploadJournal) uploaded(key string, file journalFile) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	done, ok := j.Files[key]
+	return ok && done.same(file)
+}
+
+// rateLimiter spreads writes so they average at most rate bytes per second.
+type rateLimiter struct {
+	rate int64
+	mu   sync.Mutex
+	next time.Time
+}
+
+// wait blocks until n more bytes may be sent.
+func (l *rateLimiter) wait(n int) {
+	if l == nil || l.rate <= 0 {
+		return
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	start := l.next
+	l.next = l.next.Add(time.Duration(float64(n) / float64(l.rate) * float64(time.Second)))
+	l.mu.Unlock()
+	time.Sleep(time.Until(start))
+}
+
+// UploadRunWithOptions copies a run directory to storage below
+// "runs/<run>/" like UploadRun, with several files in flight, retries with
+// backoff and an optional bandwidth limit. Large files are uploaded in
+// chunks when storage supports it. Progress is journaled in the run
+// directory, so running it again after a failure or interruption uploads
+// only what is missing, continuing chunked uploads from their last part.
+// Files that keep failing don't stop the others; their errors are returned
+// together. It returns the number of files uploaded by this call.
+func UploadRunWithOptions(storage Storage, runDir string, opts UploadOptions) (int, error) {
+	opts = opts.withDefaults()
+	prefix := path.Join(storageRuns, filepath.Base(filepath.Clean(runDir)))
+	journal := loadUploadJournal(runDir)
+	limiter := &rateLimiter{rate: opts.BytesPerSecond}
+
+	type job struct {
+		path, key string
+		file      journalFile
+	}
+	jobs := make([]job, 0)
+	err := filepath.WalkDir(runDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || p == journal.path {
+			return err
+		}
+		rel, err := filepath.Rel(runDir, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		key := path.Join(prefix, filepath.ToSlash(rel))
+		file := journalFile{Size: info.Size(), ModTime: info.ModTime()}
+		if !journal.uploaded(key, file) {
+			jobs = append(jobs, job{path: p, key: key, file: file})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		mu       sync.Mutex
+		uploaded int
+		errs     []error
+		wg       sync.WaitGroup
+	)
+	queue := make(chan job)
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				err := uploadFile(storage, j.path, j.key, j.file, opts, journal, limiter)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to upload %s: %w", j.key, err))
+				} else {
+					uploaded++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, j := range jobs {
+		queue <- j
+	}
+	close(queue)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return uploaded, errors.Join(errs...)
+	}
+	// Nothing left to resume
+	os.Remove(journal.path)
+	return uploaded, nil
+}
+
+// uploadFile uploads one file, in chunks when it is large and storage
+// allows, and records it in the journal.
+func uploadFile(storage Storage, filePath, key string, file journalFile, opts UploadOptions, journal *uploadJournal, limiter *rateLimiter) error {
+	chunked, ok := storage.(ChunkedStorage)
+	if !ok || file.Size <= int64(opts.ChunkSize) {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		err = retry(opts.Retries, func() error {
+			limiter.wait(len(data))
+			return storage.Put(key, data)
+		})
+		if err != nil {
+			return err
+		}
+		journal.update(func() { journal.Files[key] = file })
+		return nil
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Continue an earlier upload of the same version of the file
+	journal.mu.Lock()
+	progress := journal.Chunked[key]
+	journal.mu.Unlock()
+	if progress == nil || !progress.same(file) || progress.ChunkSize != opts.ChunkSize {
+		progress = &journalChunks{journalFile: file, ChunkSize: opts.ChunkSize}
+	}
+	if progress.UploadID == "" {
+		err := retry(opts.Retries, func() (err error) {
+			progress.UploadID, err = chunked.BeginChunked(key)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		journal.update(func() { journal.Chunked[key] = progress })
+	}
+
+	buf := make([]byte, opts.ChunkSize)
+	for n := len(progress.Tags) + 1; int64(n-1)*int64(opts.ChunkSize) < file.Size; n++ {
+		size, err := f.ReadAt(buf, int64(n-1)*int64(opts.ChunkSize))
+		if err != nil && err != io.EOF {
+			return err
+		}
+		var tag string
+		err = retry(opts.Retries, func() (err error) {
+			limiter.wait(size)
+			tag, err = chunked.PutChunk(key, progress.UploadID, n, buf[:size])
+			return err
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			// The upload expired; start over on the next attempt
+			journal.update(func() { delete(journal.Chunked, key) })
+		}
+		if err != nil {
+			return err
+		}
+		journal.update(func() { progress.Tags = append(progress.Tags, tag) })
+	}
+
+	err = retry(opts.Retries, func() error {
+		return chunked.CompleteChunked(key, progress.UploadID, progress.Tags)
+	})
+	if err != nil {
+		return err
+	}
+	journal.update(func() {
+		delete(journal.Chunked, key)
+		journal.Files[key] = file
+	})
+	return nil
+}
+
+// retry calls fn until it succeeds or has been retried retries times,
+// doubling the wait between attempts. Missing objects are not retried.
+func retry(retries int, fn func() error) error {
+	delay := uploadRetryDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
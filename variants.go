@@ -0,0 +1,75 @@
+package fynetest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+// VariantSeparator separates the test name from the variant suffixes in
+// baseline names, as in "button@dark@375x667.png".
+const VariantSeparator = "@"
+
+// BaselineName returns the file name, without extension, of the baseline of
+// test. With VariantBaselines it carries the theme, window size and, when
+// not 1, the scale the test renders with, plus "gl" for GL captures, so a
+// test run under several themes or sizes, e.g. by run profiles, keeps one
+// baseline per variant.
+func (r *Runner) BaselineName(test Test) string {
+	base := sanitizeFilename(test.Name)
+	if !r.VariantBaselines {
+		return base
+	}
+	name := base
+	for _, suffix := range r.variantSuffixes(test) {
+		// Names made by ScaleVariants already end in their scale
+		if !strings.HasSuffix(base, VariantSeparator+suffix) {
+			name += VariantSeparator + suffix
+		}
+	}
+	return name
+}
+
+// variantSuffixes returns the theme, size and scale parts of the variant
+// name of test, resolving the runner's defaults like render does.
+func (r *Runner) variantSuffixes(test Test) []string {
+	th := test.Theme
+	if th == nil {
+		th = r.DefaultTheme
+	}
+	size := r.DefaultSize
+	if test.Size != nil {
+		size = *test.Size
+	}
+
+	suffixes := []string{variantThemeName(th), fmt.Sprintf("%gx%g", size.Width, size.Height)}
+	if test.Scale > 0 && test.Scale != 1 {
+		suffixes = append(suffixes, fmt.Sprintf("%gx", test.Scale))
+	}
+	if path := r.renderPath(test); path != RenderSoftware {
+		suffixes = append(suffixes, string(path))
+	}
+	return suffixes
+}
+
+// variantThemeName names a theme for baseline names. Custom themes are
+// named by their Name method when they have one, or else by their type, so
+// two custom themes don't share baselines.
+func variantThemeName(th fyne.Theme) string {
+	if name := getThemeName(th); name != "custom" {
+		return name
+	}
+	if named, ok := th.(interface{ Name() string }); ok && named.Name() != "" {
+		return sanitizeFilename(strings.ToLower(named.Name()))
+	}
+	t := reflect.TypeOf(th)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return "custom"
+	}
+	return strings.ToLower(t.Name())
+}
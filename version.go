@@ -0,0 +1,92 @@
+package fynetest
+
+import (
+	"encoding/json"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetaSuffix is appended to a baseline path to name its metadata file.
+const MetaSuffix = ".meta.json"
+
+// MetadataBaselineFyneVersion is set in Result.Metadata to the fyne version
+// of a baseline created with another fyne minor version than the run's.
+const MetadataBaselineFyneVersion = "baseline_fyne_version"
+
+// fynePath is the module path of the fyne library.
+const fynePath = "fyne.io/fyne/v2"
+
+var (
+	fyneVersionOnce sync.Once
+	fyneVersion     string
+)
+
+// FyneVersion returns the version of the fyne module linked into the test
+// binary, e.g. "v2.4.3", or "" when it cannot be determined.
+func FyneVersion() string {
+	fyneVersionOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		for _, dep := range info.Deps {
+			if dep.Path != fynePath {
+				continue
+			}
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				dep = dep.Replace
+			}
+			fyneVersion = dep.Version
+		}
+	})
+	return fyneVersion
+}
+
+// BaselineMeta records how a baseline was created.
+type BaselineMeta struct {
+	FyneVersion string    `json:"fyne_version,omitempty"`
+	Created     time.Time `json:"created"`
+}
+
+// ReadBaselineMeta returns the metadata stored next to a baseline. It
+// reports false for baselines written before metadata was recorded.
+func ReadBaselineMeta(path string) (BaselineMeta, bool) {
+	var meta BaselineMeta
+	data, err := os.ReadFile(path + MetaSuffix)
+	if err != nil || json.Unmarshal(data, &meta) != nil {
+		return BaselineMeta{}, false
+	}
+	return meta, true
+}
+
+func writeBaselineMeta(path string) error {
+	data, err := json.MarshalIndent(BaselineMeta{FyneVersion: FyneVersion(), Created: time.Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+MetaSuffix, append(data, '\n'), 0644)
+}
+
+// CrossesFyneMinor reports whether the baseline was created with a fyne
+// minor version other than current. Upgrades across minor versions often
+// change rendering, so mismatches may come from the upgrade rather than
+// from the code under test. Unknown versions never cross.
+func (m BaselineMeta) CrossesFyneMinor(current string) bool {
+	baseline, now := minorVersion(m.FyneVersion), minorVersion(current)
+	return baseline != "" && now != "" && baseline != now
+}
+
+// minorVersion returns the "vMAJOR.MINOR" prefix of a semantic version.
+func minorVersion(version string) string {
+	if !strings.HasPrefix(version, "v") {
+		return ""
+	}
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0] + "." + parts[1]
+}
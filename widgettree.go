@@ -0,0 +1,173 @@
+package fynetest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+
+	"fyne.io/fyne/v2"
+)
+
+// WidgetTree is a captured widget tree, the one representation that
+// selectors, ignore masks, focus regions, structural diffs and exports
+// share. Its JSON form, written by Marshal and by SaveWidgetTree next to
+// baselines, is the root WidgetNode; new fields are only ever added to it.
+type WidgetTree struct {
+	Root *WidgetNode
+}
+
+// NewWidgetTree returns the tree rooted at root, such as a Result's
+// WidgetTree or the node returned by CaptureWidgetTree.
+func NewWidgetTree(root *WidgetNode) *WidgetTree {
+	return &WidgetTree{Root: root}
+}
+
+// Tree returns the widget tree recorded with the capture, or nil without
+// one.
+func (r Result) Tree() *WidgetTree {
+	if r.WidgetTree == nil {
+		return nil
+	}
+	return NewWidgetTree(r.WidgetTree)
+}
+
+// WidgetVisit is a node met by WidgetTree.Walk.
+type WidgetVisit struct {
+	Node   *WidgetNode
+	Parent *WidgetNode
+
+	// Path locates the node as WidgetChange paths do, e.g.
+	// "fyne.Container/widget.Button#1"
+	Path string
+
+	// Position is the node's top-left corner on the canvas, rather than
+	// relative to its parent
+	Position fyne.Position
+
+	// Depth is 0 for the root
+	Depth int
+}
+
+// Region returns the canvas area of the visited node.
+func (v WidgetVisit) Region() Region {
+	return Region{X: v.Position.X, Y: v.Position.Y, Width: v.Node.Size.Width, Height: v.Node.Size.Height}
+}
+
+// Walk calls fn for every node of the tree, parents before their children
+// and siblings in order. Returning false from fn skips the node's children.
+func (t *WidgetTree) Walk(fn func(WidgetVisit) bool) {
+	if t == nil {
+		return
+	}
+	var walk func(visit WidgetVisit)
+	walk = func(visit WidgetVisit) {
+		if !fn(visit) {
+			return
+		}
+		keys, byKey := keyNodes(visit.Node.Children)
+		for _, key := range keys {
+			child := byKey[key]
+			walk(WidgetVisit{
+				Node:     child,
+				Parent:   visit.Node,
+				Path:     joinPath(visit.Path, key),
+				Position: fyne.NewPos(visit.Position.X+child.Position.X, visit.Position.Y+child.Position.Y),
+				Depth:    visit.Depth + 1,
+			})
+		}
+	}
+	if t.Root != nil {
+		keys, _ := keyNodes([]*WidgetNode{t.Root})
+		walk(WidgetVisit{Node: t.Root, Path: keys[0], Position: t.Root.Position})
+	}
+}
+
+// Find returns the nodes the selector matches, hidden ones included, in
+// tree order.
+func (t *WidgetTree) Find(selector string) ([]*WidgetNode, error) {
+	sel, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*WidgetNode, 0)
+	if t != nil {
+		sel.match(t.Root, false, func(node *WidgetNode, _ fyne.Position) {
+			nodes = append(nodes, node)
+		})
+	}
+	return nodes, nil
+}
+
+// Count returns the number of nodes in the tree.
+func (t *WidgetTree) Count() int {
+	if t == nil {
+		return 0
+	}
+	return t.Root.Count()
+}
+
+// Diff returns the widgets added, removed or changed from t to actual, as
+// DiffWidgetTrees does.
+func (t *WidgetTree) Diff(actual *WidgetTree) []WidgetChange {
+	var expectedRoot, actualRoot *WidgetNode
+	if t != nil {
+		expectedRoot = t.Root
+	}
+	if actual != nil {
+		actualRoot = actual.Root
+	}
+	return DiffWidgetTrees(expectedRoot, actualRoot)
+}
+
+// Hash returns a hex SHA-256 of the structure of the tree: the type, name
+// and visibility of every node and how they nest. Text, properties and
+// geometry are left out, so the hash changes when widgets are added,
+// removed, moved between containers or shown and hidden, but not when a
+// label's text or the window size does.
+func (t *WidgetTree) Hash() string {
+	h := sha256.New()
+	if t != nil {
+		hashNode(h, t.Root)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashNode writes the structure of node to h, every string length-prefixed
+// so distinct trees can't hash alike.
+func hashNode(h hash.Hash, node *WidgetNode) {
+	if node == nil {
+		fmt.Fprint(h, "-;")
+		return
+	}
+	fmt.Fprintf(h, "%d:%s%d:%s%t%d(", len(node.Type), node.Type, len(node.Name), node.Name, node.Hidden, len(node.Children))
+	for _, child := range node.Children {
+		hashNode(h, child)
+	}
+	fmt.Fprint(h, ")")
+}
+
+// Marshal returns the tree as indented JSON.
+func (t *WidgetTree) Marshal() ([]byte, error) {
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// UnmarshalWidgetTree parses a tree written by Marshal or SaveWidgetTree.
+func UnmarshalWidgetTree(data []byte) (*WidgetTree, error) {
+	var tree WidgetTree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	return &tree, nil
+}
+
+// MarshalJSON encodes the tree as its root node.
+func (t *WidgetTree) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Root)
+}
+
+// UnmarshalJSON decodes a tree encoded by MarshalJSON.
+func (t *WidgetTree) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &t.Root)
+}